@@ -0,0 +1,151 @@
+// Command backtest replays recorded market windows through the strategy and
+// reports trades, per-window P&L, hit rate and drawdown.
+//
+// Windows come from the raw feed recordings in -recordings (default
+// RECORD_DIR), which replay the full books. Without recordings the price
+// tracker logs matched by -logs are replayed instead; they only hold the top
+// of book, so fills ignore depth.
+//
+// Strategy and risk parameters come from the same environment / .env as the
+// bot, so configs can be compared offline by changing env vars between runs:
+//
+//	BASE_HURDLE=0.04 go run ./cmd/backtest -recordings data/raw -from 2024-01-01T00:00:00Z
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Polybot/internal/backtest"
+	"Polybot/internal/config"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/model"
+	"Polybot/internal/service"
+)
+
+func main() {
+	recordDir := flag.String("recordings", "", "raw recording directory to replay (default RECORD_DIR)")
+	from := flag.String("from", "", "replay recordings received at or after this RFC 3339 time")
+	to := flag.String("to", "", "replay recordings received before this RFC 3339 time")
+	logsGlob := flag.String("logs", "logs/prices_*.json", "glob of price tracker logs to replay when there are no recordings")
+	jsonOut := flag.String("json", "", "write the full report as JSON to this file")
+	showTrades := flag.Bool("trades", false, "print every simulated trade")
+	verbose := flag.Bool("v", false, "log strategy decisions")
+	flag.Parse()
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	if err := run(*recordDir, *from, *to, *logsGlob, *jsonOut, *showTrades, logger); err != nil {
+		fmt.Fprintln(os.Stderr, "backtest:", err)
+		os.Exit(1)
+	}
+}
+
+func run(recordDir, from, to, logsGlob, jsonOut string, showTrades bool, logger *slog.Logger) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if recordDir == "" {
+		recordDir = cfg.RecordDir
+	}
+	windows, err := loadWindows(recordDir, from, to, logsGlob)
+	if err != nil {
+		return err
+	}
+
+	engine := backtest.NewEngine(cfg, buildPricingModel(cfg, logger), logger)
+	report, err := engine.Run(context.Background(), windows)
+	if err != nil {
+		return err
+	}
+
+	if err := report.WriteText(os.Stdout, showTrades); err != nil {
+		return err
+	}
+
+	if jsonOut != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal report: %w", err)
+		}
+		if err := os.WriteFile(jsonOut, data, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", jsonOut, err)
+		}
+	}
+	return nil
+}
+
+// loadWindows replays the raw recordings in recordDir if there are any in
+// range, falling back to the tracker logs matched by logsGlob.
+func loadWindows(recordDir, from, to, logsGlob string) ([]backtest.Window, error) {
+	if recordDir != "" {
+		fromTime, err := parseTime(from)
+		if err != nil {
+			return nil, fmt.Errorf("bad -from: %w", err)
+		}
+		toTime, err := parseTime(to)
+		if err != nil {
+			return nil, fmt.Errorf("bad -to: %w", err)
+		}
+		files, err := recorder.ListFiles(recordDir, fromTime, toTime)
+		if err != nil {
+			return nil, fmt.Errorf("list recordings in %s: %w", recordDir, err)
+		}
+		if len(files) > 0 {
+			return backtest.LoadRecordings(recordDir, fromTime, toTime)
+		}
+	}
+
+	paths, err := filepath.Glob(logsGlob)
+	if err != nil {
+		return nil, fmt.Errorf("bad glob %q: %w", logsGlob, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recordings in %q and no tracker logs match %q", recordDir, logsGlob)
+	}
+	return backtest.LoadTrackerLogs(paths)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// buildPricingModel mirrors the bot's model selection.
+func buildPricingModel(cfg *config.Config, logger *slog.Logger) service.PricingModel {
+	if cfg.ModelParamsFile != "" {
+		source, err := model.NewFileBasedMixtureParamSource(cfg.ModelParamsFile)
+		if err != nil {
+			logger.Warn("failed to load model params, falling back to dynamic gaussian",
+				"file", cfg.ModelParamsFile, "error", err)
+		} else {
+			return model.NewMixtureModel("", source)
+		}
+	}
+
+	m := model.NewDynamicGaussianModel(0.001, cfg.DefaultModelUncertainty)
+	if cfg.CalibrationFile != "" {
+		calMap, err := model.NewCalibrationMapFromFile(cfg.CalibrationFile)
+		if err != nil {
+			logger.Warn("failed to load calibration file, using uncalibrated model",
+				"file", cfg.CalibrationFile, "error", err)
+		} else {
+			m.Calibration = calMap
+		}
+	}
+	return m
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	"Polybot/internal/config"
 	infraChainlink "Polybot/internal/infra/chainlink"
 	infraLogger "Polybot/internal/infra/logger"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/ports"
 )
 
 // Chainlink Data Streams feed IDs (mainnet v3 feeds)
@@ -40,27 +41,46 @@ func main() {
 		cancel()
 	}()
 
-	// Initialize Chainlink — only register the feed for our asset
+	// Initialize Chainlink — register a feed for every asset we trade
 	refStream, err := infraChainlink.NewStream(infraChainlink.StreamConfig{
 		ApiKey:    cfg.ChainlinkUserID,
 		ApiSecret: cfg.ChainlinkSecret,
 		RestURL:   cfg.ChainlinkRestURL,
 		WsURL:     cfg.ChainlinkWSURL,
+
+		HA:           cfg.ChainlinkHA,
+		StallTimeout: cfg.ChainlinkStall,
+		MaxGapFill:   cfg.ChainlinkGapFill,
 	}, logger)
 	if err != nil {
 		logger.Error("failed to create chainlink stream", "error", err)
 		os.Exit(1)
 	}
 
-	asset := strings.ToUpper(cfg.Market)
-	feedID, ok := chainlinkFeeds[asset]
-	if !ok {
-		logger.Error("no chainlink feed for asset", "asset", asset)
-		os.Exit(1)
+	for _, asset := range cfg.Assets() {
+		feedID, ok := chainlinkFeeds[asset]
+		if !ok {
+			logger.Error("no chainlink feed for asset", "asset", asset)
+			os.Exit(1)
+		}
+		if err := refStream.RegisterFeedFromString(asset, feedID); err != nil {
+			logger.Error("failed to register feed", "asset", asset, "error", err)
+			os.Exit(1)
+		}
 	}
-	if err := refStream.RegisterFeedFromString(asset, feedID); err != nil {
-		logger.Error("failed to register feed", "asset", asset, "error", err)
-		os.Exit(1)
+
+	// Raw feed recorder: captures Chainlink reports and Polymarket WS messages
+	var rawRecorder ports.RawRecorder
+	if cfg.RecordDir != "" {
+		rec, err := recorder.New(cfg.RecordDir, cfg.RecordRotate, ports.SystemClock{}, logger)
+		if err != nil {
+			logger.Error("failed to create raw recorder", "dir", cfg.RecordDir, "error", err)
+			os.Exit(1)
+		}
+		defer rec.Close()
+		rawRecorder = rec
+		refStream.SetRecorder(rec)
+		logger.Info("raw feed recording enabled", "dir", cfg.RecordDir, "rotate", cfg.RecordRotate)
 	}
 
 	if cfg.Mode == "debug" || os.Getenv("DEBUG_CHAINLINK") == "1" {
@@ -68,9 +88,34 @@ func main() {
 		return
 	}
 
-	logger.Info("bot config", "market", cfg.Market, "interval", cfg.Interval, "mode", cfg.Mode)
+	streams := make([]string, 0, len(cfg.Markets))
+	for _, m := range cfg.Markets {
+		streams = append(streams, m.Key())
+	}
+	logger.Info("bot config", "markets", streams, "mode", cfg.Mode)
+
+	store, err := buildStorage(cfg, logger)
+	if err != nil {
+		logger.Error("failed to open storage", "backend", cfg.StorageBackend, "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := store.close(); err != nil {
+			logger.Error("failed to close storage", "error", err)
+		}
+	}()
+
+	application := buildApp(cfg, refStream, rawRecorder, store, logger)
+
+	// SIGUSR1 manually resets a tripped circuit breaker
+	resetCh := make(chan os.Signal, 1)
+	signal.Notify(resetCh, syscall.SIGUSR1)
+	go func() {
+		for range resetCh {
+			application.ResetBreaker("SIGUSR1")
+		}
+	}()
 
-	application := buildApp(cfg, refStream, logger)
 	if err := application.Run(ctx); err != nil {
 		logger.Error("application error", "error", err)
 		os.Exit(1)
@@ -2,41 +2,10 @@ package main
 
 import (
 	"context"
-	"log/slog"
 
 	"Polybot/internal/domain"
-	"Polybot/internal/ports"
 )
 
-// fixedCostModel returns a constant cost estimate.
-type fixedCostModel struct {
-	cost float64
-}
-
-func (f *fixedCostModel) EstimateAllInCost(_ context.Context, _ domain.MarketID) (float64, error) {
-	return f.cost, nil
-}
-
-// paperExecutionProvider logs trades without executing.
-type paperExecutionProvider struct {
-	logger *slog.Logger
-}
-
-func (p *paperExecutionProvider) BuyUp(_ context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
-	p.logger.Info("[PAPER] buy UP", "market", marketID, "max_price", maxPrice, "size_usd", sizeUSD)
-	return ports.OrderResult{Filled: true, Price: maxPrice, Size: sizeUSD / maxPrice}, nil
-}
-
-func (p *paperExecutionProvider) BuyDown(_ context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
-	p.logger.Info("[PAPER] buy DOWN", "market", marketID, "max_price", maxPrice, "size_usd", sizeUSD)
-	return ports.OrderResult{Filled: true, Price: maxPrice, Size: sizeUSD / maxPrice}, nil
-}
-
-func (p *paperExecutionProvider) ClosePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
-	p.logger.Info("[PAPER] close position", "market", marketID, "side", side)
-	return nil
-}
-
 // stubMarketDataProvider is a placeholder until Polymarket adapter is complete.
 type stubMarketDataProvider struct{}
 
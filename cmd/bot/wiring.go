@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"Polybot/internal/app"
 	"Polybot/internal/config"
@@ -17,34 +20,43 @@ import (
 	"Polybot/internal/strategy"
 )
 
-func buildApp(cfg *config.Config, refStream *infraChainlink.Stream, logger *slog.Logger) *app.App {
-	eventRepo := storage.NewInMemoryEventRepo(logger)
-	positionRepo := storage.NewInMemoryPositionRepo()
+func buildApp(cfg *config.Config, refStream *infraChainlink.Stream, rawRecorder ports.RawRecorder, store *repositories, logger *slog.Logger) *app.App {
+	eventRepo := store.events
 
 	registry := service.NewMarketRegistry()
 	refAnalytics := service.NewReferenceAnalyticsService(5000)
-	positionSvc := service.NewPositionService(positionRepo)
+	positionSvc := service.NewPositionService(store.positions)
+
+	// Recover positions persisted by a previous run
+	if err := positionSvc.LoadFromRepo(context.Background()); err != nil {
+		logger.Error("failed to load positions from storage", "error", err)
+		os.Exit(1)
+	}
 
 	pricingModel := buildPricingModel(cfg, refAnalytics, logger)
 
-	costModel := &fixedCostModel{cost: 0.01}
+	// Taker costs: the market's fee rate plus slippage on the live books
+	clobClient := buildClobClient(cfg, logger)
+	costModel := service.NewTakerCostModel(registry, clobClient, service.TakerCostConfig{
+		RefSizeUSD: cfg.CostRefSizeUSD,
+	})
 
 	signalSvc := service.NewSignalService(
 		costModel,
+		ports.SystemClock{},
 		service.SignalConfig{
-			BaseHurdle: cfg.BaseHurdle,
-			MaxSizeUSD: cfg.MaxPositionUSDPerMarket,
+			BaseHurdle:  cfg.BaseHurdle,
+			MaxSizeUSD:  cfg.MaxPositionUSDPerMarket,
+			ExitEnabled: cfg.ExitEnabled,
+			ExitHurdle:  cfg.ExitHurdle,
 		},
 	)
 
 	// Hedge engine: monitors inventory, buys opposite side to improve guaranteed floor
-	hedgeEngine := service.NewHedgeEngine(positionSvc, costModel, service.HedgeConfig{
+	hedgeEngine := service.NewHedgeEngine(positionSvc, costModel, ports.SystemClock{}, service.HedgeConfig{
 		HedgeHurdle: cfg.HedgeHurdle,
 	})
 
-	// Persistence filter: require edge across N consecutive evaluations
-	persistenceFilter := service.NewPersistenceFilter(cfg.PersistenceCount, cfg.HedgePersistenceCount)
-
 	riskSvc := service.NewRiskService(service.RiskConfig{
 		MaxPositionUSDPerMarket: cfg.MaxPositionUSDPerMarket,
 		MaxTotalExposureUSD:     cfg.MaxTotalExposureUSD,
@@ -52,68 +64,283 @@ func buildApp(cfg *config.Config, refStream *infraChainlink.Stream, logger *slog
 		FractionalKelly:         cfg.FractionalKelly,
 		MinTradeSizeUSD:         cfg.MinTradeSizeUSD,
 		MinTradeShares:          cfg.MinTradeShares,
+		MaxSlippageEdgeFraction: cfg.MaxSlippageEdgeFraction,
 		MaxImbalanceShares:      cfg.MaxImbalanceShares,
 		MinGuaranteedFloor:      cfg.MinGuaranteedFloor,
 		MaxWorstCaseLoss:        cfg.MaxWorstCaseLoss,
 	})
+	riskSvc.Breaker = service.NewCircuitBreaker(service.BreakerConfig{
+		MaxDailyLossUSD:      cfg.BreakerMaxDailyLossUSD,
+		MaxConsecutiveLosses: cfg.BreakerMaxConsecutiveLosses,
+		MaxErrorRate:         cfg.BreakerMaxErrorRate,
+		ErrorWindow:          cfg.BreakerErrorWindow,
+		Cooldown:             cfg.BreakerCooldown,
+	}, eventRepo, ports.SystemClock{}, logger)
 
-	clobClient := buildClobClient(cfg, logger)
-	execProvider := buildExecutionProvider(cfg, clobClient, registry, logger)
+	// Order manager: tracks submissions until fills are booked, polls and
+	// cancels orders that stay unconfirmed
+	orders := service.NewOrderManager(service.OrderManagerConfig{
+		PollAfter:   cfg.OrderPollAfter,
+		CancelAfter: cfg.OrderCancelAfter,
+		ExpireAfter: cfg.OrderExpireAfter,
+	}, ports.SystemClock{}, logger)
+
+	execProvider := buildExecutionProvider(cfg, clobClient, registry, positionSvc, eventRepo, orders, logger)
+	if statusProvider, ok := execProvider.(ports.OrderStatusProvider); ok {
+		orders.SetStatusProvider(statusProvider)
+	}
 	execSvc := service.NewExecutionService(execProvider)
 
-	runner := strategy.NewStrategyRunner(
-		pricingModel,
-		signalSvc,
-		hedgeEngine,
-		persistenceFilter,
-		riskSvc,
-		execSvc,
-		positionSvc,
-		eventRepo,
-		ports.SystemClock{},
-		strategy.FreshnessConfig{
+	// Maker mode and the market maker strategy rest limit buys instead of
+	// taking the ask
+	var maker *service.MakerService
+	if cfg.ExecutionMode == "maker" || cfg.Strategy == "market_maker" {
+		if limitProvider, ok := execProvider.(ports.LimitOrderProvider); ok {
+			maker = service.NewMakerService(limitProvider, orders, service.MakerConfig{
+				TickSize:     cfg.MakerTickSize,
+				RepriceTicks: cfg.MakerRepriceTicks,
+			}, logger)
+		} else {
+			logger.Warn("execution provider does not support limit orders, using the taker fair value strategy", "mode", cfg.Mode, "strategy", cfg.Strategy)
+		}
+	}
+	imbalanceCfg := service.ImbalancePenaltyConfig{
+		Alpha: cfg.ImbalanceAlpha,
+		Beta:  cfg.ImbalanceBeta,
+	}
+	var quoter *service.Quoter
+	if cfg.Strategy == "market_maker" && maker != nil {
+		quoter = service.NewQuoter(service.QuoterConfig{
+			BaseHalfSpread:    cfg.MMBaseHalfSpread,
+			UncertaintyWeight: cfg.MMUncertaintyWeight,
+			VolatileWiden:     cfg.MMVolatileWiden,
+			TickSize:          cfg.MakerTickSize,
+		}, imbalanceCfg)
+	}
+
+	// One stream per (asset, interval): own market data feed and runner state,
+	// shared positions, risk, execution and bankroll.
+	streams := make([]*app.MarketStream, 0, len(cfg.Markets))
+	for _, spec := range cfg.Markets {
+		streamLogger := logger.With("stream", spec.Key())
+
+		freshness := strategy.FreshnessConfig{
 			MaxReferenceAge:  cfg.MaxReferenceAge,
 			MaxQuoteAge:      cfg.MaxQuoteAge,
 			MaxAllowedSpread: cfg.MaxAllowedSpread,
 			MinTickCount:     cfg.MinTickCount,
 			HedgeAfterPct:    cfg.HedgeAfterPct,
-		},
-		service.ImbalancePenaltyConfig{
-			Alpha: cfg.ImbalanceAlpha,
-			Beta:  cfg.ImbalanceBeta,
+		}
+
+		marketData := polymarket.NewMarketProvider(clobClient, spec.Asset, spec.Interval, streamLogger)
+		if rawRecorder != nil {
+			marketData.SetRecorder(rawRecorder)
+		}
+		if paper, ok := execProvider.(*polymarket.PaperExchange); ok {
+			paper.AddBookSource(marketData)
+		}
+		costModel.AddBookSource(marketData)
+
+		var runner strategy.Strategy
+		if quoter != nil {
+			mm := strategy.NewMarketMaker(
+				pricingModel,
+				quoter,
+				maker,
+				riskSvc,
+				execSvc,
+				positionSvc,
+				orders,
+				eventRepo,
+				ports.SystemClock{},
+				freshness,
+				strategy.MarketMakerConfig{
+					QuoteShares: cfg.MMQuoteShares,
+					FlattenSecs: cfg.MMFlattenSecs,
+				},
+				streamLogger,
+			)
+			mm.TradeCutoffSecs = spec.NoNewTradeCutoffSecs
+			runner = mm
+		} else {
+			// Persistence filter: require edge across N consecutive evaluations
+			persistenceFilter := service.NewPersistenceFilter(cfg.PersistenceCount, cfg.HedgePersistenceCount)
+
+			fv := strategy.NewStrategyRunner(
+				pricingModel,
+				signalSvc,
+				hedgeEngine,
+				persistenceFilter,
+				riskSvc,
+				execSvc,
+				positionSvc,
+				eventRepo,
+				ports.SystemClock{},
+				freshness,
+				imbalanceCfg,
+				streamLogger,
+			)
+			fv.TradeCutoffSecs = spec.NoNewTradeCutoffSecs
+			fv.Orders = orders
+			fv.Books = marketData
+			if cfg.ExecutionMode == "maker" {
+				fv.Maker = maker
+			}
+			runner = fv
+		}
+
+		streams = append(streams, &app.MarketStream{
+			Key:        spec.Key(),
+			Asset:      strings.ToUpper(spec.Asset),
+			Interval:   spec.Interval,
+			MarketData: marketData,
+			Runner:     runner,
+			Logger:     streamLogger,
+		})
+	}
+
+	// Settlement: Chainlink price at EndTime vs PriceToBeat, confirmed against Gamma
+	settlementSvc := service.NewSettlementService(
+		positionSvc,
+		refStream,
+		polymarket.NewGammaMarket(),
+		eventRepo,
+		ports.SystemClock{},
+		service.SettlementConfig{
+			PriceRetries:    5,
+			PriceRetryDelay: 2 * time.Second,
+			ConfirmInterval: 30 * time.Second,
+			ConfirmTimeout:  cfg.SettlementConfirmTimeout,
 		},
 		logger,
 	)
-
-	asset := strings.ToUpper(cfg.Market)
-	marketData := polymarket.NewMarketProvider(clobClient, cfg.Market, cfg.Interval, logger)
+	settlementSvc.SetListener(riskSvc.Breaker)
 
 	// Fill listener: in live mode, subscribes to Polymarket user WS for trade confirmations.
 	// In paper mode, fills are simulated immediately — no listener needed.
+	// Reconciler: in live mode, checks positions against the venue.
+	// Wallet: in live mode, caps sizes at the USDC balance and blocks orders
+	// the wallet can't fund.
 	var fillListener ports.FillListener
+	var reconciler *service.Reconciler
+	var wallet *service.WalletService
 	if cfg.Mode == "live" {
-		fillListener = polymarket.NewFillListener(clobClient, registry, positionSvc, logger)
+		listener := polymarket.NewFillListener(clobClient, registry, positionSvc, eventRepo, logger)
+		listener.SetOrderManager(orders)
+		if rawRecorder != nil {
+			listener.SetRecorder(rawRecorder)
+		}
+		fillListener = listener
 		logger.Info("fill listener enabled (live mode)")
+
+		reconciler = service.NewReconciler(service.ReconcilerConfig{
+			Interval:      cfg.ReconcileInterval,
+			Tolerance:     cfg.ReconcileTolerance,
+			MaxCorrection: cfg.ReconcileMaxCorrection,
+			TradeGrace:    cfg.ReconcileTradeGrace,
+		}, polymarket.NewPositionSource(clobClient), registry, positionSvc, eventRepo, ports.SystemClock{}, logger)
+		reconciler.SetOrderManager(orders)
+		reconciler.SetBreaker(riskSvc.Breaker)
+		// Fills sent while the user channel was down are never replayed
+		listener.OnConnState(func(ev polymarket.WSStateEvent) {
+			if ev.State == polymarket.WSConnected && ev.Reconnect {
+				reconciler.Trigger()
+			}
+		})
+
+		if source, err := polymarket.NewWalletSource(clobClient); err != nil {
+			logger.Warn("wallet checks disabled", "error", err)
+		} else {
+			wallet = service.NewWalletService(service.WalletConfig{
+				RefreshInterval: cfg.WalletRefreshInterval,
+			}, source, registry, ports.SystemClock{}, logger)
+			wallet.SetOrderManager(orders)
+			riskSvc.Wallet = wallet
+			execSvc.Wallet = wallet
+			if maker != nil {
+				maker.SetWallet(wallet)
+			}
+		}
+	}
+
+	// Redemption: merge settled pairs and redeem resolved tokens on-chain
+	var redemption *service.RedemptionService
+	if chain := buildChainBackend(cfg, logger); chain != nil {
+		redemption = service.NewRedemptionService(service.RedemptionConfig{
+			Interval:    cfg.RedeemInterval,
+			GiveUpAfter: cfg.RedeemGiveUpAfter,
+		}, chain, store.redemptions, ports.SystemClock{}, logger)
+		// Redeem what a previous run left queued
+		if err := redemption.LoadFromRepo(context.Background()); err != nil {
+			logger.Error("failed to load pending redemptions from storage", "error", err)
+		}
 	}
 
 	return &app.App{
 		Config: &app.AppConfig{
 			BankrollUSD: cfg.BankrollUSD,
-			Asset:       asset,
 			Mode:        cfg.Mode,
 		},
 		Registry:       registry,
 		RefAnalytics:   refAnalytics,
 		PositionSvc:    positionSvc,
-		Runner:         runner,
-		MarketData:     marketData,
+		Settlement:     settlementSvc,
+		Breaker:        riskSvc.Breaker,
+		Orders:         orders,
+		Reconciler:     reconciler,
+		Wallet:         wallet,
+		Redemption:     redemption,
+		Streams:        streams,
 		RefPriceStream: refStream,
-		PriceTracker:   tracker.NewPriceTracker(registry, refAnalytics, pricingModel, positionSvc, hedgeEngine, "logs", cfg.TrackerIntervalMs, logger),
+		PriceTracker:   tracker.NewPriceTracker(registry, refAnalytics, pricingModel, positionSvc, hedgeEngine, costModel, "logs", cfg.TrackerIntervalMs, logger),
 		FillListener:   fillListener,
 		Logger:         logger,
 	}
 }
 
+// repositories holds the event, position and redemption stores selected by
+// config.
+type repositories struct {
+	events      ports.EventRepository
+	positions   ports.PositionRepository
+	redemptions ports.RedemptionRepository
+	close       func() error
+}
+
+func buildStorage(cfg *config.Config, logger *slog.Logger) (*repositories, error) {
+	switch cfg.StorageBackend {
+	case "", "memory":
+		logger.Info("storage: in-memory (events, positions and pending redemptions are lost on restart)")
+		return &repositories{
+			events:      storage.NewInMemoryEventRepo(logger),
+			positions:   storage.NewInMemoryPositionRepo(),
+			redemptions: storage.NewInMemoryRedemptionRepo(),
+			close:       func() error { return nil },
+		}, nil
+	case "file":
+		store, err := storage.OpenFileStore(storage.FileStoreConfig{
+			Dir:       cfg.StorageDir,
+			Retention: cfg.StorageRetention,
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("storage: file", "dir", cfg.StorageDir, "retention", cfg.StorageRetention)
+		return &repositories{events: store, positions: store, redemptions: store, close: store.Close}, nil
+	case "postgres":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		store, err := storage.OpenPostgresStore(ctx, storage.PostgresConfig{DSN: cfg.PostgresDSN}, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("storage: postgres")
+		return &repositories{events: store, positions: store, redemptions: store, close: store.Close}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
 func buildClobClient(cfg *config.Config, logger *slog.Logger) *polymarket.ClobClient {
 	client, err := polymarket.NewClobClient(cfg.PrivateKey, polymarket.SignatureEOA, cfg.FunderAddress)
 	if err != nil {
@@ -130,13 +357,55 @@ func buildClobClient(cfg *config.Config, logger *slog.Logger) *polymarket.ClobCl
 	return client
 }
 
-func buildExecutionProvider(cfg *config.Config, client *polymarket.ClobClient, registry *service.MarketRegistry, logger *slog.Logger) ports.ExecutionProvider {
+// buildChainBackend returns the Conditional Tokens backend: on-chain in live
+// mode, simulated in paper mode. Nil disables merging and redemption.
+func buildChainBackend(cfg *config.Config, logger *slog.Logger) ports.ChainBackend {
+	if cfg.Mode != "live" {
+		chain := polymarket.NewSimCTF(logger)
+		chain.AutoResolve = true
+		return chain
+	}
+	if cfg.PolygonRPCURL == "" {
+		logger.Warn("POLYGON_RPC_URL not set, settled positions must be merged and redeemed by hand")
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	chain, err := polymarket.NewCTFBackend(ctx, cfg.PolygonRPCURL, cfg.PrivateKey, polymarket.POLYGON, logger)
+	if err != nil {
+		logger.Error("failed to connect to polygon, merging and redemption disabled", "error", err)
+		return nil
+	}
+	logger.Info("redemption enabled (live mode)")
+	return chain
+}
+
+func buildExecutionProvider(cfg *config.Config, client *polymarket.ClobClient, registry *service.MarketRegistry, positionSvc *service.PositionService, eventRepo ports.EventRepository, orders *service.OrderManager, logger *slog.Logger) ports.ExecutionProvider {
 	if cfg.Mode == "live" {
 		logger.Info("live mode — real execution enabled")
-		return polymarket.NewExecutionProvider(client, registry, logger)
+		provider := polymarket.NewExecutionProvider(client, registry, logger)
+		if cfg.RFQMinSizeUSD > 0 {
+			logger.Info("rfq routing enabled", "min_size_usd", cfg.RFQMinSizeUSD, "quote_wait", cfg.RFQQuoteWait)
+			return polymarket.NewRFQExecutionProvider(provider, polymarket.RFQRouteConfig{
+				MinSizeUSD: cfg.RFQMinSizeUSD,
+				QuoteWait:  cfg.RFQQuoteWait,
+			})
+		}
+		return provider
 	}
-	logger.Info("paper mode — simulated execution")
-	return &paperExecutionProvider{logger: logger}
+	logger.Info("paper mode — simulated execution against live order books",
+		"order_type", cfg.PaperOrderType,
+		"latency", cfg.PaperLatency,
+	)
+	// Paper fills are booked by a fill listener, as in live mode
+	fills := polymarket.NewFillListener(client, registry, positionSvc, eventRepo, logger)
+	fills.SetOrderManager(orders)
+	return polymarket.NewPaperExchange(client, registry, fills, polymarket.PaperExchangeConfig{
+		OrderType:      polymarket.OrderType(cfg.PaperOrderType),
+		Latency:        cfg.PaperLatency,
+		MinTradeShares: cfg.MinTradeShares,
+		FeeRateBps:     cfg.PaperFeeRateBps,
+	}, logger)
 }
 
 func buildPricingModel(cfg *config.Config, refAnalytics *service.ReferenceAnalyticsService, logger *slog.Logger) service.PricingModel {
@@ -3,13 +3,13 @@ package app
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"Polybot/internal/domain"
 	"Polybot/internal/infra/tracker"
 	"Polybot/internal/ports"
 	"Polybot/internal/service"
-	"Polybot/internal/strategy"
 )
 
 type App struct {
@@ -17,8 +17,13 @@ type App struct {
 	Registry       *service.MarketRegistry
 	RefAnalytics   *service.ReferenceAnalyticsService
 	PositionSvc    *service.PositionService
-	Runner         *strategy.StrategyRunner
-	MarketData     ports.MarketDataProvider
+	Settlement     *service.SettlementService
+	Breaker        *service.CircuitBreaker    // optional: reset manually via ResetBreaker
+	Orders         *service.OrderManager      // optional: sweeps unconfirmed orders
+	Reconciler     *service.Reconciler        // optional: bootstraps and reconciles positions (live mode)
+	Wallet         *service.WalletService     // optional: refreshes USDC balance and allowance (live mode)
+	Redemption     *service.RedemptionService // optional: merges and redeems settled positions
+	Streams        []*MarketStream            // one per (asset, interval)
 	RefPriceStream ports.ReferencePriceProvider
 	PriceTracker   *tracker.PriceTracker
 	FillListener   ports.FillListener // nil in paper mode
@@ -26,44 +31,74 @@ type App struct {
 }
 
 type AppConfig struct {
-	BankrollUSD float64
-	Asset       string // single asset (BTC, ETH, etc.)
+	BankrollUSD float64 // shared across all streams
 	Mode        string
 }
 
 func (a *App) Run(ctx context.Context) error {
+	streams := make([]string, 0, len(a.Streams))
+	for _, s := range a.Streams {
+		streams = append(streams, s.Key)
+	}
 	a.Logger.Info("starting polybot",
 		"mode", a.Config.Mode,
-		"asset", a.Config.Asset,
+		"streams", streams,
 		"bankroll", a.Config.BankrollUSD,
 	)
 
-	// Bootstrap: load existing positions from Polymarket API (live mode only).
-	// This must happen after market lifecycle resolves the first market so that
-	// token IDs are in the registry. We start it after a short delay below.
-	if a.FillListener != nil {
-		go func() {
-			// Wait briefly for market lifecycle to populate the registry
-			time.Sleep(8 * time.Second)
-			if err := a.FillListener.LoadPositionsFromAPI(ctx); err != nil {
-				a.Logger.Warn("failed to bootstrap positions from API", "error", err)
-			}
-		}()
+	// Position reconciliation (live mode): the first pass bootstraps positions
+	// from the venue once market lifecycle has put token IDs in the registry,
+	// later passes correct drift or halt trading.
+	if a.Reconciler != nil {
+		go a.Reconciler.Run(ctx)
+	}
+
+	// Wallet (live mode): keep the cached USDC balance and allowance fresh
+	// for sizing and pre-sign order checks
+	if a.Wallet != nil {
+		go a.Wallet.Run(ctx)
 	}
 
-	repriceCh := make(chan domain.RepriceEvent, 1024)
+	var wg sync.WaitGroup
 
-	// Stream Chainlink reference prices for our asset
-	go a.streamChainlinkTicks(ctx, a.Config.Asset, repriceCh)
+	for _, s := range a.Streams {
+		s.repriceCh = make(chan domain.RepriceEvent, 1024)
+	}
 
-	// Market lifecycle: resolve market, stream quotes via WS, roll on expiry
-	go a.marketLifecycle(ctx, repriceCh)
+	// Stream Chainlink reference prices once per asset; ticks fan out to
+	// every stream trading that asset.
+	for _, asset := range a.assets() {
+		go a.streamChainlinkTicks(ctx, asset)
+	}
+
+	for _, s := range a.Streams {
+
+		// Market lifecycle: resolve market, stream quotes via WS, roll on expiry
+		go a.marketLifecycle(ctx, s)
+
+		// Stream Polymarket quotes via WebSocket
+		go a.streamPolymarketQuotes(ctx, s)
+
+		// Periodic reprice ticker (catch time decay)
+		go a.timeDecayTicker(ctx, s)
+
+		// One reprice loop per stream — its runner owns cooldown and persistence state
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.repriceLoop(ctx, s)
+		}()
+	}
 
-	// Stream Polymarket quotes via WebSocket
-	go a.streamPolymarketQuotes(ctx, repriceCh)
+	// Order manager: poll, cancel and expire unconfirmed orders
+	if a.Orders != nil {
+		go a.Orders.Run(ctx)
+	}
 
-	// Periodic reprice ticker (catch time decay)
-	go a.timeDecayTicker(ctx, repriceCh)
+	// Redemption: redeem settled positions once their conditions resolve
+	if a.Redemption != nil {
+		go a.Redemption.Run(ctx)
+	}
 
 	// Price tracker: log model vs market prices every second
 	go a.PriceTracker.Run(ctx)
@@ -73,21 +108,50 @@ func (a *App) Run(ctx context.Context) error {
 		go a.FillListener.Run(ctx)
 	}
 
-	// Single reprice loop — one market, one asset, CPU-bound evaluation
-	a.repriceLoop(ctx, repriceCh)
+	wg.Wait()
 
 	a.Logger.Info("shutting down")
 	return nil
 }
 
+// ResetBreaker manually clears a tripped circuit breaker.
+func (a *App) ResetBreaker(detail string) {
+	if a.Breaker == nil {
+		return
+	}
+	a.Breaker.Reset(detail)
+}
+
+// bankroll is what Kelly sizing starts from: the configured bankroll, shrunk
+// to the wallet's spendable USDC in live mode.
+func (a *App) bankroll() float64 {
+	if a.Wallet == nil {
+		return a.Config.BankrollUSD
+	}
+	return a.Wallet.Bankroll(a.Config.BankrollUSD)
+}
+
+// assets returns the distinct assets across all streams.
+func (a *App) assets() []string {
+	seen := make(map[string]bool)
+	var assets []string
+	for _, s := range a.Streams {
+		if !seen[s.Asset] {
+			seen[s.Asset] = true
+			assets = append(assets, s.Asset)
+		}
+	}
+	return assets
+}
+
 // marketLifecycle resolves the current market, polls quotes, and rolls to the next
 // market when the current one expires.
-func (a *App) marketLifecycle(ctx context.Context, repriceCh chan<- domain.RepriceEvent) {
+func (a *App) marketLifecycle(ctx context.Context, s *MarketStream) {
 	for {
 		// Resolve current market
-		markets, err := a.MarketData.GetActiveMarkets(ctx)
+		markets, err := s.MarketData.GetActiveMarkets(ctx)
 		if err != nil || len(markets) == 0 {
-			a.Logger.Warn("waiting for market to become available", "error", err)
+			s.Logger.Warn("waiting for market to become available", "error", err)
 			select {
 			case <-ctx.Done():
 				return
@@ -101,7 +165,7 @@ func (a *App) marketLifecycle(ctx context.Context, repriceCh chan<- domain.Repri
 		// Set PriceToBeat from Chainlink at market start time
 		snap, err := a.RefPriceStream.GetPriceAtTime(ctx, market.Asset, market.StartTime)
 		if err != nil {
-			a.Logger.Warn("failed to fetch price at market start, using current",
+			s.Logger.Warn("failed to fetch price at market start, using current",
 				"asset", market.Asset, "start_time", market.StartTime, "error", err)
 			// Fallback: use current price from analytics
 			if refState, ok := a.RefAnalytics.GetState(market.Asset); ok && refState.CurrentPrice > 0 {
@@ -109,7 +173,7 @@ func (a *App) marketLifecycle(ctx context.Context, repriceCh chan<- domain.Repri
 			}
 		} else {
 			market.PriceToBeat = snap.Price
-			a.Logger.Info("price to beat set from chainlink",
+			s.Logger.Info("price to beat set from chainlink",
 				"asset", market.Asset,
 				"start_time", market.StartTime.Format(time.RFC3339),
 				"price_to_beat", snap.Price,
@@ -117,7 +181,8 @@ func (a *App) marketLifecycle(ctx context.Context, repriceCh chan<- domain.Repri
 		}
 
 		a.Registry.SetMarket(market)
-		a.Logger.Info("active market",
+		s.setActiveMarket(market.ID)
+		s.Logger.Info("active market",
 			"id", market.ID,
 			"slug", market.Slug,
 			"asset", market.Asset,
@@ -129,10 +194,76 @@ func (a *App) marketLifecycle(ctx context.Context, repriceCh chan<- domain.Repri
 
 		// Wait until market expires or context is cancelled
 		a.waitForExpiry(ctx, market)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Pick up any late-bound PriceToBeat before the market leaves the registry
+		if m, ok := a.Registry.GetMarket(market.ID); ok {
+			market = m
+		}
+
+		// Settle in the background: the Chainlink report at EndTime can lag by a
+		// few seconds and the next window should start streaming immediately.
+		if a.Settlement != nil {
+			go a.settleMarket(ctx, market)
+		}
 
 		// Clean up expired market
+		s.setActiveMarket("")
+		s.Runner.CloseMarket(ctx, market.ID)
 		a.Registry.RemoveMarket(market.ID)
-		a.Logger.Info("market expired, rolling to next", "expired_slug", market.Slug)
+		s.Logger.Info("market expired, rolling to next", "expired_slug", market.Slug)
+	}
+}
+
+// Settlement retries after a failed attempt. Each attempt already retries
+// the price fetch and waits up to the confirm timeout for the official
+// outcome.
+const (
+	settleAttempts   = 3
+	settleRetryDelay = time.Minute
+)
+
+// settleMarket resolves an expired market, books its realized P&L and hands
+// the settled tokens to redemption. The window is queued for redemption on
+// the first failure, so its tokens are redeemed even if every retry fails.
+func (a *App) settleMarket(ctx context.Context, market domain.BinaryMarket) {
+	for attempt := 1; ; attempt++ {
+		st, err := a.Settlement.Settle(ctx, market)
+		if err == nil {
+			if a.Redemption != nil {
+				a.Redemption.OnSettlement(ctx, st)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if attempt == 1 && a.Redemption != nil {
+			a.Redemption.OnSettlementFailed(ctx, market.ID)
+		}
+		if attempt == settleAttempts {
+			a.Logger.Error("settlement failed, positions left open",
+				"market", market.ID,
+				"slug", market.Slug,
+				"attempts", attempt,
+				"error", err,
+			)
+			return
+		}
+		a.Logger.Warn("settlement failed, retrying",
+			"market", market.ID,
+			"slug", market.Slug,
+			"attempt", attempt,
+			"retry_in", settleRetryDelay,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(settleRetryDelay):
+		}
 	}
 }
 
@@ -150,11 +281,11 @@ func (a *App) waitForExpiry(ctx context.Context, market domain.BinaryMarket) {
 	}
 }
 
-// streamPolymarketQuotes subscribes to the Polymarket WebSocket and feeds quotes into repriceCh.
-func (a *App) streamPolymarketQuotes(ctx context.Context, repriceCh chan<- domain.RepriceEvent) {
-	quoteCh, err := a.MarketData.SubscribeQuotes(ctx)
+// streamPolymarketQuotes subscribes to the Polymarket WebSocket and feeds quotes into the stream's reprice loop.
+func (a *App) streamPolymarketQuotes(ctx context.Context, s *MarketStream) {
+	quoteCh, err := s.MarketData.SubscribeQuotes(ctx)
 	if err != nil {
-		a.Logger.Error("failed to subscribe to polymarket quotes", "error", err)
+		s.Logger.Error("failed to subscribe to polymarket quotes", "error", err)
 		return
 	}
 
@@ -167,16 +298,16 @@ func (a *App) streamPolymarketQuotes(ctx context.Context, repriceCh chan<- domai
 				return
 			}
 			a.Registry.SetQuote(quote)
-			select {
-			case repriceCh <- domain.RepriceEvent{MarketID: quote.MarketID, Reason: "polymarket_ws"}:
-			default:
-			}
+			s.reprice(quote.MarketID, "polymarket_ws")
 		}
 	}
 }
 
-// streamChainlinkTicks processes Chainlink price feed for our asset in real time.
-func (a *App) streamChainlinkTicks(ctx context.Context, asset string, repriceCh chan<- domain.RepriceEvent) {
+// streamChainlinkTicks processes the Chainlink price feed for one asset in real
+// time and triggers a reprice on every stream trading that asset. The
+// subscription reconnects and backfills missed reports itself, so its channel
+// only closes at shutdown.
+func (a *App) streamChainlinkTicks(ctx context.Context, asset string) {
 	ch, err := a.RefPriceStream.SubscribePrices(ctx, asset)
 	if err != nil {
 		a.Logger.Error("failed to subscribe to chainlink", "asset", asset, "error", err)
@@ -198,17 +329,19 @@ func (a *App) streamChainlinkTicks(ctx context.Context, asset string, repriceCh
 				Timestamp: snap.Timestamp,
 			})
 
-			for _, marketID := range a.Registry.ListMarketIDsForAsset(asset) {
-				select {
-				case repriceCh <- domain.RepriceEvent{MarketID: marketID, Reason: "chainlink_tick"}:
-				default:
+			for _, s := range a.Streams {
+				if s.Asset != asset {
+					continue
+				}
+				if marketID := s.ActiveMarket(); marketID != "" {
+					s.reprice(marketID, "chainlink_tick")
 				}
 			}
 		}
 	}
 }
 
-func (a *App) timeDecayTicker(ctx context.Context, repriceCh chan<- domain.RepriceEvent) {
+func (a *App) timeDecayTicker(ctx context.Context, s *MarketStream) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
@@ -217,22 +350,19 @@ func (a *App) timeDecayTicker(ctx context.Context, repriceCh chan<- domain.Repri
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for _, m := range a.Registry.ListMarkets() {
-				select {
-				case repriceCh <- domain.RepriceEvent{MarketID: m.ID, Reason: "time_decay"}:
-				default:
-				}
+			if marketID := s.ActiveMarket(); marketID != "" {
+				s.reprice(marketID, "time_decay")
 			}
 		}
 	}
 }
 
-func (a *App) repriceLoop(ctx context.Context, repriceCh <-chan domain.RepriceEvent) {
+func (a *App) repriceLoop(ctx context.Context, s *MarketStream) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case evt, ok := <-repriceCh:
+		case evt, ok := <-s.repriceCh:
 			if !ok {
 				return
 			}
@@ -251,7 +381,7 @@ func (a *App) repriceLoop(ctx context.Context, repriceCh <-chan domain.RepriceEv
 			if market.PriceToBeat == 0 && refState.CurrentPrice > 0 {
 				market.PriceToBeat = refState.CurrentPrice
 				a.Registry.SetMarket(market)
-				a.Logger.Info("price to beat set (late bind)",
+				s.Logger.Info("price to beat set (late bind)",
 					"asset", market.Asset,
 					"price_to_beat", refState.CurrentPrice,
 				)
@@ -273,9 +403,9 @@ func (a *App) repriceLoop(ctx context.Context, repriceCh <-chan domain.RepriceEv
 				Timestamp:   quote.Timestamp,
 			}
 
-			err := a.Runner.EvaluateMarket(ctx, &market, &refState, &mktState, a.Config.BankrollUSD)
+			err := s.Runner.EvaluateMarket(ctx, &market, &refState, &mktState, a.bankroll())
 			if err != nil {
-				a.Logger.Error("evaluation error",
+				s.Logger.Error("evaluation error",
 					"market", evt.MarketID,
 					"reason", evt.Reason,
 					"error", err,
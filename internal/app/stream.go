@@ -0,0 +1,49 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+	"Polybot/internal/strategy"
+)
+
+// MarketStream is one rolling (asset, interval) market series. Each stream has
+// its own market data feed and strategy (cooldown, persistence streaks, quotes);
+// positions, risk and bankroll are shared across streams through the App.
+type MarketStream struct {
+	Key        string // e.g. "btc-5m"
+	Asset      string // upper-case asset (BTC, ETH, ...)
+	Interval   int    // window length in minutes
+	MarketData ports.MarketDataProvider
+	Runner     strategy.Strategy
+	Logger     *slog.Logger
+
+	repriceCh chan domain.RepriceEvent
+
+	mu           sync.RWMutex
+	activeMarket domain.MarketID
+}
+
+// ActiveMarket returns the market currently live on this stream, or "" between windows.
+func (s *MarketStream) ActiveMarket() domain.MarketID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.activeMarket
+}
+
+func (s *MarketStream) setActiveMarket(id domain.MarketID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeMarket = id
+}
+
+// reprice queues a reprice event without blocking; events are dropped when the
+// loop is behind since the next one re-reads the latest state anyway.
+func (s *MarketStream) reprice(marketID domain.MarketID, reason string) {
+	select {
+	case s.repriceCh <- domain.RepriceEvent{MarketID: marketID, Reason: reason}:
+	default:
+	}
+}
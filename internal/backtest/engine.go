@@ -0,0 +1,316 @@
+package backtest
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"Polybot/internal/config"
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/polymarket"
+	"Polybot/internal/infra/storage"
+	"Polybot/internal/service"
+	"Polybot/internal/strategy"
+)
+
+// Engine replays recorded windows through the live strategy stack.
+//
+// Every service is built fresh per Run from the config, so two runs over the
+// same recordings with the same config produce identical reports. Windows from
+// different streams are interleaved by event time and share positions, risk
+// and bankroll exactly as they do in the bot.
+type Engine struct {
+	cfg          *config.Config
+	pricingModel service.PricingModel
+	logger       *slog.Logger
+}
+
+func NewEngine(cfg *config.Config, pricingModel service.PricingModel, logger *slog.Logger) *Engine {
+	return &Engine{cfg: cfg, pricingModel: pricingModel, logger: logger}
+}
+
+// replay holds the per-run state.
+type replay struct {
+	clock        *SimClock
+	registry     *service.MarketRegistry
+	refAnalytics *service.ReferenceAnalyticsService
+	prices       *recordedPrices
+	books        *replayBooks
+	exec         *SimExecutionProvider
+	positionSvc  *service.PositionService
+	signalSvc    *service.SignalService
+	hedgeEngine  *service.HedgeEngine
+	riskSvc      *service.RiskService
+	execSvc      *service.ExecutionService
+	settlement   *service.SettlementService
+
+	runners  map[string]*strategy.StrategyRunner // stream key -> runner
+	lastTick map[string]int64                    // asset -> last tick (unix nanos) fed to analytics
+	started  []bool
+	settled  []bool
+	results  []WindowResult
+}
+
+type windowEvent struct {
+	window int
+	event  Event
+}
+
+// Run replays the windows and returns the report.
+func (e *Engine) Run(ctx context.Context, windows []Window) (*Report, error) {
+	rp := e.newReplay()
+
+	merged := make([]windowEvent, 0)
+	for i, w := range windows {
+		for _, ev := range w.Events {
+			merged = append(merged, windowEvent{window: i, event: ev})
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].event.Time.Before(merged[j].event.Time)
+	})
+
+	rp.started = make([]bool, len(windows))
+	rp.settled = make([]bool, len(windows))
+
+	for _, we := range merged {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e.settleExpired(ctx, rp, windows, we.event.Time)
+		rp.clock.Set(we.event.Time)
+
+		w := windows[we.window]
+		if !rp.started[we.window] {
+			rp.registry.SetMarket(w.Market)
+			rp.started[we.window] = true
+		}
+
+		switch we.event.Kind {
+		case EventTick:
+			asset := w.Market.Asset
+			ts := we.event.Observed
+			if ts.IsZero() {
+				ts = we.event.Time
+			}
+			// Overlapping windows of one asset record the same ticks.
+			if ts.UnixNano() <= rp.lastTick[asset] {
+				break
+			}
+			rp.lastTick[asset] = ts.UnixNano()
+			tick := domain.ChainlinkTick{Asset: asset, Price: we.event.Price, Timestamp: ts}
+			rp.refAnalytics.OnTick(tick)
+			rp.prices.add(domain.ReferenceSnapshot{Asset: asset, Price: tick.Price, Timestamp: tick.Timestamp})
+		case EventQuote:
+			rp.registry.SetQuote(we.event.Quote)
+		case EventBook:
+			if quote, ok := rp.books.replay(w, we.event); ok {
+				rp.registry.SetQuote(quote)
+			}
+		}
+
+		e.evaluate(ctx, rp, w)
+	}
+	e.settleExpired(ctx, rp, windows, time.Time{})
+
+	return buildReport(rp.results, rp.exec.Trades()), nil
+}
+
+func (e *Engine) newReplay() *replay {
+	cfg := e.cfg
+	clock := &SimClock{}
+	prices := newRecordedPrices()
+	registry := service.NewMarketRegistry()
+	books := newReplayBooks(e.logger)
+	exec := NewSimExecutionProvider(clock)
+	exec.Books = books
+	exec.Markets = registry
+	positionSvc := service.NewPositionService(storage.NewInMemoryPositionRepo())
+	costModel := &service.FixedCostModel{Cost: 0.01}
+
+	return &replay{
+		clock:        clock,
+		registry:     registry,
+		refAnalytics: service.NewReferenceAnalyticsService(5000),
+		prices:       prices,
+		books:        books,
+		exec:         exec,
+		positionSvc:  positionSvc,
+		signalSvc: service.NewSignalService(costModel, clock, service.SignalConfig{
+			BaseHurdle: cfg.BaseHurdle,
+			MaxSizeUSD: cfg.MaxPositionUSDPerMarket,
+		}),
+		hedgeEngine: service.NewHedgeEngine(positionSvc, costModel, clock, service.HedgeConfig{
+			HedgeHurdle: cfg.HedgeHurdle,
+		}),
+		riskSvc: service.NewRiskService(service.RiskConfig{
+			MaxPositionUSDPerMarket: cfg.MaxPositionUSDPerMarket,
+			MaxTotalExposureUSD:     cfg.MaxTotalExposureUSD,
+			NoNewTradeCutoffSecs:    cfg.NoNewTradeCutoffSecs,
+			FractionalKelly:         cfg.FractionalKelly,
+			MinTradeSizeUSD:         cfg.MinTradeSizeUSD,
+			MinTradeShares:          cfg.MinTradeShares,
+			MaxSlippageEdgeFraction: cfg.MaxSlippageEdgeFraction,
+			MaxImbalanceShares:      cfg.MaxImbalanceShares,
+			MinGuaranteedFloor:      cfg.MinGuaranteedFloor,
+			MaxWorstCaseLoss:        cfg.MaxWorstCaseLoss,
+		}),
+		execSvc: service.NewExecutionService(exec),
+		settlement: service.NewSettlementService(
+			positionSvc, prices, nil, nopEventRepo{}, clock, service.SettlementConfig{}, e.logger,
+		),
+		runners:  make(map[string]*strategy.StrategyRunner),
+		lastTick: make(map[string]int64),
+	}
+}
+
+// runnerFor returns the stream's runner, building it on first use. Runners
+// share positions, risk and execution; each has its own persistence filter
+// and cutoff, as in the bot's wiring.
+func (e *Engine) runnerFor(rp *replay, w Window) *strategy.StrategyRunner {
+	key := w.StreamKey()
+	if r, ok := rp.runners[key]; ok {
+		return r
+	}
+
+	cfg := e.cfg
+	runner := strategy.NewStrategyRunner(
+		e.pricingModel,
+		rp.signalSvc,
+		rp.hedgeEngine,
+		service.NewPersistenceFilter(cfg.PersistenceCount, cfg.HedgePersistenceCount),
+		rp.riskSvc,
+		rp.execSvc,
+		rp.positionSvc,
+		nopEventRepo{},
+		rp.clock,
+		strategy.FreshnessConfig{
+			MaxReferenceAge:  cfg.MaxReferenceAge,
+			MaxQuoteAge:      cfg.MaxQuoteAge,
+			MaxAllowedSpread: cfg.MaxAllowedSpread,
+			MinTickCount:     cfg.MinTickCount,
+			HedgeAfterPct:    cfg.HedgeAfterPct,
+		},
+		service.ImbalancePenaltyConfig{
+			Alpha: cfg.ImbalanceAlpha,
+			Beta:  cfg.ImbalanceBeta,
+		},
+		e.logger.With("stream", key),
+	)
+	runner.TradeCutoffSecs = e.tradeCutoff(w)
+	runner.Books = rp.books
+	rp.runners[key] = runner
+	return runner
+}
+
+// tradeCutoff uses the configured spec for the stream if there is one,
+// otherwise the same default ParseMarkets applies (10% of the window).
+func (e *Engine) tradeCutoff(w Window) float64 {
+	for _, spec := range e.cfg.Markets {
+		if strings.EqualFold(spec.Asset, w.Market.Asset) && spec.Interval == w.Interval {
+			return spec.NoNewTradeCutoffSecs
+		}
+	}
+	return float64(w.Interval * 60 / 10)
+}
+
+func (e *Engine) evaluate(ctx context.Context, rp *replay, w Window) {
+	market, ok := rp.registry.GetMarket(w.Market.ID)
+	if !ok || market.PriceToBeat <= 0 {
+		return
+	}
+	refState, ok := rp.refAnalytics.GetState(market.Asset)
+	if !ok {
+		return
+	}
+	quote, ok := rp.registry.GetQuote(market.ID)
+	if !ok {
+		return
+	}
+
+	mktState := domain.MarketState{
+		MarketID:    market.ID,
+		PriceToBeat: market.PriceToBeat,
+		UpBid:       quote.Up.Bid,
+		UpAsk:       quote.Up.Ask,
+		DownBid:     quote.Down.Bid,
+		DownAsk:     quote.Down.Ask,
+		Spread:      (quote.Up.Ask - quote.Up.Bid + quote.Down.Ask - quote.Down.Bid) / 2.0,
+		Timestamp:   quote.Timestamp,
+	}
+
+	runner := e.runnerFor(rp, w)
+	if err := runner.EvaluateMarket(ctx, &market, &refState, &mktState, e.cfg.BankrollUSD); err != nil {
+		e.logger.Error("evaluation error", "market", market.ID, "error", err)
+	}
+}
+
+// settleExpired settles every started window whose EndTime is at or before
+// now, in EndTime order. A zero now settles everything left.
+func (e *Engine) settleExpired(ctx context.Context, rp *replay, windows []Window, now time.Time) {
+	due := make([]int, 0)
+	for i, w := range windows {
+		if !rp.started[i] || rp.settled[i] {
+			continue
+		}
+		if now.IsZero() || !w.Market.EndTime.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return windows[due[a]].Market.EndTime.Before(windows[due[b]].Market.EndTime)
+	})
+
+	for _, i := range due {
+		rp.settled[i] = true
+		market, ok := rp.registry.GetMarket(windows[i].Market.ID)
+		if !ok {
+			continue
+		}
+		rp.clock.Set(market.EndTime)
+
+		st, err := rp.settlement.Settle(ctx, market)
+		if err != nil {
+			e.logger.Warn("backtest: window not settled", "slug", market.Slug, "error", err)
+		} else {
+			rp.results = append(rp.results, windowResult(st))
+		}
+		rp.registry.RemoveMarket(market.ID)
+	}
+}
+
+// replayBooks rebuilds each stream's order books from its recorded market
+// messages with the live MarketProvider's own message handling.
+type replayBooks struct {
+	logger  *slog.Logger
+	streams map[string]*polymarket.MarketProvider // stream key -> books
+}
+
+func newReplayBooks(logger *slog.Logger) *replayBooks {
+	return &replayBooks{logger: logger, streams: make(map[string]*polymarket.MarketProvider)}
+}
+
+func (b *replayBooks) replay(w Window, ev Event) (domain.MarketQuote, bool) {
+	key := w.StreamKey()
+	p, ok := b.streams[key]
+	if !ok {
+		p = polymarket.NewMarketProvider(nil, w.Market.Asset, w.Interval, b.logger)
+		b.streams[key] = p
+	}
+	return p.Replay(ev.Message, w.Market, ev.Time)
+}
+
+// Book implements ports.OrderBookSource across every stream.
+func (b *replayBooks) Book(tokenID string) (*domain.OrderBook, bool) {
+	if tokenID == "" {
+		return nil, false
+	}
+	for _, p := range b.streams {
+		if book, ok := p.Book(tokenID); ok {
+			return book, true
+		}
+	}
+	return nil, false
+}
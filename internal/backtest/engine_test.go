@@ -0,0 +1,393 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"Polybot/internal/config"
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/service"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	streams "github.com/smartcontractkit/data-streams-sdk/go"
+	v3 "github.com/smartcontractkit/data-streams-sdk/go/report/v3"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// fixedModel always prices UP at prob.
+type fixedModel struct{ prob float64 }
+
+func (m fixedModel) FairProbUp(_ context.Context, in domain.PricingInput) (domain.FairValue, error) {
+	return domain.FairValue{
+		ProbUp:           m.prob,
+		ProbUpLower:      m.prob,
+		ProbUpUpper:      m.prob,
+		RemainingSeconds: in.RemainingSeconds,
+	}, nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		BaseHurdle:              0.02,
+		HedgeHurdle:             1.0, // disable hedging
+		MaxPositionUSDPerMarket: 20,
+		MaxTotalExposureUSD:     100,
+		FractionalKelly:         0.25,
+		MinTradeSizeUSD:         1,
+		MinTradeShares:          5,
+		MaxAllowedSpread:        0.2,
+		MaxQuoteAge:             30 * time.Second,
+		MaxReferenceAge:         30 * time.Second,
+		BankrollUSD:             100,
+		PersistenceCount:        1,
+		HedgePersistenceCount:   1,
+	}
+}
+
+// testWindow records 60s of ticks at refPrice and quotes with UP offered at
+// 0.50, starting 10s into the window.
+func testWindow(t *testing.T, start int64, priceToBeat, refPrice float64) Window {
+	t.Helper()
+	market, interval, err := ParseSlug(fmt.Sprintf("btc-updown-5m-%d", start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	market.PriceToBeat = priceToBeat
+
+	w := Window{Market: market, Interval: interval}
+	for i := 10; i < 70; i++ {
+		ts := market.StartTime.Add(time.Duration(i) * time.Second)
+		w.Events = append(w.Events,
+			Event{Time: ts, Kind: EventTick, Price: refPrice},
+			Event{Time: ts, Kind: EventQuote, Quote: domain.MarketQuote{
+				MarketID:  market.ID,
+				Up:        domain.SideQuote{Bid: 0.48, Ask: 0.50},
+				Down:      domain.SideQuote{Bid: 0.48, Ask: 0.50},
+				Timestamp: ts,
+			}},
+		)
+	}
+	return w
+}
+
+func TestEngine_Run(t *testing.T) {
+	ctx := context.Background()
+	const start = 1700000100
+	windows := []Window{
+		testWindow(t, start, 100, 101),     // UP wins
+		testWindow(t, start+300, 100, 99),  // DOWN wins
+		testWindow(t, start+600, 100, 100), // tie resolves UP
+	}
+
+	engine := NewEngine(testConfig(), fixedModel{prob: 0.8}, testLogger())
+	rep, err := engine.Run(ctx, windows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("one_capped_trade_per_window", func(t *testing.T) {
+		if len(rep.Trades) != 3 {
+			t.Fatalf("expected 3 trades, got %d", len(rep.Trades))
+		}
+		for _, tr := range rep.Trades {
+			// 40 shares @ 0.50 = $20 (per-market cap)
+			if tr.Side != domain.PositionUp || math.Abs(tr.SizeUSD-20) > 1e-9 {
+				t.Errorf("unexpected trade %+v", tr)
+			}
+		}
+	})
+
+	t.Run("settles_each_window", func(t *testing.T) {
+		if rep.Windows != 3 || rep.Traded != 3 {
+			t.Fatalf("unexpected windows=%d traded=%d", rep.Windows, rep.Traded)
+		}
+		want := []float64{20, -20, 20}
+		for i, res := range rep.Results {
+			if math.Abs(res.PnL-want[i]) > 1e-9 {
+				t.Errorf("window %d: expected pnl %f, got %f", i, want[i], res.PnL)
+			}
+			if res.Trades != 1 {
+				t.Errorf("window %d: expected 1 trade, got %d", i, res.Trades)
+			}
+		}
+	})
+
+	t.Run("summary_metrics", func(t *testing.T) {
+		if rep.Wins != 2 || rep.Losses != 1 {
+			t.Errorf("expected 2 wins / 1 loss, got %d / %d", rep.Wins, rep.Losses)
+		}
+		if math.Abs(rep.HitRate-2.0/3.0) > 1e-9 {
+			t.Errorf("expected hit rate 0.667, got %f", rep.HitRate)
+		}
+		if math.Abs(rep.TotalPnL-20) > 1e-9 {
+			t.Errorf("expected total pnl 20, got %f", rep.TotalPnL)
+		}
+		if math.Abs(rep.MaxDrawdown-20) > 1e-9 {
+			t.Errorf("expected max drawdown 20, got %f", rep.MaxDrawdown)
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		again, err := NewEngine(testConfig(), fixedModel{prob: 0.8}, testLogger()).Run(ctx, windows)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(rep, again) {
+			t.Error("expected identical reports across runs")
+		}
+	})
+}
+
+func TestLoadTrackerLogs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prices_eth-updown-15m-1700000100.json")
+	lines := `{"ref_price":2000,"price_to_beat":1990,"remaining_ms":600000,"up_bid":0.6,"up_ask":0.62,"down_bid":0.38,"down_ask":0.4}
+{"ref_price":2000,"price_to_beat":1990,"remaining_ms":599900,"up_bid":0.6,"up_ask":0.62,"down_bid":0.38,"down_ask":0.4}
+{"ref_price":2001,"price_to_beat":1990,"remaining_ms":599800,"up_bid":0.61,"up_ask":0.63,"down_bid":0.37,"down_ask":0.39}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	windows, err := LoadTrackerLogs([]string{path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(windows) != 1 {
+		t.Fatalf("expected 1 window, got %d", len(windows))
+	}
+	w := windows[0]
+
+	if w.StreamKey() != "eth-15m" || w.Market.Asset != "ETH" || w.Market.PriceToBeat != 1990 {
+		t.Errorf("unexpected window %s %+v", w.StreamKey(), w.Market)
+	}
+
+	var ticks, quotes int
+	for _, ev := range w.Events {
+		switch ev.Kind {
+		case EventTick:
+			ticks++
+		case EventQuote:
+			quotes++
+		}
+	}
+	// The unchanged price 100ms later is not re-emitted as a tick.
+	if ticks != 2 || quotes != 3 {
+		t.Errorf("expected 2 ticks / 3 quotes, got %d / %d", ticks, quotes)
+	}
+
+	wantFirst := w.Market.EndTime.Add(-600 * time.Second)
+	if !w.Events[0].Time.Equal(wantFirst) {
+		t.Errorf("expected first event at %v, got %v", wantFirst, w.Events[0].Time)
+	}
+}
+
+// chainlinkReport encodes a v3 report observed at ts with the given price, as
+// the recorder stores it.
+func chainlinkReport(t *testing.T, ts time.Time, price int64) []byte {
+	t.Helper()
+	abiType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return typ
+	}
+	scaled := new(big.Int).Mul(big.NewInt(price), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	unix := uint32(ts.Unix())
+	blob, err := v3.Schema().Pack([32]byte{}, unix, unix, big.NewInt(0), big.NewInt(0), unix+60, scaled, scaled, scaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := abi.Arguments{
+		{Type: abiType("bytes32[3]")},
+		{Type: abiType("bytes")},
+		{Type: abiType("bytes32[]")},
+		{Type: abiType("bytes32[]")},
+		{Type: abiType("bytes32")},
+	}
+	report, err := full.Pack([3][32]byte{}, blob, [][32]byte{}, [][32]byte{}, [32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := (&streams.ReportResponse{FullReport: report, ObservationsTimestamp: uint64(unix)}).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// testRecording records one btc-5m window whose UP asks hold $5 at 0.50 and
+// the rest at 0.55, with the reference at 101 against a price to beat of 100.
+func testRecording(t *testing.T, start time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	clock := &SimClock{}
+	clock.Set(start)
+	rec, err := recorder.New(dir, 5*time.Minute, clock, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := `[{"event_type":"book","asset_id":"up-token",` +
+		`"bids":[{"price":"0.48","size":"100"}],"asks":[{"price":"0.50","size":"10"},{"price":"0.55","size":"100"}]},` +
+		`{"event_type":"book","asset_id":"down-token",` +
+		`"bids":[{"price":"0.48","size":"100"}],"asks":[{"price":"0.52","size":"100"}]}]`
+	// Not mappable to a window yet
+	rec.Record(recorder.SourceMarket, "btc-5m", []byte(book))
+
+	clock.Set(start.Add(time.Second))
+	window, err := json.Marshal(recorder.Window{
+		Slug:        fmt.Sprintf("btc-updown-5m-%d", start.Unix()),
+		MarketID:    "0xcondition",
+		UpTokenID:   "up-token",
+		DownTokenID: "down-token",
+		StartTime:   start,
+		EndTime:     start.Add(5 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Record(recorder.SourceWindow, "btc-5m", window)
+	rec.Record(recorder.SourceChainlink, "BTC", chainlinkReport(t, start, 100))
+	rec.Record(recorder.SourceMarket, "btc-5m", []byte(book))
+	for i := 10; i < 70; i++ {
+		clock.Set(start.Add(time.Duration(i) * time.Second))
+		rec.Record(recorder.SourceChainlink, "BTC", chainlinkReport(t, clock.Now(), 101))
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadRecordings(t *testing.T) {
+	start := time.Unix(1700000100, 0)
+	windows, err := LoadRecordings(testRecording(t, start), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(windows) != 1 {
+		t.Fatalf("expected 1 window, got %d", len(windows))
+	}
+	w := windows[0]
+
+	t.Run("maps_window_record_to_market", func(t *testing.T) {
+		m := w.Market
+		if m.ID != "0xcondition" || m.UpTokenID != "up-token" || m.DownTokenID != "down-token" || w.StreamKey() != "btc-5m" {
+			t.Errorf("unexpected market %+v", m)
+		}
+		if m.PriceToBeat != 100 {
+			t.Errorf("expected price to beat 100, got %f", m.PriceToBeat)
+		}
+	})
+
+	t.Run("replays_feeds_at_receive_time", func(t *testing.T) {
+		var ticks, books int
+		for _, ev := range w.Events {
+			switch ev.Kind {
+			case EventTick:
+				ticks++
+			case EventBook:
+				books++
+				if !ev.Time.Equal(start.Add(time.Second)) {
+					t.Errorf("expected book at %v, got %v", start.Add(time.Second), ev.Time)
+				}
+			}
+		}
+		// The book received before the window record is dropped
+		if ticks != 61 || books != 1 {
+			t.Errorf("expected 61 ticks / 1 book, got %d / %d", ticks, books)
+		}
+	})
+
+	t.Run("fills_walk_the_recorded_book", func(t *testing.T) {
+		rep, err := NewEngine(testConfig(), fixedModel{prob: 0.8}, testLogger()).Run(context.Background(), windows)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rep.Trades) != 1 {
+			t.Fatalf("expected 1 trade, got %d", len(rep.Trades))
+		}
+		tr := rep.Trades[0]
+		// $5 at 0.50, the rest at 0.55
+		want := tr.SizeUSD / (10 + (tr.SizeUSD-5)/0.55)
+		if tr.Side != domain.PositionUp || tr.SizeUSD <= 5 || math.Abs(tr.Price-want) > 1e-9 {
+			t.Errorf("unexpected trade %+v, want price %f", tr, want)
+		}
+	})
+}
+
+func TestSimExecutionProvider_Depth(t *testing.T) {
+	ctx := context.Background()
+	market := domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"}
+	registry := service.NewMarketRegistry()
+	registry.SetMarket(market)
+
+	sim := NewSimExecutionProvider(&SimClock{})
+	sim.Markets = registry
+	sim.Books = bookSource{
+		"up": domain.NewOrderBook("up",
+			[]domain.PriceLevel{{Price: 0.45, Size: 10}},
+			[]domain.PriceLevel{{Price: 0.50, Size: 10}, {Price: 0.60, Size: 10}},
+		),
+	}
+
+	t.Run("buy_fills_at_vwap_within_limit", func(t *testing.T) {
+		res, err := sim.BuyUp(ctx, market.ID, 0.60, 8)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 10 @ 0.50 + 5 @ 0.60
+		if math.Abs(res.Size-15) > 1e-9 || math.Abs(res.Price-8.0/15) > 1e-9 {
+			t.Errorf("unexpected fill %+v", res)
+		}
+	})
+
+	t.Run("buy_beyond_depth_at_limit_is_not_filled", func(t *testing.T) {
+		if _, err := sim.BuyUp(ctx, market.ID, 0.55, 8); !errors.Is(err, service.ErrNotFilled) {
+			t.Errorf("expected ErrNotFilled, got %v", err)
+		}
+	})
+
+	t.Run("sell_beyond_bid_depth_is_not_filled", func(t *testing.T) {
+		if _, err := sim.Sell(ctx, market.ID, domain.PositionUp, 20, 0.40); !errors.Is(err, service.ErrNotFilled) {
+			t.Errorf("expected ErrNotFilled, got %v", err)
+		}
+	})
+
+	t.Run("no_book_fills_at_limit", func(t *testing.T) {
+		res, err := sim.BuyDown(ctx, market.ID, 0.50, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Price != 0.50 || math.Abs(res.Size-20) > 1e-9 {
+			t.Errorf("unexpected fill %+v", res)
+		}
+	})
+}
+
+// bookSource serves fixed books by token ID.
+type bookSource map[string]*domain.OrderBook
+
+func (s bookSource) Book(tokenID string) (*domain.OrderBook, bool) {
+	b, ok := s[tokenID]
+	if !ok {
+		return nil, false
+	}
+	return b.Clone(), true
+}
@@ -0,0 +1,134 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// WindowResult is the settled outcome of one replayed window.
+type WindowResult struct {
+	MarketID        domain.MarketID `json:"market_id"`
+	Slug            string          `json:"slug"`
+	Asset           string          `json:"asset"`
+	Outcome         string          `json:"outcome"`
+	PriceToBeat     float64         `json:"price_to_beat"`
+	SettlementPrice float64         `json:"settlement_price"`
+	UpQuantity      float64         `json:"up_qty"`
+	DownQuantity    float64         `json:"down_qty"`
+	UpCost          float64         `json:"up_cost"`
+	DownCost        float64         `json:"down_cost"`
+	PnL             float64         `json:"pnl"`
+	Trades          int             `json:"trades"`
+	SettledAt       time.Time       `json:"settled_at"`
+}
+
+func windowResult(st domain.Settlement) WindowResult {
+	return WindowResult{
+		MarketID:        st.MarketID,
+		Slug:            st.Slug,
+		Asset:           st.Asset,
+		Outcome:         st.Outcome,
+		PriceToBeat:     st.PriceToBeat,
+		SettlementPrice: st.SettlementPrice,
+		UpQuantity:      st.UpQuantity,
+		DownQuantity:    st.DownQuantity,
+		UpCost:          st.UpCost,
+		DownCost:        st.DownCost,
+		PnL:             st.RealizedPnL,
+		SettledAt:       st.SettledAt,
+	}
+}
+
+// Report summarizes a backtest run.
+type Report struct {
+	Windows     int     `json:"windows"`
+	Traded      int     `json:"traded"`       // windows with a position at settlement
+	Wins        int     `json:"wins"`         // traded windows with positive P&L
+	Losses      int     `json:"losses"`       // traded windows with negative P&L
+	HitRate     float64 `json:"hit_rate"`     // wins / traded
+	TotalPnL    float64 `json:"total_pnl"`    // sum of realized P&L
+	Volume      float64 `json:"volume"`       // USD bought and sold across all trades
+	MaxDrawdown float64 `json:"max_drawdown"` // largest peak-to-trough drop in cumulative P&L
+
+	Results []WindowResult `json:"results"`
+	Trades  []Trade        `json:"trades"`
+}
+
+func buildReport(results []WindowResult, trades []Trade) *Report {
+	rep := &Report{Results: results, Trades: trades}
+
+	perMarket := make(map[domain.MarketID]int)
+	for _, t := range trades {
+		perMarket[t.MarketID]++
+		rep.Volume += t.SizeUSD
+	}
+
+	var cum, peak float64
+	for i := range rep.Results {
+		r := &rep.Results[i]
+		r.Trades = perMarket[r.MarketID]
+		rep.Windows++
+		if r.Trades > 0 {
+			rep.Traded++
+			if r.PnL > 0 {
+				rep.Wins++
+			} else if r.PnL < 0 {
+				rep.Losses++
+			}
+		}
+
+		cum += r.PnL
+		if cum > peak {
+			peak = cum
+		}
+		if dd := peak - cum; dd > rep.MaxDrawdown {
+			rep.MaxDrawdown = dd
+		}
+	}
+	rep.TotalPnL = cum
+	if rep.Traded > 0 {
+		rep.HitRate = float64(rep.Wins) / float64(rep.Traded)
+	}
+	return rep
+}
+
+// WriteText prints the per-window table and summary. Individual trades are
+// included when withTrades is set.
+func (r *Report) WriteText(w io.Writer, withTrades bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	if withTrades && len(r.Trades) > 0 {
+		fmt.Fprintln(tw, "time\tmarket\tside\tprice\tshares\tsize_usd\t")
+		for _, t := range r.Trades {
+			side := string(t.Side)
+			if t.Sell {
+				side = "SELL " + side
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.3f\t%.2f\t%.2f\t\n",
+				t.Time.UTC().Format("2006-01-02T15:04:05.000"), t.MarketID, side, t.Price, t.Shares, t.SizeUSD)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	fmt.Fprintln(tw, "window\toutcome\tbeat\tsettle\tup_qty\tdown_qty\tcost\ttrades\tpnl\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t%+.2f\t\n",
+			res.Slug, res.Outcome, res.PriceToBeat, res.SettlementPrice,
+			res.UpQuantity, res.DownQuantity, res.UpCost+res.DownCost, res.Trades, res.PnL)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "windows\t%d\t\n", r.Windows)
+	fmt.Fprintf(tw, "traded\t%d\t\n", r.Traded)
+	fmt.Fprintf(tw, "wins / losses\t%d / %d\t\n", r.Wins, r.Losses)
+	fmt.Fprintf(tw, "hit rate\t%.1f%%\t\n", r.HitRate*100)
+	fmt.Fprintf(tw, "trades\t%d\t\n", len(r.Trades))
+	fmt.Fprintf(tw, "volume\t$%.2f\t\n", r.Volume)
+	fmt.Fprintf(tw, "total pnl\t$%+.2f\t\n", r.TotalPnL)
+	fmt.Fprintf(tw, "max drawdown\t$%.2f\t\n", r.MaxDrawdown)
+	return tw.Flush()
+}
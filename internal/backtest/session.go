@@ -0,0 +1,266 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/chainlink"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/infra/tracker"
+)
+
+// EventKind distinguishes the recorded input streams.
+type EventKind int
+
+const (
+	EventTick  EventKind = iota // Chainlink reference price
+	EventQuote                  // Polymarket top of book for both sides
+	EventBook                   // raw Polymarket market channel message
+)
+
+// Event is one recorded observation, replayed at Time.
+type Event struct {
+	Time     time.Time
+	Kind     EventKind
+	Price    float64            // EventTick
+	Observed time.Time          // EventTick: report observation time, zero if unknown
+	Quote    domain.MarketQuote // EventQuote
+	Message  []byte             // EventBook
+}
+
+// Window is one recorded market window and its events in time order.
+type Window struct {
+	Market   domain.BinaryMarket
+	Interval int // minutes
+	Events   []Event
+}
+
+// StreamKey identifies the (asset, interval) stream a window belongs to,
+// matching config.MarketSpec.Key.
+func (w Window) StreamKey() string {
+	return fmt.Sprintf("%s-%dm", strings.ToLower(w.Market.Asset), w.Interval)
+}
+
+// tickHeartbeat is how often an unchanged reference price is re-emitted as a
+// tick. Tracker lines are sampled far faster than Chainlink reports arrive, so
+// replaying every line as a tick would distort the vol and jump estimates.
+const tickHeartbeat = time.Second
+
+// LoadRecordings replays the raw feeds recorded in dir between from and to
+// (zero leaves that side unbounded) and returns the windows sorted by start
+// time. Events keep their receive times.
+//
+// A window record starts a window for its stream key, and the market messages
+// that follow it up to the key's next window record are its book events.
+// Market messages received before their stream's first window record cannot
+// be told apart into up and down books and are dropped. Chainlink reports
+// become ticks of every window of their asset open when they arrived, and the
+// price to beat is the report observed at the window start.
+func LoadRecordings(dir string, from, to time.Time) ([]Window, error) {
+	records, err := recorder.ReadRange(dir, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []*Window
+	current := make(map[string]*Window) // stream key -> window being streamed
+	ticks := make(map[string][]Event)   // asset -> ticks in receive order
+	for _, rec := range records {
+		switch rec.Source {
+		case recorder.SourceWindow:
+			info, err := rec.Window()
+			if err != nil {
+				return nil, fmt.Errorf("%s record at %d: %w", rec.Key, rec.RecvNs, err)
+			}
+			// A restarted bot records the window it resumes again
+			if w := current[rec.Key]; w != nil && w.Market.Slug == info.Slug {
+				continue
+			}
+			market, interval, err := ParseSlug(info.Slug)
+			if err != nil {
+				return nil, err
+			}
+			market.ID = domain.MarketID(info.MarketID)
+			market.UpTokenID = info.UpTokenID
+			market.DownTokenID = info.DownTokenID
+			market.StartTime = info.StartTime
+			market.EndTime = info.EndTime
+			w := &Window{Market: market, Interval: interval}
+			windows = append(windows, w)
+			current[rec.Key] = w
+
+		case recorder.SourceMarket:
+			if w := current[rec.Key]; w != nil {
+				w.Events = append(w.Events, Event{Time: rec.RecvTime(), Kind: EventBook, Message: rec.Payload()})
+			}
+
+		case recorder.SourceChainlink:
+			report, err := rec.ChainlinkReport()
+			if err != nil {
+				return nil, err
+			}
+			snap, err := chainlink.DecodeReport(rec.Key, report)
+			if err != nil {
+				return nil, fmt.Errorf("%s report at %d: %w", rec.Key, rec.RecvNs, err)
+			}
+			ticks[snap.Asset] = append(ticks[snap.Asset], Event{
+				Time:     rec.RecvTime(),
+				Kind:     EventTick,
+				Price:    snap.Price,
+				Observed: snap.Timestamp,
+			})
+		}
+	}
+
+	out := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		assetTicks := ticks[w.Market.Asset]
+		w.Market.PriceToBeat = priceAt(assetTicks, w.Market.StartTime)
+		for _, tick := range assetTicks {
+			if !tick.Time.Before(w.Market.StartTime) && tick.Time.Before(w.Market.EndTime) {
+				w.Events = append(w.Events, tick)
+			}
+		}
+		if w.Market.PriceToBeat <= 0 || len(w.Events) == 0 {
+			continue
+		}
+		sort.SliceStable(w.Events, func(i, j int) bool { return w.Events[i].Time.Before(w.Events[j].Time) })
+		out = append(out, *w)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Market.StartTime.Before(out[j].Market.StartTime)
+	})
+	return out, nil
+}
+
+// priceAt returns the price of the report observed at ts, or of the last one
+// observed before it; zero if there is none.
+func priceAt(ticks []Event, ts time.Time) float64 {
+	var price float64
+	var observed time.Time
+	for _, tick := range ticks {
+		if tick.Observed.After(ts) || tick.Observed.Before(observed) {
+			continue
+		}
+		price, observed = tick.Price, tick.Observed
+	}
+	return price
+}
+
+// LoadTrackerLogs reads the per-window prices_<slug>.json files written by the
+// price tracker and returns the windows sorted by start time. The logs only
+// hold the top of book, so replays of them fill without depth; prefer
+// LoadRecordings where raw recordings exist.
+//
+// Event times are reconstructed from remaining_ms against the window end
+// derived from the slug, since the ts field only has second resolution.
+func LoadTrackerLogs(paths []string) ([]Window, error) {
+	windows := make([]Window, 0, len(paths))
+	for _, path := range paths {
+		w, err := loadTrackerLog(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(w.Events) == 0 {
+			continue
+		}
+		windows = append(windows, w)
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Market.StartTime.Before(windows[j].Market.StartTime)
+	})
+	return windows, nil
+}
+
+func loadTrackerLog(path string) (Window, error) {
+	slug := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "prices_"), ".json")
+	market, interval, err := ParseSlug(slug)
+	if err != nil {
+		return Window{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Window{}, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w := Window{Market: market, Interval: interval}
+	var lastTickTime time.Time
+	var lastTickPrice float64
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var snap tracker.FullTickSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return Window{}, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if snap.RefPrice <= 0 || snap.RemainingMs <= 0 {
+			continue
+		}
+		ts := market.EndTime.Add(-time.Duration(snap.RemainingMs * float64(time.Millisecond)))
+
+		if w.Market.PriceToBeat == 0 && snap.PriceToBeat > 0 {
+			w.Market.PriceToBeat = snap.PriceToBeat
+		}
+
+		if snap.RefPrice != lastTickPrice || ts.Sub(lastTickTime) >= tickHeartbeat {
+			w.Events = append(w.Events, Event{Time: ts, Kind: EventTick, Price: snap.RefPrice})
+			lastTickTime = ts
+			lastTickPrice = snap.RefPrice
+		}
+
+		w.Events = append(w.Events, Event{
+			Time: ts,
+			Kind: EventQuote,
+			Quote: domain.MarketQuote{
+				MarketID:  market.ID,
+				Up:        domain.SideQuote{Bid: snap.UpBid, Ask: snap.UpAsk},
+				Down:      domain.SideQuote{Bid: snap.DownBid, Ask: snap.DownAsk},
+				Timestamp: ts,
+			},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return Window{}, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	sort.SliceStable(w.Events, func(i, j int) bool { return w.Events[i].Time.Before(w.Events[j].Time) })
+	return w, nil
+}
+
+// ParseSlug builds a market from a "<asset>-updown-<N>m-<start unix>" slug.
+// The slug doubles as the market ID since tracker logs carry no condition ID.
+func ParseSlug(slug string) (domain.BinaryMarket, int, error) {
+	parts := strings.Split(slug, "-")
+	if len(parts) != 4 || parts[1] != "updown" || !strings.HasSuffix(parts[2], "m") {
+		return domain.BinaryMarket{}, 0, fmt.Errorf("unrecognized market slug %q", slug)
+	}
+	interval, err := strconv.Atoi(strings.TrimSuffix(parts[2], "m"))
+	if err != nil || interval <= 0 {
+		return domain.BinaryMarket{}, 0, fmt.Errorf("invalid interval in slug %q", slug)
+	}
+	start, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return domain.BinaryMarket{}, 0, fmt.Errorf("invalid start timestamp in slug %q", slug)
+	}
+
+	startTime := time.Unix(start, 0)
+	return domain.BinaryMarket{
+		ID:        domain.MarketID(slug),
+		Slug:      slug,
+		Asset:     strings.ToUpper(parts[0]),
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Duration(interval) * time.Minute),
+		Status:    domain.MarketStatusActive,
+	}, interval, nil
+}
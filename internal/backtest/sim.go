@@ -0,0 +1,223 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+	"Polybot/internal/service"
+)
+
+// SimClock is a ports.Clock advanced by the replay loop.
+type SimClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+func (c *SimClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Set moves the clock to t. Time never runs backwards.
+func (c *SimClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Trade is one simulated fill.
+type Trade struct {
+	Time     time.Time           `json:"time"`
+	MarketID domain.MarketID     `json:"market_id"`
+	Side     domain.PositionSide `json:"side"`
+	Price    float64             `json:"price"`
+	Shares   float64             `json:"shares"`
+	SizeUSD  float64             `json:"size_usd"`
+	Sell     bool                `json:"sell,omitempty"` // SizeUSD is proceeds
+}
+
+// SimExecutionProvider fills orders like the live FOK orders do and records
+// the fill. When Books serves the order's token, a buy walks the replayed
+// asks up to its limit and a sell walks the bids down to its floor, filling
+// at the VWAP; orders the book cannot fill in full fail with
+// service.ErrNotFilled. Without a book (tracker-log replays) orders fill in
+// full at their limit price, like paper mode did before books were streamed.
+// Results carry no OrderID so the runner books the position itself.
+//
+// Liquidity is not consumed: the next order sees the book as recorded.
+type SimExecutionProvider struct {
+	clock ports.Clock
+
+	// Books serves the replayed order books; Markets maps an order's market
+	// to its tokens. Both are optional.
+	Books   ports.OrderBookSource
+	Markets *service.MarketRegistry
+
+	mu     sync.Mutex
+	trades []Trade
+}
+
+func NewSimExecutionProvider(clock ports.Clock) *SimExecutionProvider {
+	return &SimExecutionProvider{clock: clock}
+}
+
+func (p *SimExecutionProvider) BuyUp(_ context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
+	return p.fill(marketID, domain.PositionUp, maxPrice, sizeUSD)
+}
+
+func (p *SimExecutionProvider) BuyDown(_ context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
+	return p.fill(marketID, domain.PositionDown, maxPrice, sizeUSD)
+}
+
+func (p *SimExecutionProvider) Sell(_ context.Context, marketID domain.MarketID, side domain.PositionSide, shares, minPrice float64) (ports.OrderResult, error) {
+	if minPrice <= 0 || minPrice >= 1 {
+		return ports.OrderResult{}, fmt.Errorf("invalid limit price %f", minPrice)
+	}
+
+	price := minPrice
+	if book, ok := p.book(marketID, side); ok {
+		depth, _ := book.Depth(domain.BookBid, minPrice)
+		if depth < shares {
+			return ports.OrderResult{}, service.ErrNotFilled
+		}
+		proceeds, _ := book.SellProceeds(shares)
+		price = proceeds / shares
+	}
+
+	p.record(Trade{
+		Time:     p.clock.Now(),
+		MarketID: marketID,
+		Side:     side,
+		Price:    price,
+		Shares:   shares,
+		SizeUSD:  shares * price,
+		Sell:     true,
+	})
+	return ports.OrderResult{Filled: true, Price: price, Size: shares}, nil
+}
+
+func (p *SimExecutionProvider) fill(marketID domain.MarketID, side domain.PositionSide, maxPrice, sizeUSD float64) (ports.OrderResult, error) {
+	if maxPrice <= 0 || maxPrice >= 1 {
+		return ports.OrderResult{}, fmt.Errorf("invalid limit price %f", maxPrice)
+	}
+
+	shares := sizeUSD / maxPrice
+	if book, ok := p.book(marketID, side); ok {
+		if _, depthUSD := book.Depth(domain.BookAsk, maxPrice); depthUSD < sizeUSD {
+			return ports.OrderResult{}, service.ErrNotFilled
+		}
+		shares, _, _ = book.BuyWithUSD(sizeUSD)
+	}
+	price := sizeUSD / shares
+
+	p.record(Trade{
+		Time:     p.clock.Now(),
+		MarketID: marketID,
+		Side:     side,
+		Price:    price,
+		Shares:   shares,
+		SizeUSD:  sizeUSD,
+	})
+	return ports.OrderResult{Filled: true, Price: price, Size: shares}, nil
+}
+
+// book returns the replayed book of the token for side in marketID.
+func (p *SimExecutionProvider) book(marketID domain.MarketID, side domain.PositionSide) (*domain.OrderBook, bool) {
+	if p.Books == nil || p.Markets == nil {
+		return nil, false
+	}
+	market, ok := p.Markets.GetMarket(marketID)
+	if !ok {
+		return nil, false
+	}
+	tokenID := market.UpTokenID
+	if side == domain.PositionDown {
+		tokenID = market.DownTokenID
+	}
+	if tokenID == "" {
+		return nil, false
+	}
+	return p.Books.Book(tokenID)
+}
+
+func (p *SimExecutionProvider) record(trade Trade) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.trades = append(p.trades, trade)
+}
+
+// Trades returns all fills so far in execution order.
+func (p *SimExecutionProvider) Trades() []Trade {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Trade(nil), p.trades...)
+}
+
+// recordedPrices serves GetPriceAtTime from the replayed ticks so windows
+// settle against the same reference path the strategy saw.
+type recordedPrices struct {
+	mu    sync.RWMutex
+	ticks map[string][]domain.ReferenceSnapshot // asset -> ticks in time order
+}
+
+func newRecordedPrices() *recordedPrices {
+	return &recordedPrices{ticks: make(map[string][]domain.ReferenceSnapshot)}
+}
+
+func (r *recordedPrices) add(snap domain.ReferenceSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ticks[snap.Asset] = append(r.ticks[snap.Asset], snap)
+}
+
+func (r *recordedPrices) GetLatestPrice(_ context.Context, asset string) (domain.ReferenceSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ticks := r.ticks[asset]
+	if len(ticks) == 0 {
+		return domain.ReferenceSnapshot{}, fmt.Errorf("no price for asset %s", asset)
+	}
+	return ticks[len(ticks)-1], nil
+}
+
+// GetPriceAtTime returns the last recorded tick at or before ts.
+func (r *recordedPrices) GetPriceAtTime(_ context.Context, asset string, ts time.Time) (domain.ReferenceSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ticks := r.ticks[asset]
+	i := sort.Search(len(ticks), func(i int) bool { return ticks[i].Timestamp.After(ts) })
+	if i == 0 {
+		return domain.ReferenceSnapshot{}, fmt.Errorf("no recorded price for %s at %v", asset, ts)
+	}
+	return ticks[i-1], nil
+}
+
+func (r *recordedPrices) SubscribePrices(context.Context, string) (<-chan domain.ReferenceSnapshot, error) {
+	return nil, fmt.Errorf("subscribe not supported in backtest")
+}
+
+// nopEventRepo discards events; the backtest reports from its own records.
+type nopEventRepo struct{}
+
+func (nopEventRepo) SaveFairValue(context.Context, domain.FairValue) error { return nil }
+func (nopEventRepo) SaveSignal(context.Context, domain.TradeSignal) error  { return nil }
+func (nopEventRepo) SaveQuote(context.Context, domain.MarketQuote) error   { return nil }
+func (nopEventRepo) SaveReferenceSnapshot(context.Context, domain.ReferenceSnapshot) error {
+	return nil
+}
+func (nopEventRepo) SaveFill(context.Context, domain.Fill) error             { return nil }
+func (nopEventRepo) SaveSettlement(context.Context, domain.Settlement) error { return nil }
+func (nopEventRepo) SaveBreakerEvent(context.Context, domain.BreakerEvent) error {
+	return nil
+}
+func (nopEventRepo) SaveReconcileEvent(context.Context, domain.ReconcileEvent) error {
+	return nil
+}
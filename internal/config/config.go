@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -9,11 +10,25 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// MarketSpec identifies one rolling up/down market stream the bot trades.
+type MarketSpec struct {
+	Asset                string  // lower-case asset symbol (btc, eth, sol, xrp)
+	Interval             int     // window length in minutes (5 or 15)
+	NoNewTradeCutoffSecs float64 // per-stream cutoff before window end
+}
+
+// Key returns a short stream identifier such as "btc-5m".
+func (m MarketSpec) Key() string {
+	return fmt.Sprintf("%s-%dm", m.Asset, m.Interval)
+}
+
 type Config struct {
 	// Trading parameters
 	BaseHurdle              float64 `yaml:"base_hurdle"` // directional hurdle (alias)
 	HedgeHurdle             float64 `yaml:"hedge_hurdle"`
 	HedgeAfterPct           float64 `yaml:"hedge_after_pct"` // fraction of market elapsed before hedging (0.70 = last 30%)
+	ExitEnabled             bool    `yaml:"exit_enabled"`    // sell held tokens before settlement when the bid is rich or the edge flips
+	ExitHurdle              float64 `yaml:"exit_hurdle"`     // margin the bid, net of cost, must clear upper fair value by
 	DefaultModelUncertainty float64 `yaml:"default_model_uncertainty"`
 	MaxPositionUSDPerMarket float64 `yaml:"max_position_usd_per_market"`
 	MaxTotalExposureUSD     float64 `yaml:"max_total_exposure_usd"`
@@ -21,6 +36,8 @@ type Config struct {
 	MinTradeSizeUSD         float64 `yaml:"min_trade_size_usd"`
 	MinTradeShares          float64 `yaml:"min_trade_shares"` // Polymarket min order size in shares
 	FractionalKelly         float64 `yaml:"fractional_kelly"`
+	MaxSlippageEdgeFraction float64 `yaml:"max_slippage_edge_fraction"` // cap on slippage from walking the book, as a fraction of edge (0 = no cap)
+	CostRefSizeUSD          float64 `yaml:"cost_ref_size_usd"`          // buy size the all-in cost estimates slippage for
 	MaxAllowedSpread        float64 `yaml:"max_allowed_spread"`
 
 	// Inventory risk management
@@ -30,6 +47,50 @@ type Config struct {
 	ImbalanceAlpha     float64 `yaml:"imbalance_alpha"`      // exponential penalty base scale
 	ImbalanceBeta      float64 `yaml:"imbalance_beta"`       // exponential penalty growth rate
 
+	// Circuit breaker (zero disables a threshold)
+	BreakerMaxDailyLossUSD      float64       `yaml:"breaker_max_daily_loss_usd"`     // realized + mark-to-market loss per UTC day
+	BreakerMaxConsecutiveLosses int           `yaml:"breaker_max_consecutive_losses"` // losing windows in a row
+	BreakerMaxErrorRate         float64       `yaml:"breaker_max_error_rate"`         // failed fraction of recent executions
+	BreakerErrorWindow          int           `yaml:"breaker_error_window"`           // executions the error rate is measured over
+	BreakerCooldown             time.Duration `yaml:"breaker_cooldown"`               // auto-reset delay (0 = manual reset via SIGUSR1)
+
+	// Order lifecycle (submitted orders awaiting fill confirmation)
+	OrderPollAfter   time.Duration `yaml:"order_poll_after"`   // poll the exchange for orders unconfirmed this long
+	OrderCancelAfter time.Duration `yaml:"order_cancel_after"` // cancel orders resting on the book this long
+	OrderExpireAfter time.Duration `yaml:"order_expire_after"` // stop counting unconfirmed orders as pending
+
+	// Execution mode: "taker" crosses the spread, "maker" rests limit buys at
+	// or inside the bid (live mode only; hedges always take)
+	ExecutionMode     string  `yaml:"execution_mode"`
+	MakerTickSize     float64 `yaml:"maker_tick_size"`     // price increment for resting orders
+	MakerRepriceTicks int     `yaml:"maker_reprice_ticks"` // replace a resting order once its target moves this far
+
+	// Strategy: "fair_value" (directional) or "market_maker" (two-sided bids
+	// around fair value; needs limit order support, i.e. live mode)
+	Strategy            string  `yaml:"strategy"`
+	MMBaseHalfSpread    float64 `yaml:"mm_base_half_spread"`   // bid distance below fair value before widening
+	MMUncertaintyWeight float64 `yaml:"mm_uncertainty_weight"` // half-spread added per unit of model uncertainty
+	MMVolatileWiden     float64 `yaml:"mm_volatile_widen"`     // half-spread added in the volatile regime
+	MMQuoteShares       float64 `yaml:"mm_quote_shares"`       // shares bid on each side
+	MMFlattenSecs       float64 `yaml:"mm_flatten_secs"`       // seconds before the cutoff spent completing pairs
+
+	// Position reconciliation against the venue (live mode)
+	ReconcileInterval      time.Duration `yaml:"reconcile_interval"`
+	ReconcileTolerance     float64       `yaml:"reconcile_tolerance"`      // drift in shares ignored as rounding
+	ReconcileMaxCorrection float64       `yaml:"reconcile_max_correction"` // larger drift halts trading instead of being corrected
+	ReconcileTradeGrace    time.Duration `yaml:"reconcile_trade_grace"`    // defer sides traded this recently
+
+	// Merging complete sets and redeeming resolved positions after settlement
+	RedeemInterval    time.Duration `yaml:"redeem_interval"`      // time between checks for on-chain resolution
+	RedeemGiveUpAfter time.Duration `yaml:"redeem_give_up_after"` // stop waiting for resolution after this long
+
+	// RFQ routing of large orders (live mode)
+	RFQMinSizeUSD float64       `yaml:"rfq_min_size_usd"` // orders this large ask for quotes before the book (0 = off)
+	RFQQuoteWait  time.Duration `yaml:"rfq_quote_wait"`   // how long to wait for an acceptable quote
+
+	// Wallet balance and allowance checks (live mode)
+	WalletRefreshInterval time.Duration `yaml:"wallet_refresh_interval"` // time between USDC balance reads
+
 	// Price tracker
 	TrackerIntervalMs int           `yaml:"tracker_interval_ms"` // price tracker tick interval (default: 100ms)
 	MinTickCount      int           `yaml:"min_tick_count"`      // minimum Chainlink ticks before trading
@@ -37,6 +98,24 @@ type Config struct {
 	MaxReferenceAge   time.Duration `yaml:"max_reference_age"`
 	BankrollUSD       float64       `yaml:"bankroll_usd"`
 
+	// Settlement
+	SettlementConfirmTimeout time.Duration `yaml:"settlement_confirm_timeout"` // how long to poll Gamma for the official outcome
+
+	// Paper exchange (simulated execution in paper mode)
+	PaperOrderType  string        `yaml:"paper_order_type"`   // FOK or FAK
+	PaperLatency    time.Duration `yaml:"paper_latency"`      // submission → matching delay
+	PaperFeeRateBps int           `yaml:"paper_fee_rate_bps"` // fallback when the fee rate lookup fails
+
+	// Storage for events and positions
+	StorageBackend   string        `yaml:"storage_backend"`   // "memory", "file" or "postgres"
+	StorageDir       string        `yaml:"storage_dir"`       // file backend root
+	StorageRetention time.Duration `yaml:"storage_retention"` // high-volume event retention (0 = keep all)
+	PostgresDSN      string        `yaml:"postgres_dsn"`      // postgres backend connection string
+
+	// Raw feed recorder (disabled when RecordDir is empty)
+	RecordDir    string        `yaml:"record_dir"`
+	RecordRotate time.Duration `yaml:"record_rotate"` // rotation window (default: 5m)
+
 	// Persistence filter
 	PersistenceCount      int `yaml:"persistence_count"`
 	HedgePersistenceCount int `yaml:"hedge_persistence_count"`
@@ -47,16 +126,24 @@ type Config struct {
 	// Polymarket
 	PrivateKey    string
 	FunderAddress string
+	PolygonRPCURL string // merges and redemptions are sent through this node (live mode)
 
 	// Chainlink Data Streams
 	ChainlinkWSURL   string
 	ChainlinkRestURL string
 	ChainlinkUserID  string
 	ChainlinkSecret  string
+	ChainlinkHA      bool          // connect to several Streams servers at once
+	ChainlinkStall   time.Duration // reopen the stream after this long without a report (0 = default)
+	ChainlinkGapFill time.Duration // most recent part of an outage backfilled over REST (0 = default)
+
+	// Markets: every (asset, interval) stream this instance trades.
+	// Set via MARKETS="btc:5,eth:5,sol:15". Falls back to MARKET/INTERVAL.
+	Markets []MarketSpec
 
-	// Market: single asset this instance trades (btc, eth, sol, xrp)
+	// Market: single asset (legacy MARKET, used when MARKETS is unset)
 	Market string
-	// Interval: market duration in minutes (5 or 15)
+	// Interval: market duration in minutes (legacy INTERVAL)
 	Interval int
 
 	// Mode: "live", "paper", or "debug"
@@ -71,42 +158,79 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		// Conservative defaults
-		BaseHurdle:              0.03,
-		HedgeHurdle:             0.02,
-		HedgeAfterPct:           0.70,
-		DefaultModelUncertainty: 0.02,
-		MaxPositionUSDPerMarket: 50.0,
-		MaxTotalExposureUSD:     200.0,
-		NoNewTradeCutoffSecs:    60.0,
-		MinTradeSizeUSD:         1.0,
-		MinTradeShares:          5.0,
-		FractionalKelly:         0.05,
-		MaxAllowedSpread:        0.10,
-		MinTickCount:            10,
-		MaxQuoteAge:             30 * time.Second,
-		MaxReferenceAge:         10 * time.Second,
-		BankrollUSD:             100.0,
-		Market:                  "btc",
-		Interval:                5,
-		Mode:                    "paper",
-		PersistenceCount:        3,
-		HedgePersistenceCount:   1,
-		MaxImbalanceShares:      15.0,
-		MinGuaranteedFloor:      -10.0,
-		MaxWorstCaseLoss:        20.0,
-		ImbalanceAlpha:          0.005,
-		ImbalanceBeta:           0.15,
-		TrackerIntervalMs:       100,
+		BaseHurdle:                  0.03,
+		HedgeHurdle:                 0.02,
+		HedgeAfterPct:               0.70,
+		ExitHurdle:                  0.02,
+		DefaultModelUncertainty:     0.02,
+		MaxPositionUSDPerMarket:     50.0,
+		MaxTotalExposureUSD:         200.0,
+		NoNewTradeCutoffSecs:        60.0,
+		MinTradeSizeUSD:             1.0,
+		MinTradeShares:              5.0,
+		FractionalKelly:             0.05,
+		MaxSlippageEdgeFraction:     0.5,
+		CostRefSizeUSD:              10.0,
+		MaxAllowedSpread:            0.10,
+		MinTickCount:                10,
+		MaxQuoteAge:                 30 * time.Second,
+		MaxReferenceAge:             10 * time.Second,
+		BankrollUSD:                 100.0,
+		Market:                      "btc",
+		Interval:                    5,
+		Mode:                        "paper",
+		PersistenceCount:            3,
+		HedgePersistenceCount:       1,
+		MaxImbalanceShares:          15.0,
+		MinGuaranteedFloor:          -10.0,
+		MaxWorstCaseLoss:            20.0,
+		ImbalanceAlpha:              0.005,
+		ImbalanceBeta:               0.15,
+		TrackerIntervalMs:           100,
+		SettlementConfirmTimeout:    15 * time.Minute,
+		BreakerMaxDailyLossUSD:      50.0,
+		BreakerMaxConsecutiveLosses: 5,
+		BreakerMaxErrorRate:         0.5,
+		BreakerErrorWindow:          20,
+		OrderPollAfter:              5 * time.Second,
+		OrderCancelAfter:            15 * time.Second,
+		OrderExpireAfter:            2 * time.Minute,
+		ExecutionMode:               "taker",
+		MakerTickSize:               0.01,
+		MakerRepriceTicks:           1,
+		Strategy:                    "fair_value",
+		MMBaseHalfSpread:            0.02,
+		MMUncertaintyWeight:         1.0,
+		MMVolatileWiden:             0.02,
+		MMQuoteShares:               10,
+		MMFlattenSecs:               30,
+		ReconcileInterval:           time.Minute,
+		ReconcileTolerance:          0.01,
+		ReconcileMaxCorrection:      5.0,
+		ReconcileTradeGrace:         30 * time.Second,
+		RedeemInterval:              time.Minute,
+		RedeemGiveUpAfter:           24 * time.Hour,
+		RFQQuoteWait:                3 * time.Second,
+		WalletRefreshInterval:       30 * time.Second,
+		RecordRotate:                5 * time.Minute,
+		PaperOrderType:              "FOK",
+		PaperLatency:                250 * time.Millisecond,
+		StorageBackend:              "memory",
+		StorageDir:                  "data",
+		StorageRetention:            7 * 24 * time.Hour,
 	}
 
 	cfg.PrivateKey = os.Getenv("MAIN_ACCOUNT_PRIVATE_KEY")
 	cfg.FunderAddress = os.Getenv("MAIN_ACCOUNT_FUNDER_ADDRESS")
+	cfg.PolygonRPCURL = os.Getenv("POLYGON_RPC_URL")
 	cfg.ChainlinkWSURL = os.Getenv("CHAINLINK_WS_URL")
 	cfg.ChainlinkRestURL = os.Getenv("CHAINLINK_REST_URL")
 	cfg.ChainlinkUserID = os.Getenv("CHAINLINK_USER_ID")
 	cfg.ChainlinkSecret = os.Getenv("CHAINLINK_SECRET")
 	cfg.ModelParamsFile = os.Getenv("MODEL_PARAMS_FILE")
 	cfg.CalibrationFile = os.Getenv("CALIBRATION_FILE")
+	cfg.RecordDir = os.Getenv("RECORD_DIR")
+	cfg.PostgresDSN = os.Getenv("POSTGRES_DSN")
 
 	if mode := os.Getenv("MODE"); mode != "" {
 		cfg.Mode = mode
@@ -135,6 +259,16 @@ func Load() (*Config, error) {
 			cfg.HedgeAfterPct = f
 		}
 	}
+	if v := os.Getenv("EXIT_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.ExitEnabled = b
+		}
+	}
+	if v := os.Getenv("EXIT_HURDLE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.ExitHurdle = f
+		}
+	}
 	if v := os.Getenv("PERSISTENCE_COUNT"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
 			cfg.PersistenceCount = n
@@ -155,6 +289,21 @@ func Load() (*Config, error) {
 			cfg.MinTradeShares = f
 		}
 	}
+	if v := os.Getenv("MAX_SLIPPAGE_EDGE_FRACTION"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MaxSlippageEdgeFraction = f
+		}
+	}
+	if v := os.Getenv("COST_REF_SIZE_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.CostRefSizeUSD = f
+		}
+	}
+	if v := os.Getenv("MAX_TOTAL_EXPOSURE_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MaxTotalExposureUSD = f
+		}
+	}
 	if v := os.Getenv("MAX_IMBALANCE_SHARES"); v != "" {
 		if f, err := strconv.ParseFloat(v, 64); err == nil {
 			cfg.MaxImbalanceShares = f
@@ -170,10 +319,243 @@ func Load() (*Config, error) {
 			cfg.MaxWorstCaseLoss = f
 		}
 	}
+	if v := os.Getenv("BREAKER_MAX_DAILY_LOSS_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.BreakerMaxDailyLossUSD = f
+		}
+	}
+	if v := os.Getenv("BREAKER_MAX_CONSECUTIVE_LOSSES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.BreakerMaxConsecutiveLosses = n
+		}
+	}
+	if v := os.Getenv("BREAKER_MAX_ERROR_RATE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.BreakerMaxErrorRate = f
+		}
+	}
+	if v := os.Getenv("BREAKER_ERROR_WINDOW"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.BreakerErrorWindow = n
+		}
+	}
+	if v := os.Getenv("CHAINLINK_HA"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.ChainlinkHA = b
+		}
+	}
+	if v := os.Getenv("CHAINLINK_STALL_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ChainlinkStall = d
+		}
+	}
+	if v := os.Getenv("CHAINLINK_MAX_GAP_FILL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ChainlinkGapFill = d
+		}
+	}
+	if v := os.Getenv("BREAKER_COOLDOWN"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.BreakerCooldown = d
+		}
+	}
+	if v := os.Getenv("ORDER_POLL_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OrderPollAfter = d
+		}
+	}
+	if v := os.Getenv("ORDER_CANCEL_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OrderCancelAfter = d
+		}
+	}
+	if v := os.Getenv("ORDER_EXPIRE_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OrderExpireAfter = d
+		}
+	}
+	if v := os.Getenv("EXECUTION_MODE"); v != "" {
+		cfg.ExecutionMode = strings.ToLower(v)
+	}
+	if v := os.Getenv("MAKER_TICK_SIZE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MakerTickSize = f
+		}
+	}
+	if v := os.Getenv("MAKER_REPRICE_TICKS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MakerRepriceTicks = n
+		}
+	}
+	if v := os.Getenv("STRATEGY"); v != "" {
+		cfg.Strategy = strings.ToLower(v)
+	}
+	if v := os.Getenv("MM_BASE_HALF_SPREAD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MMBaseHalfSpread = f
+		}
+	}
+	if v := os.Getenv("MM_UNCERTAINTY_WEIGHT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MMUncertaintyWeight = f
+		}
+	}
+	if v := os.Getenv("MM_VOLATILE_WIDEN"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MMVolatileWiden = f
+		}
+	}
+	if v := os.Getenv("MM_QUOTE_SHARES"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MMQuoteShares = f
+		}
+	}
+	if v := os.Getenv("MM_FLATTEN_SECS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.MMFlattenSecs = f
+		}
+	}
+	if v := os.Getenv("RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ReconcileInterval = d
+		}
+	}
+	if v := os.Getenv("RECONCILE_TOLERANCE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.ReconcileTolerance = f
+		}
+	}
+	if v := os.Getenv("RECONCILE_MAX_CORRECTION"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.ReconcileMaxCorrection = f
+		}
+	}
+	if v := os.Getenv("RECONCILE_TRADE_GRACE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ReconcileTradeGrace = d
+		}
+	}
+	if v := os.Getenv("REDEEM_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RedeemInterval = d
+		}
+	}
+	if v := os.Getenv("REDEEM_GIVE_UP_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RedeemGiveUpAfter = d
+		}
+	}
+	if v := os.Getenv("RFQ_MIN_SIZE_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.RFQMinSizeUSD = f
+		}
+	}
+	if v := os.Getenv("RFQ_QUOTE_WAIT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RFQQuoteWait = d
+		}
+	}
+	if v := os.Getenv("WALLET_REFRESH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.WalletRefreshInterval = d
+		}
+	}
 	if v := os.Getenv("TRACKER_INTERVAL_MS"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
 			cfg.TrackerIntervalMs = n
 		}
 	}
+	if v := os.Getenv("PAPER_ORDER_TYPE"); v != "" {
+		cfg.PaperOrderType = strings.ToUpper(v)
+	}
+	if v := os.Getenv("PAPER_LATENCY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.PaperLatency = d
+		}
+	}
+	if v := os.Getenv("PAPER_FEE_RATE_BPS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.PaperFeeRateBps = n
+		}
+	}
+	if v := os.Getenv("STORAGE_BACKEND"); v != "" {
+		cfg.StorageBackend = strings.ToLower(v)
+	}
+	if v := os.Getenv("STORAGE_DIR"); v != "" {
+		cfg.StorageDir = v
+	}
+	if v := os.Getenv("STORAGE_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.StorageRetention = d
+		}
+	}
+	if v := os.Getenv("RECORD_ROTATE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RecordRotate = d
+		}
+	}
+
+	if v := os.Getenv("MARKETS"); v != "" {
+		markets, err := ParseMarkets(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Markets = markets
+	} else {
+		cfg.Markets = []MarketSpec{{
+			Asset:                cfg.Market,
+			Interval:             cfg.Interval,
+			NoNewTradeCutoffSecs: cfg.NoNewTradeCutoffSecs,
+		}}
+	}
 	return cfg, nil
 }
+
+// ParseMarkets parses a comma-separated "asset:interval" list, e.g.
+// "btc:5,eth:5,sol:15". The no-new-trade cutoff defaults to 10% of the window.
+func ParseMarkets(raw string) ([]MarketSpec, error) {
+	var markets []MarketSpec
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		asset, intervalStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid market %q: expected asset:interval", entry)
+		}
+		interval, err := strconv.Atoi(strings.TrimSpace(intervalStr))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid interval in market %q", entry)
+		}
+		spec := MarketSpec{
+			Asset:                strings.ToLower(strings.TrimSpace(asset)),
+			Interval:             interval,
+			NoNewTradeCutoffSecs: float64(interval * 60 / 10),
+		}
+		if seen[spec.Key()] {
+			return nil, fmt.Errorf("duplicate market %q", spec.Key())
+		}
+		seen[spec.Key()] = true
+		markets = append(markets, spec)
+	}
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("no markets in %q", raw)
+	}
+	return markets, nil
+}
+
+// Assets returns the distinct upper-case assets across all market streams.
+func (c *Config) Assets() []string {
+	seen := make(map[string]bool)
+	var assets []string
+	for _, m := range c.Markets {
+		a := strings.ToUpper(m.Asset)
+		if !seen[a] {
+			seen[a] = true
+			assets = append(assets, a)
+		}
+	}
+	return assets
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// OrderStatus is the lifecycle state of a submitted order.
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"   // submitted, exchange state not yet known
+	OrderLive      OrderStatus = "live"      // resting on the book
+	OrderMatched   OrderStatus = "matched"   // matched, fills not yet all booked
+	OrderFilled    OrderStatus = "filled"    // fills booked into positions
+	OrderCancelled OrderStatus = "cancelled" // cancelled or unmatched before any unbooked fill
+	OrderExpired   OrderStatus = "expired"   // gave up waiting for confirmation
+)
+
+// Terminal reports whether the order no longer changes.
+func (s OrderStatus) Terminal() bool {
+	return s == OrderFilled || s == OrderCancelled || s == OrderExpired
+}
+
+// Order is a submitted order tracked until its fills are booked.
+type Order struct {
+	ID            string
+	MarketID      MarketID
+	Side          TradeSignalSide
+	Price         float64 // limit price
+	SizeUSD       float64 // notional still expected to fill, including FilledUSD
+	FilledUSD     float64 // notional booked via fills so far
+	FilledShares  float64 // shares booked via fills so far
+	MatchedShares float64 // shares the exchange reports matched
+	Resting       bool    // limit order meant to rest; its owner cancels it, not a timeout
+	Status        OrderStatus
+	SubmittedAt   time.Time
+	UpdatedAt     time.Time
+}
+
+// PendingUSD is the notional not yet booked, or 0 once terminal.
+func (o Order) PendingUSD() float64 {
+	if o.Status.Terminal() || o.FilledUSD >= o.SizeUSD {
+		return 0
+	}
+	return o.SizeUSD - o.FilledUSD
+}
+
+// PendingShares is PendingUSD in shares at the limit price.
+func (o Order) PendingShares() float64 {
+	if o.Price <= 0 {
+		return 0
+	}
+	return o.PendingUSD() / o.Price
+}
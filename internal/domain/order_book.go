@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// BookSide selects the bids or the asks of an order book.
+type BookSide string
+
+const (
+	BookBid BookSide = "BID"
+	BookAsk BookSide = "ASK"
+)
+
+// PriceLevel is the resting size at one price.
+type PriceLevel struct {
+	Price float64
+	Size  float64 // shares
+}
+
+// OrderBook is one token's L2 book. Bids are kept best (highest) first and
+// asks best (lowest) first, and empty levels are dropped, so the best price
+// is always the first level.
+type OrderBook struct {
+	TokenID   string
+	Bids      []PriceLevel
+	Asks      []PriceLevel
+	TickSize  float64 // 0 if unknown
+	Hash      string  // venue hash of the book version
+	Timestamp time.Time
+}
+
+// priceEpsilon treats prices parsed from the same decimal as equal.
+const priceEpsilon = 1e-9
+
+// NewOrderBook builds a book from levels in any order. Levels with no size
+// or no price are dropped; repeated prices are summed.
+func NewOrderBook(tokenID string, bids, asks []PriceLevel) *OrderBook {
+	b := &OrderBook{TokenID: tokenID}
+	for _, l := range bids {
+		b.add(BookBid, l)
+	}
+	for _, l := range asks {
+		b.add(BookAsk, l)
+	}
+	return b
+}
+
+func (b *OrderBook) add(side BookSide, l PriceLevel) {
+	if l.Price <= 0 || l.Size <= 0 {
+		return
+	}
+	b.Set(side, l.Price, b.SizeAt(side, l.Price)+l.Size)
+}
+
+// Clone returns a deep copy.
+func (b *OrderBook) Clone() *OrderBook {
+	c := *b
+	c.Bids = slices.Clone(b.Bids)
+	c.Asks = slices.Clone(b.Asks)
+	return &c
+}
+
+// Levels returns one side, best price first. The slice is the book's own.
+func (b *OrderBook) Levels(side BookSide) []PriceLevel {
+	if side == BookBid {
+		return b.Bids
+	}
+	return b.Asks
+}
+
+// Set replaces the size at price, removing the level when size is zero.
+func (b *OrderBook) Set(side BookSide, price, size float64) {
+	levels := b.Levels(side)
+	i, found := b.find(side, price)
+	switch {
+	case size <= 0 && found:
+		levels = slices.Delete(levels, i, i+1)
+	case size <= 0:
+	case found:
+		levels[i].Size = size
+	default:
+		levels = slices.Insert(levels, i, PriceLevel{Price: price, Size: size})
+	}
+	if side == BookBid {
+		b.Bids = levels
+	} else {
+		b.Asks = levels
+	}
+}
+
+// find returns the index of price on a side, or where it would be inserted.
+func (b *OrderBook) find(side BookSide, price float64) (int, bool) {
+	return slices.BinarySearchFunc(b.Levels(side), price, func(l PriceLevel, p float64) int {
+		switch {
+		case math.Abs(l.Price-p) <= priceEpsilon:
+			return 0
+		case better(side, l.Price, p):
+			return -1
+		default:
+			return 1
+		}
+	})
+}
+
+// better reports whether price a is better than b for a taker: a higher bid
+// or a lower ask.
+func better(side BookSide, a, b float64) bool {
+	if side == BookBid {
+		return a > b
+	}
+	return a < b
+}
+
+// Best returns the best level of a side.
+func (b *OrderBook) Best(side BookSide) (PriceLevel, bool) {
+	levels := b.Levels(side)
+	if len(levels) == 0 {
+		return PriceLevel{}, false
+	}
+	return levels[0], true
+}
+
+// Quote returns the best bid and ask, zero for an empty side.
+func (b *OrderBook) Quote() SideQuote {
+	bid, _ := b.Best(BookBid)
+	ask, _ := b.Best(BookAsk)
+	return SideQuote{Bid: bid.Price, Ask: ask.Price}
+}
+
+// SizeAt returns the shares resting at price.
+func (b *OrderBook) SizeAt(side BookSide, price float64) float64 {
+	if i, found := b.find(side, price); found {
+		return b.Levels(side)[i].Size
+	}
+	return 0
+}
+
+// Depth returns the shares and their USD value resting at limit or better:
+// asks at or below limit, bids at or above it.
+func (b *OrderBook) Depth(side BookSide, limit float64) (shares, usd float64) {
+	for _, l := range b.Levels(side) {
+		worse := l.Price > limit+priceEpsilon
+		if side == BookBid {
+			worse = l.Price < limit-priceEpsilon
+		}
+		if worse {
+			break
+		}
+		shares += l.Size
+		usd += l.Size * l.Price
+	}
+	return shares, usd
+}
+
+// BuyCost walks the asks to buy shares and returns the USD cost; the VWAP is
+// cost/shares. ok is false if the asks hold fewer shares, in which case cost
+// is for all of them.
+func (b *OrderBook) BuyCost(shares float64) (costUSD float64, ok bool) {
+	return walk(b.Asks, shares)
+}
+
+// SellProceeds walks the bids to sell shares, like BuyCost.
+func (b *OrderBook) SellProceeds(shares float64) (proceedsUSD float64, ok bool) {
+	return walk(b.Bids, shares)
+}
+
+// BuyWithUSD walks the asks spending usd and returns the shares bought and
+// the worst price reached. ok is false if the asks are worth less than usd,
+// in which case shares is all of them.
+func (b *OrderBook) BuyWithUSD(usd float64) (shares, worstPrice float64, ok bool) {
+	remaining := usd
+	for _, l := range b.Asks {
+		if remaining <= priceEpsilon {
+			break
+		}
+		n := math.Min(l.Size, remaining/l.Price)
+		shares += n
+		worstPrice = l.Price
+		remaining -= n * l.Price
+	}
+	return shares, worstPrice, remaining <= priceEpsilon
+}
+
+// MaxBuyAtAvg returns the most USD that can be spent on the asks while the
+// average fill price stays at or below avgLimit.
+func (b *OrderBook) MaxBuyAtAvg(avgLimit float64) float64 {
+	var shares, cost float64
+	for _, l := range b.Asks {
+		n := l.Size
+		if l.Price > avgLimit+priceEpsilon {
+			// Shares at this price that keep (cost + n*price)/(shares + n) <= avgLimit
+			n = math.Min(n, (avgLimit*shares-cost)/(l.Price-avgLimit))
+			if n <= 0 {
+				break
+			}
+		}
+		shares += n
+		cost += n * l.Price
+		if n < l.Size {
+			break
+		}
+	}
+	return cost
+}
+
+func walk(levels []PriceLevel, shares float64) (usd float64, ok bool) {
+	remaining := shares
+	for _, l := range levels {
+		if remaining <= priceEpsilon {
+			break
+		}
+		n := math.Min(l.Size, remaining)
+		usd += n * l.Price
+		remaining -= n
+	}
+	return usd, remaining <= priceEpsilon
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrderBook(t *testing.T) {
+	newBook := func() *OrderBook {
+		// Levels in the CLOB's worst-first order
+		return NewOrderBook("t1",
+			[]PriceLevel{{Price: 0.40, Size: 100}, {Price: 0.45, Size: 10}},
+			[]PriceLevel{{Price: 0.60, Size: 100}, {Price: 0.55, Size: 20}, {Price: 0.50, Size: 10}},
+		)
+	}
+
+	t.Run("sorted_best_first", func(t *testing.T) {
+		b := newBook()
+		if q := b.Quote(); q.Bid != 0.45 || q.Ask != 0.50 {
+			t.Errorf("expected 0.45/0.50, got %+v", q)
+		}
+		if b.Asks[2].Price != 0.60 || b.Bids[1].Price != 0.40 {
+			t.Errorf("unexpected level order %+v %+v", b.Bids, b.Asks)
+		}
+	})
+
+	t.Run("updates_keep_order", func(t *testing.T) {
+		b := newBook()
+		b.Set(BookAsk, 0.48, 5)  // new best ask
+		b.Set(BookBid, 0.47, 7)  // new best bid
+		b.Set(BookAsk, 0.50, 0)  // level removed
+		b.Set(BookAsk, 0.55, 25) // size replaced
+		if q := b.Quote(); q.Bid != 0.47 || q.Ask != 0.48 {
+			t.Errorf("expected 0.47/0.48, got %+v", q)
+		}
+		if len(b.Asks) != 3 || b.SizeAt(BookAsk, 0.50) != 0 || b.SizeAt(BookAsk, 0.55) != 25 {
+			t.Errorf("unexpected asks %+v", b.Asks)
+		}
+	})
+
+	t.Run("depth_within_limit", func(t *testing.T) {
+		b := newBook()
+		shares, usd := b.Depth(BookAsk, 0.55)
+		if shares != 30 || math.Abs(usd-16) > 1e-9 {
+			t.Errorf("expected 30 shares for $16, got %f for %f", shares, usd)
+		}
+		if shares, _ := b.Depth(BookBid, 0.45); shares != 10 {
+			t.Errorf("expected 10 bid shares at 0.45 or better, got %f", shares)
+		}
+	})
+
+	t.Run("buy_cost_walks_asks", func(t *testing.T) {
+		b := newBook()
+		cost, ok := b.BuyCost(20)
+		if !ok || math.Abs(cost-10.5) > 1e-9 {
+			t.Errorf("expected $10.50 (VWAP 0.525), got %f (ok %v)", cost, ok)
+		}
+		if _, ok := b.BuyCost(500); ok {
+			t.Error("expected a buy beyond the asks to report ok=false")
+		}
+		proceeds, ok := b.SellProceeds(20)
+		if !ok || math.Abs(proceeds-8.5) > 1e-9 {
+			t.Errorf("expected $8.50, got %f (ok %v)", proceeds, ok)
+		}
+	})
+
+	t.Run("buy_with_usd", func(t *testing.T) {
+		b := newBook()
+		shares, worst, ok := b.BuyWithUSD(10)
+		// $5 buys 10 at 0.50, $5 buys 9.09 at 0.55
+		if !ok || math.Abs(shares-(10+5/0.55)) > 1e-9 || worst != 0.55 {
+			t.Errorf("expected %f shares up to 0.55, got %f up to %f", 10+5/0.55, shares, worst)
+		}
+		if shares, _, ok := b.BuyWithUSD(1000); ok || shares != 130 {
+			t.Errorf("expected all 130 shares with ok=false, got %f (ok %v)", shares, ok)
+		}
+	})
+
+	t.Run("max_buy_at_avg", func(t *testing.T) {
+		b := newBook()
+		if got := b.MaxBuyAtAvg(0.55); math.Abs(got-22) > 1e-9 {
+			t.Errorf("expected $22 keeping the average at 0.55, got %f", got)
+		}
+		if got := b.MaxBuyAtAvg(0.45); got != 0 {
+			t.Errorf("expected nothing below the best ask, got %f", got)
+		}
+		if got := b.MaxBuyAtAvg(1); math.Abs(got-76) > 1e-9 {
+			t.Errorf("expected the whole $76 book, got %f", got)
+		}
+	})
+
+	t.Run("clone_is_independent", func(t *testing.T) {
+		b := newBook()
+		c := b.Clone()
+		c.Set(BookAsk, 0.50, 0)
+		if b.SizeAt(BookAsk, 0.50) != 10 {
+			t.Error("expected the original book to be unchanged")
+		}
+	})
+}
@@ -9,6 +9,27 @@ const (
 	PositionDown PositionSide = "down"
 )
 
+// Reconciliation actions taken on a position drift.
+const (
+	ReconcileBootstrap = "bootstrap" // first pass adopts the venue position
+	ReconcileCorrected = "corrected" // drift within tolerance, local position set to the venue's
+	ReconcileHalted    = "halted"    // drift too large, trading halted for review
+	ReconcileDeferred  = "deferred"  // recent trades or pending orders; checked again next pass
+)
+
+// ReconcileEvent records a mismatch between local and venue inventory.
+type ReconcileEvent struct {
+	MarketID  MarketID
+	Side      PositionSide
+	TokenID   string
+	LocalQty  float64
+	VenueQty  float64
+	Drift     float64 // VenueQty - LocalQty
+	Action    string  // ReconcileBootstrap, ReconcileCorrected, ReconcileHalted or ReconcileDeferred
+	Detail    string
+	Timestamp time.Time
+}
+
 type Position struct {
 	MarketID         MarketID
 	Side             PositionSide
@@ -17,4 +38,5 @@ type Position struct {
 	NotionalUSD      float64
 	OpenedAt         time.Time
 	HoldToSettlement bool
+	RealizedPnL      float64 // P&L booked by selling before settlement
 }
@@ -72,8 +72,10 @@ type MarketState struct {
 	UpAsk       float64
 	DownBid     float64
 	DownAsk     float64
-	UpDepth     float64
-	DownDepth   float64
+	UpDepth     float64    // USD resting on the up asks (0 without a book)
+	DownDepth   float64    // USD resting on the down asks (0 without a book)
+	UpBook      *OrderBook // nil when no L2 book is available
+	DownBook    *OrderBook
 	Spread      float64
 	Timestamp   time.Time
 }
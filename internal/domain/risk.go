@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Circuit breaker event kinds.
+const (
+	BreakerTrip  = "trip"
+	BreakerReset = "reset"
+)
+
+// BreakerEvent records a circuit breaker trip or reset and the session state
+// at that moment.
+type BreakerEvent struct {
+	Kind              string // BreakerTrip or BreakerReset
+	Reason            string // trigger ("daily_loss", "consecutive_losses", "error_rate") or reset cause ("manual", "cooldown", "new_day")
+	Detail            string
+	RealizedPnL       float64 // realized P&L for the current day
+	UnrealizedPnL     float64 // mark-to-market P&L of open positions
+	ConsecutiveLosses int
+	ErrorRate         float64 // execution error rate over the recent window
+	Timestamp         time.Time
+}
@@ -10,18 +10,26 @@ const (
 	SignalBuyDown   TradeSignalSide = "buy_down"
 	SignalHedgeUp   TradeSignalSide = "hedge_up"
 	SignalHedgeDown TradeSignalSide = "hedge_down"
+	SignalSellUp    TradeSignalSide = "sell_up"
+	SignalSellDown  TradeSignalSide = "sell_down"
 )
 
+// IsSell reports whether the side sells held tokens rather than buying.
+func (s TradeSignalSide) IsSell() bool {
+	return s == SignalSellUp || s == SignalSellDown
+}
+
 type TradeSignal struct {
 	MarketID        MarketID
 	Side            TradeSignalSide
-	SignalType      string // "directional" or "hedge"
+	SignalType      string // "directional", "hedge" or "exit"
 	EdgeBuyUp       float64
 	EdgeBuyDown     float64
 	EffectiveHurdle float64
 	TargetSizeUSD   float64
 	GuaranteedFloor float64 // current floor value (for hedge signals)
 	HedgeEdge       float64 // ΔG from this trade (for hedge signals)
+	ExitEdge        float64 // bid net of cost over upper fair value (for exit signals)
 	Reason          string
 	Timestamp       time.Time
 }
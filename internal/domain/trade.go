@@ -5,22 +5,66 @@ import "time"
 type ExecutionRequest struct {
 	MarketID MarketID
 	Side     TradeSignalSide
-	MaxPrice float64
-	SizeUSD  float64
+	MaxPrice float64 // limit price; the minimum price for sells
+	SizeUSD  float64 // notional at MaxPrice
 	Reason   string
+	Limit    bool // rest on the book at MaxPrice (maker) instead of taking liquidity
 }
 
+// Fill roles: whether our order took or provided liquidity.
+const (
+	FillRoleMaker = "maker"
+	FillRoleTaker = "taker"
+)
+
 type Fill struct {
 	MarketID  MarketID
-	Side      TradeSignalSide
+	Side      TradeSignalSide // a sell side for tokens sold
 	Price     float64
-	SizeUSD   float64
-	Timestamp time.Time
+	SizeUSD   float64   // cost of a buy, proceeds of a sell
+	Timestamp time.Time // exchange match time when known
+
+	TradeID  string // exchange trade ID; empty for fills simulated in-process
+	Role     string // FillRoleMaker or FillRoleTaker
+	Reversed bool   // undoes the earlier fill of TradeID after the trade failed on-chain
 }
 
+// Settlement outcomes. Up/down markets resolve UP when the end price is
+// greater than or equal to the price to beat.
+const (
+	OutcomeUp   = "up"
+	OutcomeDown = "down"
+)
+
+// Settlement sources. A settlement is first booked from the Chainlink price
+// at EndTime and later confirmed (or corrected) against the venue.
+const (
+	SettlementSourceChainlink = "chainlink"
+	SettlementSourceVenue     = "gamma"
+)
+
 type Settlement struct {
-	MarketID    MarketID
-	Outcome     string
-	SettledAt   time.Time
-	RealizedPnL float64
+	MarketID        MarketID
+	Slug            string
+	Asset           string
+	Outcome         string
+	PriceToBeat     float64
+	SettlementPrice float64 // Chainlink price at EndTime (0 if resolved by venue only)
+	UpQuantity      float64
+	DownQuantity    float64
+	UpCost          float64
+	DownCost        float64
+	UpPnL           float64
+	DownPnL         float64
+	Source          string
+	Confirmed       bool // true once the venue's official outcome has been checked
+	SettledAt       time.Time
+	RealizedPnL     float64
+}
+
+// PendingRedemption is a settled condition whose leftover tokens wait for
+// on-chain resolution to be redeemed.
+type PendingRedemption struct {
+	MarketID MarketID
+	QueuedAt time.Time
 }
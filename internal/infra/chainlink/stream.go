@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/infra/retry"
+	"Polybot/internal/ports"
 
 	streams "github.com/smartcontractkit/data-streams-sdk/go"
 	"github.com/smartcontractkit/data-streams-sdk/go/feed"
@@ -23,12 +26,37 @@ type FeedMapping struct {
 	FeedID feed.ID
 }
 
-// StreamConfig holds Chainlink Data Streams connection settings.
+// StreamConfig holds Chainlink Data Streams connection settings. Zero
+// durations take defaults.
 type StreamConfig struct {
 	ApiKey    string // CHAINLINK_USER_ID
 	ApiSecret string // CHAINLINK_SECRET
 	RestURL   string
 	WsURL     string
+
+	HA           bool // keep concurrent connections to several Streams servers (SDK HA mode)
+	MaxReconnect int  // SDK reconnects per connection before a read fails (0 = SDK default)
+
+	StallTimeout   time.Duration // reopen the stream after this long without a report (default: 30s)
+	MinBackoff     time.Duration // first delay before reopening, doubled per failure (default: 500ms)
+	MaxBackoff     time.Duration // default: 30s
+	MaxGapFill     time.Duration // most recent part of an outage backfilled over REST (default: 1m)
+	GapFillTimeout time.Duration // bound on each backfill fetch (default: 2s)
+	HealthInterval time.Duration // how often stream health is logged (default: 1m)
+}
+
+// StreamHealth describes one asset's report stream.
+type StreamHealth struct {
+	Connected         bool
+	LastReport        time.Time // observation time of the latest report
+	LastReceived      time.Time // when it arrived
+	Reports           uint64    // reports delivered, including gap fills
+	GapFilled         uint64    // reports recovered over REST after an outage
+	Reconnects        uint64    // times the stream was reopened
+	LastError         string
+	ActiveConnections uint64 // from the SDK; several in HA mode
+	Deduplicated      uint64 // duplicate reports the SDK dropped in HA mode
+	PartialReconnects uint64 // SDK reconnects of single HA connections
 }
 
 // Stream connects to Chainlink Data Streams for reference prices.
@@ -39,23 +67,49 @@ type Stream struct {
 	mu     sync.RWMutex
 	latest map[string]domain.ReferenceSnapshot
 	feeds  map[string]feed.ID // asset -> feed ID
+
+	recorder ports.RawRecorder // optional: raw report capture
+
+	healthMu sync.Mutex
+	health   map[string]*StreamHealth // by asset
 }
 
 func NewStream(cfg StreamConfig, logger *slog.Logger) (*Stream, error) {
+	if cfg.StallTimeout <= 0 {
+		cfg.StallTimeout = 30 * time.Second
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.MaxGapFill <= 0 {
+		cfg.MaxGapFill = time.Minute
+	}
+	if cfg.GapFillTimeout <= 0 {
+		cfg.GapFillTimeout = 2 * time.Second
+	}
+	if cfg.HealthInterval <= 0 {
+		cfg.HealthInterval = time.Minute
+	}
 	s := &Stream{
 		config: cfg,
 		logger: logger,
 		latest: make(map[string]domain.ReferenceSnapshot),
 		feeds:  make(map[string]feed.ID),
+		health: make(map[string]*StreamHealth),
 	}
 
 	// Create the Chainlink Data Streams client
 	streamsCfg := streams.Config{
-		ApiKey:    cfg.ApiKey,
-		ApiSecret: cfg.ApiSecret,
-		RestURL:   cfg.RestURL,
-		WsURL:     cfg.WsURL,
-		Logger:    func(format string, a ...any) { logger.Debug(fmt.Sprintf(format, a...)) },
+		ApiKey:         cfg.ApiKey,
+		ApiSecret:      cfg.ApiSecret,
+		RestURL:        cfg.RestURL,
+		WsURL:          cfg.WsURL,
+		WsHA:           cfg.HA,
+		WsMaxReconnect: cfg.MaxReconnect,
+		Logger:         func(format string, a ...any) { logger.Debug(fmt.Sprintf(format, a...)) },
 	}
 
 	client, err := streams.New(streamsCfg)
@@ -92,6 +146,12 @@ func (s *Stream) RegisterFeed(asset string, feedID feed.ID) {
 	s.logger.Info("registered feed", "asset", asset, "feed_id", feedID.String())
 }
 
+// SetRecorder captures every streamed report before decoding.
+// Must be called before SubscribePrices.
+func (s *Stream) SetRecorder(rec ports.RawRecorder) {
+	s.recorder = rec
+}
+
 // RegisteredAssets returns the list of asset names with registered feeds.
 func (s *Stream) RegisteredAssets() []string {
 	s.mu.RLock()
@@ -126,7 +186,11 @@ func (s *Stream) GetPriceAtTime(ctx context.Context, asset string, ts time.Time)
 	return s.FetchReportAtTimestamp(ctx, asset, ts)
 }
 
-// SubscribePrices connects to the Chainlink WebSocket stream for one asset.
+// SubscribePrices streams reports for one asset until ctx ends. A failed or
+// silent stream is reopened with jittered exponential backoff, and the
+// seconds missed meanwhile are backfilled over REST before live reports
+// resume. Snapshots arrive in timestamp order; the channel closes when ctx
+// ends.
 func (s *Stream) SubscribePrices(ctx context.Context, asset string) (<-chan domain.ReferenceSnapshot, error) {
 	s.mu.RLock()
 	feedID, ok := s.feeds[asset]
@@ -137,53 +201,236 @@ func (s *Stream) SubscribePrices(ctx context.Context, asset string) (<-chan doma
 	}
 
 	ch := make(chan domain.ReferenceSnapshot, 256)
+	s.updateHealth(asset, func(*StreamHealth) {})
 
 	go func() {
 		defer close(ch)
-		s.logger.Info("connecting to chainlink stream", "asset", asset, "feed_id", feedID.String())
+		s.run(ctx, asset, feedID, ch)
+	}()
+	go s.logHealth(ctx, asset)
 
-		stream, err := s.client.Stream(ctx, []feed.ID{feedID})
-		if err != nil {
-			s.logger.Error("failed to open chainlink stream", "asset", asset, "error", err)
+	return ch, nil
+}
+
+// run keeps the asset's stream open until ctx ends.
+func (s *Stream) run(ctx context.Context, asset string, feedID feed.ID, ch chan<- domain.ReferenceSnapshot) {
+	var last time.Time // latest report delivered
+	failures := 0
+	for {
+		received, err := s.session(ctx, asset, feedID, ch, &last)
+		if ctx.Err() != nil {
+			return
+		}
+		// A stream that carried reports was healthy; start over
+		if received {
+			failures = 0
+		}
+		failures++
+		delay := retry.Backoff(failures, s.config.MinBackoff, s.config.MaxBackoff)
+		s.updateHealth(asset, func(h *StreamHealth) {
+			h.Connected = false
+			h.ActiveConnections = 0
+			h.LastError = err.Error()
+		})
+		s.logger.Warn("chainlink stream down, reconnecting", "asset", asset, "retry_in", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
 			return
+		case <-time.After(delay):
 		}
-		defer stream.Close()
+		s.updateHealth(asset, func(h *StreamHealth) { h.Reconnects++ })
+	}
+}
+
+// session opens one stream and reads it until it fails. After an outage it
+// first backfills from the last delivered report. It reports whether any
+// report arrived.
+func (s *Stream) session(ctx context.Context, asset string, feedID feed.ID, ch chan<- domain.ReferenceSnapshot, last *time.Time) (received bool, err error) {
+	s.logger.Info("connecting to chainlink stream", "asset", asset, "feed_id", feedID.String(), "ha", s.config.HA)
+	stream, err := s.client.Stream(ctx, []feed.ID{feedID})
+	if err != nil {
+		return false, fmt.Errorf("open stream: %w", err)
+	}
+	defer stream.Close()
 
-		s.logger.Info("chainlink stream connected", "asset", asset)
+	stats := stream.Stats()
+	s.updateHealth(asset, func(h *StreamHealth) {
+		h.Connected = true
+		h.ActiveConnections = stats.ActiveConnections
+	})
+	s.logger.Info("chainlink stream connected", "asset", asset, "connections", stats.ActiveConnections)
 
-		for {
-			report, err := stream.Read(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					return // context cancelled, clean shutdown
-				}
-				s.logger.Error("chainlink stream read error", "asset", asset, "error", err)
-				return
+	if !last.IsZero() {
+		s.fillGap(ctx, asset, ch, last)
+	}
+
+	for {
+		// No report within StallTimeout means the stream is dead even if
+		// its connections look up
+		readCtx, cancel := context.WithTimeout(ctx, s.config.StallTimeout)
+		report, err := stream.Read(readCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && readCtx.Err() != nil {
+				err = fmt.Errorf("no report for %s", s.config.StallTimeout)
 			}
+			return received, err
+		}
+		received = true
 
-			snap, err := s.reportToSnapshot(asset, report)
-			if err != nil {
-				s.logger.Warn("failed to decode report", "asset", asset, "error", err)
-				continue
+		if s.recorder != nil {
+			if raw, err := report.MarshalJSON(); err == nil {
+				s.recorder.Record(recorder.SourceChainlink, asset, raw)
 			}
+		}
 
-			s.updateLatest(snap)
+		snap, err := DecodeReport(asset, report)
+		if err != nil {
+			s.logger.Warn("failed to decode report", "asset", asset, "error", err)
+			continue
+		}
 
-			s.logger.Debug("chainlink price",
-				"asset", asset,
-				"price", snap.Price,
-				"timestamp", snap.Timestamp.Format(time.RFC3339Nano),
-			)
+		stats := stream.Stats()
+		s.updateHealth(asset, func(h *StreamHealth) {
+			h.ActiveConnections = stats.ActiveConnections
+			h.Deduplicated = stats.Deduplicated
+			h.PartialReconnects = stats.PartialReconnects
+		})
+
+		s.logger.Debug("chainlink price",
+			"asset", asset,
+			"price", snap.Price,
+			"timestamp", snap.Timestamp.Format(time.RFC3339Nano),
+		)
+		s.deliver(ch, snap, last)
+	}
+}
+
+// gapFillWorkers bounds the concurrent REST fetches of one backfill.
+const gapFillWorkers = 8
+
+// fillGap fetches one report per second after last, up to now, and delivers
+// them in order. Only the latest MaxGapFill of a longer outage is fetched.
+// The fetches run concurrently, each bounded by GapFillTimeout, so a slow
+// REST endpoint holds up the live stream for seconds rather than minutes.
+func (s *Stream) fillGap(ctx context.Context, asset string, ch chan<- domain.ReferenceSnapshot, last *time.Time) {
+	now := time.Now().Truncate(time.Second)
+	from := last.Add(time.Second)
+	if earliest := now.Add(-s.config.MaxGapFill); from.Before(earliest) {
+		s.logger.Warn("chainlink gap longer than fill window, older reports skipped",
+			"asset", asset, "gap_start", from, "fill_from", earliest)
+		from = earliest
+	}
+	if from.After(now) {
+		return
+	}
 
-			select {
-			case ch <- snap:
-			default:
-				// Channel full, skip oldest
+	n := int(now.Sub(from)/time.Second) + 1
+	snaps := make([]domain.ReferenceSnapshot, n)
+	fetched := make([]bool, n)
+	sem := make(chan struct{}, gapFillWorkers)
+	var wg sync.WaitGroup
+	for i := range n {
+		ts := from.Add(time.Duration(i) * time.Second)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			fetchCtx, cancel := context.WithTimeout(ctx, s.config.GapFillTimeout)
+			defer cancel()
+			snap, err := s.FetchReportAtTimestamp(fetchCtx, asset, ts)
+			if err != nil {
+				s.logger.Debug("chainlink gap fill failed", "asset", asset, "timestamp", ts, "error", err)
+				return
 			}
+			snaps[i], fetched[i] = snap, true
+		}()
+	}
+	wg.Wait()
+
+	filled := 0
+	for i, snap := range snaps {
+		if fetched[i] && s.deliver(ch, snap, last) {
+			filled++
 		}
-	}()
+	}
+	s.updateHealth(asset, func(h *StreamHealth) { h.GapFilled += uint64(filled) })
+	s.logger.Info("chainlink gap filled", "asset", asset, "from", from, "to", now, "reports", filled)
+}
 
-	return ch, nil
+// deliver records snap as the latest price and sends it, unless it is not
+// newer than last (a gap-filled or duplicate report). It reports whether
+// snap was delivered.
+func (s *Stream) deliver(ch chan<- domain.ReferenceSnapshot, snap domain.ReferenceSnapshot, last *time.Time) bool {
+	if !snap.Timestamp.After(*last) {
+		return false
+	}
+	*last = snap.Timestamp
+	s.updateLatest(snap)
+	s.updateHealth(snap.Asset, func(h *StreamHealth) {
+		h.Reports++
+		h.LastReport = snap.Timestamp
+		h.LastReceived = time.Now()
+	})
+
+	select {
+	case ch <- snap:
+	default:
+		// Channel full, skip oldest
+	}
+	return true
+}
+
+// Health returns the health of every subscribed asset's stream.
+func (s *Stream) Health() map[string]StreamHealth {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	out := make(map[string]StreamHealth, len(s.health))
+	for asset, h := range s.health {
+		out[asset] = *h
+	}
+	return out
+}
+
+func (s *Stream) updateHealth(asset string, fn func(*StreamHealth)) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	h, ok := s.health[asset]
+	if !ok {
+		h = &StreamHealth{}
+		s.health[asset] = h
+	}
+	fn(h)
+}
+
+// logHealth logs the asset's stream health every HealthInterval.
+func (s *Stream) logHealth(ctx context.Context, asset string) {
+	ticker := time.NewTicker(s.config.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		h := s.Health()[asset]
+		var age time.Duration
+		if !h.LastReport.IsZero() {
+			age = time.Since(h.LastReport).Round(time.Millisecond)
+		}
+		s.logger.Info("chainlink stream health",
+			"asset", asset,
+			"connected", h.Connected,
+			"connections", h.ActiveConnections,
+			"reports", h.Reports,
+			"gap_filled", h.GapFilled,
+			"reconnects", h.Reconnects,
+			"deduplicated", h.Deduplicated,
+			"report_age", age,
+			"last_error", h.LastError,
+		)
+	}
 }
 
 // FetchReportAtTimestamp fetches the price for an asset at a specific timestamp.
@@ -207,7 +454,7 @@ func (s *Stream) FetchReportAtTimestamp(ctx context.Context, asset string, ts ti
 		return domain.ReferenceSnapshot{}, fmt.Errorf("no report at %v for %s", ts, asset)
 	}
 
-	return s.reportToSnapshot(asset, reports[0])
+	return DecodeReport(asset, reports[0])
 }
 
 // FetchLatestReport does a one-shot REST fetch of the latest price for an asset.
@@ -228,10 +475,12 @@ func (s *Stream) FetchLatestReport(ctx context.Context, asset string) (domain.Re
 		return domain.ReferenceSnapshot{}, fmt.Errorf("get latest report: %w", err)
 	}
 
-	return s.reportToSnapshot(asset, report)
+	return DecodeReport(asset, report)
 }
 
-func (s *Stream) reportToSnapshot(asset string, report *streams.ReportResponse) (domain.ReferenceSnapshot, error) {
+// DecodeReport converts a v3 (crypto) report into a reference snapshot
+// stamped with its observation time.
+func DecodeReport(asset string, report *streams.ReportResponse) (domain.ReferenceSnapshot, error) {
 	decoded, err := streamsReport.Decode[v3.Data](report.FullReport)
 	if err != nil {
 		return domain.ReferenceSnapshot{}, fmt.Errorf("decode v3 report: %w", err)
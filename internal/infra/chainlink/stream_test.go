@@ -0,0 +1,238 @@
+package chainlink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	streams "github.com/smartcontractkit/data-streams-sdk/go"
+	"github.com/smartcontractkit/data-streams-sdk/go/feed"
+	v3 "github.com/smartcontractkit/data-streams-sdk/go/report/v3"
+)
+
+// fakeStream returns its reports, then fails with err, or blocks until the
+// read context ends when err is nil.
+type fakeStream struct {
+	mu      sync.Mutex
+	reports []*streams.ReportResponse
+	err     error
+}
+
+func (f *fakeStream) Read(ctx context.Context) (*streams.ReportResponse, error) {
+	f.mu.Lock()
+	if len(f.reports) > 0 {
+		r := f.reports[0]
+		f.reports = f.reports[1:]
+		f.mu.Unlock()
+		return r, nil
+	}
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakeStream) Stats() streams.Stats { return streams.Stats{ActiveConnections: 1} }
+func (f *fakeStream) Close() error         { return nil }
+
+// fakeClient opens its streams in order, then fails. REST reports exist for
+// the timestamps in rest; fetches of those in hang block until cancelled.
+type fakeClient struct {
+	streams.Client
+	mu      sync.Mutex
+	streams []*fakeStream
+	rest    map[int64]*streams.ReportResponse
+	hang    map[int64]bool
+}
+
+func (c *fakeClient) Stream(context.Context, []feed.ID) (streams.Stream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.streams) == 0 {
+		return nil, errors.New("unavailable")
+	}
+	s := c.streams[0]
+	c.streams = c.streams[1:]
+	return s, nil
+}
+
+func (c *fakeClient) GetReports(ctx context.Context, _ []feed.ID, ts uint64) ([]*streams.ReportResponse, error) {
+	if c.hang[int64(ts)] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if r, ok := c.rest[int64(ts)]; ok {
+		return []*streams.ReportResponse{r}, nil
+	}
+	return nil, nil
+}
+
+func bytesType(t *testing.T, name string) abi.Type {
+	t.Helper()
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return typ
+}
+
+// testReport encodes a v3 report observed at ts with the given price.
+func testReport(t *testing.T, ts time.Time, price int64) *streams.ReportResponse {
+	t.Helper()
+	scaled := new(big.Int).Mul(big.NewInt(price), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	unix := uint32(ts.Unix())
+	blob, err := v3.Schema().Pack([32]byte{}, unix, unix, big.NewInt(0), big.NewInt(0), unix+60, scaled, scaled, scaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := abi.Arguments{
+		{Type: bytesType(t, "bytes32[3]")},
+		{Type: bytesType(t, "bytes")},
+		{Type: bytesType(t, "bytes32[]")},
+		{Type: bytesType(t, "bytes32[]")},
+		{Type: bytesType(t, "bytes32")},
+	}
+	report, err := full.Pack([3][32]byte{}, blob, [][32]byte{}, [][32]byte{}, [32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &streams.ReportResponse{FullReport: report, ObservationsTimestamp: uint64(unix)}
+}
+
+func TestStream_Reconnect(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	s, err := NewStream(StreamConfig{
+		ApiKey:       "key",
+		ApiSecret:    "secret",
+		RestURL:      "http://localhost",
+		WsURL:        "ws://localhost",
+		StallTimeout: 100 * time.Millisecond,
+		MinBackoff:   time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.RegisterFeed("btc", feed.ID{})
+
+	base := time.Now().Truncate(time.Second).Add(-10 * time.Second)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+	client := &fakeClient{
+		streams: []*fakeStream{
+			// Drops after two reports
+			{reports: []*streams.ReportResponse{testReport(t, at(0), 100), testReport(t, at(1), 101)}, err: errors.New("connection lost")},
+			// Resends a gap-filled second, then goes silent
+			{reports: []*streams.ReportResponse{testReport(t, at(4), 104), testReport(t, at(6), 106)}},
+		},
+		rest: map[int64]*streams.ReportResponse{},
+	}
+	for sec := 2; sec <= 5; sec++ {
+		client.rest[at(sec).Unix()] = testReport(t, at(sec), int64(100+sec))
+	}
+	s.client = client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := s.SubscribePrices(ctx, "btc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var prices []float64
+	timeout := time.After(2 * time.Second)
+	for len(prices) < 7 {
+		select {
+		case snap := <-ch:
+			prices = append(prices, snap.Price)
+		case <-timeout:
+			t.Fatalf("timed out with prices %v", prices)
+		}
+	}
+	for i, p := range prices {
+		if p != float64(100+i) {
+			t.Fatalf("expected one report per second in order, got %v", prices)
+		}
+	}
+
+	// The silent second stream is replaced; the third open fails
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		h := s.Health()["btc"]
+		if h.Reconnects >= 2 {
+			if h.Reports != 7 || h.GapFilled != 4 || h.Connected {
+				t.Errorf("unexpected health %+v", h)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the stalled stream to be reopened, health %+v", h)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	for range ch {
+	}
+	if h := s.Health()["btc"]; !strings.Contains(h.LastError, "unavailable") && !strings.Contains(h.LastError, "no report") {
+		t.Errorf("unexpected last error %q", h.LastError)
+	}
+}
+
+func TestStream_FillGap(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	s, err := NewStream(StreamConfig{
+		ApiKey:         "key",
+		ApiSecret:      "secret",
+		RestURL:        "http://localhost",
+		WsURL:          "ws://localhost",
+		GapFillTimeout: 50 * time.Millisecond,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.RegisterFeed("btc", feed.ID{})
+
+	last := time.Now().Truncate(time.Second).Add(-30 * time.Second)
+	at := func(sec int) time.Time { return last.Add(time.Duration(sec) * time.Second) }
+	client := &fakeClient{rest: map[int64]*streams.ReportResponse{}, hang: map[int64]bool{}}
+	for sec := 1; sec <= 30; sec++ {
+		client.rest[at(sec).Unix()] = testReport(t, at(sec), int64(100+sec))
+		// Every third second's fetch never answers
+		if sec%3 == 0 {
+			client.hang[at(sec).Unix()] = true
+		}
+	}
+	s.client = client
+
+	ch := make(chan domain.ReferenceSnapshot, 64)
+	start := time.Now()
+	s.fillGap(context.Background(), "btc", ch, &last)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected hung fetches to time out concurrently, took %v", elapsed)
+	}
+	close(ch)
+
+	var prev float64
+	count := 0
+	for snap := range ch {
+		if snap.Price <= prev || int(snap.Price-100)%3 == 0 {
+			t.Fatalf("unexpected report %v after %v", snap.Price, prev)
+		}
+		prev = snap.Price
+		count++
+	}
+	if count != 20 || s.Health()["btc"].GapFilled != 20 {
+		t.Errorf("expected the 20 answered seconds delivered in order, got %d (health %+v)", count, s.Health()["btc"])
+	}
+}
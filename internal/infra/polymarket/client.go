@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,6 +20,7 @@ type ClobClient struct {
 	mode      int
 	builder   *OrderBuilder
 	http      *HTTPClient
+	cacheMu   sync.RWMutex // guards the per-token caches below; shared across market streams
 	tickSizes map[string]string
 	negRisk   map[string]bool
 	feeRates  map[string]int
@@ -84,6 +87,15 @@ func NewClobClient(key string, signatureType uint8, funder string) (*ClobClient,
 		feeRates:  map[string]int{},
 	}
 
+	// Throttle order traffic below the CLOB's per-endpoint limits so bursts
+	// of quotes and cancels wait here instead of coming back as 429s
+	client.http.SetRateLimit("POST", PostOrderEndpoint, 40, 80)
+	client.http.SetRateLimit("POST", PostOrdersEndpoint, 10, 20)
+	client.http.SetRateLimit("DELETE", CancelEndpoint, 40, 80)
+	client.http.SetRateLimit("DELETE", CancelOrdersEndpoint, 10, 20)
+	client.http.SetRateLimit("DELETE", CancelAllEndpoint, 2, 5)
+	client.http.SetRateLimit("POST", CancelMarketOrdersEndpoint, 10, 20)
+
 	if signer != nil {
 		client.builder = NewOrderBuilder(signer, signatureType, funder)
 		client.mode = L1
@@ -150,21 +162,12 @@ func (c *ClobClient) CreateOrDeriveAPICreds(nonce int64) (*ApiCreds, error) {
 	return c.DeriveAPIKey(nonce)
 }
 
-func (c *ClobClient) GetMarket(conditionID string) (any, error) {
-	return c.http.Request("GET", c.clobHost+GetMarketEndpoint+conditionID, nil, nil)
-}
-
-func (c *ClobClient) GetOrder(orderID string) (any, error) {
-	if err := c.assertLevel2(); err != nil {
-		return nil, err
-	}
-	reqPath := GetOrderEndpoint + orderID
-	reqArgs := RequestArgs{Method: "GET", RequestPath: reqPath, Body: nil}
-	headers, err := CreateLevel2Headers(c.signer, *c.creds, reqArgs)
-	if err != nil {
+func (c *ClobClient) GetOrder(orderID string) (*OpenOrder, error) {
+	var resp OpenOrder
+	if err := c.l2Request("GET", GetOrderEndpoint+orderID, nil, nil, &resp); err != nil {
 		return nil, err
 	}
-	return c.http.Request("GET", c.clobHost+reqPath, headers, nil)
+	return &resp, nil
 }
 
 func (c *ClobClient) GetTradesTyped(params map[string]string) (*TradesResponse, error) {
@@ -209,7 +212,113 @@ func (c *ClobClient) GetActiveOrdersTyped(params map[string]string) (*ActiveOrde
 	return &resp, nil
 }
 
-func (c *ClobClient) GetPositions(user string, params map[string]string) (any, error) {
+// GetBalanceAllowance returns the wallet's balance of an asset and the
+// exchange's approval to spend it, as last cached by the CLOB.
+func (c *ClobClient) GetBalanceAllowance(params BalanceAllowanceParams) (*BalanceAllowanceResponse, error) {
+	var resp BalanceAllowanceResponse
+	if err := c.balanceAllowanceRequest(GetBalanceAllowanceEndpoint, params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateBalanceAllowance makes the CLOB re-read the asset's balance and
+// allowance from chain, e.g. after a deposit or a new approval.
+func (c *ClobClient) UpdateBalanceAllowance(params BalanceAllowanceParams) error {
+	var resp any
+	return c.balanceAllowanceRequest(UpdateBalanceAllowanceEndpoint, params, &resp)
+}
+
+func (c *ClobClient) balanceAllowanceRequest(endpoint string, params BalanceAllowanceParams, out any) error {
+	if err := c.assertLevel2(); err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("asset_type", string(params.AssetType))
+	if params.TokenID != "" {
+		values.Set("token_id", params.TokenID)
+	}
+	values.Set("signature_type", strconv.Itoa(int(c.builder.sigType)))
+	return c.l2Request("GET", endpoint, values, nil, out)
+}
+
+// GetNotifications returns the user's unread notifications.
+func (c *ClobClient) GetNotifications() ([]Notification, error) {
+	if err := c.assertLevel2(); err != nil {
+		return nil, err
+	}
+	values := url.Values{"signature_type": {strconv.Itoa(int(c.builder.sigType))}}
+	var resp []Notification
+	if err := c.l2Request("GET", GetNotificationsEndpoint, values, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DropNotifications marks notifications as read.
+func (c *ClobClient) DropNotifications(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := url.Values{"ids": {strings.Join(ids, ",")}}
+	return c.l2Request("DELETE", DropNotificationsEndpoint, values, nil, nil)
+}
+
+// IsOrderScoring reports whether a resting order earns liquidity rewards.
+func (c *ClobClient) IsOrderScoring(orderID string) (bool, error) {
+	var resp struct {
+		Scoring bool `json:"scoring"`
+	}
+	if err := c.l2Request("GET", IsOrderScoringEndpoint, url.Values{"order_id": {orderID}}, nil, &resp); err != nil {
+		return false, err
+	}
+	return resp.Scoring, nil
+}
+
+// AreOrdersScoring reports per order ID whether it earns liquidity rewards.
+func (c *ClobClient) AreOrdersScoring(orderIDs []string) (map[string]bool, error) {
+	if len(orderIDs) == 0 {
+		return map[string]bool{}, nil
+	}
+	var resp map[string]bool
+	if err := c.l2Request("POST", AreOrdersScoringEndpoint, nil, orderIDs, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// l2Request sends a request with L2 headers. The signature covers the path
+// and body but not the query string.
+func (c *ClobClient) l2Request(method, endpoint string, query url.Values, body any, out any) error {
+	if err := c.assertLevel2(); err != nil {
+		return err
+	}
+	reqArgs := RequestArgs{Method: method, RequestPath: endpoint, Body: body}
+	if body != nil {
+		serialized, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqArgs.SerializedBody = string(serialized)
+	}
+	headers, err := CreateLevel2Headers(c.signer, *c.creds, reqArgs)
+	if err != nil {
+		return err
+	}
+	reqPath := endpoint
+	if len(query) > 0 {
+		reqPath += "?" + query.Encode()
+	}
+	var reqBody any
+	if body != nil {
+		reqBody = reqArgs.SerializedBody
+	}
+	return c.http.RequestInto(method, c.clobHost+reqPath, headers, reqBody, out)
+}
+
+// GetPositions returns a wallet's positions from the data API. Params are
+// passed through as query filters, e.g. "market" or "sizeThreshold".
+func (c *ClobClient) GetPositions(user string, params map[string]string) ([]Position, error) {
 	values := url.Values{}
 	values.Set("user", user)
 	for key, value := range params {
@@ -220,14 +329,11 @@ func (c *ClobClient) GetPositions(user string, params map[string]string) (any, e
 	if query != "" {
 		reqPath += "?" + query
 	}
-	return c.http.Request("GET", c.dataHost+reqPath, nil, nil)
-}
-
-func (c *ClobClient) GetMarkets(nextCursor string) (any, error) {
-	if nextCursor == "" {
-		nextCursor = "MA=="
+	var resp []Position
+	if err := c.http.RequestInto("GET", c.dataHost+reqPath, nil, nil, &resp); err != nil {
+		return nil, err
 	}
-	return c.http.Request("GET", fmt.Sprintf("%s%s?next_cursor=%s", c.clobHost, GetMarketsEndpoint, nextCursor), nil, nil)
+	return resp, nil
 }
 
 func (c *ClobClient) GetOrderBook(tokenID string) (OrderBookSummary, error) {
@@ -256,7 +362,10 @@ func (c *ClobClient) GetOrderBooks(tokenIDs []string) (map[string]OrderBookSumma
 }
 
 func (c *ClobClient) GetTickSize(tokenID string) (string, error) {
-	if val, ok := c.tickSizes[tokenID]; ok {
+	c.cacheMu.RLock()
+	val, ok := c.tickSizes[tokenID]
+	c.cacheMu.RUnlock()
+	if ok {
 		return val, nil
 	}
 	resp, err := c.http.Request("GET", fmt.Sprintf("%s%s?token_id=%s", c.clobHost, GetTickSizeEndpoint, tokenID), nil, nil)
@@ -268,12 +377,17 @@ func (c *ClobClient) GetTickSize(tokenID string) (string, error) {
 		return "", errors.New("invalid tick size response")
 	}
 	value := stringFromAny(result["minimum_tick_size"])
+	c.cacheMu.Lock()
 	c.tickSizes[tokenID] = value
+	c.cacheMu.Unlock()
 	return value, nil
 }
 
 func (c *ClobClient) GetNegRisk(tokenID string) (bool, error) {
-	if val, ok := c.negRisk[tokenID]; ok {
+	c.cacheMu.RLock()
+	val, ok := c.negRisk[tokenID]
+	c.cacheMu.RUnlock()
+	if ok {
 		return val, nil
 	}
 	resp, err := c.http.Request("GET", fmt.Sprintf("%s%s?token_id=%s", c.clobHost, GetNegRiskEndpoint, tokenID), nil, nil)
@@ -285,12 +399,17 @@ func (c *ClobClient) GetNegRisk(tokenID string) (bool, error) {
 		return false, errors.New("invalid neg risk response")
 	}
 	value := toBool(result["neg_risk"])
+	c.cacheMu.Lock()
 	c.negRisk[tokenID] = value
+	c.cacheMu.Unlock()
 	return value, nil
 }
 
 func (c *ClobClient) GetFeeRateBps(tokenID string) (int, error) {
-	if val, ok := c.feeRates[tokenID]; ok {
+	c.cacheMu.RLock()
+	val, ok := c.feeRates[tokenID]
+	c.cacheMu.RUnlock()
+	if ok {
 		return val, nil
 	}
 	resp, err := c.http.Request("GET", fmt.Sprintf("%s%s?token_id=%s", c.clobHost, GetFeeRateEndpoint, tokenID), nil, nil)
@@ -313,7 +432,9 @@ func (c *ClobClient) GetFeeRateBps(tokenID string) (int, error) {
 			baseFee = int(parsed)
 		}
 	}
+	c.cacheMu.Lock()
 	c.feeRates[tokenID] = baseFee
+	c.cacheMu.Unlock()
 	return baseFee, nil
 }
 
@@ -406,7 +527,14 @@ func (c *ClobClient) PostOrder(order *SignedOrder, orderType OrderType) (any, er
 	if err != nil {
 		return nil, err
 	}
-	return c.http.Request("POST", c.clobHost+PostOrderEndpoint, headers, reqArgs.SerializedBody)
+	resp, err := c.http.Request("POST", c.clobHost+PostOrderEndpoint, headers, reqArgs.SerializedBody)
+	if err != nil {
+		return nil, err
+	}
+	if err := orderRejection("POST", PostOrderEndpoint, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (c *ClobClient) PostOrders(args []PostOrdersArgs) (any, error) {
@@ -0,0 +1,194 @@
+package polymarket
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Public CLOB read endpoints: prices, spreads and market listings. None of
+// them need authentication.
+
+func (c *ClobClient) GetMidpoint(tokenID string) (float64, error) {
+	var resp struct {
+		Mid stringOrNumber `json:"mid"`
+	}
+	if err := c.publicGet(MidPointEndpoint, url.Values{"token_id": {tokenID}}, &resp); err != nil {
+		return 0, err
+	}
+	return parseDecimal(resp.Mid, "midpoint")
+}
+
+// GetMidpoints returns the midpoint per token ID.
+func (c *ClobClient) GetMidpoints(tokenIDs []string) (map[string]float64, error) {
+	var resp map[string]stringOrNumber
+	if err := c.http.RequestInto("POST", c.clobHost+MidPointsEndpoint, nil, tokenParams(tokenIDs), &resp); err != nil {
+		return nil, err
+	}
+	return parseDecimalMap(resp, "midpoint")
+}
+
+// GetPrice returns the best price a side can trade at: the best bid for
+// SELL, the best ask for BUY.
+func (c *ClobClient) GetPrice(tokenID string, side OrderSide) (float64, error) {
+	var resp struct {
+		Price stringOrNumber `json:"price"`
+	}
+	if err := c.publicGet(PriceEndpoint, url.Values{"token_id": {tokenID}, "side": {string(side)}}, &resp); err != nil {
+		return 0, err
+	}
+	return parseDecimal(resp.Price, "price")
+}
+
+// GetPrices returns the price per token ID and side for each requested pair.
+func (c *ClobClient) GetPrices(params []BookParams) (map[string]map[OrderSide]float64, error) {
+	body := make([]map[string]string, 0, len(params))
+	for _, p := range params {
+		body = append(body, map[string]string{"token_id": p.TokenID, "side": p.Side})
+	}
+	var resp map[string]map[string]stringOrNumber
+	if err := c.http.RequestInto("POST", c.clobHost+GetPricesEndpoint, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	prices := make(map[string]map[OrderSide]float64, len(resp))
+	for tokenID, sides := range resp {
+		prices[tokenID] = make(map[OrderSide]float64, len(sides))
+		for side, raw := range sides {
+			price, err := parseDecimal(raw, "price")
+			if err != nil {
+				return nil, err
+			}
+			prices[tokenID][OrderSide(side)] = price
+		}
+	}
+	return prices, nil
+}
+
+func (c *ClobClient) GetSpread(tokenID string) (float64, error) {
+	var resp struct {
+		Spread stringOrNumber `json:"spread"`
+	}
+	if err := c.publicGet(GetSpreadEndpoint, url.Values{"token_id": {tokenID}}, &resp); err != nil {
+		return 0, err
+	}
+	return parseDecimal(resp.Spread, "spread")
+}
+
+// GetSpreads returns the bid-ask spread per token ID.
+func (c *ClobClient) GetSpreads(tokenIDs []string) (map[string]float64, error) {
+	var resp map[string]stringOrNumber
+	if err := c.http.RequestInto("POST", c.clobHost+GetSpreadsEndpoint, nil, tokenParams(tokenIDs), &resp); err != nil {
+		return nil, err
+	}
+	return parseDecimalMap(resp, "spread")
+}
+
+func (c *ClobClient) GetLastTradePrice(tokenID string) (*LastTradePrice, error) {
+	var resp LastTradePrice
+	if err := c.publicGet(GetLastTradePriceEndpoint, url.Values{"token_id": {tokenID}}, &resp); err != nil {
+		return nil, err
+	}
+	resp.TokenID = tokenID
+	return &resp, nil
+}
+
+func (c *ClobClient) GetLastTradesPrices(tokenIDs []string) ([]LastTradePrice, error) {
+	var resp []LastTradePrice
+	if err := c.http.RequestInto("POST", c.clobHost+GetLastTradesPricesEndpoint, nil, tokenParams(tokenIDs), &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *ClobClient) GetMarket(conditionID string) (*Market, error) {
+	var resp Market
+	if err := c.http.RequestInto("GET", c.clobHost+GetMarketEndpoint+conditionID, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetMarkets returns one page of all markets. An empty cursor starts from
+// the first page; see CollectPages for walking the whole listing.
+func (c *ClobClient) GetMarkets(cursor string) (*Page[Market], error) {
+	return getPage[Market](c, GetMarketsEndpoint, cursor)
+}
+
+func (c *ClobClient) GetSimplifiedMarkets(cursor string) (*Page[SimplifiedMarket], error) {
+	return getPage[SimplifiedMarket](c, GetSimplifiedMarketsEndpoint, cursor)
+}
+
+// GetSamplingMarkets returns one page of the markets with liquidity rewards.
+func (c *ClobClient) GetSamplingMarkets(cursor string) (*Page[Market], error) {
+	return getPage[Market](c, GetSamplingMarkets, cursor)
+}
+
+func (c *ClobClient) GetSamplingSimplifiedMarkets(cursor string) (*Page[SimplifiedMarket], error) {
+	return getPage[SimplifiedMarket](c, GetSamplingSimplifiedMarkets, cursor)
+}
+
+// CollectPages walks a cursor-paginated listing from the first page and
+// returns every item, e.g. CollectPages(client.GetMarkets).
+func CollectPages[T any](fetch func(cursor string) (*Page[T], error)) ([]T, error) {
+	var items []T
+	seen := make(map[string]bool)
+	cursor := InitialCursor
+	for {
+		page, err := fetch(cursor)
+		if err != nil {
+			return items, fmt.Errorf("page at cursor %s: %w", cursor, err)
+		}
+		items = append(items, page.Data...)
+		if page.Last() {
+			return items, nil
+		}
+		seen[cursor] = true
+		if seen[page.NextCursor] {
+			return items, fmt.Errorf("cursor %s repeated", page.NextCursor)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func getPage[T any](c *ClobClient, endpoint, cursor string) (*Page[T], error) {
+	if cursor == "" {
+		cursor = InitialCursor
+	}
+	var page Page[T]
+	if err := c.publicGet(endpoint, url.Values{"next_cursor": {cursor}}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *ClobClient) publicGet(endpoint string, query url.Values, out any) error {
+	return c.http.RequestInto("GET", c.clobHost+endpoint+"?"+query.Encode(), nil, nil, out)
+}
+
+func tokenParams(tokenIDs []string) []map[string]string {
+	body := make([]map[string]string, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		body = append(body, map[string]string{"token_id": tokenID})
+	}
+	return body
+}
+
+func parseDecimal(raw stringOrNumber, what string) (float64, error) {
+	f, err := strconv.ParseFloat(raw.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", what, raw.String())
+	}
+	return f, nil
+}
+
+func parseDecimalMap(raw map[string]stringOrNumber, what string) (map[string]float64, error) {
+	out := make(map[string]float64, len(raw))
+	for tokenID, v := range raw {
+		f, err := parseDecimal(v, what)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tokenID, err)
+		}
+		out[tokenID] = f
+	}
+	return out, nil
+}
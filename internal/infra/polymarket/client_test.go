@@ -0,0 +1,308 @@
+package polymarket
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// newTestClobClient returns an L2 client whose CLOB and data API hosts are
+// an httptest server running handler.
+func newTestClobClient(t *testing.T, handler http.HandlerFunc) *ClobClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := NewClobClient(testPrivateKey, SignatureEOA, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAPICreds(&ApiCreds{APIKey: "key", APISecret: "c2VjcmV0", APIPassphrase: "pass"})
+	c.clobHost = srv.URL
+	c.dataHost = srv.URL
+	c.http.SetRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	return c
+}
+
+// route serves a fixed JSON body for method and path, and fails the test on
+// anything else.
+func route(t *testing.T, method, path, body string, check func(r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method || r.URL.Path != path {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if check != nil {
+			check(r)
+		}
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func readBody(t *testing.T, r *http.Request, out any) {
+	t.Helper()
+	if err := json.NewDecoder(r.Body).Decode(out); err != nil {
+		t.Errorf("decode request body: %v", err)
+	}
+}
+
+func TestClobClient_Prices(t *testing.T) {
+	t.Run("midpoint", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", MidPointEndpoint, `{"mid":"0.555"}`, func(r *http.Request) {
+			if r.URL.Query().Get("token_id") != "t1" {
+				t.Errorf("expected token_id t1, got %q", r.URL.RawQuery)
+			}
+		}))
+		mid, err := c.GetMidpoint("t1")
+		if err != nil || mid != 0.555 {
+			t.Errorf("expected 0.555, got %v (err %v)", mid, err)
+		}
+	})
+
+	t.Run("midpoints", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "POST", MidPointsEndpoint, `{"t1":"0.5","t2":0.25}`, func(r *http.Request) {
+			var body []map[string]string
+			readBody(t, r, &body)
+			if len(body) != 2 || body[1]["token_id"] != "t2" {
+				t.Errorf("unexpected body %v", body)
+			}
+		}))
+		mids, err := c.GetMidpoints([]string{"t1", "t2"})
+		if err != nil || mids["t1"] != 0.5 || mids["t2"] != 0.25 {
+			t.Errorf("unexpected midpoints %v (err %v)", mids, err)
+		}
+	})
+
+	t.Run("price", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", PriceEndpoint, `{"price":"0.61"}`, func(r *http.Request) {
+			if r.URL.Query().Get("side") != "BUY" {
+				t.Errorf("expected side BUY, got %q", r.URL.RawQuery)
+			}
+		}))
+		price, err := c.GetPrice("t1", SideBuy)
+		if err != nil || price != 0.61 {
+			t.Errorf("expected 0.61, got %v (err %v)", price, err)
+		}
+	})
+
+	t.Run("prices", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "POST", GetPricesEndpoint, `{"t1":{"BUY":"0.61","SELL":"0.59"}}`, nil))
+		prices, err := c.GetPrices([]BookParams{{TokenID: "t1", Side: "BUY"}, {TokenID: "t1", Side: "SELL"}})
+		if err != nil || prices["t1"][SideBuy] != 0.61 || prices["t1"][SideSell] != 0.59 {
+			t.Errorf("unexpected prices %v (err %v)", prices, err)
+		}
+	})
+
+	t.Run("spread_and_spreads", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case GetSpreadEndpoint:
+				_, _ = io.WriteString(w, `{"spread":"0.02"}`)
+			case GetSpreadsEndpoint:
+				_, _ = io.WriteString(w, `{"t1":"0.02","t2":"0.04"}`)
+			default:
+				http.NotFound(w, r)
+			}
+		})
+		spread, err := c.GetSpread("t1")
+		if err != nil || spread != 0.02 {
+			t.Errorf("expected 0.02, got %v (err %v)", spread, err)
+		}
+		spreads, err := c.GetSpreads([]string{"t1", "t2"})
+		if err != nil || spreads["t2"] != 0.04 {
+			t.Errorf("unexpected spreads %v (err %v)", spreads, err)
+		}
+	})
+
+	t.Run("last_trade_prices", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case GetLastTradePriceEndpoint:
+				_, _ = io.WriteString(w, `{"price":"0.47","side":"SELL"}`)
+			case GetLastTradesPricesEndpoint:
+				_, _ = io.WriteString(w, `[{"token_id":"t1","price":"0.47","side":"SELL"},{"token_id":"t2","price":"0.53","side":"BUY"}]`)
+			default:
+				http.NotFound(w, r)
+			}
+		})
+		last, err := c.GetLastTradePrice("t1")
+		if err != nil || last.TokenID != "t1" || last.Price.String() != "0.47" || last.Side != "SELL" {
+			t.Errorf("unexpected last trade %+v (err %v)", last, err)
+		}
+		all, err := c.GetLastTradesPrices([]string{"t1", "t2"})
+		if err != nil || len(all) != 2 || all[1].TokenID != "t2" {
+			t.Errorf("unexpected last trades %+v (err %v)", all, err)
+		}
+	})
+
+	t.Run("invalid_price_is_an_error", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", MidPointEndpoint, `{"mid":""}`, nil))
+		if _, err := c.GetMidpoint("t1"); err == nil {
+			t.Error("expected an error for an empty midpoint")
+		}
+	})
+
+	t.Run("http_error", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"error":"No orderbook exists"}`, http.StatusNotFound)
+		})
+		if _, err := c.GetSpread("t1"); err == nil || !strings.Contains(err.Error(), "404") {
+			t.Errorf("expected http 404 error, got %v", err)
+		}
+	})
+}
+
+func TestClobClient_Markets(t *testing.T) {
+	t.Run("market", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", GetMarketEndpoint+"0xabc", `{
+			"condition_id":"0xabc","question":"BTC up?","minimum_tick_size":0.01,"neg_risk":false,
+			"accepting_orders":true,"tokens":[{"token_id":"t1","outcome":"Up","price":0.55,"winner":false}]}`, nil))
+		m, err := c.GetMarket("0xabc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ConditionID != "0xabc" || !m.AcceptingOrders || len(m.Tokens) != 1 || m.Tokens[0].Outcome != "Up" || m.MinimumTickSize.String() != "0.01" {
+			t.Errorf("unexpected market %+v", m)
+		}
+	})
+
+	t.Run("collects_every_page", func(t *testing.T) {
+		pages := map[string]string{
+			InitialCursor: `{"limit":2,"count":2,"next_cursor":"Mg==","data":[{"condition_id":"a"},{"condition_id":"b"}]}`,
+			"Mg==":        `{"limit":2,"count":1,"next_cursor":"LTE=","data":[{"condition_id":"c"}]}`,
+		}
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, pages[r.URL.Query().Get("next_cursor")])
+		})
+		for name, fetch := range map[string]func(string) (*Page[Market], error){
+			"markets":          c.GetMarkets,
+			"sampling_markets": c.GetSamplingMarkets,
+		} {
+			markets, err := CollectPages(fetch)
+			if err != nil || len(markets) != 3 || markets[2].ConditionID != "c" {
+				t.Errorf("%s: unexpected markets %+v (err %v)", name, markets, err)
+			}
+		}
+		for name, fetch := range map[string]func(string) (*Page[SimplifiedMarket], error){
+			"simplified":          c.GetSimplifiedMarkets,
+			"sampling_simplified": c.GetSamplingSimplifiedMarkets,
+		} {
+			markets, err := CollectPages(fetch)
+			if err != nil || len(markets) != 3 || markets[2].ConditionID != "c" {
+				t.Errorf("%s: unexpected markets %+v (err %v)", name, markets, err)
+			}
+		}
+	})
+
+	t.Run("repeated_cursor_stops", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", GetMarketsEndpoint,
+			`{"next_cursor":"MA==","data":[{"condition_id":"a"}]}`, nil))
+		if _, err := CollectPages(c.GetMarkets); err == nil {
+			t.Error("expected an error for a cursor that loops")
+		}
+	})
+}
+
+func TestClobClient_Account(t *testing.T) {
+	authed := func(t *testing.T, r *http.Request) {
+		if r.Header.Get(PolyAPIKey) != "key" || r.Header.Get(PolySignature) == "" {
+			t.Errorf("expected L2 headers on %s", r.URL.Path)
+		}
+	}
+
+	t.Run("order", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", GetOrderEndpoint+"o1",
+			`{"id":"o1","status":"LIVE","side":"BUY","original_size":"10","size_matched":"4","price":"0.5"}`,
+			func(r *http.Request) { authed(t, r) }))
+		o, err := c.GetOrder("o1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state := parseOrderState(o); state.SizeMatched != 4 {
+			t.Errorf("expected 4 shares matched, got %+v", state)
+		}
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "GET", GetPositionsEndpoint,
+			`[{"asset":"t1","conditionId":"0xabc","size":12.5,"avgPrice":0.42,"outcome":"Up"}]`,
+			func(r *http.Request) {
+				if r.URL.Query().Get("user") != "0xme" {
+					t.Errorf("expected user filter, got %q", r.URL.RawQuery)
+				}
+			}))
+		positions, err := c.GetPositions("0xme", nil)
+		if err != nil || len(positions) != 1 || positions[0].Size != 12.5 || positions[0].AvgPrice != 0.42 {
+			t.Errorf("unexpected positions %+v (err %v)", positions, err)
+		}
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			authed(t, r)
+			switch r.Method {
+			case "GET":
+				_, _ = io.WriteString(w, `[{"id":7,"type":2,"owner":"key","payload":{"order_id":"o1"}}]`)
+			case "DELETE":
+				if r.URL.Query().Get("ids") != "7,8" {
+					t.Errorf("expected ids 7,8, got %q", r.URL.RawQuery)
+				}
+				_, _ = io.WriteString(w, `"OK"`)
+			}
+		})
+		notes, err := c.GetNotifications()
+		if err != nil || len(notes) != 1 || notes[0].Type != 2 || notes[0].Payload["order_id"] != "o1" {
+			t.Errorf("unexpected notifications %+v (err %v)", notes, err)
+		}
+		if err := c.DropNotifications([]string{"7", "8"}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("order_scoring", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			authed(t, r)
+			switch r.URL.Path {
+			case IsOrderScoringEndpoint:
+				_, _ = io.WriteString(w, `{"scoring":true}`)
+			case AreOrdersScoringEndpoint:
+				var ids []string
+				readBody(t, r, &ids)
+				if len(ids) != 2 {
+					t.Errorf("expected 2 order IDs, got %v", ids)
+				}
+				_, _ = io.WriteString(w, `{"o1":true,"o2":false}`)
+			}
+		})
+		scoring, err := c.IsOrderScoring("o1")
+		if err != nil || !scoring {
+			t.Errorf("expected o1 scoring, got %v (err %v)", scoring, err)
+		}
+		all, err := c.AreOrdersScoring([]string{"o1", "o2"})
+		if err != nil || !all["o1"] || all["o2"] {
+			t.Errorf("unexpected scoring %v (err %v)", all, err)
+		}
+	})
+
+	t.Run("requires_api_creds", func(t *testing.T) {
+		c, err := NewClobClient(testPrivateKey, SignatureEOA, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetNotifications(); err == nil {
+			t.Error("expected an error without API creds")
+		}
+	})
+}
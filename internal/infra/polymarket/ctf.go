@@ -0,0 +1,206 @@
+package polymarket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ctfABI covers the Conditional Tokens calls used to merge and redeem.
+const ctfABI = `[
+	{"name":"mergePositions","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"collateralToken","type":"address"},{"name":"parentCollectionId","type":"bytes32"},
+		{"name":"conditionId","type":"bytes32"},{"name":"partition","type":"uint256[]"},
+		{"name":"amount","type":"uint256"}],"outputs":[]},
+	{"name":"redeemPositions","type":"function","stateMutability":"nonpayable","inputs":[
+		{"name":"collateralToken","type":"address"},{"name":"parentCollectionId","type":"bytes32"},
+		{"name":"conditionId","type":"bytes32"},{"name":"indexSets","type":"uint256[]"}],"outputs":[]},
+	{"name":"payoutDenominator","type":"function","stateMutability":"view","inputs":[
+		{"name":"","type":"bytes32"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// binaryPartition is the index sets of a binary condition's two outcomes.
+var binaryPartition = []*big.Int{big.NewInt(1), big.NewInt(2)}
+
+// CTFBackend implements ports.ChainBackend against the Conditional Tokens
+// contract from GetContractConfig. Transactions are sent from the signer's
+// own address, so this only works for EOA wallets (SignatureEOA); tokens
+// held by a proxy wallet must be merged and redeemed through the proxy.
+type CTFBackend struct {
+	client       *ethclient.Client
+	signer       *Signer
+	contracts    ContractConfig
+	abi          abi.ABI
+	receiptWait  time.Duration
+	pollInterval time.Duration
+	logger       *slog.Logger
+}
+
+func NewCTFBackend(ctx context.Context, rpcURL, privateKey string, chainID int, logger *slog.Logger) (*CTFBackend, error) {
+	signer, err := NewSigner(privateKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := GetContractConfig(chainID, false)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(ctfABI))
+	if err != nil {
+		return nil, fmt.Errorf("parse CTF ABI: %w", err)
+	}
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", rpcURL, err)
+	}
+	return &CTFBackend{
+		client:       client,
+		signer:       signer,
+		contracts:    contracts,
+		abi:          parsed,
+		receiptWait:  2 * time.Minute,
+		pollInterval: 2 * time.Second,
+		logger:       logger,
+	}, nil
+}
+
+// Close releases the RPC connection.
+func (b *CTFBackend) Close() {
+	b.client.Close()
+}
+
+// MergePositions implements ports.ChainBackend.
+func (b *CTFBackend) MergePositions(ctx context.Context, conditionID string, amount float64) (string, error) {
+	units := big.NewInt(ToTokenDecimals(amount))
+	if units.Sign() <= 0 {
+		return "", fmt.Errorf("merge amount %f too small", amount)
+	}
+	tx, err := b.send(ctx, "mergePositions",
+		common.HexToAddress(b.contracts.Collateral), common.Hash{}, common.HexToHash(conditionID), binaryPartition, units)
+	if err != nil {
+		return "", fmt.Errorf("merge %s: %w", conditionID, err)
+	}
+	return tx, nil
+}
+
+// Resolved implements ports.ChainBackend: payouts are reported once the
+// condition's payout denominator is non-zero.
+func (b *CTFBackend) Resolved(ctx context.Context, conditionID string) (bool, error) {
+	data, err := b.abi.Pack("payoutDenominator", common.HexToHash(conditionID))
+	if err != nil {
+		return false, err
+	}
+	to := common.HexToAddress(b.contracts.ConditionalTokens)
+	out, err := b.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("payout denominator of %s: %w", conditionID, err)
+	}
+	values, err := b.abi.Unpack("payoutDenominator", out)
+	if err != nil || len(values) != 1 {
+		return false, fmt.Errorf("decode payout denominator of %s: %v", conditionID, err)
+	}
+	denominator, ok := values[0].(*big.Int)
+	if !ok {
+		return false, fmt.Errorf("unexpected payout denominator type %T", values[0])
+	}
+	return denominator.Sign() > 0, nil
+}
+
+// RedeemPositions implements ports.ChainBackend.
+func (b *CTFBackend) RedeemPositions(ctx context.Context, conditionID string) (string, error) {
+	tx, err := b.send(ctx, "redeemPositions",
+		common.HexToAddress(b.contracts.Collateral), common.Hash{}, common.HexToHash(conditionID), binaryPartition)
+	if err != nil {
+		return "", fmt.Errorf("redeem %s: %w", conditionID, err)
+	}
+	return tx, nil
+}
+
+// send signs and submits a Conditional Tokens call as an EIP-1559
+// transaction and waits for it to be mined.
+func (b *CTFBackend) send(ctx context.Context, method string, args ...any) (string, error) {
+	data, err := b.abi.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("pack %s: %w", method, err)
+	}
+	from := b.signer.address
+	to := common.HexToAddress(b.contracts.ConditionalTokens)
+
+	nonce, err := b.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return "", fmt.Errorf("nonce: %w", err)
+	}
+	tip, err := b.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gas tip: %w", err)
+	}
+	head, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("latest header: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	// Estimating also surfaces reverts (no balance, unresolved) before sending
+	gas, err := b.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("estimate gas: %w", err)
+	}
+
+	chainID := big.NewInt(int64(b.signer.chainID))
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas * 12 / 10,
+		To:        &to,
+		Data:      data,
+	}), types.LatestSignerForChainID(chainID), b.signer.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+	if err := b.client.SendTransaction(ctx, tx); err != nil {
+		return "", fmt.Errorf("send: %w", err)
+	}
+	hash := tx.Hash().Hex()
+	b.logger.Info("[LIVE] ctf transaction sent", "method", method, "tx", hash, "nonce", nonce)
+
+	receipt, err := b.waitMined(ctx, tx.Hash())
+	if err != nil {
+		return hash, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return hash, fmt.Errorf("transaction %s reverted", hash)
+	}
+	return hash, nil
+}
+
+func (b *CTFBackend) waitMined(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.receiptWait)
+	defer cancel()
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	for {
+		receipt, err := b.client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("receipt for %s: %w", hash.Hex(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s not mined: %w", hash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
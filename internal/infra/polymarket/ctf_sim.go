@@ -0,0 +1,88 @@
+package polymarket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// SimCTF is an in-memory ports.ChainBackend for paper mode and tests. It
+// records merges and redemptions without touching a chain; conditions
+// resolve when Resolve is called, or immediately with AutoResolve.
+type SimCTF struct {
+	AutoResolve bool // treat every condition as resolved
+	logger      *slog.Logger
+
+	mu       sync.Mutex
+	resolved map[string]bool
+	merged   map[string]float64 // condition -> complete sets merged
+	redeemed map[string]int     // condition -> redemptions
+	nextTx   int
+}
+
+func NewSimCTF(logger *slog.Logger) *SimCTF {
+	return &SimCTF{
+		logger:   logger,
+		resolved: make(map[string]bool),
+		merged:   make(map[string]float64),
+		redeemed: make(map[string]int),
+	}
+}
+
+// Resolve marks a condition's payouts as reported.
+func (s *SimCTF) Resolve(conditionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resolved[conditionID] = true
+}
+
+// Merged returns the complete sets merged for a condition.
+func (s *SimCTF) Merged(conditionID string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.merged[conditionID]
+}
+
+// Redeemed returns how many times a condition was redeemed.
+func (s *SimCTF) Redeemed(conditionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.redeemed[conditionID]
+}
+
+func (s *SimCTF) MergePositions(_ context.Context, conditionID string, amount float64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("merge amount %f too small", amount)
+	}
+	s.mu.Lock()
+	s.merged[conditionID] += amount
+	tx := s.txLocked()
+	s.mu.Unlock()
+	s.logger.Info("[PAPER] merge", "condition", conditionID, "pairs", amount, "tx", tx)
+	return tx, nil
+}
+
+func (s *SimCTF) Resolved(_ context.Context, conditionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.AutoResolve || s.resolved[conditionID], nil
+}
+
+func (s *SimCTF) RedeemPositions(_ context.Context, conditionID string) (string, error) {
+	s.mu.Lock()
+	if !s.AutoResolve && !s.resolved[conditionID] {
+		s.mu.Unlock()
+		return "", fmt.Errorf("condition %s not resolved", conditionID)
+	}
+	s.redeemed[conditionID]++
+	tx := s.txLocked()
+	s.mu.Unlock()
+	s.logger.Info("[PAPER] redeem", "condition", conditionID, "tx", tx)
+	return tx, nil
+}
+
+func (s *SimCTF) txLocked() string {
+	s.nextTx++
+	return fmt.Sprintf("sim-tx-%d", s.nextTx)
+}
@@ -1,9 +1,171 @@
 package polymarket
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Polybot/internal/service"
+)
 
 var (
 	ErrMissingPrivateKey = errors.New("polymarket: private key is required")
 	ErrLevel1Auth        = errors.New(L1AuthUnavailable)
 	ErrLevel2Auth        = errors.New(L2AuthUnavailable)
 )
+
+// Kinds of failed exchange requests. An *APIError unwraps to one of these,
+// so callers branch with errors.Is and read details with errors.As.
+var (
+	ErrOrderNotFilled      = errors.New("polymarket: order not filled")
+	ErrInsufficientBalance = errors.New("polymarket: not enough balance or allowance")
+	ErrRejected            = errors.New("polymarket: request rejected")
+	ErrNotFound            = errors.New("polymarket: not found")
+	ErrUnauthorized        = errors.New("polymarket: unauthorized")
+	ErrRateLimited         = errors.New("polymarket: rate limited")
+	ErrServer              = errors.New("polymarket: server error")
+	ErrNetwork             = errors.New("polymarket: network error")
+	ErrCircuitOpen         = errors.New("polymarket: circuit open")
+)
+
+// APIError is a failed CLOB request: its kind, the HTTP status (0 if no
+// response arrived) and the exchange's message.
+type APIError struct {
+	Kind       error
+	Status     int
+	Message    string
+	Method     string
+	Path       string
+	RetryAfter time.Duration // from a 429's Retry-After header
+}
+
+func (e *APIError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("%v: %s %s: %s", e.Kind, e.Method, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%v: %s %s: http %d: %s", e.Kind, e.Method, e.Path, e.Status, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// Is matches the service errors execution callers branch on, so an order
+// rejection reads the same from the live venue as from the paper exchange.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case service.ErrNotFilled:
+		return e.Kind == ErrOrderNotFilled
+	case service.ErrInsufficientFunds:
+		return e.Kind == ErrInsufficientBalance
+	}
+	return false
+}
+
+// Temporary reports whether the same request may succeed later.
+func (e *APIError) Temporary() bool {
+	switch e.Kind {
+	case ErrRateLimited, ErrServer, ErrNetwork, ErrCircuitOpen:
+		return true
+	}
+	return false
+}
+
+// IsTemporary reports whether err is an APIError worth retrying later.
+func IsTemporary(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Temporary()
+}
+
+// newHTTPError classifies an error response from its status and body.
+func newHTTPError(method, path string, resp *http.Response, payload []byte) *APIError {
+	message := errorMessage(payload)
+	e := &APIError{
+		Kind:    classifyError(resp.StatusCode, message),
+		Status:  resp.StatusCode,
+		Message: message,
+		Method:  method,
+		Path:    path,
+	}
+	if e.Kind == ErrRateLimited {
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return e
+}
+
+// classifyError maps a CLOB status and message to an error kind. Order
+// rejections share status 400, so the message tells them apart.
+func classifyError(status int, message string) error {
+	msg := strings.ToLower(message)
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrServer
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case strings.Contains(msg, "not enough balance"), strings.Contains(msg, "allowance"):
+		return ErrInsufficientBalance
+	case strings.Contains(msg, "fully filled"), strings.Contains(msg, "no match"),
+		strings.Contains(msg, "no orders found to match"):
+		return ErrOrderNotFilled
+	default:
+		return ErrRejected
+	}
+}
+
+// orderRejection returns the error for an order answered with a 200 but
+// {"success": false, "errorMsg": "..."}, or nil.
+func orderRejection(method, path string, resp any) error {
+	m, ok := resp.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if success, ok := m["success"].(bool); !ok || success {
+		return nil
+	}
+	message, _ := m["errorMsg"].(string)
+	return &APIError{
+		Kind:    classifyError(http.StatusOK, message),
+		Status:  http.StatusOK,
+		Message: message,
+		Method:  method,
+		Path:    path,
+	}
+}
+
+// errorMessage extracts the message from a CLOB error body, which is
+// {"error": "..."} or {"errorMsg": "..."}, falling back to the raw body.
+func errorMessage(payload []byte) string {
+	var body struct {
+		Error    string `json:"error"`
+		ErrorMsg string `json:"errorMsg"`
+		Message  string `json:"message"`
+	}
+	if err := json.Unmarshal(payload, &body); err == nil {
+		for _, m := range []string{body.Error, body.ErrorMsg, body.Message} {
+			if m != "" {
+				return m
+			}
+		}
+	}
+	return strings.TrimSpace(string(payload))
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := time.ParseDuration(v + "s"); err == nil && secs > 0 {
+		return secs
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
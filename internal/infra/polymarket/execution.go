@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"Polybot/internal/domain"
@@ -50,44 +51,44 @@ func (e *ExecutionProvider) BuyDown(_ context.Context, marketID domain.MarketID,
 	return e.placeMarketBuy(market.DownTokenID, maxPrice, sizeUSD, marketID, "DOWN")
 }
 
-func (e *ExecutionProvider) ClosePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
-	market, ok := e.registry.GetMarket(marketID)
-	if !ok {
-		return fmt.Errorf("market %s not found in registry", marketID)
-	}
-
-	var tokenID string
-	if side == domain.PositionUp {
-		tokenID = market.UpTokenID
-	} else {
-		tokenID = market.DownTokenID
-	}
-	if tokenID == "" {
-		return fmt.Errorf("market %s has no token ID for side %s", marketID, side)
+// Sell places a FOK market sell of shares, priced no lower than minPrice.
+// The fill listener books the proceeds.
+func (e *ExecutionProvider) Sell(_ context.Context, marketID domain.MarketID, side domain.PositionSide, shares, minPrice float64) (ports.OrderResult, error) {
+	tokenID, err := e.tokenID(marketID, side)
+	if err != nil {
+		return ports.OrderResult{}, err
 	}
 
+	// For sells the market order amount is in shares
 	order, err := e.client.CreateMarketOrder(MarketOrderArgs{
 		TokenID:   tokenID,
-		Amount:    0,
+		Amount:    shares,
 		Side:      SideSell,
+		Price:     minPrice,
 		OrderType: OrderTypeFOK,
 	}, nil)
 	if err != nil {
-		return fmt.Errorf("create close order for %s %s: %w", marketID, side, err)
+		return ports.OrderResult{}, fmt.Errorf("create %s sell order for %s: %w", side, marketID, err)
 	}
 
 	resp, err := e.client.PostOrder(&order, OrderTypeFOK)
 	if err != nil {
-		return fmt.Errorf("post close order for %s %s: %w", marketID, side, err)
+		return ports.OrderResult{}, fmt.Errorf("post %s sell order for %s: %w", side, marketID, err)
 	}
 
-	e.logger.Info("[LIVE] close position",
+	result := parseOrderResponse(resp)
+
+	e.logger.Info("[LIVE] sell",
 		"market", marketID,
 		"side", side,
 		"token_id", tokenID,
-		"response", fmt.Sprintf("%v", resp),
+		"min_price", minPrice,
+		"shares", shares,
+		"order_id", result.OrderID,
+		"filled", result.Filled,
+		"timestamp", time.Now().Format(time.RFC3339),
 	)
-	return nil
+	return result, nil
 }
 
 func (e *ExecutionProvider) placeMarketBuy(tokenID string, maxPrice float64, sizeUSD float64, marketID domain.MarketID, sideLabel string) (ports.OrderResult, error) {
@@ -122,6 +123,113 @@ func (e *ExecutionProvider) placeMarketBuy(tokenID string, maxPrice float64, siz
 	return result, nil
 }
 
+// LimitBuy implements ports.LimitOrderProvider: a GTC limit buy, or GTD when
+// expiresAt is set.
+func (e *ExecutionProvider) LimitBuy(_ context.Context, marketID domain.MarketID, side domain.PositionSide, price, shares float64, expiresAt time.Time) (ports.OrderResult, error) {
+	market, ok := e.registry.GetMarket(marketID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("market %s not found in registry", marketID)
+	}
+	tokenID := market.UpTokenID
+	if side == domain.PositionDown {
+		tokenID = market.DownTokenID
+	}
+	if tokenID == "" {
+		return ports.OrderResult{}, fmt.Errorf("market %s has no token ID for side %s", marketID, side)
+	}
+
+	args := &OrderArgs{
+		TokenID: tokenID,
+		Price:   price,
+		Size:    shares,
+		Side:    SideBuy,
+	}
+	orderType := OrderTypeGTC
+	if !expiresAt.IsZero() {
+		// The CLOB enforces a one-minute security threshold on GTD expirations
+		args.Expiration = expiresAt.Add(time.Minute).Unix()
+		orderType = OrderTypeGTD
+	}
+
+	order, err := e.client.CreateOrder(args, nil)
+	if err != nil {
+		return ports.OrderResult{}, fmt.Errorf("create %s limit order for %s: %w", side, marketID, err)
+	}
+	resp, err := e.client.PostOrder(order, orderType)
+	if err != nil {
+		return ports.OrderResult{}, fmt.Errorf("post %s limit order for %s: %w", side, marketID, err)
+	}
+
+	result := parseOrderResponse(resp)
+
+	e.logger.Info("[LIVE] limit buy",
+		"market", marketID,
+		"side", side,
+		"token_id", tokenID,
+		"price", price,
+		"shares", shares,
+		"order_type", orderType,
+		"order_id", result.OrderID,
+		"filled", result.Filled,
+	)
+	return result, nil
+}
+
+// tokenID returns the token of a market side.
+func (e *ExecutionProvider) tokenID(marketID domain.MarketID, side domain.PositionSide) (string, error) {
+	market, ok := e.registry.GetMarket(marketID)
+	if !ok {
+		return "", fmt.Errorf("market %s not found in registry", marketID)
+	}
+	tokenID := market.UpTokenID
+	if side == domain.PositionDown {
+		tokenID = market.DownTokenID
+	}
+	if tokenID == "" {
+		return "", fmt.Errorf("market %s has no token ID for side %s", marketID, side)
+	}
+	return tokenID, nil
+}
+
+// GetOrderState implements ports.OrderStatusProvider.
+func (e *ExecutionProvider) GetOrderState(_ context.Context, orderID string) (ports.OrderState, error) {
+	resp, err := e.client.GetOrder(orderID)
+	if err != nil {
+		return ports.OrderState{}, fmt.Errorf("get order %s: %w", orderID, err)
+	}
+	return parseOrderState(resp), nil
+}
+
+// CancelOrder implements ports.OrderStatusProvider.
+func (e *ExecutionProvider) CancelOrder(_ context.Context, orderID string) error {
+	if _, err := e.client.CancelOrders([]string{orderID}); err != nil {
+		return fmt.Errorf("cancel order %s: %w", orderID, err)
+	}
+	e.logger.Info("[LIVE] cancel order", "order_id", orderID)
+	return nil
+}
+
+// parseOrderState extracts status and matched size from a GetOrder response.
+func parseOrderState(o *OpenOrder) ports.OrderState {
+	state := ports.OrderState{Status: orderStatus(o.Status)}
+	state.SizeMatched, _ = o.SizeMatched.Float64()
+	return state
+}
+
+// orderStatus maps a CLOB order status to the domain lifecycle.
+func orderStatus(status string) domain.OrderStatus {
+	switch strings.ToUpper(status) {
+	case "LIVE", "DELAYED":
+		return domain.OrderLive
+	case "MATCHED":
+		return domain.OrderMatched
+	case "CANCELED", "CANCELLED", "UNMATCHED", "CANCELED_MARKET_RESOLVED", "INVALID":
+		return domain.OrderCancelled
+	default:
+		return domain.OrderPending
+	}
+}
+
 // parseOrderResponse extracts order ID and fill status from the PostOrder API response.
 // Polymarket returns: {"orderID": "...", "status": "matched"|"live"|...}
 func parseOrderResponse(resp any) ports.OrderResult {
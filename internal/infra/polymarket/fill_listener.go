@@ -4,10 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
-	"strconv"
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/ports"
 	"Polybot/internal/service"
 )
 
@@ -17,6 +18,11 @@ type FillListener struct {
 	client      *ClobClient
 	registry    *service.MarketRegistry
 	positionSvc *service.PositionService
+	eventRepo   ports.EventRepository
+	recorder    ports.RawRecorder     // optional: raw message capture
+	orders      *service.OrderManager // optional: order lifecycle updates
+	ledger      *tradeLedger          // trade state, kept across reconnects
+	observers   []func(WSStateEvent)
 	logger      *slog.Logger
 }
 
@@ -24,69 +30,57 @@ func NewFillListener(
 	client *ClobClient,
 	registry *service.MarketRegistry,
 	positionSvc *service.PositionService,
+	eventRepo ports.EventRepository,
 	logger *slog.Logger,
 ) *FillListener {
 	return &FillListener{
 		client:      client,
 		registry:    registry,
 		positionSvc: positionSvc,
+		eventRepo:   eventRepo,
+		ledger:      newTradeLedger(),
 		logger:      logger,
 	}
 }
 
-// Run connects to the Polymarket user WebSocket and listens for trade events.
-// It reconnects automatically on disconnection. Blocks until ctx is cancelled.
-func (f *FillListener) Run(ctx context.Context) {
-	for {
-		if ctx.Err() != nil {
-			return
-		}
-
-		f.logger.Info("fill_listener: connecting to user channel")
-		err := f.stream(ctx)
-		if ctx.Err() != nil {
-			return
-		}
-		f.logger.Warn("fill_listener: disconnected, reconnecting", "error", err)
-		time.Sleep(2 * time.Second)
-	}
+// SetRecorder captures every user channel message as received.
+// Must be called before Run.
+func (f *FillListener) SetRecorder(rec ports.RawRecorder) {
+	f.recorder = rec
 }
 
-func (f *FillListener) stream(ctx context.Context) error {
-	msgCh := make(chan []byte, 256)
-
-	ws := NewWebSocketOrderBook(UserChannel, func(message []byte) {
-		select {
-		case msgCh <- message:
-		default:
-		}
-	})
+// SetOrderManager forwards fills and order events to the order manager.
+// Must be called before Run.
+func (f *FillListener) SetOrderManager(orders *service.OrderManager) {
+	f.orders = orders
+}
 
-	// The user channel requires L2 auth headers for the initial subscription.
-	// We authenticate by sending the API key in the subscription payload.
-	connectDone := make(chan error, 1)
-	go func() {
-		connectDone <- ws.Run(map[string]any{
-			"auth":    f.buildAuthPayload(),
-			"type":    UserChannel,
-			"markets": []string{}, // empty = subscribe to all user events
-		})
-	}()
+// OnConnState registers fn to receive the user channel's connection state
+// changes. Fills are missed while disconnected, so live wiring runs a
+// reconcile pass on every reconnect. Must be called before Run.
+func (f *FillListener) OnConnState(fn func(WSStateEvent)) {
+	f.observers = append(f.observers, fn)
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case err := <-connectDone:
-			return err
-		case raw := <-msgCh:
-			rawStr := string(raw)
-			if rawStr == "PONG" || rawStr == "" {
-				continue
-			}
-			f.handleMessage(ctx, raw)
+// Run connects to the Polymarket user WebSocket and listens for trade events.
+// The client reconnects automatically on disconnection. Blocks until ctx is
+// cancelled.
+func (f *FillListener) Run(ctx context.Context) {
+	f.logger.Info("fill_listener: connecting to user channel")
+	// Messages are handled on the read goroutine so no fill is dropped
+	ws := NewWSClient(UserChannel, WSConfig{}, func(message []byte) {
+		if f.recorder != nil {
+			f.recorder.Record(recorder.SourceUser, "", message)
 		}
+		f.handleMessage(ctx, message)
+	}, f.logger)
+	// The user channel authenticates with the API key in the subscription;
+	// no tracked markets means all of our events
+	ws.SetAuth(f.buildAuthPayload())
+	for _, fn := range f.observers {
+		ws.OnStateChange(fn)
 	}
+	_ = ws.Run(ctx)
 }
 
 func (f *FillListener) buildAuthPayload() map[string]any {
@@ -105,6 +99,11 @@ func (f *FillListener) buildAuthPayload() map[string]any {
 type userWSMessage struct {
 	EventType string        `json:"event_type"`
 	Trades    []userWSTrade `json:"trades"`
+
+	// Order events ("order")
+	ID          string      `json:"id"`
+	Type        string      `json:"type"` // "PLACEMENT", "UPDATE" or "CANCELLATION"
+	SizeMatched json.Number `json:"size_matched"`
 }
 
 type userWSTrade struct {
@@ -112,9 +111,35 @@ type userWSTrade struct {
 	Side       string      `json:"side"` // "BUY" or "SELL"
 	Price      json.Number `json:"price"`
 	Size       json.Number `json:"size"`
-	Status     string      `json:"status"`      // "MATCHED", "MINED", etc.
+	Status     string      `json:"status"`      // "MATCHED", "MINED", "CONFIRMED", "RETRYING" or "FAILED"
 	TraderSide string      `json:"trader_side"` // "MAKER" or "TAKER"
 	Market     string      `json:"market"`
+	ID         string      `json:"id"`         // trade ID, stable across status updates
+	MatchTime  string      `json:"match_time"` // Unix seconds (or ms)
+	Timestamp  string      `json:"timestamp"`
+
+	TakerOrderID string             `json:"taker_order_id"`
+	MakerOrders  []userWSMakerOrder `json:"maker_orders"`
+}
+
+type userWSMakerOrder struct {
+	OrderID       string      `json:"order_id"`
+	Owner         string      `json:"owner"` // API key of the maker
+	AssetID       string      `json:"asset_id"`
+	Side          string      `json:"side"`
+	Price         json.Number `json:"price"`
+	MatchedAmount json.Number `json:"matched_amount"`
+}
+
+// tradeLeg is our side of a trade: the taker order when we took liquidity,
+// or one of our resting orders among the makers.
+type tradeLeg struct {
+	tradeID string // ledger key; maker legs are keyed per order
+	orderID string
+	assetID string
+	side    string
+	price   json.Number
+	size    json.Number
 }
 
 func (f *FillListener) handleMessage(ctx context.Context, raw []byte) {
@@ -135,210 +160,191 @@ func (f *FillListener) handleMessage(ctx context.Context, raw []byte) {
 }
 
 func (f *FillListener) processMessage(ctx context.Context, msg *userWSMessage) {
+	if msg.EventType == "order" {
+		f.processOrderEvent(msg)
+		return
+	}
 	for _, trade := range msg.Trades {
-		// Only process confirmed fills
-		if trade.Status != "MATCHED" && trade.Status != "MINED" {
-			continue
-		}
-		// Only process buys (we don't sell mid-market)
-		if trade.Side != "BUY" {
-			continue
-		}
-
-		price, err := trade.Price.Float64()
-		if err != nil || price <= 0 {
-			continue
-		}
-		size, err := trade.Size.Float64()
-		if err != nil || size <= 0 {
-			continue
-		}
-
-		// Resolve which market and side this token belongs to
-		marketID, signalSide, ok := f.resolveTokenSide(trade.AssetID)
-		if !ok {
-			f.logger.Debug("fill_listener: unknown token in trade", "asset_id", trade.AssetID)
-			continue
-		}
-
-		sizeUSD := price * size
-
-		fill := domain.Fill{
-			MarketID:  marketID,
-			Side:      signalSide,
-			Price:     price,
-			SizeUSD:   sizeUSD,
-			Timestamp: time.Now(),
-		}
-
-		f.logger.Info("fill_listener: confirmed fill",
-			"market", marketID,
-			"side", signalSide,
-			"price", price,
-			"size", size,
-			"size_usd", sizeUSD,
-			"asset_id", trade.AssetID,
-		)
-
-		if err := f.positionSvc.AccumulateFill(ctx, fill); err != nil {
-			f.logger.Error("fill_listener: failed to accumulate fill", "error", err)
+		for _, leg := range f.tradeLegs(&trade) {
+			f.processLeg(ctx, &trade, leg)
 		}
 	}
 }
 
-// resolveTokenSide maps a token asset ID to a market ID and signal side
-// by looking through the registry for markets that use this token.
-func (f *FillListener) resolveTokenSide(assetID string) (domain.MarketID, domain.TradeSignalSide, bool) {
-	for _, market := range f.registry.ListMarkets() {
-		if market.UpTokenID == assetID {
-			return market.ID, domain.SignalBuyUp, true
-		}
-		if market.DownTokenID == assetID {
-			return market.ID, domain.SignalBuyDown, true
-		}
+func (f *FillListener) processLeg(ctx context.Context, trade *userWSTrade, leg tradeLeg) {
+	if leg.side != "BUY" && leg.side != "SELL" {
+		return
 	}
-	return "", "", false
-}
 
-// LoadTradesFromAPI fetches recent trades from the Polymarket REST API
-// and accumulates them into positions. Used for bootstrap on startup.
-func (f *FillListener) LoadTradesFromAPI(ctx context.Context) error {
-	address := f.client.Address()
-	if address == "" {
-		return nil
+	price, err := leg.price.Float64()
+	if err != nil || price <= 0 {
+		return
 	}
-
-	resp, err := f.client.GetTradesTyped(map[string]string{
-		"maker_address": address,
-	})
-	if err != nil {
-		return err
+	size, err := leg.size.Float64()
+	if err != nil || size <= 0 {
+		return
 	}
 
-	loaded := 0
-	for _, trade := range resp.Data {
-		if trade.Side != "BUY" {
-			continue
-		}
-
-		price, err := trade.Price.Float64()
-		if err != nil || price <= 0 {
-			continue
-		}
-		size, err := trade.Size.Float64()
-		if err != nil || size <= 0 {
-			continue
-		}
-
-		marketID, signalSide, ok := f.resolveTokenSide(trade.AssetID)
-		if !ok {
-			// Token not in any active market — skip
-			continue
-		}
-
-		sizeUSD := price * size
-		fill := domain.Fill{
-			MarketID:  marketID,
-			Side:      signalSide,
-			Price:     price,
-			SizeUSD:   sizeUSD,
-			Timestamp: time.Now(),
-		}
-
-		if err := f.positionSvc.AccumulateFill(ctx, fill); err != nil {
-			f.logger.Error("bootstrap: failed to accumulate trade", "error", err)
-			continue
+	// Resolve which market and side this token belongs to
+	marketID, signalSide, ok := f.resolveTokenSide(leg.assetID)
+	if !ok {
+		// The market may have rolled out of the registry since the trade was
+		// booked; a late FAILED still reverses the stored fill
+		if fill, known := f.ledger.fill(leg.tradeID); known {
+			f.ingest(ctx, trade.Status, fill, true)
+			return
 		}
-		loaded++
+		f.logger.Debug("fill_listener: unknown token in trade", "asset_id", leg.assetID)
+		return
+	}
+	if leg.side == "SELL" {
+		signalSide = sellSide(signalSide)
 	}
 
-	f.logger.Info("bootstrap: loaded trades from API", "count", loaded, "total_api_trades", len(resp.Data))
-	return nil
-}
-
-// LoadPositionsFromAPI fetches current positions from the Polymarket data API
-// and sets them directly. This is a more accurate bootstrap than loading trades
-// because it reflects the current on-chain state.
-func (f *FillListener) LoadPositionsFromAPI(ctx context.Context) error {
-	address := f.client.Address()
-	if address == "" {
-		return nil
+	ts, ok := parseExchangeTime(trade.MatchTime, trade.Timestamp)
+	if !ok {
+		ts = time.Now()
+	}
+	fill := domain.Fill{
+		MarketID:  marketID,
+		Side:      signalSide,
+		Price:     price,
+		SizeUSD:   price * size,
+		Timestamp: ts,
+		TradeID:   leg.tradeID,
+		Role:      fillRole(trade.TraderSide),
 	}
 
-	resp, err := f.client.GetPositions(address, nil)
-	if err != nil {
-		return err
+	if f.ingest(ctx, trade.Status, fill, true) && f.orders != nil {
+		f.orders.OnFill(leg.orderID, price, size)
 	}
+}
 
-	positions, ok := resp.([]any)
-	if !ok {
-		// Try map response
-		return f.LoadTradesFromAPI(ctx)
+// tradeLegs returns our legs of a trade. As taker, the trade's own asset,
+// side, price and size are ours. As maker, they are the taker's, and our
+// fills are our orders among maker_orders, which may be on the complementary
+// token.
+func (f *FillListener) tradeLegs(trade *userWSTrade) []tradeLeg {
+	if trade.TraderSide != "MAKER" {
+		return []tradeLeg{{
+			tradeID: trade.ID,
+			orderID: trade.TakerOrderID,
+			assetID: trade.AssetID,
+			side:    trade.Side,
+			price:   trade.Price,
+			size:    trade.Size,
+		}}
 	}
 
-	loaded := 0
-	for _, pos := range positions {
-		pm, ok := pos.(map[string]any)
-		if !ok {
+	var legs []tradeLeg
+	for _, mo := range trade.MakerOrders {
+		if !f.ownOrder(mo.Owner) {
 			continue
 		}
-
-		assetID, _ := pm["asset"].(string)
-		if assetID == "" {
-			assetID, _ = pm["asset_id"].(string)
+		leg := tradeLeg{
+			orderID: mo.OrderID,
+			assetID: mo.AssetID,
+			side:    mo.Side,
+			price:   mo.Price,
+			size:    mo.MatchedAmount,
 		}
-		sizeStr, _ := pm["size"].(string)
-		if sizeStr == "" {
-			if sizeNum, ok := pm["size"].(float64); ok {
-				sizeStr = strconv.FormatFloat(sizeNum, 'f', -1, 64)
-			}
+		if trade.ID != "" {
+			leg.tradeID = trade.ID + ":" + mo.OrderID
 		}
-
-		size, err := strconv.ParseFloat(sizeStr, 64)
-		if err != nil || size <= 0 {
-			continue
+		if leg.assetID == "" {
+			leg.assetID = trade.AssetID
 		}
+		if leg.side == "" {
+			leg.side = trade.Side
+		}
+		legs = append(legs, leg)
+	}
+	return legs
+}
 
-		avgPriceStr, _ := pm["avg_price"].(string)
-		if avgPriceStr == "" {
-			if priceNum, ok := pm["avg_price"].(float64); ok {
-				avgPriceStr = strconv.FormatFloat(priceNum, 'f', -1, 64)
-			}
+// ownOrder reports whether a maker order belongs to our API key. Without
+// credentials (or an owner) every maker order is taken as ours.
+func (f *FillListener) ownOrder(owner string) bool {
+	if f.client == nil || f.client.creds == nil || owner == "" {
+		return true
+	}
+	return owner == f.client.creds.APIKey
+}
+
+// ingest applies a trade status update: the first update of a trade books
+// its fill, a FAILED update reverses it, anything else is a no-op. Fills are
+// saved as events when record is set. It reports whether the fill was booked.
+func (f *FillListener) ingest(ctx context.Context, status string, fill domain.Fill, record bool) bool {
+	action, fill := f.ledger.apply(fill.TradeID, status, fill, time.Now())
+	switch action {
+	case ledgerBook:
+		f.logger.Info("fill_listener: confirmed fill",
+			"market", fill.MarketID,
+			"side", fill.Side,
+			"price", fill.Price,
+			"size_usd", fill.SizeUSD,
+			"trade_id", fill.TradeID,
+			"role", fill.Role,
+			"status", status,
+		)
+		if record {
+			_ = f.eventRepo.SaveFill(ctx, fill)
 		}
-		avgPrice, _ := strconv.ParseFloat(avgPriceStr, 64)
-		if avgPrice <= 0 {
-			avgPrice = 0.5 // fallback
+		booked, err := f.positionSvc.BookFill(ctx, fill)
+		if err != nil {
+			f.logger.Error("fill_listener: failed to accumulate fill", "error", err)
 		}
-
-		marketID, signalSide, ok := f.resolveTokenSide(assetID)
-		if !ok {
-			continue
+		f.ledger.booked(fill.TradeID, booked, booked.SizeUSD > 0)
+		return true
+	case ledgerReverse:
+		f.logger.Warn("fill_listener: trade failed, reversing fill",
+			"market", fill.MarketID,
+			"side", fill.Side,
+			"price", fill.Price,
+			"size_usd", fill.SizeUSD,
+			"trade_id", fill.TradeID,
+		)
+		fill.Reversed = true
+		fill.Timestamp = time.Now()
+		_ = f.eventRepo.SaveFill(ctx, fill)
+		if err := f.positionSvc.ReverseFill(ctx, fill); err != nil {
+			f.logger.Error("fill_listener: failed to reverse fill", "error", err)
 		}
+	}
+	return false
+}
 
-		var posSide domain.PositionSide
-		if signalSide == domain.SignalBuyUp {
-			posSide = domain.PositionUp
-		} else {
-			posSide = domain.PositionDown
-		}
+// processOrderEvent forwards an order placement, update or cancellation.
+func (f *FillListener) processOrderEvent(msg *userWSMessage) {
+	if f.orders == nil || msg.ID == "" {
+		return
+	}
+	matched, _ := msg.SizeMatched.Float64()
+	state := ports.OrderState{Status: domain.OrderLive, SizeMatched: matched}
+	if msg.Type == "CANCELLATION" {
+		state.Status = domain.OrderCancelled
+	}
+	f.orders.OnOrderUpdate(msg.ID, state)
+}
 
-		p := domain.Position{
-			MarketID:         marketID,
-			Side:             posSide,
-			AvgEntryPrice:    avgPrice,
-			Quantity:         size,
-			NotionalUSD:      avgPrice * size,
-			OpenedAt:         time.Now(),
-			HoldToSettlement: true,
+// resolveTokenSide maps a token asset ID to a market ID and signal side
+// by looking through the registry for markets that use this token.
+func (f *FillListener) resolveTokenSide(assetID string) (domain.MarketID, domain.TradeSignalSide, bool) {
+	for _, market := range f.registry.ListMarkets() {
+		if market.UpTokenID == assetID {
+			return market.ID, domain.SignalBuyUp, true
 		}
-
-		if err := f.positionSvc.RecordPosition(ctx, p); err != nil {
-			f.logger.Error("bootstrap: failed to record position", "error", err)
-			continue
+		if market.DownTokenID == assetID {
+			return market.ID, domain.SignalBuyDown, true
 		}
-		loaded++
 	}
+	return "", "", false
+}
 
-	f.logger.Info("bootstrap: loaded positions from API", "count", loaded)
-	return nil
+// sellSide maps a token's buy side to its sell side.
+func sellSide(side domain.TradeSignalSide) domain.TradeSignalSide {
+	if side == domain.SignalBuyUp {
+		return domain.SignalSellUp
+	}
+	return domain.SignalSellDown
 }
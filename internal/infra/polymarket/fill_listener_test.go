@@ -0,0 +1,186 @@
+package polymarket
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/storage"
+	"Polybot/internal/service"
+)
+
+func newTestFillListener(t *testing.T) (*FillListener, *service.PositionService, *storage.InMemoryEventRepo) {
+	t.Helper()
+	logger := testLogger()
+	registry := service.NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"})
+	positionSvc := service.NewPositionService(storage.NewInMemoryPositionRepo())
+	events := storage.NewInMemoryEventRepo(logger)
+	return NewFillListener(nil, registry, positionSvc, events, logger), positionSvc, events
+}
+
+func tradeMessage(id, status, asset, price, size string) []byte {
+	return sideTradeMessage(id, status, asset, "BUY", price, size)
+}
+
+func sideTradeMessage(id, status, asset, side, price, size string) []byte {
+	return []byte(fmt.Sprintf(`{"event_type":"trade","trades":[{"id":%q,"status":%q,"asset_id":%q,"side":%q,"price":%q,"size":%q,"trader_side":"TAKER","match_time":"1772366400"}]}`,
+		id, status, asset, side, price, size))
+}
+
+func TestFillListener_Ingest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("status_progression_books_once", func(t *testing.T) {
+		f, positionSvc, events := newTestFillListener(t)
+		for _, status := range []string{"MATCHED", "MINED", "RETRYING", "MINED", "CONFIRMED"} {
+			f.handleMessage(ctx, tradeMessage("t1", status, "up", "0.5", "10"))
+		}
+		upQty, _, upCost, _ := positionSvc.GetInventory("m1")
+		if upQty != 10 || upCost != 5 {
+			t.Errorf("expected one 10-share fill, got qty=%v cost=%v", upQty, upCost)
+		}
+		fills := mustFills(t, events)
+		if len(fills) != 1 {
+			t.Fatalf("expected 1 saved fill, got %d", len(fills))
+		}
+		want := time.Unix(1772366400, 0)
+		if fills[0].TradeID != "t1" || fills[0].Role != domain.FillRoleTaker || !fills[0].Timestamp.Equal(want) {
+			t.Errorf("unexpected fill %+v", fills[0])
+		}
+	})
+
+	t.Run("reconnect_replay_not_duplicated", func(t *testing.T) {
+		f, positionSvc, _ := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "MATCHED", "down", "0.4", "5"))
+		// Replay after reconnect
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "CONFIRMED", "down", "0.4", "5"))
+		upQty, downQty, _, _ := positionSvc.GetInventory("m1")
+		if upQty != 10 || downQty != 5 {
+			t.Errorf("expected 10 UP and 5 DOWN, got %v %v", upQty, downQty)
+		}
+	})
+
+	t.Run("failed_trade_reversed", func(t *testing.T) {
+		f, positionSvc, events := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.4", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "MATCHED", "up", "0.6", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "FAILED", "up", "0.6", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "FAILED", "up", "0.6", "10"))
+
+		upQty, _, upCost, _ := positionSvc.GetInventory("m1")
+		if math.Abs(upQty-10) > 1e-9 || math.Abs(upCost-4) > 1e-9 {
+			t.Errorf("expected failed trade removed from inventory, got qty=%v cost=%v", upQty, upCost)
+		}
+		fills := mustFills(t, events)
+		if len(fills) != 3 || !fills[2].Reversed || fills[2].TradeID != "t2" {
+			t.Errorf("expected a reversal fill event, got %+v", fills)
+		}
+
+		// Failing the only trade closes the position
+		f.handleMessage(ctx, tradeMessage("t1", "FAILED", "up", "0.4", "10"))
+		if upQty, _, _, _ := positionSvc.GetInventory("m1"); upQty != 0 {
+			t.Errorf("expected position closed, got %v shares", upQty)
+		}
+	})
+
+	t.Run("failed_first_never_booked", func(t *testing.T) {
+		f, positionSvc, _ := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "FAILED", "up", "0.5", "10"))
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		if upQty, _, _, _ := positionSvc.GetInventory("m1"); upQty != 0 {
+			t.Errorf("expected failed trade ignored, got %v shares", upQty)
+		}
+	})
+
+	t.Run("sell_reduces_position", func(t *testing.T) {
+		f, positionSvc, _ := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		f.handleMessage(ctx, sideTradeMessage("t2", "MATCHED", "up", "SELL", "0.7", "4"))
+		p, _ := positionSvc.GetPosition("m1", domain.PositionUp)
+		if math.Abs(p.Quantity-6) > 1e-9 || math.Abs(p.RealizedPnL-0.8) > 1e-9 {
+			t.Errorf("expected 6 shares and 0.80 realized, got %+v", p)
+		}
+
+		// A failed sell puts the shares back
+		f.handleMessage(ctx, sideTradeMessage("t2", "FAILED", "up", "SELL", "0.7", "4"))
+		p, _ = positionSvc.GetPosition("m1", domain.PositionUp)
+		if math.Abs(p.Quantity-10) > 1e-9 || math.Abs(p.RealizedPnL) > 1e-9 {
+			t.Errorf("expected sell reversed, got %+v", p)
+		}
+	})
+
+	t.Run("failed_oversell_reverses_booked_shares", func(t *testing.T) {
+		f, positionSvc, _ := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		// Only the 10 shares held are booked
+		f.handleMessage(ctx, sideTradeMessage("t2", "MATCHED", "up", "SELL", "0.7", "15"))
+		f.handleMessage(ctx, sideTradeMessage("t2", "FAILED", "up", "SELL", "0.7", "15"))
+		p, _ := positionSvc.GetPosition("m1", domain.PositionUp)
+		if math.Abs(p.Quantity-10) > 1e-9 || math.Abs(p.NotionalUSD-5) > 1e-9 || math.Abs(p.RealizedPnL) > 1e-9 {
+			t.Errorf("expected the 10 booked shares restored, got %+v", p)
+		}
+	})
+
+	t.Run("failed_after_market_rolled_out_reversed", func(t *testing.T) {
+		f, positionSvc, events := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("t1", "MATCHED", "up", "0.5", "10"))
+		f.handleMessage(ctx, tradeMessage("t2", "MATCHED", "up", "0.4", "5"))
+		f.registry.RemoveMarket("m1")
+
+		f.handleMessage(ctx, tradeMessage("t2", "FAILED", "up", "0.4", "5"))
+		if upQty, _, _, _ := positionSvc.GetInventory("m1"); math.Abs(upQty-10) > 1e-9 {
+			t.Errorf("expected the failed trade reversed, got %v shares", upQty)
+		}
+		if fills := mustFills(t, events); len(fills) != 3 || !fills[2].Reversed || fills[2].MarketID != "m1" {
+			t.Errorf("expected a reversal fill event for m1, got %+v", fills)
+		}
+	})
+
+	t.Run("trade_without_id_booked_on_matched_only", func(t *testing.T) {
+		f, positionSvc, _ := newTestFillListener(t)
+		f.handleMessage(ctx, tradeMessage("", "MATCHED", "up", "0.5", "10"))
+		f.handleMessage(ctx, tradeMessage("", "MINED", "up", "0.5", "10"))
+		if upQty, _, _, _ := positionSvc.GetInventory("m1"); upQty != 10 {
+			t.Errorf("expected 10 shares, got %v", upQty)
+		}
+	})
+}
+
+func mustFills(t *testing.T, events *storage.InMemoryEventRepo) []domain.Fill {
+	t.Helper()
+	fills, err := events.Fills(context.Background(), "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fills
+}
+
+func TestFillListener_MakerFills(t *testing.T) {
+	ctx := context.Background()
+	f, positionSvc, events := newTestFillListener(t)
+	f.client = &ClobClient{creds: &ApiCreds{APIKey: "ours"}}
+
+	// A taker buying DOWN matched our resting UP buy (a mint) and someone
+	// else's DOWN sell. Only our maker order is booked, at its own price.
+	msg := []byte(`{"event_type":"trade","trades":[{"id":"t1","status":"MATCHED","asset_id":"down","side":"BUY","price":"0.45","size":"30","trader_side":"MAKER","match_time":"1772366400",
+		"maker_orders":[
+			{"order_id":"o1","owner":"ours","asset_id":"up","side":"BUY","price":"0.55","matched_amount":"20"},
+			{"order_id":"o2","owner":"theirs","asset_id":"down","side":"SELL","price":"0.45","matched_amount":"10"}]}]}`)
+	f.handleMessage(ctx, msg)
+	f.handleMessage(ctx, msg) // replay
+
+	upQty, downQty, upCost, _ := positionSvc.GetInventory("m1")
+	if upQty != 20 || downQty != 0 || math.Abs(upCost-11) > 1e-9 {
+		t.Errorf("expected 20 UP at 0.55, got up=%v down=%v cost=%v", upQty, downQty, upCost)
+	}
+	fills := mustFills(t, events)
+	if len(fills) != 1 || fills[0].TradeID != "t1:o1" || fills[0].Role != domain.FillRoleMaker {
+		t.Errorf("unexpected fills %+v", fills)
+	}
+}
@@ -1,12 +1,15 @@
 package polymarket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
+
+	"Polybot/internal/domain"
 )
 
 type GammaMarketSummary struct {
@@ -185,3 +188,81 @@ func parseClobTokenIDs(raw any) []string {
 		return []string{}
 	}
 }
+
+// GammaResolution is the resolution state of a market as reported by Gamma.
+type GammaResolution struct {
+	Closed   bool
+	Resolved bool
+	Outcome  string // lower-cased winning outcome label ("up"/"down"), empty until resolved
+}
+
+// GetResolution fetches the current resolution state for a market. Unlike
+// GetMarketBySlug it is never cached and accepts closed markets.
+func (g *GammaMarket) GetResolution(slug string) (GammaResolution, error) {
+	url := fmt.Sprintf("https://gamma-api.polymarket.com/markets/slug/%s", slug)
+	resp, err := g.httpClient.Get(url)
+	if err != nil {
+		return GammaResolution{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return GammaResolution{}, fmt.Errorf("gamma market http %d", resp.StatusCode)
+	}
+
+	var payload map[string]any
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return GammaResolution{}, err
+	}
+	return gammaResolutionFromDict(payload), nil
+}
+
+// ResolvedOutcome implements ports.OutcomeResolver using Gamma's outcome prices.
+func (g *GammaMarket) ResolvedOutcome(_ context.Context, market domain.BinaryMarket) (string, bool, error) {
+	res, err := g.GetResolution(market.Slug)
+	if err != nil {
+		return "", false, fmt.Errorf("resolve outcome %s: %w", market.Slug, err)
+	}
+	if !res.Resolved {
+		return "", false, nil
+	}
+	switch res.Outcome {
+	case domain.OutcomeUp, domain.OutcomeDown:
+		return res.Outcome, true, nil
+	default:
+		return "", false, fmt.Errorf("unexpected outcome %q for %s", res.Outcome, market.Slug)
+	}
+}
+
+// gammaResolutionFromDict derives the winning outcome from outcomePrices.
+// A market is resolved once exactly one outcome is priced at 1.
+func gammaResolutionFromDict(payload map[string]any) GammaResolution {
+	res := GammaResolution{Closed: toBool(payload["closed"])}
+
+	outcomes := parseClobTokenIDs(payload["outcomes"])
+	prices := parseClobTokenIDs(payload["outcomePrices"])
+	if len(outcomes) == 0 || len(outcomes) != len(prices) {
+		return res
+	}
+
+	winner := -1
+	for i, p := range prices {
+		if parseFloatDefault(p) == 1 {
+			if winner >= 0 {
+				return res
+			}
+			winner = i
+		}
+	}
+	if winner < 0 {
+		return res
+	}
+
+	status := strings.ToLower(stringFromAny(payload["umaResolutionStatus"]))
+	res.Resolved = res.Closed || status == "resolved"
+	if res.Resolved {
+		res.Outcome = strings.ToLower(outcomes[winner])
+	}
+	return res
+}
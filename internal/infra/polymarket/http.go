@@ -4,128 +4,325 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"math"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
+// RetryPolicy retries idempotent requests that fail temporarily (network
+// errors, 429s and 5xx) with jittered exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts int           // including the first (default: 3)
+	BaseDelay   time.Duration // backoff before the first retry, doubled after (default: 200ms)
+	MaxDelay    time.Duration // cap on one backoff (default: 2s)
+}
+
+// BreakerPolicy opens a host's circuit after Threshold consecutive temporary
+// failures; requests then fail fast with ErrCircuitOpen until Cooldown has
+// passed and a single probe request succeeds.
+type BreakerPolicy struct {
+	Threshold int           // default: 5
+	Cooldown  time.Duration // default: 10s
+}
+
+// HTTPClient sends CLOB requests. Reads are retried, throttled endpoints
+// wait for their token bucket, and each host has a circuit breaker. Failed
+// requests return an *APIError.
 type HTTPClient struct {
-	client *http.Client
+	client  *http.Client
+	retry   RetryPolicy
+	breaker BreakerPolicy
+
+	mu       sync.Mutex
+	limits   map[string]*tokenBucket // by "METHOD /path"
+	breakers map[string]*hostBreaker // by host
 }
 
 func NewHTTPClient(timeout time.Duration) *HTTPClient {
-	return &HTTPClient{client: &http.Client{Timeout: timeout}}
+	h := &HTTPClient{
+		client:   &http.Client{Timeout: timeout},
+		limits:   make(map[string]*tokenBucket),
+		breakers: make(map[string]*hostBreaker),
+	}
+	h.SetRetryPolicy(RetryPolicy{})
+	h.SetBreakerPolicy(BreakerPolicy{})
+	return h
+}
+
+// SetRetryPolicy replaces the retry policy; zero fields take defaults.
+// Must be called before the first request.
+func (h *HTTPClient) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 200 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 2 * time.Second
+	}
+	h.retry = policy
+}
+
+// SetBreakerPolicy replaces the circuit breaker policy; zero fields take
+// defaults. Must be called before the first request.
+func (h *HTTPClient) SetBreakerPolicy(policy BreakerPolicy) {
+	if policy.Threshold <= 0 {
+		policy.Threshold = 5
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = 10 * time.Second
+	}
+	h.breaker = policy
+}
+
+// SetRateLimit throttles method requests to path to perSecond on average,
+// with bursts of up to burst. Requests over the limit wait for a token.
+func (h *HTTPClient) SetRateLimit(method, path string, perSecond float64, burst int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limits[method+" "+path] = newTokenBucket(perSecond, burst)
 }
 
 func (h *HTTPClient) Request(method, url string, headers map[string]string, body any) (any, error) {
-	reqBody, contentType, err := buildRequestBody(body)
+	payload, err := h.send(method, url, headers, body)
 	if err != nil {
 		return nil, err
 	}
+	var out any
+	if err := decodeJSON(payload, &out); err != nil {
+		return string(payload), nil
+	}
+	return out, nil
+}
+
+func (h *HTTPClient) RequestInto(method, url string, headers map[string]string, body any, out any) error {
+	payload, err := h.send(method, url, headers, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return decodeJSON(payload, out)
+}
 
-	req, err := http.NewRequest(method, url, reqBody)
+// send makes the request, retrying it if it is idempotent, and returns the
+// response body of a 200.
+func (h *HTTPClient) send(method, rawURL string, headers map[string]string, body any) ([]byte, error) {
+	data, err := buildRequestBody(body)
 	if err != nil {
 		return nil, err
 	}
-	applyDefaultHeaders(req, method)
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if idempotent(method, u.Path) {
+		attempts = h.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		payload, err := h.attempt(method, rawURL, u, headers, data)
+		if err == nil || attempt >= attempts || !IsTemporary(err) || errors.Is(err, ErrCircuitOpen) {
+			return payload, err
+		}
+		delay, ok := h.backoff(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		time.Sleep(delay)
+	}
+}
+
+func (h *HTTPClient) attempt(method, rawURL string, u *url.URL, headers map[string]string, data []byte) ([]byte, error) {
+	breaker := h.hostBreaker(u.Host)
+	if !breaker.allow(time.Now()) {
+		return nil, &APIError{Kind: ErrCircuitOpen, Method: method, Path: u.Path, Message: "too many failures from " + u.Host}
+	}
+	if bucket := h.rateLimit(method, u.Path); bucket != nil {
+		time.Sleep(bucket.reserve(time.Now()))
+	}
+
+	payload, err := h.roundTrip(method, rawURL, u.Path, headers, data)
+	breaker.record(time.Now(), !IsTemporary(err))
+	return payload, err
+}
+
+func (h *HTTPClient) roundTrip(method, rawURL, path string, headers map[string]string, data []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, rawURL, reqBody)
+	if err != nil {
+		return nil, err
 	}
+	applyDefaultHeaders(req, method)
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &APIError{Kind: ErrNetwork, Method: method, Path: path, Message: err.Error()}
 	}
 	defer resp.Body.Close()
 	bodyReader := io.Reader(resp.Body)
 	if resp.Header.Get("Content-Encoding") == "gzip" {
 		gz, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, err
+			return nil, &APIError{Kind: ErrNetwork, Method: method, Path: path, Message: err.Error()}
 		}
 		defer gz.Close()
 		bodyReader = gz
 	}
 	payload, err := io.ReadAll(bodyReader)
 	if err != nil {
-		return nil, err
+		return nil, &APIError{Kind: ErrNetwork, Method: method, Path: path, Message: err.Error()}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("polymarket: http %d: %s", resp.StatusCode, string(payload))
+		return nil, newHTTPError(method, path, resp, payload)
 	}
+	return payload, nil
+}
 
-	var out any
-	if err := decodeJSON(payload, &out); err != nil {
-		return string(payload), nil
+// backoff returns the wait before retry number attempt, or false if a
+// 429 asks for longer than MaxDelay.
+func (h *HTTPClient) backoff(attempt int, err error) (time.Duration, bool) {
+	delay := h.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > h.retry.MaxDelay {
+		delay = h.retry.MaxDelay
 	}
-	return out, nil
+	// Full jitter over the upper half keeps retries from synchronizing
+	delay = delay/2 + rand.N(delay/2+1)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		if apiErr.RetryAfter > h.retry.MaxDelay {
+			return 0, false
+		}
+		delay = apiErr.RetryAfter
+	}
+	return delay, true
 }
 
-func (h *HTTPClient) RequestInto(method, url string, headers map[string]string, body any, out any) error {
-	reqBody, contentType, err := buildRequestBody(body)
-	if err != nil {
-		return err
+func (h *HTTPClient) rateLimit(method, path string) *tokenBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.limits[method+" "+path]
+}
+
+func (h *HTTPClient) hostBreaker(host string) *hostBreaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.breakers[host]
+	if !ok {
+		b = &hostBreaker{policy: h.breaker}
+		h.breakers[host] = b
 	}
+	return b
+}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return err
+// readPosts are POST endpoints that only read, so they retry like GETs.
+var readPosts = map[string]bool{
+	GetOrderBooksEndpoint:       true,
+	MidPointsEndpoint:           true,
+	GetPricesEndpoint:           true,
+	GetSpreadsEndpoint:          true,
+	GetLastTradesPricesEndpoint: true,
+}
+
+// idempotent reports whether a request can be repeated safely. Order
+// posts and cancels never are: a timed-out post may still have matched.
+func idempotent(method, path string) bool {
+	return method == http.MethodGet || (method == http.MethodPost && readPosts[path])
+}
+
+// tokenBucket refills at rate tokens per second up to burst.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
 	}
-	applyDefaultHeaders(req, method)
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+	return &tokenBucket{rate: perSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait until it is due.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
 	}
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	b.tokens--
+	if b.tokens >= 0 || b.rate <= 0 {
+		return 0
 	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
 
-	resp, err := h.client.Do(req)
-	if err != nil {
-		return err
+// hostBreaker is the circuit breaker of one host.
+type hostBreaker struct {
+	mu        sync.Mutex
+	policy    BreakerPolicy
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may go out. Once the cooldown has passed
+// a single probe is let through; its outcome closes or reopens the circuit.
+func (b *hostBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.policy.Threshold {
+		return true
 	}
-	defer resp.Body.Close()
-	bodyReader := io.Reader(resp.Body)
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gz, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return err
-		}
-		defer gz.Close()
-		bodyReader = gz
+	if now.Before(b.openUntil) || b.probing {
+		return false
 	}
+	b.probing = true
+	return true
+}
 
-	// On error status, read body for error message
-	if resp.StatusCode != http.StatusOK {
-		payload, _ := io.ReadAll(bodyReader)
-		return fmt.Errorf("polymarket: http %d: %s", resp.StatusCode, string(payload))
+func (b *hostBreaker) record(now time.Time, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if ok {
+		b.failures = 0
+		return
 	}
-	if out == nil {
-		return nil
+	b.failures++
+	if b.failures >= b.policy.Threshold {
+		b.openUntil = now.Add(b.policy.Cooldown)
 	}
-	// Stream-decode directly from response body, avoiding double-buffer
-	decoder := json.NewDecoder(bodyReader)
-	decoder.UseNumber()
-	return decoder.Decode(out)
 }
 
-func buildRequestBody(body any) (io.Reader, string, error) {
+func buildRequestBody(body any) ([]byte, error) {
 	if body == nil {
-		return nil, "", nil
+		return nil, nil
 	}
 	switch v := body.(type) {
 	case string:
-		return bytes.NewReader([]byte(v)), "application/json", nil
+		return []byte(v), nil
 	default:
-		data, err := json.Marshal(v)
-		if err != nil {
-			return nil, "", err
-		}
-		return bytes.NewReader(data), "application/json", nil
+		return json.Marshal(v)
 	}
 }
 
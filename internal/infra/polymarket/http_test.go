@@ -0,0 +1,172 @@
+package polymarket
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Polybot/internal/service"
+)
+
+// testOrder signs a small buy without looking up tick size or fees.
+func testOrder(t *testing.T, c *ClobClient) *SignedOrder {
+	t.Helper()
+	order, err := c.builder.CreateOrder(&OrderArgs{TokenID: "101", Price: 0.5, Size: 10, Side: SideBuy}, CreateOrderOptions{TickSize: "0.01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		status  int
+		message string
+		want    error
+	}{
+		{400, "not enough balance / allowance", ErrInsufficientBalance},
+		{400, "order couldn't be fully filled. FOK orders are fully filled or killed.", ErrOrderNotFilled},
+		{400, "no orders found to match with FAK order", ErrOrderNotFilled},
+		{400, "invalid tick size", ErrRejected},
+		{401, "Unauthorized/Invalid api key", ErrUnauthorized},
+		{404, "No orderbook exists for the requested token id", ErrNotFound},
+		{429, "Too Many Requests", ErrRateLimited},
+		{503, "", ErrServer},
+	}
+	for _, tc := range cases {
+		if got := classifyError(tc.status, tc.message); got != tc.want {
+			t.Errorf("classifyError(%d, %q) = %v, want %v", tc.status, tc.message, got, tc.want)
+		}
+	}
+
+	err := error(&APIError{Kind: ErrOrderNotFilled})
+	if !errors.Is(err, service.ErrNotFilled) || errors.Is(err, service.ErrInsufficientFunds) {
+		t.Errorf("expected %v to match only service.ErrNotFilled", err)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	t.Run("reads_retry_temporary_failures", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				http.Error(w, `{"error":"upstream"}`, http.StatusBadGateway)
+				return
+			}
+			_, _ = io.WriteString(w, `{"spread":"0.02"}`)
+		})
+		spread, err := c.GetSpread("t1")
+		if err != nil || spread != 0.02 {
+			t.Fatalf("expected the third attempt to succeed, got %v (err %v)", spread, err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("expected 3 attempts, got %d", calls.Load())
+		}
+	})
+
+	t.Run("reads_do_not_retry_rejections", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			http.Error(w, `{"error":"No orderbook exists"}`, http.StatusNotFound)
+		})
+		if _, err := c.GetSpread("t1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected 1 attempt, got %d", calls.Load())
+		}
+	})
+
+	t.Run("order_posts_are_not_retried", func(t *testing.T) {
+		var calls atomic.Int32
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			http.Error(w, `{"error":"internal"}`, http.StatusInternalServerError)
+		})
+		_, err := c.PostOrder(testOrder(t, c), OrderTypeFOK)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Kind != ErrServer || apiErr.Status != 500 {
+			t.Fatalf("expected a server APIError, got %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected 1 attempt, got %d", calls.Load())
+		}
+	})
+
+	t.Run("rejected_order_in_200_response", func(t *testing.T) {
+		c := newTestClobClient(t, route(t, "POST", PostOrderEndpoint,
+			`{"success":false,"errorMsg":"not enough balance / allowance","orderID":""}`, nil))
+		_, err := c.PostOrder(testOrder(t, c), OrderTypeFOK)
+		if !errors.Is(err, ErrInsufficientBalance) || !errors.Is(err, service.ErrInsufficientFunds) {
+			t.Errorf("expected insufficient balance, got %v", err)
+		}
+	})
+
+	t.Run("rate_limited_reports_retry_after", func(t *testing.T) {
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, `{"error":"Too Many Requests"}`, http.StatusTooManyRequests)
+		})
+		_, err := c.PostOrder(testOrder(t, c), OrderTypeFOK)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !errors.Is(err, ErrRateLimited) || apiErr.RetryAfter != 30*time.Second {
+			t.Errorf("expected a 429 with a 30s Retry-After, got %v", err)
+		}
+		if !IsTemporary(err) {
+			t.Error("expected a 429 to be temporary")
+		}
+	})
+
+	t.Run("circuit_opens_after_consecutive_failures", func(t *testing.T) {
+		var calls atomic.Int32
+		var healthy atomic.Bool
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			if !healthy.Load() {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = io.WriteString(w, `{"spread":"0.02"}`)
+		})
+		c.http.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+		c.http.SetBreakerPolicy(BreakerPolicy{Threshold: 3, Cooldown: 20 * time.Millisecond})
+
+		for range 3 {
+			_, _ = c.GetSpread("t1")
+		}
+		if _, err := c.GetSpread("t1"); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected an open circuit, got %v", err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("expected the open circuit to skip the request, got %d calls", calls.Load())
+		}
+
+		healthy.Store(true)
+		time.Sleep(30 * time.Millisecond)
+		if _, err := c.GetSpread("t1"); err != nil {
+			t.Fatalf("expected the probe to succeed, got %v", err)
+		}
+		if _, err := c.GetSpread("t1"); err != nil {
+			t.Errorf("expected the circuit to close, got %v", err)
+		}
+	})
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(10, 2)
+	b.last = now
+	if b.reserve(now) != 0 || b.reserve(now) != 0 {
+		t.Fatal("expected the burst to pass without waiting")
+	}
+	if wait := b.reserve(now); wait != 100*time.Millisecond {
+		t.Errorf("expected the third request to wait 100ms, got %v", wait)
+	}
+	if wait := b.reserve(now.Add(time.Second)); wait != 0 {
+		t.Errorf("expected the bucket to refill, got %v", wait)
+	}
+}
@@ -8,23 +8,34 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/infra/recorder"
+	"Polybot/internal/ports"
 )
 
 // MarketProvider resolves rolling Polymarket binary options and streams order books via WebSocket.
 type MarketProvider struct {
 	gamma    *GammaMarket
 	clob     *ClobClient
-	asset    string // e.g. "btc"
-	interval int    // 5 or 15 (minutes)
+	asset    string            // e.g. "btc"
+	interval int               // 5 or 15 (minutes)
+	recorder ports.RawRecorder // optional: raw message capture
 	logger   *slog.Logger
 
-	// Live order book state, updated by WebSocket
-	mu       sync.RWMutex
-	upBook   OrderBookSummary
-	downBook OrderBookSummary
+	// Live order book state keyed by token ID, updated by WebSocket
+	mu          sync.RWMutex
+	books       map[string]*domain.OrderBook
+	upTokenID   string // tokens of the window currently being streamed
+	downTokenID string
+
+	// Set when updates were lost (the message queue overflowed or the
+	// connection dropped): the books are stale until re-seeded from REST
+	dropped atomic.Bool
+
+	hashWarned atomic.Bool // first WS snapshot hash mismatch logged
 }
 
 func NewMarketProvider(clob *ClobClient, asset string, interval int, logger *slog.Logger) *MarketProvider {
@@ -34,9 +45,34 @@ func NewMarketProvider(clob *ClobClient, asset string, interval int, logger *slo
 		asset:    strings.ToLower(asset),
 		interval: interval,
 		logger:   logger,
+		books:    make(map[string]*domain.OrderBook),
 	}
 }
 
+// SetRecorder captures every market channel message as received.
+// Must be called before SubscribeQuotes.
+func (p *MarketProvider) SetRecorder(rec ports.RawRecorder) {
+	p.recorder = rec
+}
+
+// streamKey matches config.MarketSpec.Key, e.g. "btc-5m".
+func (p *MarketProvider) streamKey() string {
+	return fmt.Sprintf("%s-%dm", p.asset, p.interval)
+}
+
+// Book returns a copy of the live order book for a token, if this provider
+// is streaming it.
+func (p *MarketProvider) Book(tokenID string) (*domain.OrderBook, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	book, ok := p.books[tokenID]
+	if !ok {
+		return nil, false
+	}
+	// Levels are updated in place by the WS loop
+	return book.Clone(), true
+}
+
 // CurrentSlug returns the slug for the currently active market window.
 // Polymarket slugs use the window start timestamp (aligned to interval boundary).
 func (p *MarketProvider) CurrentSlug() string {
@@ -91,16 +127,16 @@ func (p *MarketProvider) GetActiveMarkets(_ context.Context) ([]domain.BinaryMar
 // GetQuote returns the latest order book state (from REST fallback or cached WS state).
 func (p *MarketProvider) GetQuote(_ context.Context, marketID domain.MarketID) (domain.MarketQuote, error) {
 	p.mu.RLock()
-	up := p.upBook
-	down := p.downBook
+	up := p.books[p.upTokenID]
+	down := p.books[p.downTokenID]
 	p.mu.RUnlock()
 
 	// If WS has populated the books, use them
-	if len(up.Bids) > 0 || len(up.Asks) > 0 || len(down.Bids) > 0 || len(down.Asks) > 0 {
+	if hasLevels(up) || hasLevels(down) {
 		return domain.MarketQuote{
 			MarketID:  marketID,
-			Up:        extractBestQuote(up),
-			Down:      extractBestQuote(down),
+			Up:        quoteOf(up),
+			Down:      quoteOf(down),
 			Timestamp: time.Now(),
 		}, nil
 	}
@@ -121,18 +157,18 @@ func (p *MarketProvider) fetchQuoteREST(marketID domain.MarketID) (domain.Market
 		return domain.MarketQuote{}, fmt.Errorf("get order books: %w", err)
 	}
 
-	upBook := books[summary.ClobTokenIDs[0]]
-	downBook := books[summary.ClobTokenIDs[1]]
+	upBook := books[summary.ClobTokenIDs[0]].OrderBook()
+	downBook := books[summary.ClobTokenIDs[1]].OrderBook()
 
 	p.mu.Lock()
-	p.upBook = upBook
-	p.downBook = downBook
+	p.books[summary.ClobTokenIDs[0]] = upBook
+	p.books[summary.ClobTokenIDs[1]] = downBook
 	p.mu.Unlock()
 
 	return domain.MarketQuote{
 		MarketID:  marketID,
-		Up:        extractBestQuote(upBook),
-		Down:      extractBestQuote(downBook),
+		Up:        upBook.Quote(),
+		Down:      downBook.Quote(),
 		Timestamp: time.Now(),
 	}, nil
 }
@@ -140,16 +176,38 @@ func (p *MarketProvider) fetchQuoteREST(marketID domain.MarketID) (domain.Market
 // SubscribeQuotes connects to the Polymarket WebSocket and streams live order book updates.
 func (p *MarketProvider) SubscribeQuotes(ctx context.Context) (<-chan domain.MarketQuote, error) {
 	ch := make(chan domain.MarketQuote, 64)
+	msgCh := make(chan []byte, 256)
 
+	ws := NewWSClient(MarketChannel, WSConfig{}, func(message []byte) {
+		if p.recorder != nil {
+			p.recorder.Record(recorder.SourceMarket, p.streamKey(), message)
+		}
+		select {
+		case msgCh <- message:
+		default:
+			p.markStale("message queue full, update dropped")
+		}
+	}, p.logger)
+	// Updates sent while disconnected are lost
+	ws.OnStateChange(func(ev WSStateEvent) {
+		if ev.State == WSConnected && ev.Reconnect {
+			p.markStale("reconnected")
+		}
+	})
+
+	go func() { _ = ws.Run(ctx) }()
 	go func() {
 		defer close(ch)
-		p.runWSLoop(ctx, ch)
+		p.runWSLoop(ctx, ws, msgCh, ch)
 	}()
 
 	return ch, nil
 }
 
-func (p *MarketProvider) runWSLoop(ctx context.Context, ch chan<- domain.MarketQuote) {
+// runWSLoop follows the rolling market windows on one connection, moving
+// its subscription to each new window's tokens. The client reconnects and
+// resubscribes on its own.
+func (p *MarketProvider) runWSLoop(ctx context.Context, ws *WSClient, msgCh <-chan []byte, ch chan<- domain.MarketQuote) {
 	p.logger.Info("ws: starting polymarket quote loop", "asset", p.asset, "interval", p.interval)
 	for {
 		if ctx.Err() != nil {
@@ -173,8 +231,14 @@ func (p *MarketProvider) runWSLoop(ctx context.Context, ch chan<- domain.MarketQ
 		downTokenID := summary.ClobTokenIDs[1]
 		marketID := domain.MarketID(summary.MarketID)
 
+		p.mu.Lock()
+		p.upTokenID = upTokenID
+		p.downTokenID = downTokenID
+		p.mu.Unlock()
+
 		// Seed the books with a REST fetch first
 		p.seedBooks(upTokenID, downTokenID)
+		p.recordWindow(slug, summary)
 
 		p.logger.Info("ws: subscribing to market",
 			"slug", slug,
@@ -182,35 +246,95 @@ func (p *MarketProvider) runWSLoop(ctx context.Context, ch chan<- domain.MarketQ
 			"end_time", time.Unix(summary.EndDateTS, 0).Format(time.RFC3339),
 			"seconds_to_end", summary.ToEnd(),
 		)
+		if err := ws.Subscribe(upTokenID, downTokenID); err != nil {
+			// Still tracked: the client subscribes again when it reconnects
+			p.logger.Warn("ws: subscribe failed", "error", err)
+		}
 
 		endTime := time.Unix(summary.EndDateTS, 0)
+		p.streamWS(ctx, msgCh, upTokenID, downTokenID, marketID, endTime, ch)
+		if ctx.Err() != nil {
+			return
+		}
 
-		// Connect WebSocket and stream until error or market expires
-		err = p.streamWS(ctx, upTokenID, downTokenID, marketID, endTime, ch)
-		if err != nil && ctx.Err() == nil {
-			p.logger.Warn("ws: connection lost or market expired, reconnecting", "error", err)
-			time.Sleep(1 * time.Second)
+		if err := ws.Unsubscribe(upTokenID, downTokenID); err != nil {
+			p.logger.Debug("ws: unsubscribe failed", "error", err)
 		}
+		p.logger.Info("ws: market expired, unsubscribed", "market", marketID)
 
 		// Clear stale book state so new market starts fresh
 		p.mu.Lock()
-		p.upBook = OrderBookSummary{}
-		p.downBook = OrderBookSummary{}
+		delete(p.books, upTokenID)
+		delete(p.books, downTokenID)
 		p.mu.Unlock()
 	}
 }
 
-func (p *MarketProvider) seedBooks(upTokenID, downTokenID string) {
+// seedBooks replaces both books with a REST fetch and reports whether both
+// were seeded.
+func (p *MarketProvider) seedBooks(upTokenID, downTokenID string) bool {
 	books, err := p.clob.GetOrderBooks([]string{upTokenID, downTokenID})
 	if err != nil {
 		p.logger.Warn("ws: failed to seed books via REST", "error", err)
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seeded := true
+	for _, tokenID := range []string{upTokenID, downTokenID} {
+		summary, ok := books[tokenID]
+		if !ok {
+			seeded = false
+			continue
+		}
+		// A corrupt snapshot would poison every update applied on top of it;
+		// the WS snapshot sent on subscribe replaces it anyway
+		if !summary.HashValid() {
+			p.logger.Warn("ws: rest book hash mismatch, not seeding", "token_id", tokenID, "hash", summary.Hash)
+			seeded = false
+			continue
+		}
+		p.books[tokenID] = summary.OrderBook()
+	}
+	return seeded
+}
+
+// markStale records that market messages were lost and discards the
+// streamed books, so nothing reads a book missing those updates.
+func (p *MarketProvider) markStale(reason string) {
+	if p.dropped.Swap(true) {
 		return
 	}
+	p.logger.Warn("ws: books stale until re-seeded", "reason", reason)
+	p.mu.Lock()
+	delete(p.books, p.upTokenID)
+	delete(p.books, p.downTokenID)
+	p.mu.Unlock()
+}
 
+// resync re-seeds stale books from REST and returns the quote they give.
+// ok is false while the books are still stale.
+func (p *MarketProvider) resync(upTokenID, downTokenID string, marketID domain.MarketID) (domain.MarketQuote, bool) {
+	p.dropped.Store(false)
 	p.mu.Lock()
-	p.upBook = books[upTokenID]
-	p.downBook = books[downTokenID]
+	delete(p.books, upTokenID)
+	delete(p.books, downTokenID)
 	p.mu.Unlock()
+	if !p.seedBooks(upTokenID, downTokenID) {
+		p.dropped.Store(true)
+		return domain.MarketQuote{}, false
+	}
+	p.logger.Info("ws: stale books re-seeded", "up", upTokenID, "down", downTokenID)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return domain.MarketQuote{
+		MarketID:  marketID,
+		Up:        quoteOf(p.books[upTokenID]),
+		Down:      quoteOf(p.books[downTokenID]),
+		Timestamp: time.Now(),
+	}, true
 }
 
 // wsMessage represents a Polymarket WebSocket market channel message.
@@ -236,6 +360,7 @@ type wsPriceChange struct {
 	Price   string `json:"price"`
 	Size    string `json:"size"`
 	Side    string `json:"side"`
+	Hash    string `json:"hash"`
 }
 
 type wsBookSide struct {
@@ -243,35 +368,9 @@ type wsBookSide struct {
 	Size  string `json:"size"`
 }
 
-func (p *MarketProvider) streamWS(ctx context.Context, upTokenID, downTokenID string, marketID domain.MarketID, endTime time.Time, ch chan<- domain.MarketQuote) error {
-	msgCh := make(chan []byte, 256)
-
-	ws := NewWebSocketOrderBook(MarketChannel, func(message []byte) {
-		select {
-		case msgCh <- message:
-		default:
-		}
-	})
-
-	// Connect WS with initial subscription payload
-	connectDone := make(chan error, 1)
-	go func() {
-		connectDone <- ws.Run(map[string]any{
-			"assets_ids": []string{upTokenID, downTokenID},
-			"type":       MarketChannel,
-		})
-	}()
-
-	// Ensure WS is closed when we return (causes Run goroutine to exit)
-	defer ws.Close()
-
-	// Also send explicit subscribe after connection establishes
-	time.Sleep(1 * time.Second)
-	if err := ws.SubscribeToTokenIDs([]string{upTokenID, downTokenID}); err != nil {
-		p.logger.Warn("ws: explicit subscribe failed (initial may have worked)", "error", err)
-	}
-	p.logger.Info("ws: subscribed to token IDs", "up", upTokenID[:20]+"...", "down", downTokenID[:20]+"...")
-
+// streamWS applies messages for one market window to the books and emits
+// quotes until the window ends or ctx is cancelled.
+func (p *MarketProvider) streamWS(ctx context.Context, msgCh <-chan []byte, upTokenID, downTokenID string, marketID domain.MarketID, endTime time.Time, ch chan<- domain.MarketQuote) {
 	// Timer to detect market expiry so we can roll to the next market
 	expiryTimer := time.NewTimer(time.Until(endTime))
 	defer expiryTimer.Stop()
@@ -281,52 +380,28 @@ func (p *MarketProvider) streamWS(ctx context.Context, upTokenID, downTokenID st
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return
 		case <-expiryTimer.C:
-			// Market expired — unsubscribe from old tokens and return
-			tokenIDs := []string{upTokenID, downTokenID}
-			if err := ws.UnsubscribeToTokenIDs(tokenIDs); err != nil {
-				p.logger.Debug("ws: unsubscribe on expiry failed (connection may be closing)", "error", err)
-			}
-			p.logger.Info("ws: market expired, unsubscribed", "market", marketID)
-			return nil
-		case err := <-connectDone:
-			return fmt.Errorf("ws disconnected: %w", err)
+			return
 		case raw := <-msgCh:
 			rawStr := string(raw)
-			if rawStr == "PONG" || rawStr == "" || rawStr == "NO NEW ASSETS" {
+			if rawStr == "" || rawStr == "NO NEW ASSETS" {
 				continue
 			}
 
 			p.logger.Debug("ws: raw message", "len", len(raw), "preview", rawStr[:min(len(rawStr), 200)])
 
-			var msgs []wsMessage
-			// Try array first, then single message
-			if err := json.Unmarshal(raw, &msgs); err != nil {
-				var single wsMessage
-				if err2 := json.Unmarshal(raw, &single); err2 != nil {
-					continue
-				}
-				msgs = []wsMessage{single}
-			}
-
-			updated := false
-			for _, msg := range msgs {
-				if p.applyWSMessage(msg, upTokenID, downTokenID) {
-					updated = true
-				}
+			var quote domain.MarketQuote
+			var ok bool
+			if p.dropped.Load() {
+				// The message in hand predates the REST snapshot, which
+				// already includes it; level sizes are absolute, so later
+				// queued updates converge on top of the new books
+				quote, ok = p.resync(upTokenID, downTokenID, marketID)
+			} else {
+				quote, ok = p.applyRaw(raw, upTokenID, downTokenID, marketID, time.Now())
 			}
-
-			if updated {
-				p.mu.RLock()
-				quote := domain.MarketQuote{
-					MarketID:  marketID,
-					Up:        extractBestQuote(p.upBook),
-					Down:      extractBestQuote(p.downBook),
-					Timestamp: time.Now(),
-				}
-				p.mu.RUnlock()
-
+			if ok {
 				if quote.Up != lastUp || quote.Down != lastDown {
 					p.logger.Debug("ws: price change",
 						"up_bid", quote.Up.Bid,
@@ -347,33 +422,109 @@ func (p *MarketProvider) streamWS(ctx context.Context, upTokenID, downTokenID st
 	}
 }
 
+// Replay applies one recorded market channel message for market's tokens to
+// the books, exactly as the live stream does, and returns the quote after it.
+// ok is false when no book changed. Books of earlier windows are kept, so one
+// provider can rebuild every window of a recording.
+func (p *MarketProvider) Replay(raw []byte, market domain.BinaryMarket, recvTime time.Time) (domain.MarketQuote, bool) {
+	return p.applyRaw(raw, market.UpTokenID, market.DownTokenID, market.ID, recvTime)
+}
+
+// applyRaw parses a market channel message, which is either one message or
+// an array of them, and applies it to the window's books.
+func (p *MarketProvider) applyRaw(raw []byte, upTokenID, downTokenID string, marketID domain.MarketID, now time.Time) (domain.MarketQuote, bool) {
+	var msgs []wsMessage
+	// Try array first, then single message
+	if err := json.Unmarshal(raw, &msgs); err != nil {
+		var single wsMessage
+		if err2 := json.Unmarshal(raw, &single); err2 != nil {
+			return domain.MarketQuote{}, false
+		}
+		msgs = []wsMessage{single}
+	}
+
+	updated := false
+	for _, msg := range msgs {
+		if p.applyWSMessage(msg, upTokenID, downTokenID) {
+			updated = true
+		}
+	}
+	if !updated {
+		return domain.MarketQuote{}, false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return domain.MarketQuote{
+		MarketID:  marketID,
+		Up:        quoteOf(p.books[upTokenID]),
+		Down:      quoteOf(p.books[downTokenID]),
+		Timestamp: now,
+	}, true
+}
+
+// recordWindow notes which market the stream follows from now on, so a
+// replay of the recording can map market messages to the up and down books.
+func (p *MarketProvider) recordWindow(slug string, summary *GammaMarketSummary) {
+	if p.recorder == nil {
+		return
+	}
+	data, err := json.Marshal(recorder.Window{
+		Slug:        slug,
+		MarketID:    summary.MarketID,
+		UpTokenID:   summary.ClobTokenIDs[0],
+		DownTokenID: summary.ClobTokenIDs[1],
+		StartTime:   time.Unix(summary.StartDateTS, 0),
+		EndTime:     time.Unix(summary.EndDateTS, 0),
+	})
+	if err != nil {
+		return
+	}
+	p.recorder.Record(recorder.SourceWindow, p.streamKey(), data)
+}
+
 // parsedChange holds pre-parsed price change data computed outside the lock.
 type parsedChange struct {
-	isUp   bool
-	isBid  bool
-	price  string
-	size   string // kept as string to avoid FormatFloat allocation
-	isZero bool
+	assetID string
+	hash    string
+	side    domain.BookSide
+	price   float64
+	size    float64 // 0 removes the level
 }
 
 func (p *MarketProvider) applyWSMessage(msg wsMessage, upTokenID, downTokenID string) bool {
 	// Book snapshot: top-level asset_id with bids/asks
 	if len(msg.Bids) > 0 || len(msg.Asks) > 0 {
-		// Pre-compute conversion outside lock
-		bids := wsBookSidesToOrderSummary(msg.Bids)
-		asks := wsBookSidesToOrderSummary(msg.Asks)
+		if msg.AssetID != upTokenID && msg.AssetID != downTokenID {
+			return false
+		}
+		// The WS snapshot lacks the REST fields the venue hashes
+		// (min_order_size, tick_size, neg_risk), and no recorded message
+		// has confirmed the hash yet, so a mismatch is only reported
+		summary := msg.summary()
+		if !summary.HashValid() {
+			if !p.hashWarned.Swap(true) {
+				p.logger.Warn("ws: book hash mismatch, not enforced", "token_id", msg.AssetID, "hash", msg.Hash, "computed", summary.ComputeHash())
+			} else {
+				p.logger.Debug("ws: book hash mismatch", "token_id", msg.AssetID, "hash", msg.Hash)
+			}
+		}
+		// Build the sorted book outside the lock
+		book := domain.NewOrderBook(msg.AssetID, priceLevels(summary.Bids), priceLevels(summary.Asks))
+		book.Hash = msg.Hash
+		book.Timestamp = parseBookTimestamp(msg.Timestamp)
 
 		p.mu.Lock()
-		book := &p.downBook
-		if msg.AssetID == upTokenID {
-			book = &p.upBook
-		} else if msg.AssetID != downTokenID {
-			p.mu.Unlock()
+		defer p.mu.Unlock()
+		prev := p.books[msg.AssetID]
+		// The same hash is the same book, resent
+		if prev != nil && msg.Hash != "" && prev.Hash == msg.Hash {
 			return false
 		}
-		book.Bids = bids
-		book.Asks = asks
-		p.mu.Unlock()
+		if prev != nil {
+			book.TickSize = prev.TickSize
+		}
+		p.books[msg.AssetID] = book
 		return true
 	}
 
@@ -381,18 +532,28 @@ func (p *MarketProvider) applyWSMessage(msg wsMessage, upTokenID, downTokenID st
 	if len(msg.PriceChanges) > 0 {
 		changes := make([]parsedChange, 0, len(msg.PriceChanges))
 		for _, change := range msg.PriceChanges {
-			isUp := change.AssetID == upTokenID
-			if !isUp && change.AssetID != downTokenID {
+			if change.AssetID != upTokenID && change.AssetID != downTokenID {
 				continue
 			}
-			side := change.Side
-			isBid := side == "buy" || side == "BUY" || side == "bid" || side == "BID"
+			price, err := strconv.ParseFloat(change.Price, 64)
+			if err != nil {
+				continue
+			}
+			size, err := strconv.ParseFloat(change.Size, 64)
+			if err != nil {
+				continue
+			}
+			side := domain.BookAsk
+			switch change.Side {
+			case "buy", "BUY", "bid", "BID":
+				side = domain.BookBid
+			}
 			changes = append(changes, parsedChange{
-				isUp:   isUp,
-				isBid:  isBid,
-				price:  change.Price,
-				size:   change.Size,
-				isZero: change.Size == "0" || change.Size == "0.0" || change.Size == "0.00",
+				assetID: change.AssetID,
+				hash:    change.Hash,
+				side:    side,
+				price:   price,
+				size:    size,
 			})
 		}
 
@@ -400,17 +561,17 @@ func (p *MarketProvider) applyWSMessage(msg wsMessage, upTokenID, downTokenID st
 			return false
 		}
 
+		ts := parseBookTimestamp(msg.Timestamp)
 		p.mu.Lock()
 		for _, c := range changes {
-			targetBook := &p.downBook
-			if c.isUp {
-				targetBook = &p.upBook
-			}
-			if c.isBid {
-				targetBook.Bids = applyChange(targetBook.Bids, c.price, c.size, c.isZero)
-			} else {
-				targetBook.Asks = applyChange(targetBook.Asks, c.price, c.size, c.isZero)
+			book := p.books[c.assetID]
+			if book == nil {
+				book = domain.NewOrderBook(c.assetID, nil, nil)
+				p.books[c.assetID] = book
 			}
+			book.Set(c.side, c.price, c.size)
+			book.Hash = c.hash
+			book.Timestamp = ts
 		}
 		p.mu.Unlock()
 		return true
@@ -419,33 +580,24 @@ func (p *MarketProvider) applyWSMessage(msg wsMessage, upTokenID, downTokenID st
 	return false
 }
 
-// applyChange updates a price level. If isZero, removes it. Otherwise upserts.
-// Uses the raw size string from WS to avoid FormatFloat allocation.
-func applyChange(levels []OrderSummary, price, size string, isZero bool) []OrderSummary {
-	if isZero {
-		for i, l := range levels {
-			if l.Price == price {
-				return append(levels[:i], levels[i+1:]...)
-			}
-		}
-		return levels
-	}
-
-	for i, l := range levels {
-		if l.Price == price {
-			levels[i].Size = size
-			return levels
-		}
+// summary is the snapshot as the book summary its hash is computed over.
+func (m wsMessage) summary() OrderBookSummary {
+	return OrderBookSummary{
+		Market:    m.Market,
+		AssetID:   m.AssetID,
+		Timestamp: m.Timestamp,
+		Bids:      orderSummaries(m.Bids),
+		Asks:      orderSummaries(m.Asks),
+		Hash:      m.Hash,
 	}
-	return append(levels, OrderSummary{Price: price, Size: size})
 }
 
-func wsBookSidesToOrderSummary(sides []wsBookSide) []OrderSummary {
-	result := make([]OrderSummary, len(sides))
+func orderSummaries(sides []wsBookSide) []OrderSummary {
+	levels := make([]OrderSummary, len(sides))
 	for i, s := range sides {
-		result[i] = OrderSummary{Price: s.Price, Size: s.Size}
+		levels[i] = OrderSummary{Price: s.Price, Size: s.Size}
 	}
-	return result
+	return levels
 }
 
 // SecondsUntilExpiry returns how many seconds remain in the current market window.
@@ -456,17 +608,13 @@ func (p *MarketProvider) SecondsUntilExpiry() float64 {
 	return float64(endTS - now)
 }
 
-func extractBestQuote(book OrderBookSummary) domain.SideQuote {
-	var bestBid, bestAsk float64
+func hasLevels(book *domain.OrderBook) bool {
+	return book != nil && (len(book.Bids) > 0 || len(book.Asks) > 0)
+}
 
-	// CLOB sorts bids ascending (worst→best), asks descending (worst→best)
-	// Best bid = last bid, best ask = last ask
-	if n := len(book.Bids); n > 0 {
-		bestBid, _ = strconv.ParseFloat(book.Bids[n-1].Price, 64)
-	}
-	if n := len(book.Asks); n > 0 {
-		bestAsk, _ = strconv.ParseFloat(book.Asks[n-1].Price, 64)
+func quoteOf(book *domain.OrderBook) domain.SideQuote {
+	if book == nil {
+		return domain.SideQuote{}
 	}
-
-	return domain.SideQuote{Bid: bestBid, Ask: bestAsk}
+	return book.Quote()
 }
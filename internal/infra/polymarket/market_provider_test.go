@@ -0,0 +1,150 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+func TestMarketProvider_ApplyWSMessage(t *testing.T) {
+	logger := testLogger()
+	p := NewMarketProvider(nil, "btc", 15, logger)
+
+	snapshot := wsMessage{
+		AssetID:   "up",
+		Market:    "0xabc",
+		Timestamp: "1700000000000",
+		// Worst first, as the venue sends them
+		Bids: []wsBookSide{{Price: "0.40", Size: "100"}, {Price: "0.45", Size: "10"}},
+		Asks: []wsBookSide{{Price: "0.60", Size: "100"}, {Price: "0.50", Size: "10"}},
+		Hash: "h1",
+	}
+	if !p.applyWSMessage(snapshot, "up", "down") {
+		t.Fatal("expected the snapshot to be applied")
+	}
+
+	t.Run("resent_snapshot_is_skipped", func(t *testing.T) {
+		if p.applyWSMessage(snapshot, "up", "down") {
+			t.Error("expected a snapshot with the same hash to be skipped")
+		}
+	})
+
+	t.Run("hash_mismatch_is_not_enforced", func(t *testing.T) {
+		// Until a recorded WS snapshot confirms the venue's hash input, a
+		// mismatch must not drop live books
+		next := snapshot
+		next.Asks = []wsBookSide{{Price: "0.60", Size: "100"}, {Price: "0.50", Size: "11"}}
+		next.Hash = "h1b"
+		if !p.applyWSMessage(next, "up", "down") {
+			t.Error("expected a snapshot failing its hash to be applied")
+		}
+		if book, _ := p.Book("up"); book.SizeAt(domain.BookAsk, 0.50) != 11 {
+			t.Error("expected the snapshot to replace the book")
+		}
+	})
+
+	t.Run("price_changes_keep_best_levels", func(t *testing.T) {
+		p.applyWSMessage(wsMessage{PriceChanges: []wsPriceChange{
+			{AssetID: "up", Side: "SELL", Price: "0.48", Size: "5", Hash: "h2"},
+			{AssetID: "up", Side: "BUY", Price: "0.45", Size: "0", Hash: "h2"},
+			{AssetID: "up", Side: "BUY", Price: "0.42", Size: "30", Hash: "h2"},
+			{AssetID: "other", Side: "BUY", Price: "0.99", Size: "1"},
+		}}, "up", "down")
+
+		book, ok := p.Book("up")
+		if !ok {
+			t.Fatal("expected the up book")
+		}
+		if q := book.Quote(); q.Bid != 0.42 || q.Ask != 0.48 {
+			t.Errorf("expected 0.42/0.48, got %+v", q)
+		}
+		if book.SizeAt(domain.BookBid, 0.45) != 0 || book.Hash != "h2" {
+			t.Errorf("unexpected book %+v", book)
+		}
+		if _, ok := p.Book("other"); ok {
+			t.Error("expected changes for other assets to be ignored")
+		}
+	})
+
+	t.Run("book_returns_a_copy", func(t *testing.T) {
+		book, _ := p.Book("up")
+		book.Set(domain.BookAsk, 0.48, 0)
+		if again, _ := p.Book("up"); again.SizeAt(domain.BookAsk, 0.48) != 5 {
+			t.Error("expected the provider's book to be unchanged")
+		}
+	})
+}
+
+func TestMarketProvider_DroppedMessage(t *testing.T) {
+	books := `[
+		{"market":"0xabc","asset_id":"up","bids":[{"price":"0.44","size":"10"}],"asks":[{"price":"0.47","size":"10"}]},
+		{"market":"0xabc","asset_id":"down","bids":[{"price":"0.52","size":"10"}],"asks":[{"price":"0.55","size":"10"}]}
+	]`
+	clob := newTestClobClient(t, route(t, "POST", GetOrderBooksEndpoint, books, nil))
+	p := NewMarketProvider(clob, "btc", 15, testLogger())
+	p.upTokenID, p.downTokenID = "up", "down"
+	p.applyWSMessage(wsMessage{
+		AssetID: "up",
+		Bids:    []wsBookSide{{Price: "0.40", Size: "100"}},
+		Asks:    []wsBookSide{{Price: "0.60", Size: "100"}},
+	}, "up", "down")
+
+	p.markStale("test")
+	if _, ok := p.Book("up"); ok {
+		t.Fatal("expected the stale book discarded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msgCh := make(chan []byte, 1)
+	ch := make(chan domain.MarketQuote, 1)
+	go p.streamWS(ctx, msgCh, "up", "down", "0xabc", time.Now().Add(time.Minute), ch)
+
+	msgCh <- []byte(`{"price_changes":[{"asset_id":"up","side":"BUY","price":"0.41","size":"5"}]}`)
+	select {
+	case quote := <-ch:
+		if quote.Up.Bid != 0.44 || quote.Up.Ask != 0.47 || quote.Down.Bid != 0.52 {
+			t.Errorf("expected the quote from the re-seeded books, got %+v", quote)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a quote after the re-seed")
+	}
+	if p.dropped.Load() {
+		t.Error("expected the books no longer stale")
+	}
+	if book, _ := p.Book("up"); book.SizeAt(domain.BookBid, 0.41) != 0 {
+		t.Error("expected the message queued behind the drop not applied")
+	}
+}
+
+// testdata/book.json is a /book response, in the endpoint's field order,
+// whose hash was computed independently of this package with the reference
+// CLOB client's algorithm.
+func TestOrderBookSummary_Hash(t *testing.T) {
+	raw, err := os.ReadFile("testdata/book.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary OrderBookSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Hash != "1efc80b6b560d6a2fc45aadbf3e7234eb6d45bfb" {
+		t.Fatalf("unexpected fixture hash %q", summary.Hash)
+	}
+	if !summary.HashValid() {
+		t.Errorf("expected the response hash to verify, computed %s", summary.ComputeHash())
+	}
+
+	summary.Asks[1].Size = "96"
+	if summary.HashValid() {
+		t.Error("expected a tampered book to fail verification")
+	}
+	if got := summary.OrderBook().Timestamp.UnixMilli(); got != 1772366400123 {
+		t.Errorf("expected the timestamp to parse, got %d", got)
+	}
+}
@@ -0,0 +1,60 @@
+package polymarket
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// ComputeHash returns the CLOB's hash of a book summary: the SHA-1 of its
+// compact JSON with the hash field empty.
+func (o OrderBookSummary) ComputeHash() string {
+	o.Hash = ""
+	sum := sha1.Sum([]byte(o.ToJSON()))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashValid reports whether the summary's hash matches its contents. A
+// summary without a hash is taken as valid.
+func (o OrderBookSummary) HashValid() bool {
+	return o.Hash == "" || o.ComputeHash() == o.Hash
+}
+
+// OrderBook converts the summary to a sorted book.
+func (o OrderBookSummary) OrderBook() *domain.OrderBook {
+	book := domain.NewOrderBook(o.AssetID, priceLevels(o.Bids), priceLevels(o.Asks))
+	book.TickSize, _ = strconv.ParseFloat(o.TickSize, 64)
+	book.Hash = o.Hash
+	book.Timestamp = parseBookTimestamp(o.Timestamp)
+	return book
+}
+
+// priceLevels parses CLOB levels, skipping any that don't parse.
+func priceLevels(levels []OrderSummary) []domain.PriceLevel {
+	out := make([]domain.PriceLevel, 0, len(levels))
+	for _, l := range levels {
+		price, err := strconv.ParseFloat(l.Price, 64)
+		if err != nil {
+			continue
+		}
+		size, err := strconv.ParseFloat(l.Size, 64)
+		if err != nil {
+			continue
+		}
+		out = append(out, domain.PriceLevel{Price: price, Size: size})
+	}
+	return out
+}
+
+// parseBookTimestamp parses the CLOB's Unix millisecond book timestamp,
+// returning the zero time if it is missing.
+func parseBookTimestamp(ts string) time.Time {
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
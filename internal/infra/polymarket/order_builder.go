@@ -3,6 +3,8 @@ package polymarket
 import (
 	"errors"
 	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 const (
@@ -149,7 +151,7 @@ func (b *OrderBuilder) CreateOrder(orderArgs *OrderArgs, options CreateOrderOpti
 	if err != nil {
 		return nil, err
 	}
-	return &SignedOrder{Order: order, Signature: signature}, nil
+	return &SignedOrder{Order: order, Signature: signature, Hash: hexutil.Encode(digest)}, nil
 }
 
 func (b *OrderBuilder) CreateMarketOrder(orderArgs MarketOrderArgs, options CreateOrderOptions) (SignedOrder, error) {
@@ -201,7 +203,7 @@ func (b *OrderBuilder) CreateMarketOrder(orderArgs MarketOrderArgs, options Crea
 	if err != nil {
 		return SignedOrder{}, err
 	}
-	return SignedOrder{Order: order, Signature: signature}, nil
+	return SignedOrder{Order: order, Signature: signature, Hash: hexutil.Encode(digest)}, nil
 }
 
 func (b *OrderBuilder) CalculateBuyMarketPrice(positions []OrderSummary, amountToMatch float64, orderType OrderType) (float64, error) {
@@ -0,0 +1,435 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+	"Polybot/internal/service"
+)
+
+// PaperExchangeConfig controls the simulated exchange.
+type PaperExchangeConfig struct {
+	OrderType      OrderType     // OrderTypeFOK (default) or OrderTypeFAK
+	Latency        time.Duration // delay between submission and matching
+	MinTradeShares float64       // reject fills smaller than this (default: 5)
+	FeeRateBps     int           // fallback when the CLOB fee rate lookup fails
+}
+
+// PaperExchange implements ports.ExecutionProvider by matching market buys
+// and sells against the live order books streamed by the MarketProviders.
+//
+// Orders wait Latency, then walk the asks up to the limit price (rounded down
+// to the book's tick). FOK orders that cannot be filled in full are rejected;
+// FAK orders fill what is available. The taker fee is charged in shares, as
+// the exchange does for buys. Fills are delivered as user-channel trade
+// messages through FillListener, the same path live fills take.
+//
+// Liquidity taken by earlier paper orders is remembered until the book for
+// that token changes, so repeated orders cannot fill against the same level
+// twice.
+type PaperExchange struct {
+	client   *ClobClient // optional: fee rate and tick size lookups
+	registry *service.MarketRegistry
+	fills    *FillListener
+	config   PaperExchangeConfig
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	sources  []*MarketProvider
+	consumed map[string]*takenLiquidity // token ID -> liquidity taken from its current book
+	nextID   int
+}
+
+type takenLiquidity struct {
+	bookKey string              // book version the amounts apply to
+	levels  map[float64]float64 // price level -> shares taken
+}
+
+func NewPaperExchange(
+	client *ClobClient,
+	registry *service.MarketRegistry,
+	fills *FillListener,
+	config PaperExchangeConfig,
+	logger *slog.Logger,
+) *PaperExchange {
+	if config.OrderType == "" {
+		config.OrderType = OrderTypeFOK
+	}
+	if config.MinTradeShares <= 0 {
+		config.MinTradeShares = 5
+	}
+	return &PaperExchange{
+		client:   client,
+		registry: registry,
+		fills:    fills,
+		config:   config,
+		logger:   logger,
+		consumed: make(map[string]*takenLiquidity),
+	}
+}
+
+// AddBookSource registers a provider whose order books orders match against.
+func (p *PaperExchange) AddBookSource(source *MarketProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources = append(p.sources, source)
+}
+
+func (p *PaperExchange) BuyUp(ctx context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
+	market, ok := p.registry.GetMarket(marketID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("market %s not found in registry", marketID)
+	}
+	return p.buy(ctx, market, market.UpTokenID, maxPrice, sizeUSD, "UP")
+}
+
+func (p *PaperExchange) BuyDown(ctx context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (ports.OrderResult, error) {
+	market, ok := p.registry.GetMarket(marketID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("market %s not found in registry", marketID)
+	}
+	return p.buy(ctx, market, market.DownTokenID, maxPrice, sizeUSD, "DOWN")
+}
+
+// Sell walks the bids down to minPrice (rounded up to the book's tick). The
+// taker fee comes out of the proceeds.
+func (p *PaperExchange) Sell(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, shares, minPrice float64) (ports.OrderResult, error) {
+	market, ok := p.registry.GetMarket(marketID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("market %s not found in registry", marketID)
+	}
+	tokenID := market.UpTokenID
+	if side == domain.PositionDown {
+		tokenID = market.DownTokenID
+	}
+	return p.sell(ctx, market, tokenID, shares, minPrice, string(side))
+}
+
+// paperFill is the result of walking the book.
+type paperFill struct {
+	shares  float64 // gross shares matched
+	costUSD float64 // USD paid, or received for sells
+}
+
+func (p *PaperExchange) buy(ctx context.Context, market domain.BinaryMarket, tokenID string, maxPrice, sizeUSD float64, sideLabel string) (ports.OrderResult, error) {
+	if tokenID == "" {
+		return ports.OrderResult{}, fmt.Errorf("market %s has no %s token ID", market.ID, sideLabel)
+	}
+
+	if p.config.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ports.OrderResult{}, ctx.Err()
+		case <-time.After(p.config.Latency):
+		}
+	}
+
+	book, ok := p.book(tokenID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("no order book for %s token of %s", sideLabel, market.ID)
+	}
+	limit := roundDownToTick(maxPrice, p.tickSize(tokenID, book))
+
+	p.mu.Lock()
+	fill, taken := p.match(tokenID, book, limit, sizeUSD)
+	if err := p.checkFill(fill, sizeUSD, limit); err != nil {
+		p.mu.Unlock()
+		p.logger.Info("[PAPER] order rejected",
+			"market", market.ID,
+			"side", sideLabel,
+			"order_type", p.config.OrderType,
+			"limit", limit,
+			"size_usd", sizeUSD,
+			"reason", err,
+		)
+		return ports.OrderResult{}, fmt.Errorf("post %s order for %s: %w", sideLabel, market.ID, err)
+	}
+	p.commit(tokenID, book, taken)
+	p.nextID++
+	orderID := fmt.Sprintf("paper-%d", p.nextID)
+	p.mu.Unlock()
+
+	// Taker fee, charged in shares: rate × min(p, 1−p) × shares (USD), at the
+	// average price.
+	avgPrice := fill.costUSD / fill.shares
+	feeUSD := float64(p.feeRateBps(tokenID)) / 10000 * math.Min(avgPrice, 1-avgPrice) * fill.shares
+	netShares := fill.shares - feeUSD/avgPrice
+	effPrice := fill.costUSD / netShares
+
+	p.logger.Info("[PAPER] buy",
+		"market", market.ID,
+		"side", sideLabel,
+		"order_id", orderID,
+		"order_type", p.config.OrderType,
+		"limit", limit,
+		"size_usd", sizeUSD,
+		"filled_usd", fill.costUSD,
+		"shares", netShares,
+		"avg_price", avgPrice,
+		"fee_usd", feeUSD,
+	)
+
+	p.emitFill(ctx, market, tokenID, orderID, "BUY", effPrice, netShares)
+
+	return ports.OrderResult{OrderID: orderID, Filled: true, Price: effPrice, Size: netShares}, nil
+}
+
+func (p *PaperExchange) sell(ctx context.Context, market domain.BinaryMarket, tokenID string, shares, minPrice float64, sideLabel string) (ports.OrderResult, error) {
+	if tokenID == "" {
+		return ports.OrderResult{}, fmt.Errorf("market %s has no %s token ID", market.ID, sideLabel)
+	}
+
+	if p.config.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ports.OrderResult{}, ctx.Err()
+		case <-time.After(p.config.Latency):
+		}
+	}
+
+	book, ok := p.book(tokenID)
+	if !ok {
+		return ports.OrderResult{}, fmt.Errorf("no order book for %s token of %s", sideLabel, market.ID)
+	}
+	limit := roundUpToTick(minPrice, p.tickSize(tokenID, book))
+
+	p.mu.Lock()
+	fill, taken := p.matchBids(tokenID, book, limit, shares)
+	if err := p.checkSell(fill, shares, limit); err != nil {
+		p.mu.Unlock()
+		p.logger.Info("[PAPER] order rejected",
+			"market", market.ID,
+			"side", sideLabel,
+			"order_type", p.config.OrderType,
+			"limit", limit,
+			"shares", shares,
+			"reason", err,
+		)
+		return ports.OrderResult{}, fmt.Errorf("post %s sell order for %s: %w", sideLabel, market.ID, err)
+	}
+	p.commit(tokenID, book, taken)
+	p.nextID++
+	orderID := fmt.Sprintf("paper-%d", p.nextID)
+	p.mu.Unlock()
+
+	// Taker fee, charged in USD on sells: rate × min(p, 1−p) × shares
+	avgPrice := fill.costUSD / fill.shares
+	feeUSD := float64(p.feeRateBps(tokenID)) / 10000 * math.Min(avgPrice, 1-avgPrice) * fill.shares
+	effPrice := (fill.costUSD - feeUSD) / fill.shares
+
+	p.logger.Info("[PAPER] sell",
+		"market", market.ID,
+		"side", sideLabel,
+		"order_id", orderID,
+		"order_type", p.config.OrderType,
+		"limit", limit,
+		"shares", fill.shares,
+		"proceeds_usd", fill.costUSD-feeUSD,
+		"avg_price", avgPrice,
+		"fee_usd", feeUSD,
+	)
+
+	p.emitFill(ctx, market, tokenID, orderID, "SELL", effPrice, fill.shares)
+
+	return ports.OrderResult{OrderID: orderID, Filled: true, Price: effPrice, Size: fill.shares}, nil
+}
+
+// match walks the asks from best to worst up to limit, skipping liquidity
+// earlier paper orders already took. It returns the fill and the shares taken
+// per level without committing them.
+func (p *PaperExchange) match(tokenID string, book *domain.OrderBook, limit, sizeUSD float64) (paperFill, map[float64]float64) {
+	prior := p.prior(tokenID, book)
+
+	var fill paperFill
+	taken := make(map[float64]float64)
+	remaining := sizeUSD
+	for _, level := range book.Asks {
+		if level.Price > limit+1e-9 || remaining <= 1e-9 {
+			break
+		}
+		size := level.Size
+		if prior != nil {
+			size -= prior.levels[level.Price]
+		}
+		if size <= 0 {
+			continue
+		}
+
+		shares := math.Min(size, remaining/level.Price)
+		taken[level.Price] += shares
+		fill.shares += shares
+		fill.costUSD += shares * level.Price
+		remaining -= shares * level.Price
+	}
+	return fill, taken
+}
+
+// checkFill applies the order type and minimum size rules.
+func (p *PaperExchange) checkFill(fill paperFill, sizeUSD, limit float64) error {
+	if fill.shares <= 0 {
+		return fmt.Errorf("%w: no liquidity at or below %.4f", service.ErrNotFilled, limit)
+	}
+	if p.config.OrderType == OrderTypeFOK && fill.costUSD < sizeUSD-1e-6 {
+		return fmt.Errorf("%w: FOK order couldn't be fully filled: $%.2f of $%.2f available at or below %.4f",
+			service.ErrNotFilled, fill.costUSD, sizeUSD, limit)
+	}
+	if fill.shares < p.config.MinTradeShares {
+		return fmt.Errorf("fill of %.2f shares below minimum %.0f", fill.shares, p.config.MinTradeShares)
+	}
+	return nil
+}
+
+// matchBids walks the bids from best to worst down to limit, like match, for
+// a sell of shares.
+func (p *PaperExchange) matchBids(tokenID string, book *domain.OrderBook, limit, shares float64) (paperFill, map[float64]float64) {
+	prior := p.prior(tokenID, book)
+
+	var fill paperFill
+	taken := make(map[float64]float64)
+	remaining := shares
+	for _, level := range book.Bids {
+		if level.Price < limit-1e-9 || remaining <= 1e-9 {
+			break
+		}
+		size := level.Size
+		if prior != nil {
+			size -= prior.levels[level.Price]
+		}
+		if size <= 0 {
+			continue
+		}
+
+		n := math.Min(size, remaining)
+		taken[level.Price] += n
+		fill.shares += n
+		fill.costUSD += n * level.Price
+		remaining -= n
+	}
+	return fill, taken
+}
+
+// prior returns the liquidity already taken from this version of the book.
+func (p *PaperExchange) prior(tokenID string, book *domain.OrderBook) *takenLiquidity {
+	prior := p.consumed[tokenID]
+	if prior == nil || prior.bookKey != bookKey(book) {
+		return nil
+	}
+	return prior
+}
+
+// checkSell applies the order type and minimum size rules to a sell.
+func (p *PaperExchange) checkSell(fill paperFill, shares, limit float64) error {
+	if fill.shares <= 0 {
+		return fmt.Errorf("%w: no liquidity at or above %.4f", service.ErrNotFilled, limit)
+	}
+	if p.config.OrderType == OrderTypeFOK && fill.shares < shares-1e-6 {
+		return fmt.Errorf("%w: FOK order couldn't be fully filled: %.2f of %.2f shares available at or above %.4f",
+			service.ErrNotFilled, fill.shares, shares, limit)
+	}
+	if fill.shares < p.config.MinTradeShares {
+		return fmt.Errorf("fill of %.2f shares below minimum %.0f", fill.shares, p.config.MinTradeShares)
+	}
+	return nil
+}
+
+func (p *PaperExchange) commit(tokenID string, book *domain.OrderBook, taken map[float64]float64) {
+	key := bookKey(book)
+	c := p.consumed[tokenID]
+	if c == nil || c.bookKey != key {
+		c = &takenLiquidity{bookKey: key, levels: make(map[float64]float64)}
+		p.consumed[tokenID] = c
+	}
+	for price, shares := range taken {
+		c.levels[price] += shares
+	}
+}
+
+// emitFill hands the fill to FillListener as a user-channel trade message.
+func (p *PaperExchange) emitFill(ctx context.Context, market domain.BinaryMarket, tokenID, orderID, side string, price, shares float64) {
+	if p.fills == nil {
+		p.logger.Warn("[PAPER] no fill listener, fill not booked", "market", market.ID)
+		return
+	}
+	raw, err := json.Marshal(userWSMessage{
+		EventType: "trade",
+		Trades: []userWSTrade{{
+			AssetID:      tokenID,
+			Side:         side,
+			Price:        json.Number(strconv.FormatFloat(price, 'f', -1, 64)),
+			Size:         json.Number(strconv.FormatFloat(shares, 'f', -1, 64)),
+			Status:       "MATCHED",
+			TraderSide:   "TAKER",
+			Market:       string(market.ID),
+			TakerOrderID: orderID,
+			ID:           orderID,
+			MatchTime:    strconv.FormatInt(time.Now().UnixMilli(), 10),
+		}},
+	})
+	if err != nil {
+		p.logger.Error("[PAPER] failed to encode fill", "market", market.ID, "error", err)
+		return
+	}
+	p.fills.handleMessage(ctx, raw)
+}
+
+func (p *PaperExchange) book(tokenID string) (*domain.OrderBook, bool) {
+	p.mu.Lock()
+	sources := p.sources
+	p.mu.Unlock()
+	for _, s := range sources {
+		if book, ok := s.Book(tokenID); ok {
+			return book, true
+		}
+	}
+	return nil, false
+}
+
+func (p *PaperExchange) tickSize(tokenID string, book *domain.OrderBook) float64 {
+	if book.TickSize > 0 {
+		return book.TickSize
+	}
+	if p.client != nil {
+		if raw, err := p.client.GetTickSize(tokenID); err == nil {
+			if tick, err := strconv.ParseFloat(raw, 64); err == nil && tick > 0 {
+				return tick
+			}
+		}
+	}
+	return 0.01
+}
+
+func (p *PaperExchange) feeRateBps(tokenID string) int {
+	if p.client != nil {
+		if bps, err := p.client.GetFeeRateBps(tokenID); err == nil {
+			return bps
+		}
+	}
+	return p.config.FeeRateBps
+}
+
+// bookKey identifies a book version; any update changes the hash or timestamp.
+func bookKey(book *domain.OrderBook) string {
+	return book.Hash + "|" + strconv.FormatInt(book.Timestamp.UnixMilli(), 10)
+}
+
+func roundDownToTick(price, tick float64) float64 {
+	if tick <= 0 {
+		return price
+	}
+	return math.Floor(price/tick+1e-9) * tick
+}
+
+func roundUpToTick(price, tick float64) float64 {
+	if tick <= 0 {
+		return price
+	}
+	return math.Ceil(price/tick-1e-9) * tick
+}
@@ -0,0 +1,159 @@
+package polymarket
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/storage"
+	"Polybot/internal/service"
+)
+
+func newTestPaperExchange(t *testing.T, cfg PaperExchangeConfig) (*PaperExchange, *service.PositionService) {
+	t.Helper()
+	logger := testLogger()
+
+	registry := service.NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"})
+	positionSvc := service.NewPositionService(storage.NewInMemoryPositionRepo())
+
+	provider := &MarketProvider{books: map[string]*domain.OrderBook{
+		// Asks worst→best, as the CLOB sends them
+		"up": OrderBookSummary{
+			AssetID:  "up",
+			TickSize: "0.01",
+			Hash:     "h1",
+			Asks: []OrderSummary{
+				{Price: "0.60", Size: "100"},
+				{Price: "0.55", Size: "20"},
+				{Price: "0.50", Size: "10"},
+			},
+			Bids: []OrderSummary{
+				{Price: "0.40", Size: "100"},
+				{Price: "0.45", Size: "10"},
+			},
+		}.OrderBook(),
+	}}
+
+	ex := NewPaperExchange(nil, registry, NewFillListener(nil, registry, positionSvc, storage.NewInMemoryEventRepo(logger), logger), cfg, logger)
+	ex.AddBookSource(provider)
+	return ex, positionSvc
+}
+
+func TestPaperExchange_Buy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fok_walks_book_and_books_fill", func(t *testing.T) {
+		ex, positionSvc := newTestPaperExchange(t, PaperExchangeConfig{})
+		// $5 @ 0.50 (10 shares) + $6.05 @ 0.55 (11 shares)
+		res, err := ex.BuyUp(ctx, "m1", 0.559, 11.05)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.OrderID == "" || !res.Filled || math.Abs(res.Size-21) > 1e-9 {
+			t.Errorf("unexpected result %+v", res)
+		}
+		upQty, _, upCost, _ := positionSvc.GetInventory("m1")
+		if math.Abs(upQty-21) > 1e-9 || math.Abs(upCost-11.05) > 1e-9 {
+			t.Errorf("expected fill booked via listener, got qty=%f cost=%f", upQty, upCost)
+		}
+	})
+
+	t.Run("fok_rejected_when_depth_insufficient", func(t *testing.T) {
+		ex, positionSvc := newTestPaperExchange(t, PaperExchangeConfig{})
+		// Only $16 available at or below 0.55
+		if _, err := ex.BuyUp(ctx, "m1", 0.55, 20); !errors.Is(err, service.ErrNotFilled) {
+			t.Fatalf("expected FOK rejection, got %v", err)
+		}
+		if upQty, _, _, _ := positionSvc.GetInventory("m1"); upQty != 0 {
+			t.Errorf("expected no position, got %f", upQty)
+		}
+	})
+
+	t.Run("fak_fills_available_depth", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{OrderType: OrderTypeFAK})
+		res, err := ex.BuyUp(ctx, "m1", 0.55, 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if math.Abs(res.Size-30) > 1e-9 {
+			t.Errorf("expected 30 shares, got %f", res.Size)
+		}
+	})
+
+	t.Run("taken_liquidity_not_reused_until_book_changes", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{})
+		if _, err := ex.BuyUp(ctx, "m1", 0.50, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The 10 shares at 0.50 are gone; only $0 remains at that price
+		if _, err := ex.BuyUp(ctx, "m1", 0.50, 5); err == nil {
+			t.Error("expected rejection against consumed level")
+		}
+	})
+
+	t.Run("below_min_shares_rejected", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{MinTradeShares: 5})
+		if _, err := ex.BuyUp(ctx, "m1", 0.50, 2); err == nil {
+			t.Error("expected min size rejection")
+		}
+	})
+
+	t.Run("fee_charged_in_shares", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{FeeRateBps: 1000})
+		res, err := ex.BuyUp(ctx, "m1", 0.50, 5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// fee = 10% × min(0.5, 0.5) × 10 = $0.50 → 1 share
+		if math.Abs(res.Size-9) > 1e-9 {
+			t.Errorf("expected 9 net shares, got %f", res.Size)
+		}
+	})
+}
+
+func TestPaperExchange_Sell(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fok_walks_bids_and_books_proceeds", func(t *testing.T) {
+		ex, positionSvc := newTestPaperExchange(t, PaperExchangeConfig{})
+		if _, err := ex.BuyUp(ctx, "m1", 0.50, 5); err != nil {
+			t.Fatal(err)
+		}
+		// 5 @ 0.45
+		res, err := ex.Sell(ctx, "m1", domain.PositionUp, 5, 0.45)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !res.Filled || math.Abs(res.Size-5) > 1e-9 || math.Abs(res.Price-0.45) > 1e-9 {
+			t.Errorf("unexpected result %+v", res)
+		}
+		p, _ := positionSvc.GetPosition("m1", domain.PositionUp)
+		// Sold 5 of 10 bought at 0.50 for 0.45: -0.25
+		if math.Abs(p.Quantity-5) > 1e-9 || math.Abs(p.RealizedPnL+0.25) > 1e-9 {
+			t.Errorf("expected sale booked via listener, got %+v", p)
+		}
+	})
+
+	t.Run("fok_rejected_below_min_price", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{})
+		// Only 10 shares bid at or above 0.45
+		if _, err := ex.Sell(ctx, "m1", domain.PositionUp, 20, 0.45); !errors.Is(err, service.ErrNotFilled) {
+			t.Fatalf("expected FOK rejection, got %v", err)
+		}
+	})
+
+	t.Run("fee_deducted_from_proceeds", func(t *testing.T) {
+		ex, _ := newTestPaperExchange(t, PaperExchangeConfig{FeeRateBps: 1000})
+		res, err := ex.Sell(ctx, "m1", domain.PositionUp, 10, 0.45)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// fee = 10% × 0.45 × 10 = $0.45 → $4.05 for 10 shares
+		if math.Abs(res.Price-0.405) > 1e-9 {
+			t.Errorf("expected net price 0.405, got %f", res.Price)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package polymarket
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// PositionSource implements ports.PositionSource with the Polymarket data
+// API (positions) and the CLOB trades endpoint.
+type PositionSource struct {
+	client *ClobClient
+}
+
+func NewPositionSource(client *ClobClient) *PositionSource {
+	return &PositionSource{client: client}
+}
+
+// positionsPageSize is the largest page the data API serves.
+const positionsPageSize = 500
+
+// GetPositions returns the wallet's token balances in the given markets,
+// paging through the data API until it runs out. sizeThreshold=0 keeps dust
+// and sold-out positions, which the API drops by default.
+func (p *PositionSource) GetPositions(_ context.Context, marketIDs []domain.MarketID) ([]ports.VenuePosition, error) {
+	address := p.client.Address()
+	if address == "" {
+		return nil, fmt.Errorf("no wallet address")
+	}
+	if len(marketIDs) == 0 {
+		return nil, nil
+	}
+	markets := make([]string, len(marketIDs))
+	for i, id := range marketIDs {
+		markets[i] = string(id)
+	}
+	params := map[string]string{
+		"market":        strings.Join(markets, ","),
+		"sizeThreshold": "0",
+		"limit":         strconv.Itoa(positionsPageSize),
+	}
+
+	var positions []ports.VenuePosition
+	for offset := 0; ; offset += positionsPageSize {
+		params["offset"] = strconv.Itoa(offset)
+		page, err := p.client.GetPositions(address, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, pos := range page {
+			if pos.Asset == "" {
+				continue
+			}
+			positions = append(positions, ports.VenuePosition{
+				TokenID:  pos.Asset,
+				Size:     max(pos.Size, 0),
+				AvgPrice: pos.AvgPrice,
+			})
+		}
+		if len(page) < positionsPageSize {
+			return positions, nil
+		}
+	}
+}
+
+// GetRecentTrades returns the wallet's trades matched since the given time.
+func (p *PositionSource) GetRecentTrades(_ context.Context, since time.Time) ([]ports.VenueTrade, error) {
+	address := p.client.Address()
+	if address == "" {
+		return nil, fmt.Errorf("no wallet address")
+	}
+	resp, err := p.client.GetTradesTyped(map[string]string{
+		"maker_address": address,
+		"after":         strconv.FormatInt(since.Unix(), 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]ports.VenueTrade, 0, len(resp.Data))
+	for _, t := range resp.Data {
+		size, _ := t.Size.Float64()
+		ts, ok := parseExchangeTime(t.MatchTime)
+		if ok && ts.Before(since) {
+			continue
+		}
+		trades = append(trades, ports.VenueTrade{
+			TradeID:   t.ID,
+			TokenID:   t.AssetID,
+			Status:    t.Status,
+			Size:      size,
+			Timestamp: ts,
+		})
+	}
+	return trades, nil
+}
@@ -0,0 +1,48 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"Polybot/internal/domain"
+)
+
+func TestPositionSource_GetPositions(t *testing.T) {
+	var offsets []string
+	c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != GetPositionsEndpoint || q.Get("market") != "c1,c2" || q.Get("sizeThreshold") != "0" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		offsets = append(offsets, q.Get("offset"))
+
+		var page []Position
+		switch q.Get("offset") {
+		case "0":
+			for i := 0; i < positionsPageSize; i++ {
+				page = append(page, Position{Asset: fmt.Sprintf("t%d", i), Size: 1})
+			}
+		case "500":
+			page = []Position{{Asset: "sold", Size: 0}}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	positions, err := NewPositionSource(c).GetPositions(context.Background(), []domain.MarketID{"c1", "c2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(offsets) != 2 || offsets[1] != "500" {
+		t.Errorf("expected two pages, got offsets %v", offsets)
+	}
+	if len(positions) != positionsPageSize+1 {
+		t.Fatalf("expected %d positions, got %d", positionsPageSize+1, len(positions))
+	}
+	// Sold-out positions are reported, at zero
+	if last := positions[len(positions)-1]; last.TokenID != "sold" || last.Size != 0 {
+		t.Errorf("unexpected last position %+v", last)
+	}
+}
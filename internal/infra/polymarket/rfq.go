@@ -0,0 +1,318 @@
+package polymarket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RFQ (request for quote): a requester asks quoters for a price on a size
+// the book can't absorb, accepts the best quote by signing an order at its
+// price, and the quoter approves with the opposite order. Both orders then
+// match on the exchange like any other trade.
+//
+// Requests and quotes carry amounts in base units. The collateral side is
+// asset "0"; the other asset is the outcome token.
+
+const rfqCollateralAsset = "0"
+
+// RFQRequestArgs asks for a quote to trade Size shares of TokenID at Price
+// or better, from the requester's side.
+type RFQRequestArgs struct {
+	TokenID string
+	Side    OrderSide
+	Price   float64
+	Size    float64
+}
+
+// RFQQuoteArgs answers a request from the quoter's side, which is the
+// opposite of the requester's.
+type RFQQuoteArgs struct {
+	RequestID string
+	TokenID   string
+	Side      OrderSide
+	Price     float64
+	Size      float64
+}
+
+// RFQListParams filters the request and quote listings.
+type RFQListParams struct {
+	Cursor     string
+	State      string // e.g. "active"
+	RequestIDs []string
+	QuoteIDs   []string
+	Markets    []string
+}
+
+// rfqTerms are the amounts of a request or quote, from its creator's side:
+// the creator receives AmountIn of AssetIn and pays AmountOut of AssetOut.
+type rfqTerms struct {
+	AssetIn   string      `json:"assetIn"`
+	AssetOut  string      `json:"assetOut"`
+	AmountIn  json.Number `json:"amountIn"`
+	AmountOut json.Number `json:"amountOut"`
+}
+
+// TokenID returns the outcome token traded.
+func (t rfqTerms) TokenID() string {
+	if t.AssetIn == rfqCollateralAsset {
+		return t.AssetOut
+	}
+	return t.AssetIn
+}
+
+// Side returns the creator's side: BUY when it receives the token.
+func (t rfqTerms) Side() OrderSide {
+	if t.AssetIn == rfqCollateralAsset {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// Shares returns the number of outcome tokens traded.
+func (t rfqTerms) Shares() float64 {
+	if t.AssetIn == rfqCollateralAsset {
+		return fromTokenDecimals(stringOrNumber(t.AmountOut))
+	}
+	return fromTokenDecimals(stringOrNumber(t.AmountIn))
+}
+
+// Price returns USDC per share.
+func (t rfqTerms) Price() float64 {
+	shares := t.Shares()
+	if shares <= 0 {
+		return 0
+	}
+	usd := fromTokenDecimals(stringOrNumber(t.AmountOut))
+	if t.AssetIn == rfqCollateralAsset {
+		usd = fromTokenDecimals(stringOrNumber(t.AmountIn))
+	}
+	return math.Round(usd/shares*1e6) / 1e6
+}
+
+func newRFQTerms(tokenID string, side OrderSide, price, size float64) rfqTerms {
+	shares := strconv.FormatInt(ToTokenDecimals(size), 10)
+	usd := strconv.FormatInt(ToTokenDecimals(RoundNormal(size*price, 6)), 10)
+	if side == SideBuy {
+		return rfqTerms{AssetIn: tokenID, AssetOut: rfqCollateralAsset, AmountIn: json.Number(shares), AmountOut: json.Number(usd)}
+	}
+	return rfqTerms{AssetIn: rfqCollateralAsset, AssetOut: tokenID, AmountIn: json.Number(usd), AmountOut: json.Number(shares)}
+}
+
+// RFQRequest is a request as listed by /rfq/data/requests.
+type RFQRequest struct {
+	rfqTerms
+	RequestID string      `json:"requestId"`
+	State     string      `json:"state"`
+	Expiry    json.Number `json:"expiry"`
+}
+
+// RFQQuote is a quote as listed by /rfq/data/quotes. Its terms are from the
+// quoter's side.
+type RFQQuote struct {
+	rfqTerms
+	QuoteID   string      `json:"quoteId"`
+	RequestID string      `json:"requestId"`
+	State     string      `json:"state"`
+	Expiry    json.Number `json:"expiry"`
+}
+
+type RFQRequestCreated struct {
+	RequestID string      `json:"requestId"`
+	Expiry    json.Number `json:"expiry"`
+}
+
+// CreateRFQRequest asks quoters for a price.
+func (c *ClobClient) CreateRFQRequest(args RFQRequestArgs) (*RFQRequestCreated, error) {
+	if err := c.assertLevel2(); err != nil {
+		return nil, err
+	}
+	if args.TokenID == "" || args.Size <= 0 || args.Price <= 0 {
+		return nil, errors.New("rfq request needs a token, size and price")
+	}
+	body := c.rfqBody(newRFQTerms(args.TokenID, args.Side, args.Price, args.Size))
+	var resp RFQRequestCreated
+	if err := c.l2Request("POST", CreateRFQRequestEndpoint, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.RequestID == "" {
+		return nil, errors.New("rfq request: no request ID in response")
+	}
+	return &resp, nil
+}
+
+func (c *ClobClient) CancelRFQRequest(requestID string) error {
+	return c.l2Request("DELETE", CancelRFQRequestEndpoint, nil, map[string]string{"requestId": requestID}, nil)
+}
+
+func (c *ClobClient) GetRFQRequests(params RFQListParams) (*Page[RFQRequest], error) {
+	var page Page[RFQRequest]
+	if err := c.l2Request("GET", GetRFQRequestsEndpoint, params.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// CreateRFQQuote answers a request as a quoter and returns the quote ID.
+func (c *ClobClient) CreateRFQQuote(args RFQQuoteArgs) (string, error) {
+	if err := c.assertLevel2(); err != nil {
+		return "", err
+	}
+	if args.RequestID == "" || args.TokenID == "" || args.Size <= 0 || args.Price <= 0 {
+		return "", errors.New("rfq quote needs a request, token, size and price")
+	}
+	body := c.rfqBody(newRFQTerms(args.TokenID, args.Side, args.Price, args.Size))
+	body["requestId"] = args.RequestID
+	var resp struct {
+		QuoteID string `json:"quoteId"`
+	}
+	if err := c.l2Request("POST", CreateRFQQuoteEndpoint, nil, body, &resp); err != nil {
+		return "", err
+	}
+	if resp.QuoteID == "" {
+		return "", errors.New("rfq quote: no quote ID in response")
+	}
+	return resp.QuoteID, nil
+}
+
+func (c *ClobClient) CancelRFQQuote(quoteID string) error {
+	return c.l2Request("DELETE", CancelRFQQuoteEndpoint, nil, map[string]string{"quoteId": quoteID}, nil)
+}
+
+func (c *ClobClient) GetRFQQuotes(params RFQListParams) (*Page[RFQQuote], error) {
+	var page Page[RFQQuote]
+	if err := c.l2Request("GET", GetRFQQuotesEndpoint, params.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetRFQBestQuote returns the best quote on a request, or nil if there is
+// none yet.
+func (c *ClobClient) GetRFQBestQuote(requestID string) (*RFQQuote, error) {
+	var resp RFQQuote
+	if err := c.l2Request("GET", GetRFQBestQuoteEndpoint, url.Values{"requestId": {requestID}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.QuoteID == "" {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// AcceptRFQQuote accepts a quote on our request by signing an order at the
+// quote's price and size. The returned order's Hash is its order ID.
+func (c *ClobClient) AcceptRFQQuote(requestID string, quote *RFQQuote, expiresAt time.Time) (*SignedOrder, error) {
+	// The quote is from the quoter's side; our order takes the other
+	side := SideBuy
+	if quote.Side() == SideBuy {
+		side = SideSell
+	}
+	return c.signRFQOrder(rfqOrderArgs{
+		endpoint:  RFQRequestsAcceptEndpoint,
+		requestID: requestID,
+		quoteID:   quote.QuoteID,
+		tokenID:   quote.TokenID(),
+		side:      side,
+		price:     quote.Price(),
+		size:      quote.Shares(),
+		expiresAt: expiresAt,
+	})
+}
+
+// ApproveRFQQuote confirms our quote after the requester accepted it, by
+// signing the quoter's order.
+func (c *ClobClient) ApproveRFQQuote(quoteID string, args RFQQuoteArgs, expiresAt time.Time) (*SignedOrder, error) {
+	return c.signRFQOrder(rfqOrderArgs{
+		endpoint:  RFQQuoteApproveEndpoint,
+		requestID: args.RequestID,
+		quoteID:   quoteID,
+		tokenID:   args.TokenID,
+		side:      args.Side,
+		price:     args.Price,
+		size:      args.Size,
+		expiresAt: expiresAt,
+	})
+}
+
+// GetRFQConfig returns the venue's RFQ settings, e.g. size limits.
+func (c *ClobClient) GetRFQConfig() (map[string]any, error) {
+	var resp map[string]any
+	if err := c.l2Request("GET", RFQConfigEndpoint, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// rfqOrderArgs is the order signed to accept or approve a quote.
+type rfqOrderArgs struct {
+	endpoint           string
+	requestID, quoteID string
+	tokenID            string
+	side               OrderSide
+	price, size        float64
+	expiresAt          time.Time
+}
+
+func (c *ClobClient) signRFQOrder(args rfqOrderArgs) (*SignedOrder, error) {
+	if err := c.assertLevel2(); err != nil {
+		return nil, err
+	}
+	var expiration int64
+	if !args.expiresAt.IsZero() {
+		expiration = args.expiresAt.Unix()
+	}
+	order, err := c.CreateOrder(&OrderArgs{
+		TokenID:    args.tokenID,
+		Price:      args.price,
+		Size:       args.size,
+		Side:       args.side,
+		Expiration: expiration,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sign rfq order: %w", err)
+	}
+	body := order.ToJSONMap()
+	body["requestId"] = args.requestID
+	body["quoteId"] = args.quoteID
+	body["owner"] = c.creds.APIKey
+	if err := c.l2Request("POST", args.endpoint, nil, body, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (c *ClobClient) rfqBody(terms rfqTerms) map[string]any {
+	return map[string]any{
+		"assetIn":   terms.AssetIn,
+		"assetOut":  terms.AssetOut,
+		"amountIn":  terms.AmountIn.String(),
+		"amountOut": terms.AmountOut.String(),
+		"userType":  c.builder.sigType,
+	}
+}
+
+func (p RFQListParams) query() url.Values {
+	values := url.Values{}
+	if p.Cursor != "" {
+		values.Set("next_cursor", p.Cursor)
+	}
+	if p.State != "" {
+		values.Set("state", p.State)
+	}
+	if len(p.RequestIDs) > 0 {
+		values.Set("requestIds", strings.Join(p.RequestIDs, ","))
+	}
+	if len(p.QuoteIDs) > 0 {
+		values.Set("quoteIds", strings.Join(p.QuoteIDs, ","))
+	}
+	if len(p.Markets) > 0 {
+		values.Set("markets", strings.Join(p.Markets, ","))
+	}
+	return values
+}
@@ -0,0 +1,149 @@
+package polymarket
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// RFQRouteConfig sets which orders go through RFQ instead of the book.
+type RFQRouteConfig struct {
+	MinSizeUSD   float64       // orders at least this large ask for quotes first
+	QuoteWait    time.Duration // how long to wait for an acceptable quote (default: 3s)
+	PollInterval time.Duration // time between best-quote checks (default: 250ms)
+	OrderTTL     time.Duration // expiry of the order signed to accept a quote (default: 1m)
+}
+
+// RFQExecutionProvider routes orders of MinSizeUSD or more through RFQ and
+// everything else, including large orders no quoter prices within the
+// limit, to the CLOB book through ExecutionProvider. Limit orders and order
+// status pass straight through.
+//
+// An accepted quote returns the signed order's hash as the order ID; the
+// fill listener books the trade when it matches, as for book orders.
+type RFQExecutionProvider struct {
+	*ExecutionProvider
+	config RFQRouteConfig
+}
+
+func NewRFQExecutionProvider(book *ExecutionProvider, config RFQRouteConfig) *RFQExecutionProvider {
+	if config.QuoteWait <= 0 {
+		config.QuoteWait = 3 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 250 * time.Millisecond
+	}
+	if config.OrderTTL <= 0 {
+		config.OrderTTL = time.Minute
+	}
+	return &RFQExecutionProvider{ExecutionProvider: book, config: config}
+}
+
+func (r *RFQExecutionProvider) BuyUp(ctx context.Context, marketID domain.MarketID, maxPrice, sizeUSD float64) (ports.OrderResult, error) {
+	if result, routed, err := r.route(ctx, marketID, domain.PositionUp, SideBuy, maxPrice, sizeUSD/maxPrice); routed {
+		return result, err
+	}
+	return r.ExecutionProvider.BuyUp(ctx, marketID, maxPrice, sizeUSD)
+}
+
+func (r *RFQExecutionProvider) BuyDown(ctx context.Context, marketID domain.MarketID, maxPrice, sizeUSD float64) (ports.OrderResult, error) {
+	if result, routed, err := r.route(ctx, marketID, domain.PositionDown, SideBuy, maxPrice, sizeUSD/maxPrice); routed {
+		return result, err
+	}
+	return r.ExecutionProvider.BuyDown(ctx, marketID, maxPrice, sizeUSD)
+}
+
+func (r *RFQExecutionProvider) Sell(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, shares, minPrice float64) (ports.OrderResult, error) {
+	if result, routed, err := r.route(ctx, marketID, side, SideSell, minPrice, shares); routed {
+		return result, err
+	}
+	return r.ExecutionProvider.Sell(ctx, marketID, side, shares, minPrice)
+}
+
+// route trades through RFQ if the order is large enough and a quote at the
+// limit or better arrives in time. It reports routed=false to fall back to
+// the book. A failed accept is returned as an error rather than retried on
+// the book, since the venue may have taken it.
+func (r *RFQExecutionProvider) route(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, orderSide OrderSide, limit, shares float64) (result ports.OrderResult, routed bool, err error) {
+	if r.config.MinSizeUSD <= 0 || limit <= 0 || shares*limit < r.config.MinSizeUSD {
+		return ports.OrderResult{}, false, nil
+	}
+	shares = math.Floor(shares*100) / 100
+	tokenID, err := r.tokenID(marketID, side)
+	if err != nil {
+		return ports.OrderResult{}, true, err
+	}
+
+	req, err := r.client.CreateRFQRequest(RFQRequestArgs{TokenID: tokenID, Side: orderSide, Price: limit, Size: shares})
+	if err != nil {
+		r.logger.Warn("[LIVE] rfq request failed, using the book", "market", marketID, "side", side, "error", err)
+		return ports.OrderResult{}, false, nil
+	}
+
+	quote, err := r.awaitQuote(ctx, req.RequestID, orderSide, limit, shares)
+	if err != nil || quote == nil {
+		if cancelErr := r.client.CancelRFQRequest(req.RequestID); cancelErr != nil {
+			r.logger.Warn("[LIVE] rfq cancel failed", "request_id", req.RequestID, "error", cancelErr)
+		}
+		r.logger.Info("[LIVE] no acceptable rfq quote, using the book",
+			"market", marketID, "side", side, "limit", limit, "shares", shares, "error", err)
+		return ports.OrderResult{}, false, nil
+	}
+
+	order, err := r.client.AcceptRFQQuote(req.RequestID, quote, time.Now().Add(r.config.OrderTTL))
+	if err != nil {
+		return ports.OrderResult{}, true, fmt.Errorf("accept rfq quote %s for %s: %w", quote.QuoteID, marketID, err)
+	}
+
+	r.logger.Info("[LIVE] rfq quote accepted",
+		"market", marketID,
+		"side", side,
+		"order_side", orderSide,
+		"token_id", tokenID,
+		"limit", limit,
+		"price", quote.Price(),
+		"shares", quote.Shares(),
+		"request_id", req.RequestID,
+		"quote_id", quote.QuoteID,
+		"order_id", order.Hash,
+	)
+	return ports.OrderResult{OrderID: order.Hash}, true, nil
+}
+
+// awaitQuote polls the best quote until one is for the requested size at
+// the limit or better, or QuoteWait runs out (nil quote).
+func (r *RFQExecutionProvider) awaitQuote(ctx context.Context, requestID string, orderSide OrderSide, limit, shares float64) (*RFQQuote, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.QuoteWait)
+	defer cancel()
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-ticker.C:
+		}
+		quote, err := r.client.GetRFQBestQuote(requestID)
+		if err != nil {
+			return nil, fmt.Errorf("best quote for %s: %w", requestID, err)
+		}
+		if quote != nil && acceptableQuote(quote, orderSide, limit, shares) {
+			return quote, nil
+		}
+	}
+}
+
+func acceptableQuote(q *RFQQuote, orderSide OrderSide, limit, shares float64) bool {
+	// Accepting signs for the quote's size, which must be what we asked for
+	if math.Abs(q.Shares()-shares) > 0.005 {
+		return false
+	}
+	if orderSide == SideBuy {
+		return q.Price() <= limit+1e-9
+	}
+	return q.Price() >= limit-1e-9
+}
@@ -0,0 +1,206 @@
+package polymarket
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/service"
+)
+
+// rfqVenue is a fake CLOB with RFQ endpoints. bestQuote is served from
+// /rfq/data/best-quote; every request path is recorded.
+type rfqVenue struct {
+	mu        sync.Mutex
+	paths     []string
+	bodies    map[string]map[string]any
+	bestQuote string
+}
+
+func (v *rfqVenue) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.paths = append(v.paths, r.Method+" "+r.URL.Path)
+		if r.Body != nil && r.ContentLength != 0 && r.Method != "GET" {
+			var body map[string]any
+			readBody(t, r, &body)
+			v.bodies[r.Method+" "+r.URL.Path] = body
+		}
+		switch r.URL.Path {
+		case GetTickSizeEndpoint:
+			_, _ = io.WriteString(w, `{"minimum_tick_size":"0.01"}`)
+		case GetNegRiskEndpoint:
+			_, _ = io.WriteString(w, `{"neg_risk":false}`)
+		case GetFeeRateEndpoint:
+			_, _ = io.WriteString(w, `{"base_fee":0}`)
+		case CreateRFQRequestEndpoint:
+			_, _ = io.WriteString(w, `{"requestId":"r1","expiry":1767225600}`)
+		case GetRFQBestQuoteEndpoint:
+			_, _ = io.WriteString(w, v.bestQuote)
+		case PostOrderEndpoint:
+			_, _ = io.WriteString(w, `{"orderID":"book1","status":"matched"}`)
+		default:
+			_, _ = io.WriteString(w, `"OK"`)
+		}
+	}
+}
+
+func (v *rfqVenue) called(path string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, p := range v.paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestRFQ(t *testing.T, bestQuote string) (*RFQExecutionProvider, *rfqVenue) {
+	t.Helper()
+	venue := &rfqVenue{bodies: make(map[string]map[string]any), bestQuote: bestQuote}
+	client := newTestClobClient(t, venue.handler(t))
+	registry := service.NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "101", DownTokenID: "202"})
+	logger := testLogger()
+	rfq := NewRFQExecutionProvider(NewExecutionProvider(client, registry, logger), RFQRouteConfig{
+		MinSizeUSD:   30,
+		QuoteWait:    50 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	return rfq, venue
+}
+
+func TestRFQTerms(t *testing.T) {
+	buy := newRFQTerms("101", SideBuy, 0.4, 100)
+	if buy.AssetIn != "101" || buy.AmountIn.String() != "100000000" || buy.AmountOut.String() != "40000000" {
+		t.Errorf("unexpected buy terms %+v", buy)
+	}
+	sell := newRFQTerms("101", SideSell, 0.4, 100)
+	if sell.AssetOut != "101" || sell.AmountIn.String() != "40000000" || sell.Side() != SideSell {
+		t.Errorf("unexpected sell terms %+v", sell)
+	}
+	for _, terms := range []rfqTerms{buy, sell} {
+		if terms.TokenID() != "101" || terms.Shares() != 100 || terms.Price() != 0.4 {
+			t.Errorf("expected 100 shares of 101 at 0.40, got %s %.2f@%.4f", terms.TokenID(), terms.Shares(), terms.Price())
+		}
+	}
+}
+
+func TestClobClient_RFQ(t *testing.T) {
+	t.Run("request_quote_accept", func(t *testing.T) {
+		venue := &rfqVenue{
+			bodies:    make(map[string]map[string]any),
+			bestQuote: `{"quoteId":"q1","requestId":"r1","assetIn":"0","assetOut":"101","amountIn":"39000000","amountOut":"100000000"}`,
+		}
+		c := newTestClobClient(t, venue.handler(t))
+
+		req, err := c.CreateRFQRequest(RFQRequestArgs{TokenID: "101", Side: SideBuy, Price: 0.4, Size: 100})
+		if err != nil || req.RequestID != "r1" {
+			t.Fatalf("unexpected request %+v (err %v)", req, err)
+		}
+		if body := venue.bodies["POST "+CreateRFQRequestEndpoint]; body["assetIn"] != "101" || body["amountOut"] != "40000000" {
+			t.Errorf("unexpected request body %v", body)
+		}
+
+		quote, err := c.GetRFQBestQuote("r1")
+		if err != nil || quote == nil {
+			t.Fatalf("expected a quote, got %v", err)
+		}
+		if quote.Side() != SideSell || quote.Price() != 0.39 || quote.Shares() != 100 {
+			t.Errorf("unexpected quote terms %s %.2f@%.2f", quote.Side(), quote.Shares(), quote.Price())
+		}
+
+		order, err := c.AcceptRFQQuote("r1", quote, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := venue.bodies["POST "+RFQRequestsAcceptEndpoint]
+		if body["requestId"] != "r1" || body["quoteId"] != "q1" || body["side"] != "BUY" || body["signature"] == "" {
+			t.Errorf("unexpected accept body %v", body)
+		}
+		if !strings.HasPrefix(order.Hash, "0x") {
+			t.Errorf("expected an order hash, got %q", order.Hash)
+		}
+	})
+
+	t.Run("no_quote_yet", func(t *testing.T) {
+		venue := &rfqVenue{bodies: make(map[string]map[string]any), bestQuote: `{}`}
+		c := newTestClobClient(t, venue.handler(t))
+		quote, err := c.GetRFQBestQuote("r1")
+		if err != nil || quote != nil {
+			t.Errorf("expected no quote, got %+v (err %v)", quote, err)
+		}
+	})
+
+	t.Run("quoter_creates_and_approves", func(t *testing.T) {
+		venue := &rfqVenue{bodies: make(map[string]map[string]any)}
+		c := newTestClobClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == CreateRFQQuoteEndpoint {
+				_, _ = io.WriteString(w, `{"quoteId":"q9"}`)
+				return
+			}
+			venue.handler(t)(w, r)
+		})
+		args := RFQQuoteArgs{RequestID: "r1", TokenID: "101", Side: SideSell, Price: 0.41, Size: 100}
+		quoteID, err := c.CreateRFQQuote(args)
+		if err != nil || quoteID != "q9" {
+			t.Fatalf("unexpected quote ID %q (err %v)", quoteID, err)
+		}
+		if _, err := c.ApproveRFQQuote(quoteID, args, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		if body := venue.bodies["POST "+RFQQuoteApproveEndpoint]; body["quoteId"] != "q9" || body["side"] != "SELL" {
+			t.Errorf("unexpected approve body %v", body)
+		}
+	})
+}
+
+func TestRFQExecutionProvider(t *testing.T) {
+	ctx := context.Background()
+	quote := `{"quoteId":"q1","requestId":"r1","assetIn":"0","assetOut":"101","amountIn":"39000000","amountOut":"100000000"}`
+
+	t.Run("small_orders_use_the_book", func(t *testing.T) {
+		rfq, venue := newTestRFQ(t, quote)
+		result, err := rfq.BuyUp(ctx, "m1", 0.4, 20)
+		if err != nil || result.OrderID != "book1" {
+			t.Fatalf("expected book order, got %+v (err %v)", result, err)
+		}
+		if venue.called("POST " + CreateRFQRequestEndpoint) {
+			t.Error("expected no rfq request for a small order")
+		}
+	})
+
+	t.Run("large_order_takes_quote_within_limit", func(t *testing.T) {
+		rfq, venue := newTestRFQ(t, quote)
+		// 100 shares at up to 0.40, quoted at 0.39
+		result, err := rfq.BuyUp(ctx, "m1", 0.4, 40)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(result.OrderID, "0x") || venue.called("POST "+PostOrderEndpoint) {
+			t.Errorf("expected the rfq order, got %+v (paths %v)", result, venue.paths)
+		}
+		if !venue.called("POST " + RFQRequestsAcceptEndpoint) {
+			t.Error("expected the quote to be accepted")
+		}
+	})
+
+	t.Run("quote_above_limit_falls_back_to_book", func(t *testing.T) {
+		rfq, venue := newTestRFQ(t, quote)
+		// 0.39 quote vs a 0.38 limit, for 100 shares
+		result, err := rfq.BuyUp(ctx, "m1", 0.38, 38)
+		if err != nil || result.OrderID != "book1" {
+			t.Fatalf("expected book fallback, got %+v (err %v)", result, err)
+		}
+		if !venue.called("DELETE " + CancelRFQRequestEndpoint) {
+			t.Error("expected the rfq request to be cancelled")
+		}
+	})
+}
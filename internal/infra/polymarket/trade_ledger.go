@@ -0,0 +1,167 @@
+package polymarket
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// Trade statuses on the user channel. A trade is MATCHED off-chain, then
+// MINED and CONFIRMED on-chain; RETRYING while the settlement transaction is
+// resubmitted, and FAILED if it never lands.
+const (
+	tradeMatched   = "MATCHED"
+	tradeMined     = "MINED"
+	tradeConfirmed = "CONFIRMED"
+	tradeRetrying  = "RETRYING"
+	tradeFailed    = "FAILED"
+)
+
+// tradeRetention is how long a trade's state is kept to absorb late status
+// updates and reconnect replays.
+const tradeRetention = 24 * time.Hour
+
+// ledgerAction is what the caller must do with a trade update.
+type ledgerAction int
+
+const (
+	ledgerIgnore  ledgerAction = iota
+	ledgerBook                 // accumulate the fill
+	ledgerReverse              // undo the previously booked fill
+)
+
+// tradeLedger tracks each trade through its status progression so that its
+// fill is booked exactly once and reversed if the trade fails.
+type tradeLedger struct {
+	mu        sync.Mutex
+	trades    map[string]*tradeRecord
+	lastPrune time.Time
+}
+
+type tradeRecord struct {
+	status string
+	fill   domain.Fill // booked fill while booked is true
+	booked bool
+	seenAt time.Time
+}
+
+func newTradeLedger() *tradeLedger {
+	return &tradeLedger{trades: make(map[string]*tradeRecord)}
+}
+
+// apply records a status update for a trade and returns the action to take,
+// with the fill to book or reverse.
+//
+// Trades without an ID can't be deduplicated; they are booked on MATCHED
+// only, so later statuses of the same trade don't count it again.
+func (l *tradeLedger) apply(tradeID, status string, fill domain.Fill, now time.Time) (ledgerAction, domain.Fill) {
+	if !knownTradeStatus(status) {
+		return ledgerIgnore, domain.Fill{}
+	}
+	if tradeID == "" {
+		if status == tradeMatched {
+			return ledgerBook, fill
+		}
+		return ledgerIgnore, domain.Fill{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneLocked(now)
+
+	rec, ok := l.trades[tradeID]
+	if !ok {
+		rec = &tradeRecord{status: status, seenAt: now}
+		l.trades[tradeID] = rec
+		if status == tradeFailed {
+			return ledgerIgnore, domain.Fill{}
+		}
+		rec.fill = fill
+		rec.booked = true
+		return ledgerBook, fill
+	}
+
+	if rec.status == tradeFailed {
+		return ledgerIgnore, domain.Fill{}
+	}
+	rec.status = status
+	if status == tradeFailed && rec.booked {
+		rec.booked = false
+		return ledgerReverse, rec.fill
+	}
+	return ledgerIgnore, domain.Fill{}
+}
+
+// fill returns the fill stored for a trade, if the trade is known.
+func (l *tradeLedger) fill(tradeID string) (domain.Fill, bool) {
+	if tradeID == "" {
+		return domain.Fill{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec, ok := l.trades[tradeID]
+	if !ok {
+		return domain.Fill{}, false
+	}
+	return rec.fill, true
+}
+
+// booked replaces a trade's fill with the one positions actually booked, so
+// a later FAILED update reverses exactly that. ok false records that nothing
+// was booked and there is nothing to reverse.
+func (l *tradeLedger) booked(tradeID string, fill domain.Fill, ok bool) {
+	if tradeID == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rec, found := l.trades[tradeID]; found {
+		rec.fill = fill
+		rec.booked = ok
+	}
+}
+
+func (l *tradeLedger) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Hour {
+		return
+	}
+	l.lastPrune = now
+	for id, rec := range l.trades {
+		if now.Sub(rec.seenAt) >= tradeRetention {
+			delete(l.trades, id)
+		}
+	}
+}
+
+func knownTradeStatus(status string) bool {
+	switch status {
+	case tradeMatched, tradeMined, tradeConfirmed, tradeRetrying, tradeFailed:
+		return true
+	}
+	return false
+}
+
+// parseExchangeTime parses a Unix timestamp in seconds or milliseconds.
+func parseExchangeTime(values ...string) (time.Time, bool) {
+	for _, v := range values {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		if n > 1e12 {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
+
+// fillRole maps a CLOB trader side to a fill role.
+func fillRole(traderSide string) string {
+	if traderSide == "MAKER" {
+		return domain.FillRoleMaker
+	}
+	return domain.FillRoleTaker
+}
@@ -3,6 +3,7 @@ package polymarket
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type OrderType string
@@ -111,6 +112,7 @@ type ContractConfig struct {
 type SignedOrder struct {
 	Order     *Order
 	Signature string
+	Hash      string // EIP-712 digest, the CLOB's order ID
 }
 
 type stringOrNumber string
@@ -141,6 +143,8 @@ func (s *stringOrNumber) UnmarshalJSON(data []byte) error {
 }
 
 type Trade struct {
+	ID           string       `json:"id"`
+	MatchTime    string       `json:"match_time"`
 	AssetID      string       `json:"asset_id"`
 	Market       string       `json:"market"`
 	Side         string       `json:"side"`
@@ -177,3 +181,178 @@ type ActiveOrder struct {
 type ActiveOrdersResponse struct {
 	Data []ActiveOrder `json:"data"`
 }
+
+// AssetType selects which balance a balance-allowance call reports.
+type AssetType string
+
+const (
+	AssetCollateral  AssetType = "COLLATERAL"
+	AssetConditional AssetType = "CONDITIONAL"
+)
+
+type BalanceAllowanceParams struct {
+	AssetType AssetType
+	TokenID   string // conditional assets only
+}
+
+// BalanceAllowanceResponse is in base units (6 decimals for USDC and
+// outcome tokens). Allowances are keyed by spender contract; older
+// responses carry a single Allowance instead.
+type BalanceAllowanceResponse struct {
+	Balance    stringOrNumber            `json:"balance"`
+	Allowance  stringOrNumber            `json:"allowance"`
+	Allowances map[string]stringOrNumber `json:"allowances"`
+}
+
+// BalanceUnits returns the balance in USDC or shares.
+func (r *BalanceAllowanceResponse) BalanceUnits() float64 {
+	return fromTokenDecimals(r.Balance)
+}
+
+// AllowanceUnits returns how much the spender contract may move, in USDC or
+// shares. An unlimited approval comes back as a very large number.
+func (r *BalanceAllowanceResponse) AllowanceUnits(spender string) float64 {
+	for addr, v := range r.Allowances {
+		if strings.EqualFold(addr, spender) {
+			return fromTokenDecimals(v)
+		}
+	}
+	if len(r.Allowances) > 0 {
+		return 0
+	}
+	return fromTokenDecimals(r.Allowance)
+}
+
+// Cursors of the CLOB's paginated listings: InitialCursor starts a listing,
+// EndCursor is returned on the last page.
+const (
+	InitialCursor = "MA=="
+	EndCursor     = "LTE="
+)
+
+// Page is one page of a cursor-paginated listing.
+type Page[T any] struct {
+	Limit      int    `json:"limit"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor"`
+	Data       []T    `json:"data"`
+}
+
+// Last reports whether no pages follow this one.
+func (p *Page[T]) Last() bool {
+	return p.NextCursor == "" || p.NextCursor == EndCursor
+}
+
+type MarketToken struct {
+	TokenID string      `json:"token_id"`
+	Outcome string      `json:"outcome"`
+	Price   json.Number `json:"price"`
+	Winner  bool        `json:"winner"`
+}
+
+type RewardRate struct {
+	AssetAddress     string      `json:"asset_address"`
+	RewardsDailyRate json.Number `json:"rewards_daily_rate"`
+}
+
+type MarketRewards struct {
+	Rates     []RewardRate `json:"rates"`
+	MinSize   json.Number  `json:"min_size"`
+	MaxSpread json.Number  `json:"max_spread"`
+}
+
+// Market is a CLOB market as returned by /markets and /sampling-markets.
+type Market struct {
+	ConditionID          string        `json:"condition_id"`
+	QuestionID           string        `json:"question_id"`
+	Question             string        `json:"question"`
+	Description          string        `json:"description"`
+	MarketSlug           string        `json:"market_slug"`
+	EndDateISO           string        `json:"end_date_iso"`
+	GameStartTime        string        `json:"game_start_time"`
+	Tokens               []MarketToken `json:"tokens"`
+	Rewards              MarketRewards `json:"rewards"`
+	MinimumOrderSize     json.Number   `json:"minimum_order_size"`
+	MinimumTickSize      json.Number   `json:"minimum_tick_size"`
+	MakerBaseFee         json.Number   `json:"maker_base_fee"`
+	TakerBaseFee         json.Number   `json:"taker_base_fee"`
+	SecondsDelay         int           `json:"seconds_delay"`
+	Active               bool          `json:"active"`
+	Closed               bool          `json:"closed"`
+	Archived             bool          `json:"archived"`
+	AcceptingOrders      bool          `json:"accepting_orders"`
+	NegRisk              bool          `json:"neg_risk"`
+	NegRiskMarketID      string        `json:"neg_risk_market_id"`
+	NotificationsEnabled bool          `json:"notifications_enabled"`
+	Tags                 []string      `json:"tags"`
+}
+
+// SimplifiedMarket is the reduced market returned by /simplified-markets and
+// /sampling-simplified-markets.
+type SimplifiedMarket struct {
+	ConditionID     string        `json:"condition_id"`
+	Tokens          []MarketToken `json:"tokens"`
+	Rewards         MarketRewards `json:"rewards"`
+	Active          bool          `json:"active"`
+	Closed          bool          `json:"closed"`
+	Archived        bool          `json:"archived"`
+	AcceptingOrders bool          `json:"accepting_orders"`
+}
+
+// OpenOrder is an order as returned by /data/order/{id}.
+type OpenOrder struct {
+	ID              string      `json:"id"`
+	Status          string      `json:"status"`
+	Owner           string      `json:"owner"`
+	MakerAddress    string      `json:"maker_address"`
+	Market          string      `json:"market"`
+	AssetID         string      `json:"asset_id"`
+	Side            string      `json:"side"`
+	OriginalSize    json.Number `json:"original_size"`
+	SizeMatched     json.Number `json:"size_matched"`
+	Price           json.Number `json:"price"`
+	Outcome         string      `json:"outcome"`
+	OrderType       string      `json:"order_type"`
+	Expiration      json.Number `json:"expiration"`
+	CreatedAt       json.Number `json:"created_at"`
+	AssociateTrades []string    `json:"associate_trades"`
+}
+
+// Position is a wallet position as returned by the data API.
+type Position struct {
+	ProxyWallet  string  `json:"proxyWallet"`
+	Asset        string  `json:"asset"`
+	ConditionID  string  `json:"conditionId"`
+	Size         float64 `json:"size"`
+	AvgPrice     float64 `json:"avgPrice"`
+	InitialValue float64 `json:"initialValue"`
+	CurrentValue float64 `json:"currentValue"`
+	CashPnl      float64 `json:"cashPnl"`
+	PercentPnl   float64 `json:"percentPnl"`
+	TotalBought  float64 `json:"totalBought"`
+	RealizedPnl  float64 `json:"realizedPnl"`
+	CurPrice     float64 `json:"curPrice"`
+	Redeemable   bool    `json:"redeemable"`
+	Mergeable    bool    `json:"mergeable"`
+	Title        string  `json:"title"`
+	Slug         string  `json:"slug"`
+	Outcome      string  `json:"outcome"`
+	OutcomeIndex int     `json:"outcomeIndex"`
+	EndDate      string  `json:"endDate"`
+	NegativeRisk bool    `json:"negativeRisk"`
+}
+
+// LastTradePrice is the price and taker side of a token's latest trade.
+type LastTradePrice struct {
+	TokenID string      `json:"token_id"`
+	Price   json.Number `json:"price"`
+	Side    string      `json:"side"`
+}
+
+// Notification is a user notification, e.g. an order fill or cancellation.
+type Notification struct {
+	ID      json.Number    `json:"id"`
+	Type    int            `json:"type"`
+	Owner   string         `json:"owner"`
+	Payload map[string]any `json:"payload"`
+}
@@ -82,3 +82,12 @@ func toBool(value any) bool {
 		return false
 	}
 }
+
+// fromTokenDecimals converts base units (6 decimals) to USDC or shares.
+func fromTokenDecimals(units stringOrNumber) float64 {
+	f, err := strconv.ParseFloat(units.String(), 64)
+	if err != nil {
+		return 0
+	}
+	return f / 1_000_000
+}
@@ -0,0 +1,49 @@
+package polymarket
+
+import (
+	"context"
+	"fmt"
+
+	"Polybot/internal/ports"
+)
+
+// WalletSource implements ports.WalletSource with the CLOB balance-allowance
+// endpoints. Each read asks the CLOB to refresh from chain first, so
+// deposits and approvals show up without a restart.
+type WalletSource struct {
+	client  *ClobClient
+	spender string // exchange contract orders are signed for
+}
+
+func NewWalletSource(client *ClobClient) (*WalletSource, error) {
+	contracts, err := GetContractConfig(client.chainID, false)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletSource{client: client, spender: contracts.Exchange}, nil
+}
+
+// Collateral returns the wallet's USDC balance and exchange allowance.
+func (w *WalletSource) Collateral(_ context.Context) (ports.BalanceAllowance, error) {
+	return w.read(BalanceAllowanceParams{AssetType: AssetCollateral})
+}
+
+// Token returns the wallet's shares of an outcome token and the exchange's
+// approval to move them.
+func (w *WalletSource) Token(_ context.Context, tokenID string) (ports.BalanceAllowance, error) {
+	return w.read(BalanceAllowanceParams{AssetType: AssetConditional, TokenID: tokenID})
+}
+
+func (w *WalletSource) read(params BalanceAllowanceParams) (ports.BalanceAllowance, error) {
+	if err := w.client.UpdateBalanceAllowance(params); err != nil {
+		return ports.BalanceAllowance{}, fmt.Errorf("refresh %s balance: %w", params.AssetType, err)
+	}
+	resp, err := w.client.GetBalanceAllowance(params)
+	if err != nil {
+		return ports.BalanceAllowance{}, fmt.Errorf("get %s balance: %w", params.AssetType, err)
+	}
+	return ports.BalanceAllowance{
+		Balance:   resp.BalanceUnits(),
+		Allowance: resp.AllowanceUnits(w.spender),
+	}, nil
+}
@@ -0,0 +1,335 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"Polybot/internal/infra/retry"
+
+	"github.com/gorilla/websocket"
+)
+
+const MarketChannel = "market"
+const UserChannel = "user"
+
+type WSMessageCallback func(message []byte)
+
+// WSConfig tunes a WSClient. Zero fields take defaults.
+type WSConfig struct {
+	URL          string        // default: PolyWSEndpoint + channel
+	PingInterval time.Duration // time between PING heartbeats (default: 10s)
+	ReadTimeout  time.Duration // connection is dead after this long without a message (default: 30s)
+	WriteTimeout time.Duration // default: 5s
+	MinBackoff   time.Duration // first reconnect delay, doubled per failure (default: 500ms)
+	MaxBackoff   time.Duration // default: 30s
+}
+
+// WSState is the connection state of a WSClient.
+type WSState int
+
+const (
+	WSConnecting WSState = iota
+	WSConnected
+	WSDisconnected // dropped; a reconnect is scheduled
+	WSClosed       // Run returned
+)
+
+func (s WSState) String() string {
+	switch s {
+	case WSConnecting:
+		return "connecting"
+	case WSConnected:
+		return "connected"
+	case WSDisconnected:
+		return "disconnected"
+	case WSClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("WSState(%d)", int(s))
+	}
+}
+
+// WSStateEvent reports a connection state change.
+type WSStateEvent struct {
+	Channel   string
+	State     WSState
+	Reconnect bool          // WSConnected after an earlier connection dropped
+	Err       error         // why the connection dropped (WSDisconnected)
+	RetryIn   time.Duration // delay before the next attempt (WSDisconnected)
+}
+
+// WSClient is a Polymarket channel connection that stays up until its
+// context ends. It detects dead connections from missing heartbeats,
+// reconnects with jittered exponential backoff and resubscribes the tracked
+// IDs on every new connection. Writes are serialized, so Subscribe and
+// Unsubscribe are safe from any goroutine.
+type WSClient struct {
+	channel   string
+	config    WSConfig
+	onMessage WSMessageCallback
+	auth      map[string]any // user channel credentials
+	observers []func(WSStateEvent)
+	logger    *slog.Logger
+
+	mu      sync.Mutex // guards conn, tracked and state; held across subscription writes
+	conn    *websocket.Conn
+	tracked map[string]struct{}
+	state   WSState
+
+	writeMu sync.Mutex // one writer at a time, as gorilla/websocket requires
+}
+
+func NewWSClient(channel string, config WSConfig, onMessage WSMessageCallback, logger *slog.Logger) *WSClient {
+	if config.URL == "" {
+		config.URL = PolyWSEndpoint + channel
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = 10 * time.Second
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = 30 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 5 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	return &WSClient{
+		channel:   channel,
+		config:    config,
+		onMessage: onMessage,
+		logger:    logger,
+		tracked:   make(map[string]struct{}),
+		state:     WSClosed,
+	}
+}
+
+// SetAuth sets the API credentials sent on each user channel connection.
+// Must be called before Run.
+func (w *WSClient) SetAuth(auth map[string]any) {
+	w.auth = auth
+}
+
+// OnStateChange registers fn to receive every connection state change.
+// fn runs on the connection goroutine and must not block.
+// Must be called before Run.
+func (w *WSClient) OnStateChange(fn func(WSStateEvent)) {
+	w.observers = append(w.observers, fn)
+}
+
+// State returns the current connection state.
+func (w *WSClient) State() WSState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Subscribe tracks ids (asset IDs on the market channel, condition IDs on
+// the user channel) and subscribes to them now if connected. Tracked IDs
+// are resubscribed after every reconnect.
+func (w *WSClient) Subscribe(ids ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		w.tracked[id] = struct{}{}
+	}
+	return w.sendOperation("subscribe", ids)
+}
+
+// Unsubscribe stops tracking ids and unsubscribes from them if connected.
+func (w *WSClient) Unsubscribe(ids ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		delete(w.tracked, id)
+	}
+	return w.sendOperation("unsubscribe", ids)
+}
+
+// sendOperation changes the subscriptions of the live connection, if any.
+// Callers hold w.mu.
+func (w *WSClient) sendOperation(operation string, ids []string) error {
+	if w.conn == nil || len(ids) == 0 {
+		return nil
+	}
+	return w.write(w.conn, map[string]any{
+		w.idsField(): ids,
+		"operation":  operation,
+	})
+}
+
+// Run connects and reads until ctx is cancelled, reconnecting whenever the
+// connection drops. It returns ctx.Err().
+func (w *WSClient) Run(ctx context.Context) error {
+	failures := 0
+	everConnected := false
+	for {
+		connected, received, err := w.session(ctx, everConnected)
+		if ctx.Err() != nil {
+			w.setState(WSStateEvent{State: WSClosed})
+			return ctx.Err()
+		}
+		everConnected = everConnected || connected
+		// A connection that carried messages was healthy; start over
+		if received {
+			failures = 0
+		}
+		failures++
+		delay := retry.Backoff(failures, w.config.MinBackoff, w.config.MaxBackoff)
+		w.setState(WSStateEvent{State: WSDisconnected, Err: err, RetryIn: delay})
+
+		select {
+		case <-ctx.Done():
+			w.setState(WSStateEvent{State: WSClosed})
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// session runs one connection until it fails. It reports whether the
+// connection was established and whether any message arrived on it.
+func (w *WSClient) session(ctx context.Context, reconnect bool) (connected, received bool, err error) {
+	w.setState(WSStateEvent{State: WSConnecting})
+	dialer := websocket.Dialer{HandshakeTimeout: w.config.WriteTimeout * 2}
+	conn, _, err := dialer.DialContext(ctx, w.config.URL, nil)
+	if err != nil {
+		return false, false, fmt.Errorf("dial %s: %w", w.config.URL, err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the read when ctx ends
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(w.config.ReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(w.config.ReadTimeout))
+	})
+
+	w.mu.Lock()
+	err = w.write(conn, w.handshake())
+	if err == nil {
+		w.conn = conn
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return false, false, fmt.Errorf("subscribe: %w", err)
+	}
+	defer func() {
+		w.mu.Lock()
+		w.conn = nil
+		w.mu.Unlock()
+	}()
+	w.setState(WSStateEvent{State: WSConnected, Reconnect: reconnect})
+
+	go w.pingLoop(conn, done)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return true, received, err
+		}
+		received = true
+		_ = conn.SetReadDeadline(time.Now().Add(w.config.ReadTimeout))
+		// The venue answers each PING with a PONG, which only keeps the
+		// connection alive
+		if string(message) == "PONG" {
+			continue
+		}
+		if w.onMessage != nil {
+			w.onMessage(message)
+		}
+	}
+}
+
+// handshake is the first message of a connection: the channel and every
+// tracked ID. Callers hold w.mu.
+func (w *WSClient) handshake() map[string]any {
+	ids := make([]string, 0, len(w.tracked))
+	for id := range w.tracked {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	msg := map[string]any{
+		"type":       w.channel,
+		w.idsField(): ids,
+	}
+	if w.auth != nil {
+		msg["auth"] = w.auth
+	}
+	return msg
+}
+
+func (w *WSClient) idsField() string {
+	if w.channel == UserChannel {
+		return "markets"
+	}
+	return "assets_ids"
+}
+
+// pingLoop sends heartbeats until done closes. A failed write closes the
+// connection so the read loop reconnects.
+func (w *WSClient) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(w.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if err := w.writeRaw(conn, []byte("PING")); err != nil {
+			_ = conn.Close()
+			return
+		}
+	}
+}
+
+func (w *WSClient) write(conn *websocket.Conn, payload map[string]any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return w.writeRaw(conn, data)
+}
+
+func (w *WSClient) writeRaw(conn *websocket.Conn, data []byte) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	_ = conn.SetWriteDeadline(time.Now().Add(w.config.WriteTimeout))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (w *WSClient) setState(ev WSStateEvent) {
+	ev.Channel = w.channel
+	w.mu.Lock()
+	w.state = ev.State
+	w.mu.Unlock()
+
+	switch ev.State {
+	case WSConnected:
+		w.logger.Info("ws: connected", "channel", w.channel, "reconnect", ev.Reconnect)
+	case WSDisconnected:
+		w.logger.Warn("ws: disconnected, reconnecting", "channel", w.channel, "retry_in", ev.RetryIn, "error", ev.Err)
+	}
+	for _, fn := range w.observers {
+		fn(ev)
+	}
+}
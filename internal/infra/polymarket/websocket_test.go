@@ -0,0 +1,246 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsVenue is a fake channel server. It records the messages of each
+// connection, answers PING unless silent, and can drop its connections.
+type wsVenue struct {
+	mu     sync.Mutex
+	conns  []*websocket.Conn
+	msgs   [][]string // per connection
+	silent bool
+}
+
+func newWSVenue(t *testing.T) (*wsVenue, string) {
+	t.Helper()
+	v := &wsVenue{}
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		v.mu.Lock()
+		idx := len(v.conns)
+		v.conns = append(v.conns, conn)
+		v.msgs = append(v.msgs, nil)
+		v.mu.Unlock()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			// Writes share the lock with send: a conn allows one writer
+			v.mu.Lock()
+			v.msgs[idx] = append(v.msgs[idx], string(msg))
+			if string(msg) == "PING" && !v.silent {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte("PONG"))
+			}
+			v.mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return v, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// messages returns the messages of connection i, without heartbeats.
+func (v *wsVenue) messages(i int) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if i >= len(v.msgs) {
+		return nil
+	}
+	var out []string
+	for _, m := range v.msgs[i] {
+		if m != "PING" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (v *wsVenue) dropAll() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, c := range v.conns {
+		_ = c.Close()
+	}
+}
+
+func (v *wsVenue) send(i int, msg string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_ = v.conns[i].WriteMessage(websocket.TextMessage, []byte(msg))
+}
+
+// stateRecorder collects state events.
+type stateRecorder struct {
+	mu     sync.Mutex
+	events []WSStateEvent
+}
+
+func (s *stateRecorder) record(ev WSStateEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+func (s *stateRecorder) count(state WSState) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, ev := range s.events {
+		if ev.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newTestWSClient(t *testing.T, url string, onMessage WSMessageCallback) (*WSClient, *stateRecorder) {
+	t.Helper()
+	logger := testLogger()
+	ws := NewWSClient(MarketChannel, WSConfig{
+		URL:          url,
+		PingInterval: 10 * time.Millisecond,
+		ReadTimeout:  100 * time.Millisecond,
+		MinBackoff:   5 * time.Millisecond,
+		MaxBackoff:   20 * time.Millisecond,
+	}, onMessage, logger)
+	states := &stateRecorder{}
+	ws.OnStateChange(states.record)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		_ = ws.Run(ctx)
+	}()
+	return ws, states
+}
+
+func TestWSClient(t *testing.T) {
+	t.Run("subscribes_and_delivers_messages", func(t *testing.T) {
+		venue, url := newWSVenue(t)
+		var mu sync.Mutex
+		var got []string
+		ws, _ := newTestWSClient(t, url, func(m []byte) {
+			mu.Lock()
+			got = append(got, string(m))
+			mu.Unlock()
+		})
+		waitFor(t, "connection", func() bool { return ws.State() == WSConnected })
+		if err := ws.Subscribe("a1", "a2"); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, "subscribe", func() bool { return len(venue.messages(0)) == 2 })
+
+		var sub map[string]any
+		_ = json.Unmarshal([]byte(venue.messages(0)[1]), &sub)
+		if sub["operation"] != "subscribe" || len(sub["assets_ids"].([]any)) != 2 {
+			t.Errorf("unexpected subscribe message %v", sub)
+		}
+
+		venue.send(0, `{"event_type":"book"}`)
+		waitFor(t, "message", func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(got) == 1
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range got {
+			if m == "PONG" {
+				t.Error("expected heartbeats to be filtered")
+			}
+		}
+	})
+
+	t.Run("resubscribes_tracked_ids_after_reconnect", func(t *testing.T) {
+		venue, url := newWSVenue(t)
+		ws, states := newTestWSClient(t, url, nil)
+		waitFor(t, "connection", func() bool { return ws.State() == WSConnected })
+		_ = ws.Subscribe("a1", "a2")
+		_ = ws.Unsubscribe("a1")
+
+		venue.dropAll()
+		waitFor(t, "reconnect", func() bool { return len(venue.messages(1)) > 0 })
+
+		var hello map[string]any
+		_ = json.Unmarshal([]byte(venue.messages(1)[0]), &hello)
+		ids, _ := hello["assets_ids"].([]any)
+		if hello["type"] != MarketChannel || len(ids) != 1 || ids[0] != "a2" {
+			t.Errorf("expected resubscription to a2 only, got %v", hello)
+		}
+		waitFor(t, "reconnected event", func() bool { return states.count(WSConnected) == 2 })
+		if states.count(WSDisconnected) == 0 {
+			t.Error("expected a disconnected event")
+		}
+		states.mu.Lock()
+		defer states.mu.Unlock()
+		var reconnects []bool
+		for _, ev := range states.events {
+			if ev.State == WSConnected {
+				reconnects = append(reconnects, ev.Reconnect)
+			}
+		}
+		if reconnects[0] || !reconnects[1] {
+			t.Errorf("expected only the second connection to be a reconnect, got %v", reconnects)
+		}
+	})
+
+	t.Run("silent_connection_is_replaced", func(t *testing.T) {
+		venue, url := newWSVenue(t)
+		venue.silent = true
+		ws, states := newTestWSClient(t, url, nil)
+		waitFor(t, "connection", func() bool { return ws.State() == WSConnected })
+		// No PONG within ReadTimeout: the client gives up and redials
+		waitFor(t, "redial", func() bool { return states.count(WSConnected) >= 2 })
+	})
+}
+
+func TestWSClient_Closed(t *testing.T) {
+	_, url := newWSVenue(t)
+	logger := testLogger()
+	ws := NewWSClient(UserChannel, WSConfig{URL: url}, nil, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ws.Run(ctx) }()
+	waitFor(t, "connection", func() bool { return ws.State() == WSConnected })
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled || ws.State() != WSClosed {
+			t.Errorf("expected Run to return context.Canceled and close, got %v (%s)", err, ws.State())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected Run to return after cancel")
+	}
+}
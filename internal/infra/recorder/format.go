@@ -0,0 +1,120 @@
+// Package recorder writes raw market-data feeds to disk and reads them back.
+//
+// # File layout
+//
+// One file per rotation window, named raw_<window start unix>.jsonl, holding
+// every source received during [window start, window start + period). The
+// period defaults to 5 minutes and windows are aligned to the Unix epoch, so
+// they line up with Polymarket's up/down market windows.
+//
+// # Format (version 1)
+//
+// Newline-delimited JSON. The first line is a Header:
+//
+//	{"format":"polybot-raw","version":1,"window_start":"...","window_end":"..."}
+//
+// Every following line is a Record:
+//
+//	{"recv_ns":1700000000123456789,"src":"market","key":"btc-5m","data":[...]}
+//
+// Fields:
+//   - recv_ns is the local receive time in Unix nanoseconds.
+//   - src is "chainlink" (a Data Streams report as the SDK's ReportResponse
+//     JSON, fullReport hex-encoded), "market" (Polymarket market channel
+//     message), "window" (a Window: the market the stream key follows from
+//     then on) or "user" (Polymarket user channel message).
+//   - key is the asset for chainlink ("BTC"), the stream key for market and
+//     window ("btc-5m") and empty for user.
+//   - data holds the payload verbatim when it is valid JSON.
+//   - text holds the payload as a string when it is not (e.g. "PONG").
+//
+// Readers must reject files whose format or version they do not know.
+// Adding optional fields does not bump the version; changing the meaning of
+// an existing field does.
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	streams "github.com/smartcontractkit/data-streams-sdk/go"
+)
+
+const (
+	FormatName    = "polybot-raw"
+	FormatVersion = 1
+)
+
+// Record sources.
+const (
+	SourceChainlink = "chainlink"
+	SourceMarket    = "market"
+	SourceWindow    = "window"
+	SourceUser      = "user"
+)
+
+// Header is the first line of every recording file.
+type Header struct {
+	Format      string    `json:"format"`
+	Version     int       `json:"version"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+}
+
+// Record is one raw message with its receive time.
+type Record struct {
+	RecvNs int64           `json:"recv_ns"`
+	Source string          `json:"src"`
+	Key    string          `json:"key,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// RecvTime returns the local receive time.
+func (r Record) RecvTime() time.Time {
+	return time.Unix(0, r.RecvNs)
+}
+
+// Payload returns the message bytes as originally received.
+func (r Record) Payload() []byte {
+	if r.Data != nil {
+		return r.Data
+	}
+	return []byte(r.Text)
+}
+
+// ChainlinkReport decodes a chainlink record back into the SDK report.
+func (r Record) ChainlinkReport() (*streams.ReportResponse, error) {
+	if r.Source != SourceChainlink {
+		return nil, fmt.Errorf("record source is %q, not %q", r.Source, SourceChainlink)
+	}
+	var report streams.ReportResponse
+	if err := json.Unmarshal(r.Data, &report); err != nil {
+		return nil, fmt.Errorf("decode chainlink report: %w", err)
+	}
+	return &report, nil
+}
+
+// Window is the payload of a window record. Market channel messages carry
+// token IDs only, so a replay needs it to tell the up book from the down one.
+type Window struct {
+	Slug        string    `json:"slug"`
+	MarketID    string    `json:"market_id"`
+	UpTokenID   string    `json:"up_token_id"`
+	DownTokenID string    `json:"down_token_id"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+}
+
+// Window decodes a window record.
+func (r Record) Window() (Window, error) {
+	if r.Source != SourceWindow {
+		return Window{}, fmt.Errorf("record source is %q, not %q", r.Source, SourceWindow)
+	}
+	var w Window
+	if err := json.Unmarshal(r.Data, &w); err != nil {
+		return Window{}, fmt.Errorf("decode window: %w", err)
+	}
+	return w, nil
+}
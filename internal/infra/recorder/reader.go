@@ -0,0 +1,150 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader iterates the records of one recording file.
+type Reader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	header  Header
+	line    int
+}
+
+// Open opens a recording file and validates its header.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	r := &Reader{file: f, scanner: scanner}
+	if !scanner.Scan() {
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read header of %s: %w", path, err)
+		}
+		return nil, fmt.Errorf("%s: empty recording", path)
+	}
+	r.line = 1
+	if err := json.Unmarshal(scanner.Bytes(), &r.header); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: invalid header: %w", path, err)
+	}
+	if r.header.Format != FormatName || r.header.Version != FormatVersion {
+		f.Close()
+		return nil, fmt.Errorf("%s: unsupported format %q version %d", path, r.header.Format, r.header.Version)
+	}
+	return r, nil
+}
+
+// Header returns the file header.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next returns the next record, or io.EOF after the last one.
+func (r *Reader) Next() (Record, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return Record{}, err
+		}
+		return Record{}, io.EOF
+	}
+	r.line++
+	var rec Record
+	if err := json.Unmarshal(r.scanner.Bytes(), &rec); err != nil {
+		return Record{}, fmt.Errorf("%s:%d: %w", r.file.Name(), r.line, err)
+	}
+	return rec, nil
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// ListFiles returns the recording files in dir whose windows overlap
+// [from, to), in window order. Zero from/to leave that side unbounded.
+func ListFiles(dir string, from, to time.Time) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "raw_*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	type window struct {
+		path  string
+		start int64
+	}
+	windows := make([]window, 0, len(paths))
+	for _, p := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "raw_"), ".jsonl")
+		start, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		windows = append(windows, window{path: p, start: start})
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].start < windows[j].start })
+
+	out := make([]string, 0, len(windows))
+	for i, w := range windows {
+		if !to.IsZero() && w.start >= to.Unix() {
+			break
+		}
+		// A window ends where the next begins; the last is open-ended.
+		if !from.IsZero() && i+1 < len(windows) && windows[i+1].start <= from.Unix() {
+			continue
+		}
+		out = append(out, w.path)
+	}
+	return out, nil
+}
+
+// ReadRange reads every record received in [from, to) across the recording
+// files in dir, in receive order. Zero from/to leave that side unbounded.
+func ReadRange(dir string, from, to time.Time) ([]Record, error) {
+	paths, err := ListFiles(dir, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, path := range paths {
+		r, err := Open(path)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			rec, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				r.Close()
+				return nil, err
+			}
+			t := rec.RecvTime()
+			if (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to)) {
+				continue
+			}
+			records = append(records, rec)
+		}
+		r.Close()
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].RecvNs < records[j].RecvNs })
+	return records, nil
+}
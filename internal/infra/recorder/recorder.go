@@ -0,0 +1,154 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"Polybot/internal/ports"
+)
+
+// flushInterval bounds how much buffered data a crash can lose.
+const flushInterval = time.Second
+
+// Recorder appends raw feed messages to rotating per-window files.
+// It implements ports.RawRecorder.
+type Recorder struct {
+	dir    string
+	period time.Duration
+	clock  ports.Clock
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	file        *os.File
+	w           *bufio.Writer
+	windowStart time.Time
+	lastFlush   time.Time
+	closed      bool
+}
+
+func New(dir string, period time.Duration, clock ports.Clock, logger *slog.Logger) (*Recorder, error) {
+	if period <= 0 {
+		period = 5 * time.Minute
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording dir: %w", err)
+	}
+	return &Recorder{
+		dir:    dir,
+		period: period,
+		clock:  clock,
+		logger: logger,
+	}, nil
+}
+
+// Record writes one message stamped with the current time. Errors are logged,
+// never returned: recording must not interfere with trading.
+func (r *Recorder) Record(source, key string, payload []byte) {
+	now := r.clock.Now()
+	rec := Record{RecvNs: now.UnixNano(), Source: source, Key: key}
+	if json.Valid(payload) {
+		rec.Data = payload
+	} else {
+		rec.Text = string(payload)
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		r.logger.Warn("recorder: failed to encode record", "source", source, "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	if err := r.rotate(now); err != nil {
+		r.logger.Warn("recorder: failed to open window file", "error", err)
+		return
+	}
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		r.logger.Warn("recorder: write failed", "file", r.file.Name(), "error", err)
+		return
+	}
+	if now.Sub(r.lastFlush) >= flushInterval {
+		r.flush()
+		r.lastFlush = now
+	}
+}
+
+// Close flushes and closes the current file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return r.closeFile()
+}
+
+// rotate opens the file for now's window if it is not already open.
+func (r *Recorder) rotate(now time.Time) error {
+	start := now.Truncate(r.period)
+	if r.file != nil && start.Equal(r.windowStart) {
+		return nil
+	}
+	if err := r.closeFile(); err != nil {
+		r.logger.Warn("recorder: failed to close window file", "error", err)
+	}
+
+	path := filepath.Join(r.dir, FileName(start))
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.w = bufio.NewWriterSize(f, 64*1024)
+	r.windowStart = start
+
+	// Restarting inside a window appends to the existing file; only a new
+	// file gets a header.
+	if os.IsNotExist(statErr) {
+		header, _ := json.Marshal(Header{
+			Format:      FormatName,
+			Version:     FormatVersion,
+			WindowStart: start.UTC(),
+			WindowEnd:   start.Add(r.period).UTC(),
+		})
+		if _, err := r.w.Write(append(header, '\n')); err != nil {
+			return err
+		}
+	}
+	r.logger.Info("recorder: recording window", "file", path)
+	return nil
+}
+
+func (r *Recorder) flush() {
+	if r.w == nil {
+		return
+	}
+	if err := r.w.Flush(); err != nil {
+		r.logger.Warn("recorder: flush failed", "file", r.file.Name(), "error", err)
+	}
+}
+
+func (r *Recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	r.flush()
+	err := r.file.Close()
+	r.file = nil
+	r.w = nil
+	return err
+}
+
+// FileName returns the recording file name for a window start.
+func FileName(windowStart time.Time) string {
+	return fmt.Sprintf("raw_%d.jsonl", windowStart.Unix())
+}
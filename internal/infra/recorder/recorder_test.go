@@ -0,0 +1,96 @@
+package recorder
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type stepClock struct{ now time.Time }
+
+func (c *stepClock) Now() time.Time { return c.now }
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	start := time.Unix(1700000100, 0) // aligned to 5m
+	clock := &stepClock{now: start.Add(10 * time.Second)}
+
+	rec, err := New(dir, 5*time.Minute, clock, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Record(SourceMarket, "btc-5m", []byte(`[{"event_type":"book"}]`))
+	rec.Record(SourceUser, "", []byte("PONG"))
+	clock.now = start.Add(5*time.Minute + time.Second) // next window
+	rec.Record(SourceChainlink, "BTC", []byte(`{"feedID":"0x01"}`))
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("rotates_per_window", func(t *testing.T) {
+		files, err := ListFiles(dir, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			filepath.Join(dir, FileName(start)),
+			filepath.Join(dir, FileName(start.Add(5*time.Minute))),
+		}
+		if len(files) != 2 || files[0] != want[0] || files[1] != want[1] {
+			t.Errorf("unexpected files %v", files)
+		}
+	})
+
+	t.Run("reader_validates_header_and_preserves_payloads", func(t *testing.T) {
+		r, err := Open(filepath.Join(dir, FileName(start)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		if h := r.Header(); h.Version != FormatVersion || !h.WindowStart.Equal(start) {
+			t.Errorf("unexpected header %+v", h)
+		}
+
+		first, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.Source != SourceMarket || first.Key != "btc-5m" || string(first.Payload()) != `[{"event_type":"book"}]` {
+			t.Errorf("unexpected record %+v", first)
+		}
+		if !first.RecvTime().Equal(start.Add(10 * time.Second)) {
+			t.Errorf("unexpected receive time %v", first.RecvTime())
+		}
+
+		second, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second.Text != "PONG" || second.Data != nil {
+			t.Errorf("expected non-JSON payload as text, got %+v", second)
+		}
+	})
+
+	t.Run("read_range_filters_by_receive_time", func(t *testing.T) {
+		records, err := ReadRange(dir, start.Add(5*time.Minute), time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Source != SourceChainlink {
+			t.Errorf("unexpected records %+v", records)
+		}
+	})
+
+	t.Run("rejects_unknown_version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "raw_0.jsonl")
+		if err := os.WriteFile(path, []byte(`{"format":"polybot-raw","version":99}`+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path); err == nil {
+			t.Error("expected error for unsupported version")
+		}
+	})
+}
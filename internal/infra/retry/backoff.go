@@ -0,0 +1,19 @@
+// Package retry holds the reconnect delay shared by the venue and oracle
+// streams.
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns the delay after the given number of consecutive failures:
+// minDelay doubled per failure up to maxDelay, jittered over its upper half
+// so reconnecting clients don't move in lockstep.
+func Backoff(failures int, minDelay, maxDelay time.Duration) time.Duration {
+	delay := minDelay << min(max(failures-1, 0), 16)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,25 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		failures int
+		lo, hi   time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second}, // capped
+		{100, 500 * time.Millisecond, time.Second},
+	}
+	for _, c := range cases {
+		for i := 0; i < 50; i++ {
+			if got := Backoff(c.failures, 100*time.Millisecond, time.Second); got < c.lo || got > c.hi {
+				t.Fatalf("Backoff(%d) = %v, want within [%v, %v]", c.failures, got, c.lo, c.hi)
+			}
+		}
+	}
+}
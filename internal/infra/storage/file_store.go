@@ -0,0 +1,750 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// FileStoreConfig controls the file-backed store.
+type FileStoreConfig struct {
+	Dir string
+
+	// Retention applies to the high-volume streams (fair values, signals,
+	// quotes, reference snapshots). Fills, settlements and positions are
+	// kept indefinitely. Zero keeps everything.
+	Retention time.Duration
+
+	// FlushInterval bounds how long buffered high-volume events sit in memory
+	// (default: 1s). Fills, settlements and positions are synced on write.
+	FlushInterval time.Duration
+}
+
+// Event stream directories under <Dir>/events.
+const (
+	kindFairValues  = "fair_values"
+	kindSignals     = "signals"
+	kindQuotes      = "quotes"
+	kindSnapshots   = "reference_snapshots"
+	kindFills       = "fills"
+	kindSettlements = "settlements"
+	kindBreaker     = "breaker_events"
+	kindReconcile   = "reconcile_events"
+)
+
+var eventKinds = []string{kindFairValues, kindSignals, kindQuotes, kindSnapshots, kindFills, kindSettlements, kindBreaker, kindReconcile}
+
+// prunable streams are subject to Retention.
+var prunable = map[string]bool{
+	kindFairValues: true,
+	kindSignals:    true,
+	kindQuotes:     true,
+	kindSnapshots:  true,
+}
+
+// FileStore implements ports.EventRepository, ports.PositionRepository and
+// ports.RedemptionRepository on the local filesystem.
+//
+// Layout under Dir:
+//
+//	meta.json                      schema version and applied migrations
+//	events/<kind>/YYYY-MM-DD.jsonl one {"t":..., "data":{...}} record per line
+//	positions.log                  append-only save/delete log
+//	redemptions.json               pending redemptions, rewritten on change
+//
+// Events are partitioned by UTC day of their own timestamp, which is the
+// index time-range queries use. Positions are rebuilt into memory by
+// replaying positions.log on open; the log is compacted when it grows well
+// past the number of open positions. The few pending redemptions are small
+// enough to rewrite whole.
+type FileStore struct {
+	config FileStoreConfig
+	logger *slog.Logger
+
+	segments map[string]*segmentWriter // kind -> writer
+
+	posMu     sync.Mutex
+	positions map[string]domain.Position // posKey -> position
+	posLog    *os.File
+	posOps    int // records in positions.log
+
+	redeemMu sync.Mutex
+	redeem   map[domain.MarketID]domain.PendingRedemption
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenFileStore opens (creating if needed) a store, applies pending
+// migrations, recovers positions and starts background flushing and pruning.
+func OpenFileStore(config FileStoreConfig, logger *slog.Logger) (*FileStore, error) {
+	if config.Dir == "" {
+		return nil, errors.New("file store: empty directory")
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if err := migrateFileStore(config.Dir, logger); err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{
+		config:    config,
+		logger:    logger,
+		segments:  make(map[string]*segmentWriter, len(eventKinds)),
+		positions: make(map[string]domain.Position),
+		redeem:    make(map[domain.MarketID]domain.PendingRedemption),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, kind := range eventKinds {
+		s.segments[kind] = &segmentWriter{
+			dir:     filepath.Join(config.Dir, "events", kind),
+			durable: !prunable[kind],
+		}
+	}
+
+	if err := s.loadPositions(); err != nil {
+		return nil, err
+	}
+	if err := s.loadRedemptions(); err != nil {
+		return nil, err
+	}
+	s.prune(time.Now())
+
+	go s.background()
+	return s, nil
+}
+
+// Close flushes buffered events and closes all files.
+func (s *FileStore) Close() error {
+	close(s.stop)
+	<-s.done
+
+	var errs []error
+	for _, sw := range s.segments {
+		errs = append(errs, sw.close())
+	}
+	s.posMu.Lock()
+	if s.posLog != nil {
+		errs = append(errs, s.posLog.Close())
+		s.posLog = nil
+	}
+	s.posMu.Unlock()
+	return errors.Join(errs...)
+}
+
+func (s *FileStore) background() {
+	defer close(s.done)
+	flush := time.NewTicker(s.config.FlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-flush.C:
+			for kind, sw := range s.segments {
+				if err := sw.flush(); err != nil {
+					s.logger.Warn("file store: flush failed", "kind", kind, "error", err)
+				}
+			}
+		case now := <-prune.C:
+			s.prune(now)
+		}
+	}
+}
+
+// ---------- EventRepository ----------
+
+func (s *FileStore) SaveFairValue(_ context.Context, fv domain.FairValue) error {
+	return s.segments[kindFairValues].append(fv.Timestamp, fv)
+}
+
+func (s *FileStore) SaveSignal(_ context.Context, signal domain.TradeSignal) error {
+	return s.segments[kindSignals].append(signal.Timestamp, signal)
+}
+
+func (s *FileStore) SaveQuote(_ context.Context, quote domain.MarketQuote) error {
+	return s.segments[kindQuotes].append(quote.Timestamp, quote)
+}
+
+func (s *FileStore) SaveReferenceSnapshot(_ context.Context, snap domain.ReferenceSnapshot) error {
+	return s.segments[kindSnapshots].append(snap.Timestamp, snap)
+}
+
+func (s *FileStore) SaveFill(_ context.Context, fill domain.Fill) error {
+	return s.segments[kindFills].append(fill.Timestamp, fill)
+}
+
+func (s *FileStore) SaveSettlement(_ context.Context, st domain.Settlement) error {
+	return s.segments[kindSettlements].append(st.SettledAt, st)
+}
+
+func (s *FileStore) SaveBreakerEvent(_ context.Context, e domain.BreakerEvent) error {
+	return s.segments[kindBreaker].append(e.Timestamp, e)
+}
+
+func (s *FileStore) SaveReconcileEvent(_ context.Context, e domain.ReconcileEvent) error {
+	return s.segments[kindReconcile].append(e.Timestamp, e)
+}
+
+// ---------- Query helpers ----------
+//
+// Ranges are [from, to); a zero bound is open. An empty market ID or asset
+// matches everything.
+
+func (s *FileStore) FairValues(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.FairValue, error) {
+	return readEvents(s, kindFairValues, from, to, func(fv domain.FairValue) bool {
+		return marketID == "" || fv.MarketID == marketID
+	})
+}
+
+func (s *FileStore) Signals(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.TradeSignal, error) {
+	return readEvents(s, kindSignals, from, to, func(sig domain.TradeSignal) bool {
+		return marketID == "" || sig.MarketID == marketID
+	})
+}
+
+func (s *FileStore) Quotes(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.MarketQuote, error) {
+	return readEvents(s, kindQuotes, from, to, func(q domain.MarketQuote) bool {
+		return marketID == "" || q.MarketID == marketID
+	})
+}
+
+func (s *FileStore) ReferenceSnapshots(_ context.Context, asset string, from, to time.Time) ([]domain.ReferenceSnapshot, error) {
+	return readEvents(s, kindSnapshots, from, to, func(snap domain.ReferenceSnapshot) bool {
+		return asset == "" || snap.Asset == asset
+	})
+}
+
+func (s *FileStore) Fills(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.Fill, error) {
+	return readEvents(s, kindFills, from, to, func(f domain.Fill) bool {
+		return marketID == "" || f.MarketID == marketID
+	})
+}
+
+func (s *FileStore) Settlements(_ context.Context, from, to time.Time) ([]domain.Settlement, error) {
+	return readEvents(s, kindSettlements, from, to, func(domain.Settlement) bool { return true })
+}
+
+func (s *FileStore) BreakerEvents(_ context.Context, from, to time.Time) ([]domain.BreakerEvent, error) {
+	return readEvents(s, kindBreaker, from, to, func(domain.BreakerEvent) bool { return true })
+}
+
+func (s *FileStore) ReconcileEvents(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.ReconcileEvent, error) {
+	return readEvents(s, kindReconcile, from, to, func(e domain.ReconcileEvent) bool {
+		return marketID == "" || e.MarketID == marketID
+	})
+}
+
+// eventRecord is one line of an event segment.
+type eventRecord[T any] struct {
+	T    time.Time `json:"t"`
+	Data T         `json:"data"`
+}
+
+func readEvents[T any](s *FileStore, kind string, from, to time.Time, keep func(T) bool) ([]T, error) {
+	sw := s.segments[kind]
+	if err := sw.flush(); err != nil {
+		return nil, err
+	}
+
+	days, err := segmentDays(sw.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []T
+	for _, day := range days {
+		if !from.IsZero() && day < dayOf(from) {
+			continue
+		}
+		if !to.IsZero() && day > dayOf(to) {
+			break
+		}
+		if err := scanSegment(filepath.Join(sw.dir, day+".jsonl"), func(rec eventRecord[T]) {
+			if !inRange(rec.T, from, to) {
+				return
+			}
+			if keep(rec.Data) {
+				out = append(out, rec.Data)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func scanSegment[T any](path string, fn func(eventRecord[T])) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var rec eventRecord[T]
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// A torn final line from a crash is skipped, not fatal
+			continue
+		}
+		fn(rec)
+	}
+	return scanner.Err()
+}
+
+// segmentDays returns the YYYY-MM-DD segment names in dir, oldest first.
+func segmentDays(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	days := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".jsonl"); ok {
+			days = append(days, name)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// prune deletes segments of prunable streams older than Retention.
+func (s *FileStore) prune(now time.Time) {
+	if s.config.Retention <= 0 {
+		return
+	}
+	cutoff := dayOf(now.Add(-s.config.Retention))
+	for kind := range prunable {
+		sw := s.segments[kind]
+		days, err := segmentDays(sw.dir)
+		if err != nil {
+			s.logger.Warn("file store: list segments failed", "kind", kind, "error", err)
+			continue
+		}
+		for _, day := range days {
+			if day >= cutoff || sw.isCurrent(day) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(sw.dir, day+".jsonl")); err != nil {
+				s.logger.Warn("file store: prune failed", "kind", kind, "day", day, "error", err)
+				continue
+			}
+			s.logger.Info("file store: pruned segment", "kind", kind, "day", day)
+		}
+	}
+}
+
+// ---------- Segment writer ----------
+
+// segmentWriter appends records to the day segment of one event stream.
+type segmentWriter struct {
+	dir     string
+	durable bool // fsync every record
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+	w    *bufio.Writer
+}
+
+func (sw *segmentWriter) append(ts time.Time, data any) error {
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	line, err := json.Marshal(eventRecord[any]{T: ts, Data: data})
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	day := dayOf(ts)
+	if sw.file == nil || sw.day != day {
+		if err := sw.closeLocked(); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(filepath.Join(sw.dir, day+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open segment: %w", err)
+		}
+		sw.file, sw.w, sw.day = f, bufio.NewWriterSize(f, 64*1024), day
+	}
+
+	if _, err := sw.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	if sw.durable {
+		if err := sw.w.Flush(); err != nil {
+			return err
+		}
+		return sw.file.Sync()
+	}
+	return nil
+}
+
+func (sw *segmentWriter) flush() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.w == nil {
+		return nil
+	}
+	return sw.w.Flush()
+}
+
+func (sw *segmentWriter) isCurrent(day string) bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.file != nil && sw.day == day
+}
+
+func (sw *segmentWriter) close() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.closeLocked()
+}
+
+func (sw *segmentWriter) closeLocked() error {
+	if sw.file == nil {
+		return nil
+	}
+	err := errors.Join(sw.w.Flush(), sw.file.Close())
+	sw.file, sw.w, sw.day = nil, nil, ""
+	return err
+}
+
+// ---------- PositionRepository ----------
+
+// positionOp is one line of positions.log.
+type positionOp struct {
+	Op       string              `json:"op"` // "save" or "delete"
+	Position *domain.Position    `json:"position,omitempty"`
+	MarketID domain.MarketID     `json:"market_id,omitempty"`
+	Side     domain.PositionSide `json:"side,omitempty"`
+}
+
+// compactMinOps is the log length below which compaction is not worth it.
+const compactMinOps = 1000
+
+func (s *FileStore) SavePosition(_ context.Context, p domain.Position) error {
+	s.posMu.Lock()
+	defer s.posMu.Unlock()
+	if err := s.appendPositionOp(positionOp{Op: "save", Position: &p}); err != nil {
+		return err
+	}
+	s.positions[posKey(p.MarketID, p.Side)] = p
+	return s.maybeCompact()
+}
+
+func (s *FileStore) GetPosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) (domain.Position, error) {
+	s.posMu.Lock()
+	defer s.posMu.Unlock()
+	p, ok := s.positions[posKey(marketID, side)]
+	if !ok {
+		return domain.Position{}, fmt.Errorf("position not found")
+	}
+	return p, nil
+}
+
+func (s *FileStore) ListOpenPositions(_ context.Context) ([]domain.Position, error) {
+	s.posMu.Lock()
+	defer s.posMu.Unlock()
+	result := make([]domain.Position, 0, len(s.positions))
+	for _, p := range s.positions {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (s *FileStore) DeletePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	s.posMu.Lock()
+	defer s.posMu.Unlock()
+	if _, ok := s.positions[posKey(marketID, side)]; !ok {
+		return nil
+	}
+	if err := s.appendPositionOp(positionOp{Op: "delete", MarketID: marketID, Side: side}); err != nil {
+		return err
+	}
+	delete(s.positions, posKey(marketID, side))
+	return s.maybeCompact()
+}
+
+func (s *FileStore) positionLogPath() string {
+	return filepath.Join(s.config.Dir, "positions.log")
+}
+
+// loadPositions replays positions.log into memory and opens it for append.
+func (s *FileStore) loadPositions() error {
+	f, err := os.OpenFile(s.positionLogPath(), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("open position log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var op positionOp
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			continue // torn write
+		}
+		s.posOps++
+		switch op.Op {
+		case "save":
+			if op.Position != nil {
+				s.positions[posKey(op.Position.MarketID, op.Position.Side)] = *op.Position
+			}
+		case "delete":
+			delete(s.positions, posKey(op.MarketID, op.Side))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return fmt.Errorf("read position log: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+	s.posLog = f
+
+	if len(s.positions) > 0 {
+		s.logger.Info("file store: recovered positions", "count", len(s.positions))
+	}
+	return nil
+}
+
+func (s *FileStore) appendPositionOp(op positionOp) error {
+	line, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("encode position op: %w", err)
+	}
+	if _, err := s.posLog.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write position log: %w", err)
+	}
+	s.posOps++
+	return s.posLog.Sync()
+}
+
+// maybeCompact rewrites positions.log as one save per open position once the
+// log is mostly history. The rewrite is atomic (write temp, rename).
+func (s *FileStore) maybeCompact() error {
+	if s.posOps < compactMinOps || s.posOps < 4*len(s.positions) {
+		return nil
+	}
+
+	tmpPath := s.positionLogPath() + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("compact position log: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, p := range s.positions {
+		line, _ := json.Marshal(positionOp{Op: "save", Position: &p})
+		w.Write(append(line, '\n'))
+	}
+	if err := errors.Join(w.Flush(), tmp.Sync(), tmp.Close()); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("compact position log: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.positionLogPath()); err != nil {
+		return fmt.Errorf("compact position log: %w", err)
+	}
+
+	s.posLog.Close()
+	f, err := os.OpenFile(s.positionLogPath(), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("reopen position log: %w", err)
+	}
+	s.posLog = f
+	s.posOps = len(s.positions)
+	return nil
+}
+
+// ---------- RedemptionRepository ----------
+
+func (s *FileStore) SavePendingRedemption(_ context.Context, r domain.PendingRedemption) error {
+	s.redeemMu.Lock()
+	defer s.redeemMu.Unlock()
+	s.redeem[r.MarketID] = r
+	return s.writeRedemptions()
+}
+
+func (s *FileStore) ListPendingRedemptions(_ context.Context) ([]domain.PendingRedemption, error) {
+	s.redeemMu.Lock()
+	defer s.redeemMu.Unlock()
+	result := make([]domain.PendingRedemption, 0, len(s.redeem))
+	for _, r := range s.redeem {
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (s *FileStore) DeletePendingRedemption(_ context.Context, marketID domain.MarketID) error {
+	s.redeemMu.Lock()
+	defer s.redeemMu.Unlock()
+	if _, ok := s.redeem[marketID]; !ok {
+		return nil
+	}
+	delete(s.redeem, marketID)
+	return s.writeRedemptions()
+}
+
+func (s *FileStore) redemptionsPath() string {
+	return filepath.Join(s.config.Dir, "redemptions.json")
+}
+
+// loadRedemptions reads redemptions.json; a missing file is an empty queue.
+func (s *FileStore) loadRedemptions() error {
+	data, err := os.ReadFile(s.redemptionsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read redemptions: %w", err)
+	}
+	var pending []domain.PendingRedemption
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return fmt.Errorf("decode redemptions: %w", err)
+	}
+	for _, r := range pending {
+		s.redeem[r.MarketID] = r
+	}
+	if len(s.redeem) > 0 {
+		s.logger.Info("file store: recovered pending redemptions", "count", len(s.redeem))
+	}
+	return nil
+}
+
+// writeRedemptions replaces redemptions.json atomically (write temp, rename).
+func (s *FileStore) writeRedemptions() error {
+	pending := make([]domain.PendingRedemption, 0, len(s.redeem))
+	for _, r := range s.redeem {
+		pending = append(pending, r)
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("encode redemptions: %w", err)
+	}
+	tmpPath := s.redemptionsPath() + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("write redemptions: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if err := errors.Join(err, tmp.Sync(), tmp.Close()); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write redemptions: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.redemptionsPath()); err != nil {
+		return fmt.Errorf("write redemptions: %w", err)
+	}
+	return nil
+}
+
+// ---------- Migrations ----------
+
+// fileMigration upgrades the on-disk layout by one schema version.
+type fileMigration struct {
+	Version int
+	Name    string
+	Apply   func(dir string) error
+}
+
+// fileMigrations are applied in order; append only, never edit.
+var fileMigrations = []fileMigration{
+	{Version: 1, Name: "initial layout", Apply: func(dir string) error {
+		for _, kind := range []string{kindFairValues, kindSignals, kindQuotes, kindSnapshots, kindFills, kindSettlements} {
+			if err := os.MkdirAll(filepath.Join(dir, "events", kind), 0o755); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{Version: 2, Name: "breaker events", Apply: func(dir string) error {
+		return os.MkdirAll(filepath.Join(dir, "events", kindBreaker), 0o755)
+	}},
+	{Version: 3, Name: "reconcile events", Apply: func(dir string) error {
+		return os.MkdirAll(filepath.Join(dir, "events", kindReconcile), 0o755)
+	}},
+}
+
+type appliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+type fileStoreMeta struct {
+	SchemaVersion int                `json:"schema_version"`
+	Migrations    []appliedMigration `json:"migrations"`
+}
+
+func migrateFileStore(dir string, logger *slog.Logger) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create store dir: %w", err)
+	}
+
+	metaPath := filepath.Join(dir, "meta.json")
+	var meta fileStoreMeta
+	if data, err := os.ReadFile(metaPath); err == nil {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("read %s: %w", metaPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("read %s: %w", metaPath, err)
+	}
+
+	latest := fileMigrations[len(fileMigrations)-1].Version
+	if meta.SchemaVersion > latest {
+		return fmt.Errorf("store schema version %d is newer than supported %d", meta.SchemaVersion, latest)
+	}
+
+	for _, m := range fileMigrations {
+		if m.Version <= meta.SchemaVersion {
+			continue
+		}
+		if err := m.Apply(dir); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		meta.SchemaVersion = m.Version
+		meta.Migrations = append(meta.Migrations, appliedMigration{
+			Version:   m.Version,
+			Name:      m.Name,
+			AppliedAt: time.Now().UTC(),
+		})
+
+		// Persist after each step so a failed later migration resumes here
+		data, _ := json.MarshalIndent(meta, "", "  ")
+		tmp := metaPath + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, metaPath); err != nil {
+			return err
+		}
+		logger.Info("file store: applied migration", "version", m.Version, "name", m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+func openTestStore(t *testing.T, dir string, retention time.Duration) *FileStore {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	s, err := OpenFileStore(FileStoreConfig{Dir: dir, Retention: retention}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positions_survive_reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		s := openTestStore(t, dir, 0)
+		up := domain.Position{MarketID: "m1", Side: domain.PositionUp, Quantity: 10, NotionalUSD: 5}
+		down := domain.Position{MarketID: "m1", Side: domain.PositionDown, Quantity: 4, NotionalUSD: 2}
+		if err := s.SavePosition(ctx, up); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SavePosition(ctx, down); err != nil {
+			t.Fatal(err)
+		}
+		up.Quantity = 20
+		if err := s.SavePosition(ctx, up); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DeletePosition(ctx, "m1", domain.PositionDown); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		s = openTestStore(t, dir, 0)
+		defer s.Close()
+		open, _ := s.ListOpenPositions(ctx)
+		if len(open) != 1 || open[0].Side != domain.PositionUp || open[0].Quantity != 20 {
+			t.Errorf("unexpected positions after reopen: %+v", open)
+		}
+		if _, err := s.GetPosition(ctx, "m1", domain.PositionDown); err == nil {
+			t.Error("expected deleted position to stay deleted")
+		}
+	})
+
+	t.Run("pending_redemptions_survive_reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		s := openTestStore(t, dir, 0)
+		queuedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		for _, id := range []domain.MarketID{"m1", "m2"} {
+			if err := s.SavePendingRedemption(ctx, domain.PendingRedemption{MarketID: id, QueuedAt: queuedAt}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.DeletePendingRedemption(ctx, "m1"); err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+
+		s = openTestStore(t, dir, 0)
+		defer s.Close()
+		got, _ := s.ListPendingRedemptions(ctx)
+		if len(got) != 1 || got[0].MarketID != "m2" || !got[0].QueuedAt.Equal(queuedAt) {
+			t.Errorf("unexpected pending redemptions after reopen: %+v", got)
+		}
+	})
+
+	t.Run("position_log_compacts", func(t *testing.T) {
+		dir := t.TempDir()
+		s := openTestStore(t, dir, 0)
+		p := domain.Position{MarketID: "m1", Side: domain.PositionUp}
+		for i := 0; i < compactMinOps+10; i++ {
+			p.Quantity = float64(i)
+			if err := s.SavePosition(ctx, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s.posOps >= compactMinOps {
+			t.Errorf("expected compaction, log holds %d records", s.posOps)
+		}
+		s.Close()
+
+		s = openTestStore(t, dir, 0)
+		defer s.Close()
+		got, err := s.GetPosition(ctx, "m1", domain.PositionUp)
+		if err != nil || got.Quantity != float64(compactMinOps+9) {
+			t.Errorf("unexpected position after compaction: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("queries_filter_by_market_and_time", func(t *testing.T) {
+		s := openTestStore(t, t.TempDir(), 0)
+		defer s.Close()
+		base := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+		fills := []domain.Fill{
+			{MarketID: "m1", Price: 0.4, Timestamp: base},
+			{MarketID: "m2", Price: 0.5, Timestamp: base.Add(30 * time.Second)},
+			{MarketID: "m1", Price: 0.6, Timestamp: base.Add(2 * time.Minute)}, // next day segment
+		}
+		for _, f := range fills {
+			if err := s.SaveFill(ctx, f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := 0; i < 3; i++ {
+			_ = s.SaveQuote(ctx, domain.MarketQuote{MarketID: "m1", Timestamp: base.Add(time.Duration(i) * time.Minute)})
+		}
+
+		got, err := s.Fills(ctx, "m1", time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Price != 0.4 || got[1].Price != 0.6 {
+			t.Errorf("unexpected m1 fills: %+v", got)
+		}
+
+		got, _ = s.Fills(ctx, "", base.Add(time.Second), base.Add(2*time.Minute))
+		if len(got) != 1 || got[0].MarketID != "m2" {
+			t.Errorf("expected [from, to) to select only the m2 fill, got %+v", got)
+		}
+
+		// Buffered events are visible to queries before the background flush
+		quotes, _ := s.Quotes(ctx, "m1", base.Add(time.Minute), time.Time{})
+		if len(quotes) != 2 {
+			t.Errorf("expected 2 quotes, got %d", len(quotes))
+		}
+	})
+
+	t.Run("retention_prunes_high_volume_streams_only", func(t *testing.T) {
+		dir := t.TempDir()
+		s := openTestStore(t, dir, 0)
+		old := time.Now().Add(-30 * 24 * time.Hour)
+		_ = s.SaveFairValue(ctx, domain.FairValue{MarketID: "m1", Timestamp: old})
+		_ = s.SaveFill(ctx, domain.Fill{MarketID: "m1", Timestamp: old})
+		_ = s.SaveFairValue(ctx, domain.FairValue{MarketID: "m1", Timestamp: time.Now()})
+		s.Close()
+
+		s = openTestStore(t, dir, 7*24*time.Hour)
+		defer s.Close()
+		fvs, _ := s.FairValues(ctx, "", time.Time{}, time.Time{})
+		if len(fvs) != 1 {
+			t.Errorf("expected old fair values pruned, got %d", len(fvs))
+		}
+		fills, _ := s.Fills(ctx, "", time.Time{}, time.Time{})
+		if len(fills) != 1 {
+			t.Errorf("expected fills kept, got %d", len(fills))
+		}
+	})
+
+	t.Run("migrations_recorded_and_newer_schema_rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		openTestStore(t, dir, 0).Close()
+
+		data, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var meta fileStoreMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			t.Fatal(err)
+		}
+		latest := fileMigrations[len(fileMigrations)-1].Version
+		if meta.SchemaVersion != latest || len(meta.Migrations) != len(fileMigrations) {
+			t.Errorf("unexpected meta %+v", meta)
+		}
+
+		meta.SchemaVersion = latest + 1
+		data, _ = json.Marshal(meta)
+		os.WriteFile(filepath.Join(dir, "meta.json"), data, 0o644)
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		if _, err := OpenFileStore(FileStoreConfig{Dir: dir}, logger); err == nil {
+			t.Error("expected error for newer schema version")
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// InMemoryEventRepo stores events in memory. Nothing survives a restart; use
+// the file or Postgres store for that.
+type InMemoryEventRepo struct {
+	mu          sync.Mutex
+	fairValues  []domain.FairValue
+	signals     []domain.TradeSignal
+	quotes      []domain.MarketQuote
+	snapshots   []domain.ReferenceSnapshot
+	fills       []domain.Fill
+	settlements []domain.Settlement
+	breaker     []domain.BreakerEvent
+	reconcile   []domain.ReconcileEvent
+	logger      *slog.Logger
+}
+
+func NewInMemoryEventRepo(logger *slog.Logger) *InMemoryEventRepo {
+	return &InMemoryEventRepo{logger: logger}
+}
+
+func (r *InMemoryEventRepo) SaveFairValue(_ context.Context, fv domain.FairValue) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fairValues = append(r.fairValues, fv)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveSignal(_ context.Context, signal domain.TradeSignal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signals = append(r.signals, signal)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveQuote(_ context.Context, quote domain.MarketQuote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quotes = append(r.quotes, quote)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveReferenceSnapshot(_ context.Context, snap domain.ReferenceSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snapshots = append(r.snapshots, snap)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveFill(_ context.Context, fill domain.Fill) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fills = append(r.fills, fill)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveSettlement(_ context.Context, s domain.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settlements = append(r.settlements, s)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveBreakerEvent(_ context.Context, e domain.BreakerEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breaker = append(r.breaker, e)
+	return nil
+}
+
+func (r *InMemoryEventRepo) SaveReconcileEvent(_ context.Context, e domain.ReconcileEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconcile = append(r.reconcile, e)
+	return nil
+}
+
+// Fills returns fills for a market (all markets if empty) in [from, to); a
+// zero bound is open.
+func (r *InMemoryEventRepo) Fills(_ context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.Fill, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Fill
+	for _, f := range r.fills {
+		if (marketID == "" || f.MarketID == marketID) && inRange(f.Timestamp, from, to) {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// inRange reports whether t is in [from, to); a zero bound is open.
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// InMemoryPositionRepo stores positions in memory.
+type InMemoryPositionRepo struct {
+	mu        sync.Mutex
+	positions map[string]domain.Position // key: marketID:side
+}
+
+func NewInMemoryPositionRepo() *InMemoryPositionRepo {
+	return &InMemoryPositionRepo{
+		positions: make(map[string]domain.Position),
+	}
+}
+
+func posKey(marketID domain.MarketID, side domain.PositionSide) string {
+	return fmt.Sprintf("%s:%s", marketID, side)
+}
+
+func (r *InMemoryPositionRepo) SavePosition(_ context.Context, p domain.Position) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions[posKey(p.MarketID, p.Side)] = p
+	return nil
+}
+
+func (r *InMemoryPositionRepo) GetPosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) (domain.Position, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.positions[posKey(marketID, side)]
+	if !ok {
+		return domain.Position{}, fmt.Errorf("position not found")
+	}
+	return p, nil
+}
+
+func (r *InMemoryPositionRepo) ListOpenPositions(_ context.Context) ([]domain.Position, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]domain.Position, 0, len(r.positions))
+	for _, p := range r.positions {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (r *InMemoryPositionRepo) DeletePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.positions, posKey(marketID, side))
+	return nil
+}
+
+// InMemoryRedemptionRepo stores pending redemptions in memory.
+type InMemoryRedemptionRepo struct {
+	mu      sync.Mutex
+	pending map[domain.MarketID]domain.PendingRedemption
+}
+
+func NewInMemoryRedemptionRepo() *InMemoryRedemptionRepo {
+	return &InMemoryRedemptionRepo{
+		pending: make(map[domain.MarketID]domain.PendingRedemption),
+	}
+}
+
+func (r *InMemoryRedemptionRepo) SavePendingRedemption(_ context.Context, p domain.PendingRedemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[p.MarketID] = p
+	return nil
+}
+
+func (r *InMemoryRedemptionRepo) ListPendingRedemptions(_ context.Context) ([]domain.PendingRedemption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]domain.PendingRedemption, 0, len(r.pending))
+	for _, p := range r.pending {
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (r *InMemoryRedemptionRepo) DeletePendingRedemption(_ context.Context, marketID domain.MarketID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, marketID)
+	return nil
+}
@@ -0,0 +1,101 @@
+-- Initial schema: event streams, settlements and open positions.
+
+CREATE TABLE fair_values (
+    id                BIGSERIAL PRIMARY KEY,
+    market_id         TEXT             NOT NULL,
+    ts                TIMESTAMPTZ      NOT NULL,
+    prob_up           DOUBLE PRECISION NOT NULL,
+    prob_up_lower     DOUBLE PRECISION NOT NULL,
+    prob_up_upper     DOUBLE PRECISION NOT NULL,
+    prob_raw          DOUBLE PRECISION NOT NULL,
+    prob_calibrated   DOUBLE PRECISION NOT NULL,
+    sigma_tau         DOUBLE PRECISION NOT NULL,
+    z_score           DOUBLE PRECISION NOT NULL,
+    model_uncertainty DOUBLE PRECISION NOT NULL,
+    remaining_seconds DOUBLE PRECISION NOT NULL,
+    required_log_move DOUBLE PRECISION NOT NULL,
+    model_regime      TEXT             NOT NULL
+);
+CREATE INDEX fair_values_market_ts_idx ON fair_values (market_id, ts);
+CREATE INDEX fair_values_ts_idx ON fair_values (ts);
+
+CREATE TABLE quotes (
+    id        BIGSERIAL PRIMARY KEY,
+    market_id TEXT             NOT NULL,
+    ts        TIMESTAMPTZ      NOT NULL,
+    up_bid    DOUBLE PRECISION NOT NULL,
+    up_ask    DOUBLE PRECISION NOT NULL,
+    down_bid  DOUBLE PRECISION NOT NULL,
+    down_ask  DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX quotes_market_ts_idx ON quotes (market_id, ts);
+CREATE INDEX quotes_ts_idx ON quotes (ts);
+
+CREATE TABLE signals (
+    id               BIGSERIAL PRIMARY KEY,
+    market_id        TEXT             NOT NULL,
+    ts               TIMESTAMPTZ      NOT NULL,
+    side             TEXT             NOT NULL,
+    signal_type      TEXT             NOT NULL,
+    edge_buy_up      DOUBLE PRECISION NOT NULL,
+    edge_buy_down    DOUBLE PRECISION NOT NULL,
+    effective_hurdle DOUBLE PRECISION NOT NULL,
+    target_size_usd  DOUBLE PRECISION NOT NULL,
+    guaranteed_floor DOUBLE PRECISION NOT NULL,
+    hedge_edge       DOUBLE PRECISION NOT NULL,
+    reason           TEXT             NOT NULL
+);
+CREATE INDEX signals_market_ts_idx ON signals (market_id, ts);
+CREATE INDEX signals_ts_idx ON signals (ts);
+
+CREATE TABLE reference_snapshots (
+    id    BIGSERIAL PRIMARY KEY,
+    asset TEXT             NOT NULL,
+    ts    TIMESTAMPTZ      NOT NULL,
+    price DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX reference_snapshots_asset_ts_idx ON reference_snapshots (asset, ts);
+
+CREATE TABLE fills (
+    id        BIGSERIAL PRIMARY KEY,
+    market_id TEXT             NOT NULL,
+    ts        TIMESTAMPTZ      NOT NULL,
+    side      TEXT             NOT NULL,
+    price     DOUBLE PRECISION NOT NULL,
+    size_usd  DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX fills_market_ts_idx ON fills (market_id, ts);
+CREATE INDEX fills_ts_idx ON fills (ts);
+
+-- One row per window; a venue confirmation or correction replaces the
+-- provisional Chainlink settlement.
+CREATE TABLE settlements (
+    market_id        TEXT PRIMARY KEY,
+    slug             TEXT             NOT NULL,
+    asset            TEXT             NOT NULL,
+    outcome          TEXT             NOT NULL,
+    price_to_beat    DOUBLE PRECISION NOT NULL,
+    settlement_price DOUBLE PRECISION NOT NULL,
+    up_quantity      DOUBLE PRECISION NOT NULL,
+    down_quantity    DOUBLE PRECISION NOT NULL,
+    up_cost          DOUBLE PRECISION NOT NULL,
+    down_cost        DOUBLE PRECISION NOT NULL,
+    up_pnl           DOUBLE PRECISION NOT NULL,
+    down_pnl         DOUBLE PRECISION NOT NULL,
+    realized_pnl     DOUBLE PRECISION NOT NULL,
+    source           TEXT             NOT NULL,
+    confirmed        BOOLEAN          NOT NULL,
+    settled_at       TIMESTAMPTZ      NOT NULL
+);
+CREATE INDEX settlements_settled_at_idx ON settlements (settled_at);
+
+CREATE TABLE positions (
+    market_id          TEXT             NOT NULL,
+    side               TEXT             NOT NULL,
+    avg_entry_price    DOUBLE PRECISION NOT NULL,
+    quantity           DOUBLE PRECISION NOT NULL,
+    notional_usd       DOUBLE PRECISION NOT NULL,
+    opened_at          TIMESTAMPTZ      NOT NULL,
+    hold_to_settlement BOOLEAN          NOT NULL,
+    PRIMARY KEY (market_id, side)
+);
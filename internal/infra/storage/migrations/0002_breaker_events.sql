@@ -0,0 +1,14 @@
+-- Circuit breaker trips and resets.
+
+CREATE TABLE breaker_events (
+    id                 BIGSERIAL PRIMARY KEY,
+    ts                 TIMESTAMPTZ      NOT NULL,
+    kind               TEXT             NOT NULL,
+    reason             TEXT             NOT NULL,
+    detail             TEXT             NOT NULL,
+    realized_pnl       DOUBLE PRECISION NOT NULL,
+    unrealized_pnl     DOUBLE PRECISION NOT NULL,
+    consecutive_losses INTEGER          NOT NULL,
+    error_rate         DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX breaker_events_ts_idx ON breaker_events (ts);
@@ -0,0 +1,8 @@
+-- Exchange trade identity on fills. A reversal row undoes the earlier fill
+-- of the same trade after it failed on-chain.
+
+ALTER TABLE fills
+    ADD COLUMN trade_id TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN role     TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN reversed BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX fills_trade_id_idx ON fills (trade_id) WHERE trade_id <> '';
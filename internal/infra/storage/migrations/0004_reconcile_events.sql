@@ -0,0 +1,16 @@
+-- Position drift found by reconciliation against the venue.
+
+CREATE TABLE reconcile_events (
+    id        BIGSERIAL PRIMARY KEY,
+    market_id TEXT             NOT NULL,
+    ts        TIMESTAMPTZ      NOT NULL,
+    side      TEXT             NOT NULL,
+    token_id  TEXT             NOT NULL,
+    local_qty DOUBLE PRECISION NOT NULL,
+    venue_qty DOUBLE PRECISION NOT NULL,
+    drift     DOUBLE PRECISION NOT NULL,
+    action    TEXT             NOT NULL,
+    detail    TEXT             NOT NULL
+);
+CREATE INDEX reconcile_events_market_ts_idx ON reconcile_events (market_id, ts);
+CREATE INDEX reconcile_events_ts_idx ON reconcile_events (ts);
@@ -0,0 +1,3 @@
+-- P&L booked by selling tokens before settlement.
+
+ALTER TABLE positions ADD COLUMN realized_pnl DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Settled conditions waiting for on-chain resolution to be redeemed.
+
+CREATE TABLE pending_redemptions (
+    market_id TEXT        PRIMARY KEY,
+    queued_at TIMESTAMPTZ NOT NULL
+);
@@ -2,117 +2,636 @@ package storage
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"fmt"
 	"log/slog"
-	"sync"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"Polybot/internal/domain"
 )
 
-// InMemoryEventRepo stores events in memory. Replace with Postgres for production.
-type InMemoryEventRepo struct {
-	mu          sync.Mutex
-	fairValues  []domain.FairValue
-	signals     []domain.TradeSignal
-	quotes      []domain.MarketQuote
-	snapshots   []domain.ReferenceSnapshot
-	fills       []domain.Fill
-	settlements []domain.Settlement
-	logger      *slog.Logger
+// PostgresConfig controls the Postgres store.
+type PostgresConfig struct {
+	DSN string
+
+	// Fair values, quotes, signals and reference snapshots are queued and
+	// written in batches so the reprice loop never waits on the database.
+	BatchSize     int           // rows per table per write (default: 500)
+	FlushInterval time.Duration // max time a queued row waits (default: 1s)
+	QueueSize     int           // queued rows before new ones are dropped (default: 10000)
+}
+
+// PostgresStore implements ports.EventRepository, ports.PositionRepository
+// and ports.RedemptionRepository on Postgres. Fills, settlements, positions
+// and pending redemptions are written synchronously; the high-volume streams
+// go through an async batcher and become visible to queries after the next
+// flush.
+type PostgresStore struct {
+	pool   *pgxpool.Pool
+	config PostgresConfig
+	logger *slog.Logger
+
+	queue   chan batchRow
+	dropped atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// batchRow is one queued insert for a batched table.
+type batchRow struct {
+	table  string
+	values []any
 }
 
-func NewInMemoryEventRepo(logger *slog.Logger) *InMemoryEventRepo {
-	return &InMemoryEventRepo{logger: logger}
+// batchColumns lists the insert columns of each batched table, in the order
+// batchRow.values holds them.
+var batchColumns = map[string][]string{
+	"fair_values": {
+		"market_id", "ts", "prob_up", "prob_up_lower", "prob_up_upper", "prob_raw", "prob_calibrated",
+		"sigma_tau", "z_score", "model_uncertainty", "remaining_seconds", "required_log_move", "model_regime",
+	},
+	"quotes": {"market_id", "ts", "up_bid", "up_ask", "down_bid", "down_ask"},
+	"signals": {
+		"market_id", "ts", "side", "signal_type", "edge_buy_up", "edge_buy_down", "effective_hurdle",
+		"target_size_usd", "guaranteed_floor", "hedge_edge", "reason",
+	},
+	"reference_snapshots": {"asset", "ts", "price"},
 }
 
-func (r *InMemoryEventRepo) SaveFairValue(_ context.Context, fv domain.FairValue) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.fairValues = append(r.fairValues, fv)
+// OpenPostgresStore connects, applies pending migrations and starts the
+// batch writer.
+func OpenPostgresStore(ctx context.Context, config PostgresConfig, logger *slog.Logger) (*PostgresStore, error) {
+	if config.DSN == "" {
+		return nil, errors.New("postgres: empty DSN")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+
+	pool, err := pgxpool.New(ctx, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: connect: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres: ping: %w", err)
+	}
+
+	s := &PostgresStore{
+		pool:   pool,
+		config: config,
+		logger: logger,
+		queue:  make(chan batchRow, config.QueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := s.migrate(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	go s.batchWriter()
+	return s, nil
+}
+
+// Close writes out queued rows and closes the pool.
+func (s *PostgresStore) Close() error {
+	close(s.stop)
+	<-s.done
+	s.pool.Close()
 	return nil
 }
 
-func (r *InMemoryEventRepo) SaveSignal(_ context.Context, signal domain.TradeSignal) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.signals = append(r.signals, signal)
+// ---------- EventRepository ----------
+
+func (s *PostgresStore) SaveFairValue(_ context.Context, fv domain.FairValue) error {
+	s.enqueue("fair_values", string(fv.MarketID), eventTime(fv.Timestamp),
+		fv.ProbUp, fv.ProbUpLower, fv.ProbUpUpper, fv.ProbRaw, fv.ProbCalibrated,
+		fv.SigmaTau, fv.ZScore, fv.ModelUncertainty, fv.RemainingSeconds, fv.RequiredLogMove, fv.ModelRegime)
 	return nil
 }
 
-func (r *InMemoryEventRepo) SaveQuote(_ context.Context, quote domain.MarketQuote) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.quotes = append(r.quotes, quote)
+func (s *PostgresStore) SaveSignal(_ context.Context, signal domain.TradeSignal) error {
+	s.enqueue("signals", string(signal.MarketID), eventTime(signal.Timestamp),
+		string(signal.Side), signal.SignalType, signal.EdgeBuyUp, signal.EdgeBuyDown, signal.EffectiveHurdle,
+		signal.TargetSizeUSD, signal.GuaranteedFloor, signal.HedgeEdge, signal.Reason)
 	return nil
 }
 
-func (r *InMemoryEventRepo) SaveReferenceSnapshot(_ context.Context, snap domain.ReferenceSnapshot) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.snapshots = append(r.snapshots, snap)
+func (s *PostgresStore) SaveQuote(_ context.Context, quote domain.MarketQuote) error {
+	s.enqueue("quotes", string(quote.MarketID), eventTime(quote.Timestamp),
+		quote.Up.Bid, quote.Up.Ask, quote.Down.Bid, quote.Down.Ask)
 	return nil
 }
 
-func (r *InMemoryEventRepo) SaveFill(_ context.Context, fill domain.Fill) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.fills = append(r.fills, fill)
+func (s *PostgresStore) SaveReferenceSnapshot(_ context.Context, snap domain.ReferenceSnapshot) error {
+	s.enqueue("reference_snapshots", snap.Asset, eventTime(snap.Timestamp), snap.Price)
 	return nil
 }
 
-func (r *InMemoryEventRepo) SaveSettlement(_ context.Context, s domain.Settlement) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.settlements = append(r.settlements, s)
+func (s *PostgresStore) SaveFill(ctx context.Context, fill domain.Fill) error {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO fills (market_id, ts, side, price, size_usd, trade_id, role, reversed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		string(fill.MarketID), eventTime(fill.Timestamp), string(fill.Side), fill.Price, fill.SizeUSD,
+		fill.TradeID, fill.Role, fill.Reversed)
+	if err != nil {
+		return fmt.Errorf("save fill: %w", err)
+	}
 	return nil
 }
 
-// InMemoryPositionRepo stores positions in memory. Replace with Postgres for production.
-type InMemoryPositionRepo struct {
-	mu        sync.Mutex
-	positions map[string]domain.Position // key: marketID:side
+// SaveSettlement upserts by market: a later confirmation or correction
+// replaces the provisional settlement.
+func (s *PostgresStore) SaveSettlement(ctx context.Context, st domain.Settlement) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO settlements (
+			market_id, slug, asset, outcome, price_to_beat, settlement_price,
+			up_quantity, down_quantity, up_cost, down_cost, up_pnl, down_pnl,
+			realized_pnl, source, confirmed, settled_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (market_id) DO UPDATE SET
+			slug = EXCLUDED.slug, asset = EXCLUDED.asset, outcome = EXCLUDED.outcome,
+			price_to_beat = EXCLUDED.price_to_beat, settlement_price = EXCLUDED.settlement_price,
+			up_quantity = EXCLUDED.up_quantity, down_quantity = EXCLUDED.down_quantity,
+			up_cost = EXCLUDED.up_cost, down_cost = EXCLUDED.down_cost,
+			up_pnl = EXCLUDED.up_pnl, down_pnl = EXCLUDED.down_pnl,
+			realized_pnl = EXCLUDED.realized_pnl, source = EXCLUDED.source,
+			confirmed = EXCLUDED.confirmed, settled_at = EXCLUDED.settled_at`,
+		string(st.MarketID), st.Slug, st.Asset, st.Outcome, st.PriceToBeat, st.SettlementPrice,
+		st.UpQuantity, st.DownQuantity, st.UpCost, st.DownCost, st.UpPnL, st.DownPnL,
+		st.RealizedPnL, st.Source, st.Confirmed, eventTime(st.SettledAt))
+	if err != nil {
+		return fmt.Errorf("save settlement: %w", err)
+	}
+	return nil
 }
 
-func NewInMemoryPositionRepo() *InMemoryPositionRepo {
-	return &InMemoryPositionRepo{
-		positions: make(map[string]domain.Position),
+func (s *PostgresStore) SaveBreakerEvent(ctx context.Context, e domain.BreakerEvent) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO breaker_events (
+			ts, kind, reason, detail, realized_pnl, unrealized_pnl, consecutive_losses, error_rate
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		eventTime(e.Timestamp), e.Kind, e.Reason, e.Detail, e.RealizedPnL, e.UnrealizedPnL,
+		e.ConsecutiveLosses, e.ErrorRate)
+	if err != nil {
+		return fmt.Errorf("save breaker event: %w", err)
 	}
+	return nil
 }
 
-func posKey(marketID domain.MarketID, side domain.PositionSide) string {
-	return fmt.Sprintf("%s:%s", marketID, side)
+func (s *PostgresStore) SaveReconcileEvent(ctx context.Context, e domain.ReconcileEvent) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO reconcile_events (
+			market_id, ts, side, token_id, local_qty, venue_qty, drift, action, detail
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		string(e.MarketID), eventTime(e.Timestamp), string(e.Side), e.TokenID, e.LocalQty, e.VenueQty,
+		e.Drift, e.Action, e.Detail)
+	if err != nil {
+		return fmt.Errorf("save reconcile event: %w", err)
+	}
+	return nil
 }
 
-func (r *InMemoryPositionRepo) SavePosition(_ context.Context, p domain.Position) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.positions[posKey(p.MarketID, p.Side)] = p
+// ---------- PositionRepository ----------
+
+func (s *PostgresStore) SavePosition(ctx context.Context, p domain.Position) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO positions (market_id, side, avg_entry_price, quantity, notional_usd, opened_at, hold_to_settlement, realized_pnl)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (market_id, side) DO UPDATE SET
+			avg_entry_price = EXCLUDED.avg_entry_price, quantity = EXCLUDED.quantity,
+			notional_usd = EXCLUDED.notional_usd, opened_at = EXCLUDED.opened_at,
+			hold_to_settlement = EXCLUDED.hold_to_settlement, realized_pnl = EXCLUDED.realized_pnl`,
+		string(p.MarketID), string(p.Side), p.AvgEntryPrice, p.Quantity, p.NotionalUSD,
+		eventTime(p.OpenedAt), p.HoldToSettlement, p.RealizedPnL)
+	if err != nil {
+		return fmt.Errorf("save position: %w", err)
+	}
 	return nil
 }
 
-func (r *InMemoryPositionRepo) GetPosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) (domain.Position, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	p, ok := r.positions[posKey(marketID, side)]
-	if !ok {
+func (s *PostgresStore) GetPosition(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) (domain.Position, error) {
+	rows, err := s.pool.Query(ctx, positionSelect+` WHERE market_id = $1 AND side = $2`, string(marketID), string(side))
+	if err != nil {
+		return domain.Position{}, fmt.Errorf("get position: %w", err)
+	}
+	p, err := pgx.CollectExactlyOneRow(rows, scanPosition)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.Position{}, fmt.Errorf("position not found")
 	}
+	if err != nil {
+		return domain.Position{}, fmt.Errorf("get position: %w", err)
+	}
 	return p, nil
 }
 
-func (r *InMemoryPositionRepo) ListOpenPositions(_ context.Context) ([]domain.Position, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	result := make([]domain.Position, 0, len(r.positions))
-	for _, p := range r.positions {
-		result = append(result, p)
+func (s *PostgresStore) ListOpenPositions(ctx context.Context) ([]domain.Position, error) {
+	rows, err := s.pool.Query(ctx, positionSelect)
+	if err != nil {
+		return nil, fmt.Errorf("list positions: %w", err)
+	}
+	positions, err := pgx.CollectRows(rows, scanPosition)
+	if err != nil {
+		return nil, fmt.Errorf("list positions: %w", err)
+	}
+	return positions, nil
+}
+
+func (s *PostgresStore) DeletePosition(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM positions WHERE market_id = $1 AND side = $2`, string(marketID), string(side))
+	if err != nil {
+		return fmt.Errorf("delete position: %w", err)
+	}
+	return nil
+}
+
+const positionSelect = `SELECT market_id, side, avg_entry_price, quantity, notional_usd, opened_at, hold_to_settlement, realized_pnl FROM positions`
+
+func scanPosition(row pgx.CollectableRow) (domain.Position, error) {
+	var p domain.Position
+	err := row.Scan((*string)(&p.MarketID), (*string)(&p.Side), &p.AvgEntryPrice, &p.Quantity,
+		&p.NotionalUSD, &p.OpenedAt, &p.HoldToSettlement, &p.RealizedPnL)
+	return p, err
+}
+
+// ---------- RedemptionRepository ----------
+
+func (s *PostgresStore) SavePendingRedemption(ctx context.Context, r domain.PendingRedemption) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO pending_redemptions (market_id, queued_at) VALUES ($1, $2)
+		ON CONFLICT (market_id) DO UPDATE SET queued_at = EXCLUDED.queued_at`,
+		string(r.MarketID), eventTime(r.QueuedAt))
+	if err != nil {
+		return fmt.Errorf("save pending redemption: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListPendingRedemptions(ctx context.Context) ([]domain.PendingRedemption, error) {
+	rows, err := s.pool.Query(ctx, `SELECT market_id, queued_at FROM pending_redemptions`)
+	if err != nil {
+		return nil, fmt.Errorf("list pending redemptions: %w", err)
+	}
+	pending, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.PendingRedemption, error) {
+		var r domain.PendingRedemption
+		err := row.Scan((*string)(&r.MarketID), &r.QueuedAt)
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pending redemptions: %w", err)
+	}
+	return pending, nil
+}
+
+func (s *PostgresStore) DeletePendingRedemption(ctx context.Context, marketID domain.MarketID) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM pending_redemptions WHERE market_id = $1`, string(marketID))
+	if err != nil {
+		return fmt.Errorf("delete pending redemption: %w", err)
 	}
-	return result, nil
+	return nil
 }
 
-func (r *InMemoryPositionRepo) DeletePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.positions, posKey(marketID, side))
+// ---------- Query helpers ----------
+//
+// Ranges are [from, to); a zero bound is open. An empty market ID or asset
+// matches everything. Results are ordered by time.
+
+func (s *PostgresStore) FairValues(ctx context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.FairValue, error) {
+	where, args := rangeFilter("market_id", string(marketID), "ts", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT market_id, ts, prob_up, prob_up_lower, prob_up_upper, prob_raw, prob_calibrated,
+			sigma_tau, z_score, model_uncertainty, remaining_seconds, required_log_move, model_regime
+		FROM fair_values`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query fair values: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.FairValue, error) {
+		var fv domain.FairValue
+		err := row.Scan((*string)(&fv.MarketID), &fv.Timestamp, &fv.ProbUp, &fv.ProbUpLower, &fv.ProbUpUpper,
+			&fv.ProbRaw, &fv.ProbCalibrated, &fv.SigmaTau, &fv.ZScore, &fv.ModelUncertainty,
+			&fv.RemainingSeconds, &fv.RequiredLogMove, &fv.ModelRegime)
+		return fv, err
+	})
+}
+
+func (s *PostgresStore) Signals(ctx context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.TradeSignal, error) {
+	where, args := rangeFilter("market_id", string(marketID), "ts", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT market_id, ts, side, signal_type, edge_buy_up, edge_buy_down, effective_hurdle,
+			target_size_usd, guaranteed_floor, hedge_edge, reason
+		FROM signals`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query signals: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.TradeSignal, error) {
+		var sig domain.TradeSignal
+		err := row.Scan((*string)(&sig.MarketID), &sig.Timestamp, (*string)(&sig.Side), &sig.SignalType,
+			&sig.EdgeBuyUp, &sig.EdgeBuyDown, &sig.EffectiveHurdle, &sig.TargetSizeUSD,
+			&sig.GuaranteedFloor, &sig.HedgeEdge, &sig.Reason)
+		return sig, err
+	})
+}
+
+func (s *PostgresStore) Quotes(ctx context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.MarketQuote, error) {
+	where, args := rangeFilter("market_id", string(marketID), "ts", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT market_id, ts, up_bid, up_ask, down_bid, down_ask
+		FROM quotes`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query quotes: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.MarketQuote, error) {
+		var q domain.MarketQuote
+		err := row.Scan((*string)(&q.MarketID), &q.Timestamp, &q.Up.Bid, &q.Up.Ask, &q.Down.Bid, &q.Down.Ask)
+		return q, err
+	})
+}
+
+func (s *PostgresStore) ReferenceSnapshots(ctx context.Context, asset string, from, to time.Time) ([]domain.ReferenceSnapshot, error) {
+	where, args := rangeFilter("asset", asset, "ts", from, to)
+	rows, err := s.pool.Query(ctx, `SELECT asset, ts, price FROM reference_snapshots`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query reference snapshots: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReferenceSnapshot, error) {
+		var snap domain.ReferenceSnapshot
+		err := row.Scan(&snap.Asset, &snap.Timestamp, &snap.Price)
+		return snap, err
+	})
+}
+
+func (s *PostgresStore) Fills(ctx context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.Fill, error) {
+	where, args := rangeFilter("market_id", string(marketID), "ts", from, to)
+	rows, err := s.pool.Query(ctx, `SELECT market_id, ts, side, price, size_usd, trade_id, role, reversed FROM fills`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query fills: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Fill, error) {
+		var f domain.Fill
+		err := row.Scan((*string)(&f.MarketID), &f.Timestamp, (*string)(&f.Side), &f.Price, &f.SizeUSD,
+			&f.TradeID, &f.Role, &f.Reversed)
+		return f, err
+	})
+}
+
+func (s *PostgresStore) Settlements(ctx context.Context, from, to time.Time) ([]domain.Settlement, error) {
+	where, args := rangeFilter("", "", "settled_at", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT market_id, slug, asset, outcome, price_to_beat, settlement_price,
+			up_quantity, down_quantity, up_cost, down_cost, up_pnl, down_pnl,
+			realized_pnl, source, confirmed, settled_at
+		FROM settlements`+where+` ORDER BY settled_at, market_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query settlements: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Settlement, error) {
+		var st domain.Settlement
+		err := row.Scan((*string)(&st.MarketID), &st.Slug, &st.Asset, &st.Outcome, &st.PriceToBeat,
+			&st.SettlementPrice, &st.UpQuantity, &st.DownQuantity, &st.UpCost, &st.DownCost,
+			&st.UpPnL, &st.DownPnL, &st.RealizedPnL, &st.Source, &st.Confirmed, &st.SettledAt)
+		return st, err
+	})
+}
+
+func (s *PostgresStore) BreakerEvents(ctx context.Context, from, to time.Time) ([]domain.BreakerEvent, error) {
+	where, args := rangeFilter("", "", "ts", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT ts, kind, reason, detail, realized_pnl, unrealized_pnl, consecutive_losses, error_rate
+		FROM breaker_events`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query breaker events: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.BreakerEvent, error) {
+		var e domain.BreakerEvent
+		err := row.Scan(&e.Timestamp, &e.Kind, &e.Reason, &e.Detail, &e.RealizedPnL, &e.UnrealizedPnL,
+			&e.ConsecutiveLosses, &e.ErrorRate)
+		return e, err
+	})
+}
+
+func (s *PostgresStore) ReconcileEvents(ctx context.Context, marketID domain.MarketID, from, to time.Time) ([]domain.ReconcileEvent, error) {
+	where, args := rangeFilter("market_id", string(marketID), "ts", from, to)
+	rows, err := s.pool.Query(ctx, `
+		SELECT market_id, ts, side, token_id, local_qty, venue_qty, drift, action, detail
+		FROM reconcile_events`+where+` ORDER BY ts, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query reconcile events: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReconcileEvent, error) {
+		var e domain.ReconcileEvent
+		err := row.Scan((*string)(&e.MarketID), &e.Timestamp, (*string)(&e.Side), &e.TokenID, &e.LocalQty,
+			&e.VenueQty, &e.Drift, &e.Action, &e.Detail)
+		return e, err
+	})
+}
+
+// rangeFilter builds a WHERE clause matching key (when non-empty) and the
+// [from, to) time range on timeCol.
+func rangeFilter(keyCol, key, timeCol string, from, to time.Time) (string, []any) {
+	var conds []string
+	var args []any
+	if key != "" {
+		args = append(args, key)
+		conds = append(conds, fmt.Sprintf("%s = $%d", keyCol, len(args)))
+	}
+	if !from.IsZero() {
+		args = append(args, from)
+		conds = append(conds, fmt.Sprintf("%s >= $%d", timeCol, len(args)))
+	}
+	if !to.IsZero() {
+		args = append(args, to)
+		conds = append(conds, fmt.Sprintf("%s < $%d", timeCol, len(args)))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ---------- Batch writer ----------
+
+// enqueue hands a row to the batch writer without blocking. When the queue is
+// full (database down or too slow) the row is dropped.
+func (s *PostgresStore) enqueue(table string, values ...any) {
+	select {
+	case s.queue <- batchRow{table: table, values: values}:
+	default:
+		if n := s.dropped.Add(1); n == 1 || n%1000 == 0 {
+			s.logger.Warn("postgres: batch queue full, dropping rows", "table", table, "dropped_total", n)
+		}
+	}
+}
+
+func (s *PostgresStore) batchWriter() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string][][]any)
+	count := 0
+	flush := func() {
+		if count > 0 {
+			s.writeBatch(pending)
+			pending = make(map[string][][]any)
+			count = 0
+		}
+	}
+
+	for {
+		select {
+		case row := <-s.queue:
+			pending[row.table] = append(pending[row.table], row.values)
+			count++
+			if len(pending[row.table]) >= s.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.stop:
+			for {
+				select {
+				case row := <-s.queue:
+					pending[row.table] = append(pending[row.table], row.values)
+					count++
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeBatch copies each table's rows in one round trip. A failed batch is
+// logged and discarded; these streams are diagnostic, not state.
+func (s *PostgresStore) writeBatch(pending map[string][][]any) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for table, rows := range pending {
+		if _, err := s.pool.CopyFrom(ctx, pgx.Identifier{table}, batchColumns[table], pgx.CopyFromRows(rows)); err != nil {
+			s.logger.Error("postgres: batch insert failed", "table", table, "rows", len(rows), "error", err)
+		}
+	}
+}
+
+// eventTime defaults unset timestamps to now; every table's time column is
+// NOT NULL.
+func eventTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// ---------- Migrations ----------
+
+//go:embed migrations/*.sql
+var pgMigrationFiles embed.FS
+
+// pgMigration is one versioned schema change, loaded from
+// migrations/NNNN_name.sql. Applied migrations must never be edited; add a new
+// file instead.
+type pgMigration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrationLockID serializes concurrent migrators across processes.
+const migrationLockID = 0x706f6c79626f74 // "polybot"
+
+func loadPgMigrations() ([]pgMigration, error) {
+	entries, err := pgMigrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]pgMigration, 0, len(entries))
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".sql")
+		if !ok {
+			continue
+		}
+		num, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version prefix", e.Name())
+		}
+		sql, err := pgMigrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, pgMigration{Version: version, Name: name, SQL: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// migrate applies pending migrations, each in its own transaction, and
+// records them in schema_migrations.
+func (s *PostgresStore) migrate(ctx context.Context) error {
+	migrations, err := loadPgMigrations()
+	if err != nil {
+		return fmt.Errorf("postgres: load migrations: %w", err)
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: acquire: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("postgres: migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return fmt.Errorf("postgres: create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("postgres: read schema version: %w", err)
+	}
+	if latest := migrations[len(migrations)-1].Version; current > latest {
+		return fmt.Errorf("postgres: schema version %d is newer than supported %d", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, m.SQL); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("postgres: migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		s.logger.Info("postgres: applied migration", "version", m.Version, "name", m.Name)
+	}
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/ports"
 	"Polybot/internal/service"
 )
 
@@ -52,6 +53,7 @@ type PriceTracker struct {
 	pricingModel service.PricingModel
 	positionSvc  *service.PositionService
 	hedgeEngine  *service.HedgeEngine
+	costModel    ports.CostModel
 	logDir       string
 	interval     time.Duration
 	logger       *slog.Logger
@@ -63,6 +65,7 @@ func NewPriceTracker(
 	pricingModel service.PricingModel,
 	positionSvc *service.PositionService,
 	hedgeEngine *service.HedgeEngine,
+	costModel ports.CostModel,
 	logDir string,
 	intervalMs int,
 	logger *slog.Logger,
@@ -77,6 +80,7 @@ func NewPriceTracker(
 		pricingModel: pricingModel,
 		positionSvc:  positionSvc,
 		hedgeEngine:  hedgeEngine,
+		costModel:    costModel,
 		logDir:       logDir,
 		interval:     interval,
 		logger:       logger,
@@ -87,151 +91,160 @@ func (t *PriceTracker) Run(ctx context.Context) {
 	ticker := time.NewTicker(t.interval)
 	defer ticker.Stop()
 
-	var (
-		file        *os.File
-		currentSlug string
-	)
+	files := make(map[string]*os.File) // market slug -> log file
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
-			if file != nil {
-				file.Close()
-			}
 			return
 		case <-ticker.C:
 			markets := t.registry.ListMarkets()
-			if len(markets) == 0 {
-				continue
-			}
-			market := markets[0]
+			active := make(map[string]bool, len(markets))
 
-			// Rotate file on market change
-			if market.Slug != currentSlug {
-				if file != nil {
-					file.Close()
-				}
-				currentSlug = market.Slug
-				f, err := t.openLogFile(currentSlug)
-				if err != nil {
-					t.logger.Warn("price tracker: failed to open log file", "error", err)
-					continue
-				}
-				file = f
-			}
+			for _, market := range markets {
+				active[market.Slug] = true
 
-			if file == nil {
-				continue
+				// One file per market window
+				file, ok := files[market.Slug]
+				if !ok {
+					f, err := t.openLogFile(market.Slug)
+					if err != nil {
+						t.logger.Warn("price tracker: failed to open log file", "slug", market.Slug, "error", err)
+						continue
+					}
+					files[market.Slug] = f
+					file = f
+				}
+				t.logMarket(ctx, file, market)
 			}
 
-			refState, ok := t.refAnalytics.GetState(market.Asset)
-			if !ok || refState.CurrentPrice <= 0 || market.PriceToBeat <= 0 {
-				continue
+			// Close files for windows that have rolled off
+			for slug, f := range files {
+				if !active[slug] {
+					f.Close()
+					delete(files, slug)
+				}
 			}
+		}
+	}
+}
 
-			quote, ok := t.registry.GetQuote(market.ID)
-			if !ok {
-				continue
-			}
+// logMarket writes one snapshot line for a market.
+func (t *PriceTracker) logMarket(ctx context.Context, file *os.File, market domain.BinaryMarket) {
+	refState, ok := t.refAnalytics.GetState(market.Asset)
+	if !ok || refState.CurrentPrice <= 0 || market.PriceToBeat <= 0 {
+		return
+	}
 
-			remaining := time.Until(market.EndTime).Seconds()
-			if remaining <= 0 {
-				continue
-			}
+	quote, ok := t.registry.GetQuote(market.ID)
+	if !ok {
+		return
+	}
 
-			fv, err := t.pricingModel.FairProbUp(ctx, domain.PricingInput{
-				CurrentPrice:     refState.CurrentPrice,
-				PriceToBeat:      market.PriceToBeat,
-				RemainingSeconds: remaining,
-				RealizedVol1m:    refState.RealizedVol1m,
-				RealizedVol5m:    refState.RealizedVol5m,
-				JumpScore:        refState.JumpScore,
-				Regime:           refState.Regime,
-				DriftPerSec:      refState.DriftPerSec,
-				DriftTicks:       refState.DriftTicks,
-			})
-			if err != nil {
-				continue
-			}
+	remaining := time.Until(market.EndTime).Seconds()
+	if remaining <= 0 {
+		return
+	}
 
-			// Compute directional edges (same formula as SignalService)
-			cost := 0.01 // 1% fee
-			dirEdgeUp := fv.ProbUpLower - quote.Up.Ask - cost
-			dirEdgeDown := (1.0 - fv.ProbUpUpper) - quote.Down.Ask - cost
+	fv, err := t.pricingModel.FairProbUp(ctx, domain.PricingInput{
+		CurrentPrice:     refState.CurrentPrice,
+		PriceToBeat:      market.PriceToBeat,
+		RemainingSeconds: remaining,
+		RealizedVol1m:    refState.RealizedVol1m,
+		RealizedVol5m:    refState.RealizedVol5m,
+		JumpScore:        refState.JumpScore,
+		Regime:           refState.Regime,
+		DriftPerSec:      refState.DriftPerSec,
+		DriftTicks:       refState.DriftTicks,
+	})
+	if err != nil {
+		return
+	}
 
-			// Compute inventory and hedge edges
-			var upQty, downQty, upCost, downCost float64
-			var hedgeEdgeBuyUp, hedgeEdgeBuyDown, floor float64
-			if t.positionSvc != nil {
-				upQty, downQty, upCost, downCost = t.positionSvc.GetInventory(market.ID)
-			}
-			if t.hedgeEngine != nil {
-				hedgeEdgeBuyUp, hedgeEdgeBuyDown, floor = t.hedgeEngine.ComputeHedgeEdges(ctx, market.ID, &quote)
-			}
+	// Directional edges at the costs the runner trades on
+	costUp, costDown, err := t.costModel.EstimateSideCosts(ctx, &quote, 0)
+	if err != nil {
+		t.logger.Warn("price tracker: cost estimate failed", "market", market.ID, "error", err)
+		return
+	}
+	dirEdgeUp, dirEdgeDown := service.DirectionalEdges(&fv, &quote, costUp, costDown)
 
-			// Compute drift delta_z for logging
-			var driftDeltaZ float64
-			if refState.DriftTicks >= 5 && refState.Regime != "jump" && fv.SigmaTau > 0 {
-				const driftHL = 10.0
-				lam := math.Ln2 / driftHL
-				effT := (1.0 / lam) * (1.0 - math.Exp(-lam*remaining))
-				dz := refState.DriftPerSec * effT / fv.SigmaTau
-				if dz > 0.5 {
-					dz = 0.5
-				} else if dz < -0.5 {
-					dz = -0.5
-				}
-				driftDeltaZ = dz
-			}
+	// Compute inventory and hedge edges
+	var upQty, downQty, upCost, downCost float64
+	var hedgeEdgeBuyUp, hedgeEdgeBuyDown, floor float64
+	if t.positionSvc != nil {
+		upQty, downQty, upCost, downCost = t.positionSvc.GetInventory(market.ID)
+	}
+	if t.hedgeEngine != nil {
+		hedgeEdgeBuyUp, hedgeEdgeBuyDown, floor = t.hedgeEngine.ComputeHedgeEdges(ctx, market.ID, &quote)
+	}
 
-			// Determine action label
-			action := "no_trade"
-			if hedgeEdgeBuyDown > 0.002 {
-				action = "hedge_buy_down"
-			} else if hedgeEdgeBuyUp > 0.002 {
-				action = "hedge_buy_up"
-			} else if dirEdgeUp > 0.03 {
-				action = "dir_buy_up"
-			} else if dirEdgeDown > 0.03 {
-				action = "dir_buy_down"
-			}
+	// Compute drift delta_z for logging
+	var driftDeltaZ float64
+	if refState.DriftTicks >= 5 && refState.Regime != "jump" && fv.SigmaTau > 0 {
+		const driftHL = 10.0
+		lam := math.Ln2 / driftHL
+		effT := (1.0 / lam) * (1.0 - math.Exp(-lam*remaining))
+		dz := refState.DriftPerSec * effT / fv.SigmaTau
+		if dz > 0.5 {
+			dz = 0.5
+		} else if dz < -0.5 {
+			dz = -0.5
+		}
+		driftDeltaZ = dz
+	}
 
-			snap := FullTickSnapshot{
-				Ts:               time.Now().Format(time.RFC3339),
-				RefPrice:         refState.CurrentPrice,
-				PriceToBeat:      market.PriceToBeat,
-				RemainingMs:      remaining * 1000,
-				SigmaTau:         fv.SigmaTau,
-				Z:                fv.ZScore,
-				PRaw:             fv.ProbRaw,
-				PCal:             fv.ProbCalibrated,
-				PLo:              fv.ProbUpLower,
-				PHi:              fv.ProbUpUpper,
-				UpBid:            quote.Up.Bid,
-				UpAsk:            quote.Up.Ask,
-				DownBid:          quote.Down.Bid,
-				DownAsk:          quote.Down.Ask,
-				DirEdgeUp:        dirEdgeUp,
-				DirEdgeDown:      dirEdgeDown,
-				UpQty:            upQty,
-				DownQty:          downQty,
-				UpCost:           upCost,
-				DownCost:         downCost,
-				GuaranteedFloor:  floor,
-				HedgeEdgeBuyDown: hedgeEdgeBuyDown,
-				HedgeEdgeBuyUp:   hedgeEdgeBuyUp,
-				DriftPerSec:      refState.DriftPerSec,
-				DriftDeltaZ:      driftDeltaZ,
-				DriftTicks:       refState.DriftTicks,
-				Regime:           refState.Regime,
-				Action:           action,
-			}
+	// Determine action label
+	action := "no_trade"
+	if hedgeEdgeBuyDown > 0.002 {
+		action = "hedge_buy_down"
+	} else if hedgeEdgeBuyUp > 0.002 {
+		action = "hedge_buy_up"
+	} else if dirEdgeUp > 0.03 {
+		action = "dir_buy_up"
+	} else if dirEdgeDown > 0.03 {
+		action = "dir_buy_down"
+	}
 
-			line, _ := json.Marshal(snap)
-			file.Write(line)
-			file.Write([]byte("\n"))
-		}
+	snap := FullTickSnapshot{
+		Ts:               time.Now().Format(time.RFC3339),
+		RefPrice:         refState.CurrentPrice,
+		PriceToBeat:      market.PriceToBeat,
+		RemainingMs:      remaining * 1000,
+		SigmaTau:         fv.SigmaTau,
+		Z:                fv.ZScore,
+		PRaw:             fv.ProbRaw,
+		PCal:             fv.ProbCalibrated,
+		PLo:              fv.ProbUpLower,
+		PHi:              fv.ProbUpUpper,
+		UpBid:            quote.Up.Bid,
+		UpAsk:            quote.Up.Ask,
+		DownBid:          quote.Down.Bid,
+		DownAsk:          quote.Down.Ask,
+		DirEdgeUp:        dirEdgeUp,
+		DirEdgeDown:      dirEdgeDown,
+		UpQty:            upQty,
+		DownQty:          downQty,
+		UpCost:           upCost,
+		DownCost:         downCost,
+		GuaranteedFloor:  floor,
+		HedgeEdgeBuyDown: hedgeEdgeBuyDown,
+		HedgeEdgeBuyUp:   hedgeEdgeBuyUp,
+		DriftPerSec:      refState.DriftPerSec,
+		DriftDeltaZ:      driftDeltaZ,
+		DriftTicks:       refState.DriftTicks,
+		Regime:           refState.Regime,
+		Action:           action,
 	}
+
+	line, _ := json.Marshal(snap)
+	file.Write(line)
+	file.Write([]byte("\n"))
 }
 
 func (t *PriceTracker) openLogFile(slug string) (*os.File, error) {
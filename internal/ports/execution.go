@@ -2,6 +2,8 @@ package ports
 
 import (
 	"context"
+	"math"
+	"time"
 
 	"Polybot/internal/domain"
 )
@@ -18,11 +20,101 @@ type OrderResult struct {
 type ExecutionProvider interface {
 	BuyUp(ctx context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (OrderResult, error)
 	BuyDown(ctx context.Context, marketID domain.MarketID, maxPrice float64, sizeUSD float64) (OrderResult, error)
-	ClosePosition(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) error
+	// Sell sells shares of a held token for no less than minPrice each.
+	// Size in the result is the shares sold; Price the average sale price.
+	Sell(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, shares, minPrice float64) (OrderResult, error)
 }
 
+// LimitOrderProvider rests limit buys on the book (maker mode). A non-zero
+// expiresAt makes the exchange cancel the order at that time.
+type LimitOrderProvider interface {
+	LimitBuy(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, price, shares float64, expiresAt time.Time) (OrderResult, error)
+	CancelOrder(ctx context.Context, orderID string) error
+}
+
+// OrderState is the exchange's view of an order.
+type OrderState struct {
+	Status      domain.OrderStatus // OrderLive, OrderMatched or OrderCancelled
+	SizeMatched float64            // shares matched so far
+}
+
+// OrderStatusProvider looks up and cancels submitted orders.
+type OrderStatusProvider interface {
+	GetOrderState(ctx context.Context, orderID string) (OrderState, error)
+	CancelOrder(ctx context.Context, orderID string) error
+}
+
+// VenuePosition is a token balance as the venue reports it.
+type VenuePosition struct {
+	TokenID  string
+	Size     float64 // shares
+	AvgPrice float64 // 0 if unknown
+}
+
+// VenueTrade is a recent trade as the venue reports it.
+type VenueTrade struct {
+	TradeID   string
+	TokenID   string
+	Status    string
+	Size      float64
+	Timestamp time.Time
+}
+
+// PositionSource reads our positions and recent trades from the venue.
+// GetPositions returns every position the venue reports in the given
+// markets, including sold-out ones at size zero.
+type PositionSource interface {
+	GetPositions(ctx context.Context, marketIDs []domain.MarketID) ([]VenuePosition, error)
+	GetRecentTrades(ctx context.Context, since time.Time) ([]VenueTrade, error)
+}
+
+// BalanceAllowance is a wallet balance and how much of it the exchange is
+// approved to spend: USDC for collateral, shares for an outcome token.
+type BalanceAllowance struct {
+	Balance   float64
+	Allowance float64
+}
+
+// Spendable is the part of the balance the exchange can actually move.
+func (b BalanceAllowance) Spendable() float64 {
+	return math.Min(b.Balance, b.Allowance)
+}
+
+// WalletSource reads the wallet's balances and allowances from the venue.
+type WalletSource interface {
+	Collateral(ctx context.Context) (BalanceAllowance, error)
+	Token(ctx context.Context, tokenID string) (BalanceAllowance, error)
+}
+
+// ChainBackend sends Conditional Token Framework transactions for a binary
+// market's condition. Amounts are in shares; a complete set (one UP and one
+// DOWN token) is worth one unit of collateral.
+type ChainBackend interface {
+	// MergePositions turns amount complete sets back into collateral.
+	MergePositions(ctx context.Context, conditionID string, amount float64) (txHash string, err error)
+	// Resolved reports whether the condition's payouts have been reported
+	// on-chain, which RedeemPositions requires.
+	Resolved(ctx context.Context, conditionID string) (bool, error)
+	// RedeemPositions burns every token held on a resolved condition and pays
+	// out the winning ones.
+	RedeemPositions(ctx context.Context, conditionID string) (txHash string, err error)
+}
+
+// CostModel estimates the per-share cost of trading on top of the quoted
+// price.
 type CostModel interface {
+	// EstimateAllInCost is one taker cost for either side of a market, for
+	// exits.
 	EstimateAllInCost(ctx context.Context, marketID domain.MarketID) (float64, error)
+	// EstimateSideCosts returns the cost of a taker buy of sizeUSD at each
+	// side's ask in quote: the fee plus the expected slippage of walking the
+	// book that far. A sizeUSD of 0 prices the fee alone.
+	EstimateSideCosts(ctx context.Context, quote *domain.MarketQuote, sizeUSD float64) (up, down float64, err error)
+}
+
+// FeeRateSource serves a token's taker fee rate in basis points.
+type FeeRateSource interface {
+	GetFeeRateBps(tokenID string) (int, error)
 }
 
 // FillListener listens for confirmed trade fills and updates positions.
@@ -30,5 +122,4 @@ type CostModel interface {
 // Run blocks until ctx is cancelled.
 type FillListener interface {
 	Run(ctx context.Context)
-	LoadPositionsFromAPI(ctx context.Context) error
 }
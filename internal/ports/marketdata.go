@@ -13,8 +13,27 @@ type MarketDataProvider interface {
 	SubscribeQuotes(ctx context.Context) (<-chan domain.MarketQuote, error)
 }
 
+// OrderBookSource serves the live L2 book of a token. The book returned is
+// a copy the caller may keep.
+type OrderBookSource interface {
+	Book(tokenID string) (*domain.OrderBook, bool)
+}
+
 type ReferencePriceProvider interface {
 	GetLatestPrice(ctx context.Context, asset string) (domain.ReferenceSnapshot, error)
 	GetPriceAtTime(ctx context.Context, asset string, ts time.Time) (domain.ReferenceSnapshot, error)
 	SubscribePrices(ctx context.Context, asset string) (<-chan domain.ReferenceSnapshot, error)
 }
+
+// OutcomeResolver reports the venue's official resolution of an expired market.
+// resolved is false until the venue has published a final outcome.
+type OutcomeResolver interface {
+	ResolvedOutcome(ctx context.Context, market domain.BinaryMarket) (outcome string, resolved bool, err error)
+}
+
+// RawRecorder captures feed messages exactly as received, for later replay.
+// source names the feed ("chainlink", "market", "user"); key narrows it
+// (asset or stream). Implementations must be safe for concurrent use.
+type RawRecorder interface {
+	Record(source, key string, payload []byte)
+}
@@ -13,6 +13,14 @@ type PositionRepository interface {
 	DeletePosition(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) error
 }
 
+// RedemptionRepository persists the conditions waiting to be redeemed, so
+// their tokens aren't stranded by a restart.
+type RedemptionRepository interface {
+	SavePendingRedemption(ctx context.Context, r domain.PendingRedemption) error
+	ListPendingRedemptions(ctx context.Context) ([]domain.PendingRedemption, error)
+	DeletePendingRedemption(ctx context.Context, marketID domain.MarketID) error
+}
+
 type EventRepository interface {
 	SaveFairValue(ctx context.Context, fv domain.FairValue) error
 	SaveSignal(ctx context.Context, signal domain.TradeSignal) error
@@ -20,4 +28,6 @@ type EventRepository interface {
 	SaveReferenceSnapshot(ctx context.Context, snap domain.ReferenceSnapshot) error
 	SaveFill(ctx context.Context, fill domain.Fill) error
 	SaveSettlement(ctx context.Context, s domain.Settlement) error
+	SaveBreakerEvent(ctx context.Context, e domain.BreakerEvent) error
+	SaveReconcileEvent(ctx context.Context, e domain.ReconcileEvent) error
 }
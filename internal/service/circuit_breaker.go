@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// Circuit breaker trip reasons.
+const (
+	TripDailyLoss         = "daily_loss"
+	TripConsecutiveLosses = "consecutive_losses"
+	TripErrorRate         = "error_rate"
+	TripPositionDrift     = "position_drift"
+)
+
+// BreakerConfig sets the circuit breaker thresholds. A zero threshold
+// disables that check.
+type BreakerConfig struct {
+	MaxDailyLossUSD      float64 // trip when realized + mark-to-market P&L for the UTC day reaches -MaxDailyLossUSD
+	MaxConsecutiveLosses int     // trip after this many losing windows in a row
+	MaxErrorRate         float64 // trip when this fraction of recent executions failed...
+	ErrorWindow          int     // ...over the last ErrorWindow executions (default: 20)
+
+	// Cooldown auto-resets the breaker this long after a trip (0 = manual
+	// reset only). A daily loss trip stays tripped through the cooldown while
+	// the loss persists; it always clears when the UTC day rolls over.
+	Cooldown time.Duration
+}
+
+// CircuitBreaker halts new trading when the session goes wrong: too much
+// realized plus unrealized loss today, a run of losing windows, or a high
+// execution error rate. It is fed by settlements (OnSettlement), per-market
+// marks (UpdateMark) and execution outcomes (RecordExecution).
+//
+// Resets clear the losing streak and the error window. A manual Reset also
+// re-bases today's P&L, so the daily limit applies again from that point.
+// Every trip and reset is logged and persisted as a domain.BreakerEvent.
+type CircuitBreaker struct {
+	config    BreakerConfig
+	eventRepo ports.EventRepository
+	clock     ports.Clock
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	day       string                      // UTC date the daily P&L applies to
+	realized  float64                     // realized P&L booked today
+	baseline  float64                     // daily P&L level the loss limit is measured from
+	marks     map[domain.MarketID]float64 // unrealized P&L of open markets
+	results   []windowResult              // recent traded windows, oldest first
+	execs     []bool                      // ring of recent execution outcomes (true = error)
+	execNext  int
+	execCount int
+
+	tripped   bool
+	reason    string
+	trippedAt time.Time
+}
+
+type windowResult struct {
+	marketID domain.MarketID
+	pnl      float64
+}
+
+// maxWindowResults bounds the history kept for the losing streak.
+const maxWindowResults = 100
+
+func NewCircuitBreaker(
+	config BreakerConfig,
+	eventRepo ports.EventRepository,
+	clock ports.Clock,
+	logger *slog.Logger,
+) *CircuitBreaker {
+	if config.ErrorWindow <= 0 {
+		config.ErrorWindow = 20
+	}
+	return &CircuitBreaker{
+		config:    config,
+		eventRepo: eventRepo,
+		clock:     clock,
+		logger:    logger,
+		marks:     make(map[domain.MarketID]float64),
+		execs:     make([]bool, config.ErrorWindow),
+	}
+}
+
+// Allow reports whether new trades may be placed, and the trip reason if not.
+// Time-based resets (cooldown, new day) are applied here.
+func (b *CircuitBreaker) Allow() (bool, string) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+	if b.tripped && b.config.Cooldown > 0 && now.Sub(b.trippedAt) >= b.config.Cooldown {
+		if b.reason != TripDailyLoss || !b.dailyLossBreachedLocked() {
+			events = append(events, b.resetLocked(now, "cooldown", fmt.Sprintf("%s after %s", b.reason, b.config.Cooldown)))
+		}
+	}
+	allowed, reason := !b.tripped, b.reason
+	b.mu.Unlock()
+
+	b.emit(events)
+	return allowed, reason
+}
+
+// Trip halts trading for a reason found outside the breaker, such as position
+// drift. It clears like any other trip.
+func (b *CircuitBreaker) Trip(reason, detail string) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+	if !b.tripped {
+		events = append(events, b.tripLocked(now, reason, detail))
+	}
+	b.mu.Unlock()
+
+	b.emit(events)
+}
+
+// Reset clears a trip manually and re-bases today's P&L.
+func (b *CircuitBreaker) Reset(detail string) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+	b.baseline = b.realized + b.unrealizedLocked()
+	events = append(events, b.resetLocked(now, "manual", detail))
+	b.mu.Unlock()
+
+	b.emit(events)
+}
+
+// OnSettlement books a settled window's realized P&L and releases its mark.
+// replaces is the earlier settlement of the same window when the venue
+// corrects it.
+func (b *CircuitBreaker) OnSettlement(st domain.Settlement, replaces *domain.Settlement) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+
+	delete(b.marks, st.MarketID)
+	if replaces != nil {
+		b.realized -= replaces.RealizedPnL
+	}
+	b.realized += st.RealizedPnL
+
+	if st.UpQuantity > 0 || st.DownQuantity > 0 {
+		replaced := false
+		for i := range b.results {
+			if b.results[i].marketID == st.MarketID {
+				b.results[i].pnl = st.RealizedPnL
+				replaced = true
+			}
+		}
+		if !replaced {
+			b.results = append(b.results, windowResult{marketID: st.MarketID, pnl: st.RealizedPnL})
+			if len(b.results) > maxWindowResults {
+				b.results = b.results[len(b.results)-maxWindowResults:]
+			}
+		}
+	}
+
+	events = append(events, b.checkLocked(now)...)
+	b.mu.Unlock()
+
+	b.emit(events)
+}
+
+// UpdateMark sets the unrealized P&L of a market's open inventory.
+func (b *CircuitBreaker) UpdateMark(marketID domain.MarketID, unrealizedPnL float64) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+	b.marks[marketID] = unrealizedPnL
+	events = append(events, b.checkLocked(now)...)
+	b.mu.Unlock()
+
+	b.emit(events)
+}
+
+// RecordExecution records the outcome of an order submission. FOK orders
+// the book couldn't fill and orders blocked for lack of funds are not
+// execution faults and don't count toward the error rate.
+func (b *CircuitBreaker) RecordExecution(err error) {
+	if errors.Is(err, ErrNotFilled) || errors.Is(err, ErrInsufficientFunds) {
+		return
+	}
+	b.mu.Lock()
+	now := b.clock.Now()
+	events := b.rollDayLocked(now)
+	b.execs[b.execNext] = err != nil
+	b.execNext = (b.execNext + 1) % len(b.execs)
+	if b.execCount < len(b.execs) {
+		b.execCount++
+	}
+	events = append(events, b.checkLocked(now)...)
+	b.mu.Unlock()
+
+	b.emit(events)
+}
+
+// checkLocked trips the breaker on the first breached threshold.
+func (b *CircuitBreaker) checkLocked(now time.Time) []domain.BreakerEvent {
+	if b.tripped {
+		return nil
+	}
+	switch {
+	case b.dailyLossBreachedLocked():
+		return []domain.BreakerEvent{b.tripLocked(now, TripDailyLoss,
+			fmt.Sprintf("daily P&L %.2f below -%.2f", b.dailyPnLLocked(), b.config.MaxDailyLossUSD))}
+	case b.config.MaxConsecutiveLosses > 0 && b.lossStreakLocked() >= b.config.MaxConsecutiveLosses:
+		return []domain.BreakerEvent{b.tripLocked(now, TripConsecutiveLosses,
+			fmt.Sprintf("%d losing windows in a row", b.lossStreakLocked()))}
+	case b.config.MaxErrorRate > 0 && b.execCount == len(b.execs) && b.errorRateLocked() >= b.config.MaxErrorRate:
+		return []domain.BreakerEvent{b.tripLocked(now, TripErrorRate,
+			fmt.Sprintf("%.0f%% of the last %d executions failed", b.errorRateLocked()*100, len(b.execs)))}
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) tripLocked(now time.Time, reason, detail string) domain.BreakerEvent {
+	b.tripped = true
+	b.reason = reason
+	b.trippedAt = now
+	return b.eventLocked(now, domain.BreakerTrip, reason, detail)
+}
+
+func (b *CircuitBreaker) resetLocked(now time.Time, reason, detail string) domain.BreakerEvent {
+	event := b.eventLocked(now, domain.BreakerReset, reason, detail)
+	b.tripped = false
+	b.reason = ""
+	b.results = nil
+	b.execNext, b.execCount = 0, 0
+	return event
+}
+
+// rollDayLocked starts a new P&L day, clearing a daily loss trip.
+func (b *CircuitBreaker) rollDayLocked(now time.Time) []domain.BreakerEvent {
+	day := now.UTC().Format("2006-01-02")
+	if day == b.day {
+		return nil
+	}
+	first := b.day == ""
+	b.day = day
+	b.realized = 0
+	b.baseline = 0
+	if !first && b.tripped && b.reason == TripDailyLoss {
+		return []domain.BreakerEvent{b.resetLocked(now, "new_day", day)}
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) dailyLossBreachedLocked() bool {
+	return b.config.MaxDailyLossUSD > 0 && b.dailyPnLLocked() <= -b.config.MaxDailyLossUSD
+}
+
+func (b *CircuitBreaker) dailyPnLLocked() float64 {
+	return b.realized + b.unrealizedLocked() - b.baseline
+}
+
+func (b *CircuitBreaker) unrealizedLocked() float64 {
+	var total float64
+	for _, pnl := range b.marks {
+		total += pnl
+	}
+	return total
+}
+
+func (b *CircuitBreaker) lossStreakLocked() int {
+	streak := 0
+	for i := len(b.results) - 1; i >= 0 && b.results[i].pnl < 0; i-- {
+		streak++
+	}
+	return streak
+}
+
+func (b *CircuitBreaker) errorRateLocked() float64 {
+	if b.execCount == 0 {
+		return 0
+	}
+	failed := 0
+	for i := 0; i < b.execCount; i++ {
+		if b.execs[i] {
+			failed++
+		}
+	}
+	return float64(failed) / float64(b.execCount)
+}
+
+func (b *CircuitBreaker) eventLocked(now time.Time, kind, reason, detail string) domain.BreakerEvent {
+	return domain.BreakerEvent{
+		Kind:              kind,
+		Reason:            reason,
+		Detail:            detail,
+		RealizedPnL:       b.realized,
+		UnrealizedPnL:     b.unrealizedLocked(),
+		ConsecutiveLosses: b.lossStreakLocked(),
+		ErrorRate:         b.errorRateLocked(),
+		Timestamp:         now,
+	}
+}
+
+// emit logs and persists events outside the lock.
+func (b *CircuitBreaker) emit(events []domain.BreakerEvent) {
+	for _, e := range events {
+		attrs := []any{
+			"reason", e.Reason,
+			"detail", e.Detail,
+			"realized_pnl", e.RealizedPnL,
+			"unrealized_pnl", e.UnrealizedPnL,
+			"consecutive_losses", e.ConsecutiveLosses,
+			"error_rate", e.ErrorRate,
+		}
+		if e.Kind == domain.BreakerTrip {
+			b.logger.Warn("circuit breaker tripped, new trades halted", attrs...)
+		} else {
+			b.logger.Info("circuit breaker reset, trading resumed", attrs...)
+		}
+		if err := b.eventRepo.SaveBreakerEvent(context.Background(), e); err != nil {
+			b.logger.Error("circuit breaker: failed to persist event", "kind", e.Kind, "error", err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+func newTestBreaker(cfg BreakerConfig) (*CircuitBreaker, *fixedClock, *memEventRepo) {
+	clock := &fixedClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	events := &memEventRepo{}
+	return NewCircuitBreaker(cfg, events, clock, quietLogger()), clock, events
+}
+
+func tradedWindow(id domain.MarketID, pnl float64) domain.Settlement {
+	return domain.Settlement{MarketID: id, UpQuantity: 10, RealizedPnL: pnl}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("trips_on_daily_loss_including_marks", func(t *testing.T) {
+		b, _, events := newTestBreaker(BreakerConfig{MaxDailyLossUSD: 30})
+		b.OnSettlement(tradedWindow("m1", -20), nil)
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected trading allowed at -20")
+		}
+		b.UpdateMark("m2", -10)
+		ok, reason := b.Allow()
+		if ok || reason != TripDailyLoss {
+			t.Fatalf("expected daily loss trip, got ok=%v reason=%q", ok, reason)
+		}
+		got := events.breakerEvents()
+		if len(got) != 1 || got[0].Kind != domain.BreakerTrip || got[0].RealizedPnL != -20 || got[0].UnrealizedPnL != -10 {
+			t.Errorf("unexpected events %+v", got)
+		}
+	})
+
+	t.Run("daily_loss_clears_on_new_day_not_on_cooldown", func(t *testing.T) {
+		b, clock, events := newTestBreaker(BreakerConfig{MaxDailyLossUSD: 30, Cooldown: time.Hour})
+		b.OnSettlement(tradedWindow("m1", -40), nil)
+
+		clock.now = clock.now.Add(2 * time.Hour)
+		if ok, _ := b.Allow(); ok {
+			t.Fatal("expected daily loss trip to persist through cooldown")
+		}
+
+		clock.now = clock.now.Add(12 * time.Hour) // next UTC day
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected reset on new day")
+		}
+		got := events.breakerEvents()
+		if last := got[len(got)-1]; last.Kind != domain.BreakerReset || last.Reason != "new_day" {
+			t.Errorf("expected new_day reset event, got %+v", last)
+		}
+	})
+
+	t.Run("consecutive_losses_with_cooldown_reset", func(t *testing.T) {
+		b, clock, _ := newTestBreaker(BreakerConfig{MaxConsecutiveLosses: 3, Cooldown: 30 * time.Minute})
+		b.OnSettlement(tradedWindow("m1", -1), nil)
+		b.OnSettlement(tradedWindow("m2", -1), nil)
+		b.OnSettlement(tradedWindow("m3", 2), nil)             // streak broken
+		b.OnSettlement(domain.Settlement{MarketID: "m4"}, nil) // untraded window doesn't count
+		b.OnSettlement(tradedWindow("m5", -1), nil)
+		b.OnSettlement(tradedWindow("m6", -1), nil)
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected trading allowed with a streak of 2")
+		}
+		b.OnSettlement(tradedWindow("m7", -1), nil)
+		if ok, reason := b.Allow(); ok || reason != TripConsecutiveLosses {
+			t.Fatalf("expected consecutive loss trip, got ok=%v reason=%q", ok, reason)
+		}
+
+		clock.now = clock.now.Add(30 * time.Minute)
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected cooldown reset")
+		}
+		// Streak starts over after a reset
+		b.OnSettlement(tradedWindow("m8", -1), nil)
+		if ok, _ := b.Allow(); !ok {
+			t.Error("expected a single loss after reset to be allowed")
+		}
+	})
+
+	t.Run("corrected_settlement_replaces_result", func(t *testing.T) {
+		b, _, _ := newTestBreaker(BreakerConfig{MaxConsecutiveLosses: 2})
+		b.OnSettlement(tradedWindow("m1", -5), nil)
+		provisional := tradedWindow("m2", 5)
+		b.OnSettlement(provisional, nil)
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected win to break the streak")
+		}
+		b.OnSettlement(tradedWindow("m2", -5), &provisional)
+		if ok, _ := b.Allow(); ok {
+			t.Error("expected correction to a loss to complete the streak")
+		}
+	})
+
+	t.Run("error_rate_needs_full_window", func(t *testing.T) {
+		b, _, _ := newTestBreaker(BreakerConfig{MaxErrorRate: 0.5, ErrorWindow: 4})
+		fail := errors.New("rejected")
+		b.RecordExecution(fail)
+		b.RecordExecution(fail)
+		b.RecordExecution(fail)
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected no trip before the window fills")
+		}
+		b.RecordExecution(nil)
+		if ok, reason := b.Allow(); ok || reason != TripErrorRate {
+			t.Fatalf("expected error rate trip, got ok=%v reason=%q", ok, reason)
+		}
+	})
+
+	t.Run("fok_no_fills_are_not_faults", func(t *testing.T) {
+		b, _, _ := newTestBreaker(BreakerConfig{MaxErrorRate: 0.5, ErrorWindow: 4})
+		b.RecordExecution(nil)
+		b.RecordExecution(nil)
+		b.RecordExecution(nil)
+		for i := 0; i < 4; i++ {
+			b.RecordExecution(fmt.Errorf("buy up: %w", ErrNotFilled))
+		}
+		b.RecordExecution(ErrInsufficientFunds)
+		b.RecordExecution(errors.New("rejected"))
+		if ok, reason := b.Allow(); !ok {
+			t.Fatalf("expected no trip with 1 fault in 4, got reason=%q", reason)
+		}
+	})
+
+	t.Run("manual_reset_rebases_daily_loss", func(t *testing.T) {
+		b, _, events := newTestBreaker(BreakerConfig{MaxDailyLossUSD: 30})
+		b.OnSettlement(tradedWindow("m1", -35), nil)
+		if ok, _ := b.Allow(); ok {
+			t.Fatal("expected trip")
+		}
+		b.Reset("operator")
+		if ok, _ := b.Allow(); !ok {
+			t.Fatal("expected manual reset to resume trading")
+		}
+		b.OnSettlement(tradedWindow("m2", -20), nil)
+		if ok, _ := b.Allow(); !ok {
+			t.Error("expected loss measured from the reset point")
+		}
+		got := events.breakerEvents()
+		if len(got) != 2 || got[1].Kind != domain.BreakerReset || got[1].Reason != "manual" || got[1].Detail != "operator" {
+			t.Errorf("unexpected events %+v", got)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// FixedCostModel returns a constant all-in cost estimate for every market.
+type FixedCostModel struct {
+	Cost float64
+}
+
+func (f *FixedCostModel) EstimateAllInCost(_ context.Context, _ domain.MarketID) (float64, error) {
+	return f.Cost, nil
+}
+
+func (f *FixedCostModel) EstimateSideCosts(_ context.Context, _ *domain.MarketQuote, _ float64) (float64, float64, error) {
+	return f.Cost, f.Cost, nil
+}
+
+type TakerCostConfig struct {
+	RefSizeUSD float64 // buy size EstimateAllInCost estimates slippage for (default: 10)
+}
+
+// TakerCostModel prices taker buys from each token's fee rate and live book.
+// Polymarket charges takers feeRate × min(p, 1-p) per share, so the fee
+// peaks at even odds. Slippage is how far the average price of buying the
+// order's size off the asks lies above the best ask.
+type TakerCostModel struct {
+	registry *MarketRegistry
+	fees     ports.FeeRateSource
+	config   TakerCostConfig
+
+	mu    sync.Mutex
+	books []ports.OrderBookSource
+}
+
+func NewTakerCostModel(registry *MarketRegistry, fees ports.FeeRateSource, config TakerCostConfig) *TakerCostModel {
+	if config.RefSizeUSD <= 0 {
+		config.RefSizeUSD = 10
+	}
+	return &TakerCostModel{registry: registry, fees: fees, config: config}
+}
+
+// AddBookSource registers a provider of the books slippage is measured on.
+// Without a book for a token its slippage is 0.
+func (m *TakerCostModel) AddBookSource(source ports.OrderBookSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.books = append(m.books, source)
+}
+
+// EstimateAllInCost is the larger side cost of a RefSizeUSD buy at the
+// market's latest quote, or the even-odds fee before the first quote.
+func (m *TakerCostModel) EstimateAllInCost(ctx context.Context, marketID domain.MarketID) (float64, error) {
+	quote, ok := m.registry.GetQuote(marketID)
+	if !ok {
+		quote = domain.MarketQuote{
+			MarketID: marketID,
+			Up:       domain.SideQuote{Ask: 0.5},
+			Down:     domain.SideQuote{Ask: 0.5},
+		}
+	}
+	up, down, err := m.EstimateSideCosts(ctx, &quote, m.config.RefSizeUSD)
+	return math.Max(up, down), err
+}
+
+func (m *TakerCostModel) EstimateSideCosts(_ context.Context, quote *domain.MarketQuote, sizeUSD float64) (up, down float64, err error) {
+	market, ok := m.registry.GetMarket(quote.MarketID)
+	if !ok {
+		return 0, 0, fmt.Errorf("cost model: unknown market %s", quote.MarketID)
+	}
+	if up, err = m.sideCost(market.UpTokenID, quote.Up.Ask, sizeUSD); err != nil {
+		return 0, 0, err
+	}
+	if down, err = m.sideCost(market.DownTokenID, quote.Down.Ask, sizeUSD); err != nil {
+		return 0, 0, err
+	}
+	return up, down, nil
+}
+
+func (m *TakerCostModel) sideCost(tokenID string, ask, sizeUSD float64) (float64, error) {
+	bps, err := m.fees.GetFeeRateBps(tokenID)
+	if err != nil {
+		return 0, fmt.Errorf("cost model: fee rate of %s: %w", tokenID, err)
+	}
+	cost := TakerFee(bps, ask)
+	if sizeUSD <= 0 {
+		return cost, nil
+	}
+	if book, ok := m.book(tokenID); ok {
+		cost += expectedSlippage(book, sizeUSD)
+	}
+	return cost, nil
+}
+
+func (m *TakerCostModel) book(tokenID string) (*domain.OrderBook, bool) {
+	m.mu.Lock()
+	sources := m.books
+	m.mu.Unlock()
+	for _, s := range sources {
+		if book, ok := s.Book(tokenID); ok {
+			return book, true
+		}
+	}
+	return nil, false
+}
+
+// TakerFee returns the per-share fee of a taker trade at price.
+func TakerFee(feeRateBps int, price float64) float64 {
+	if price <= 0 || price >= 1 {
+		return 0
+	}
+	return float64(feeRateBps) / 10000 * math.Min(price, 1-price)
+}
+
+// expectedSlippage is the average price of buying sizeUSD off the asks less
+// the best ask; a thinner book is walked to the end.
+func expectedSlippage(book *domain.OrderBook, sizeUSD float64) float64 {
+	best, ok := book.Best(domain.BookAsk)
+	if !ok {
+		return 0
+	}
+	shares, _, filled := book.BuyWithUSD(sizeUSD)
+	if shares <= 0 {
+		return 0
+	}
+	if !filled {
+		sizeUSD, _ = book.BuyCost(shares)
+	}
+	return math.Max(sizeUSD/shares-best.Price, 0)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"Polybot/internal/domain"
+)
+
+type stubFeeRates map[string]int
+
+func (s stubFeeRates) GetFeeRateBps(tokenID string) (int, error) {
+	bps, ok := s[tokenID]
+	if !ok {
+		return 0, errors.New("no fee rate")
+	}
+	return bps, nil
+}
+
+type stubBooks map[string]*domain.OrderBook
+
+func (s stubBooks) Book(tokenID string) (*domain.OrderBook, bool) {
+	book, ok := s[tokenID]
+	return book, ok
+}
+
+func TestTakerFee(t *testing.T) {
+	cases := []struct {
+		bps   int
+		price float64
+		want  float64
+	}{
+		{1000, 0.50, 0.05},
+		{1000, 0.20, 0.02},
+		{1000, 0.80, 0.02}, // symmetric around even odds
+		{0, 0.50, 0},
+		{1000, 1.00, 0},
+	}
+	for _, c := range cases {
+		if got := TakerFee(c.bps, c.price); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("TakerFee(%d, %.2f) = %f, want %f", c.bps, c.price, got, c.want)
+		}
+	}
+}
+
+func TestTakerCostModel(t *testing.T) {
+	ctx := context.Background()
+	registry := NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"})
+	quote := &domain.MarketQuote{
+		MarketID: "m1",
+		Up:       domain.SideQuote{Bid: 0.48, Ask: 0.50},
+		Down:     domain.SideQuote{Bid: 0.48, Ask: 0.50},
+	}
+
+	t.Run("fee_only_without_books", func(t *testing.T) {
+		model := NewTakerCostModel(registry, stubFeeRates{"up": 200, "down": 0}, TakerCostConfig{})
+		up, down, err := model.EstimateSideCosts(ctx, quote, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(up-0.01) > 1e-9 || down != 0 {
+			t.Errorf("expected 0.01/0, got %f/%f", up, down)
+		}
+	})
+
+	t.Run("adds_slippage_at_the_order_size", func(t *testing.T) {
+		model := NewTakerCostModel(registry, stubFeeRates{"up": 0, "down": 0}, TakerCostConfig{})
+		model.AddBookSource(stubBooks{
+			// $5 at 0.50, then 0.60: $10 buys 10 + 8.33 shares at 0.545
+			"up": domain.NewOrderBook("up", nil, []domain.PriceLevel{{Price: 0.50, Size: 10}, {Price: 0.60, Size: 100}}),
+		})
+		up, down, err := model.EstimateSideCosts(ctx, quote, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 10/(10+5/0.60) - 0.50
+		if math.Abs(up-want) > 1e-9 || down != 0 {
+			t.Errorf("expected %f/0, got %f/%f", want, up, down)
+		}
+
+		// $5 fits the best level, and 0 prices the fee alone
+		for _, size := range []float64{5, 0} {
+			if up, _, _ := model.EstimateSideCosts(ctx, quote, size); math.Abs(up) > 1e-9 {
+				t.Errorf("expected no slippage at $%v, got %f", size, up)
+			}
+		}
+	})
+
+	t.Run("all_in_is_larger_side", func(t *testing.T) {
+		model := NewTakerCostModel(registry, stubFeeRates{"up": 200, "down": 400}, TakerCostConfig{})
+		// No quote in the registry yet: both sides at even odds
+		cost, err := model.EstimateAllInCost(ctx, "m1")
+		if err != nil || math.Abs(cost-0.02) > 1e-9 {
+			t.Errorf("expected 0.02, got %f (%v)", cost, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		model := NewTakerCostModel(registry, stubFeeRates{"up": 200}, TakerCostConfig{})
+		if _, _, err := model.EstimateSideCosts(ctx, quote, 0); err == nil {
+			t.Error("expected an error without the down fee rate")
+		}
+		if _, _, err := model.EstimateSideCosts(ctx, &domain.MarketQuote{MarketID: "m2"}, 0); err == nil {
+			t.Error("expected an error for an unknown market")
+		}
+	})
+}
@@ -2,26 +2,60 @@ package service
 
 import (
 	"context"
+	"errors"
+	"math"
 
 	"Polybot/internal/domain"
 	"Polybot/internal/ports"
 )
 
+// ErrNotFilled is a fill-or-kill order the book couldn't fill at the limit.
+// Nothing traded; the signal may simply be retried on a later tick.
+var ErrNotFilled = errors.New("order not filled")
+
 type ExecutionService struct {
 	Provider ports.ExecutionProvider
+	Wallet   *WalletService // optional: blocks orders the wallet can't fund
 }
 
 func NewExecutionService(provider ports.ExecutionProvider) *ExecutionService {
 	return &ExecutionService{Provider: provider}
 }
 
+// Execute submits req. Sells are for SizeUSD/MaxPrice shares at no less
+// than MaxPrice. Orders the wallet can't fund fail with ErrInsufficientFunds
+// before they reach the provider; providers return the same error when the
+// venue rejects an order for funds, and ErrNotFilled when a FOK order finds
+// too little liquidity.
 func (e *ExecutionService) Execute(ctx context.Context, req domain.ExecutionRequest) (ports.OrderResult, error) {
 	switch req.Side {
 	case domain.SignalBuyUp, domain.SignalHedgeUp:
+		if err := e.checkBuy(req.SizeUSD); err != nil {
+			return ports.OrderResult{}, err
+		}
 		return e.Provider.BuyUp(ctx, req.MarketID, req.MaxPrice, req.SizeUSD)
 	case domain.SignalBuyDown, domain.SignalHedgeDown:
+		if err := e.checkBuy(req.SizeUSD); err != nil {
+			return ports.OrderResult{}, err
+		}
 		return e.Provider.BuyDown(ctx, req.MarketID, req.MaxPrice, req.SizeUSD)
+	case domain.SignalSellUp, domain.SignalSellDown:
+		shares := math.Round(req.SizeUSD/req.MaxPrice*100) / 100
+		side := fillSideToPositionSide(req.Side)
+		if e.Wallet != nil {
+			if err := e.Wallet.CheckSell(ctx, req.MarketID, side, shares); err != nil {
+				return ports.OrderResult{}, err
+			}
+		}
+		return e.Provider.Sell(ctx, req.MarketID, side, shares, req.MaxPrice)
 	default:
 		return ports.OrderResult{}, nil
 	}
 }
+
+func (e *ExecutionService) checkBuy(sizeUSD float64) error {
+	if e.Wallet == nil {
+		return nil
+	}
+	return e.Wallet.CheckBuy(sizeUSD)
+}
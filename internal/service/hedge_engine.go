@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"math"
-	"time"
 
 	"Polybot/internal/domain"
 	"Polybot/internal/ports"
@@ -22,13 +21,15 @@ type HedgeConfig struct {
 type HedgeEngine struct {
 	positionSvc *PositionService
 	costModel   ports.CostModel
+	clock       ports.Clock
 	config      HedgeConfig
 }
 
-func NewHedgeEngine(positionSvc *PositionService, costModel ports.CostModel, config HedgeConfig) *HedgeEngine {
+func NewHedgeEngine(positionSvc *PositionService, costModel ports.CostModel, clock ports.Clock, config HedgeConfig) *HedgeEngine {
 	return &HedgeEngine{
 		positionSvc: positionSvc,
 		costModel:   costModel,
+		clock:       clock,
 		config:      config,
 	}
 }
@@ -48,37 +49,25 @@ func (h *HedgeEngine) Evaluate(
 			MarketID:   marketID,
 			Side:       domain.SignalNone,
 			SignalType: "hedge",
-			Timestamp:  time.Now(),
+			Timestamp:  h.clock.Now(),
 		}, nil
 	}
 
-	cost, err := h.costModel.EstimateAllInCost(ctx, marketID)
+	// Fees only: the runner charges slippage at the hedge's size
+	costBuyUp, costBuyDown, err := h.costModel.EstimateSideCosts(ctx, quote, 0)
 	if err != nil {
-		cost = 0.005 // fallback
+		return domain.TradeSignal{}, err
 	}
 
-	// Current guaranteed floor
 	currentFloor := math.Min(upQty, downQty) - upCost - downCost
-
-	// Floor improvement if we buy 1 unit of DOWN at ask
-	deltaGDown := 0.0
-	if quote.Down.Ask > 0 && quote.Down.Ask < 1.0 {
-		newFloor := math.Min(upQty, downQty+1) - upCost - downCost - quote.Down.Ask - cost
-		deltaGDown = newFloor - currentFloor
-	}
-
-	// Floor improvement if we buy 1 unit of UP at ask
-	deltaGUp := 0.0
-	if quote.Up.Ask > 0 && quote.Up.Ask < 1.0 {
-		newFloor := math.Min(upQty+1, downQty) - upCost - downCost - quote.Up.Ask - cost
-		deltaGUp = newFloor - currentFloor
-	}
+	deltaGUp, deltaGDown := hedgeEdges(upQty, downQty, upCost, downCost, quote, costBuyUp, costBuyDown)
 
 	signal := domain.TradeSignal{
 		MarketID:        marketID,
 		SignalType:      "hedge",
 		GuaranteedFloor: currentFloor,
-		Timestamp:       time.Now(),
+		EffectiveHurdle: h.config.HedgeHurdle,
+		Timestamp:       h.clock.Now(),
 	}
 
 	// Pick the best hedge trade
@@ -115,18 +104,26 @@ func (h *HedgeEngine) ComputeHedgeEdges(
 	upQty, downQty, upCost, downCost := h.positionSvc.GetInventory(marketID)
 	floor = math.Min(upQty, downQty) - upCost - downCost
 
-	cost, err := h.costModel.EstimateAllInCost(ctx, marketID)
+	// Without costs there is no edge to report
+	costBuyUp, costBuyDown, err := h.costModel.EstimateSideCosts(ctx, quote, 0)
 	if err != nil {
-		cost = 0.005
+		return 0, 0, floor
 	}
+	hedgeEdgeBuyUp, hedgeEdgeBuyDown = hedgeEdges(upQty, downQty, upCost, downCost, quote, costBuyUp, costBuyDown)
+	return hedgeEdgeBuyUp, hedgeEdgeBuyDown, floor
+}
 
-	if quote.Down.Ask > 0 && quote.Down.Ask < 1.0 {
-		newFloor := math.Min(upQty, downQty+1) - upCost - downCost - quote.Down.Ask - cost
-		hedgeEdgeBuyDown = newFloor - floor
-	}
+// hedgeEdges returns the floor improvement ΔG of buying one share of each
+// side at its ask plus that side's cost; 0 for a side with no valid ask.
+func hedgeEdges(upQty, downQty, upCost, downCost float64, quote *domain.MarketQuote, costBuyUp, costBuyDown float64) (deltaGUp, deltaGDown float64) {
+	floor := math.Min(upQty, downQty) - upCost - downCost
 	if quote.Up.Ask > 0 && quote.Up.Ask < 1.0 {
-		newFloor := math.Min(upQty+1, downQty) - upCost - downCost - quote.Up.Ask - cost
-		hedgeEdgeBuyUp = newFloor - floor
+		newFloor := math.Min(upQty+1, downQty) - upCost - downCost - quote.Up.Ask - costBuyUp
+		deltaGUp = newFloor - floor
+	}
+	if quote.Down.Ask > 0 && quote.Down.Ask < 1.0 {
+		newFloor := math.Min(upQty, downQty+1) - upCost - downCost - quote.Down.Ask - costBuyDown
+		deltaGDown = newFloor - floor
 	}
-	return
+	return deltaGUp, deltaGDown
 }
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// MakerConfig controls resting limit orders in maker mode.
+type MakerConfig struct {
+	TickSize     float64 // price increment (default: 0.01)
+	RepriceTicks int     // replace a resting order once its target moves this many ticks (default: 1)
+}
+
+// MakerService keeps at most one resting limit buy per market side. Quote
+// leaves an order in place while its price is still on target and otherwise
+// cancels and replaces it; fills arrive through the fill listener like any
+// other, and the OrderManager counts the unfilled remainder as pending.
+type MakerService struct {
+	provider ports.LimitOrderProvider
+	orders   *OrderManager
+	wallet   *WalletService // optional: blocks bids the wallet can't fund
+	config   MakerConfig
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	resting map[makerKey]string // order ID per market side
+}
+
+type makerKey struct {
+	marketID domain.MarketID
+	side     domain.PositionSide
+}
+
+func NewMakerService(provider ports.LimitOrderProvider, orders *OrderManager, config MakerConfig, logger *slog.Logger) *MakerService {
+	if config.TickSize <= 0 {
+		config.TickSize = 0.01
+	}
+	if config.RepriceTicks <= 0 {
+		config.RepriceTicks = 1
+	}
+	return &MakerService{
+		provider: provider,
+		orders:   orders,
+		config:   config,
+		logger:   logger,
+		resting:  make(map[makerKey]string),
+	}
+}
+
+// SetWallet blocks bids the wallet can't fund. Must be called before the
+// first Place.
+func (m *MakerService) SetWallet(wallet *WalletService) {
+	m.wallet = wallet
+}
+
+// TickSize is the price increment quotes are placed on.
+func (m *MakerService) TickSize() float64 {
+	return m.config.TickSize
+}
+
+// Resting returns the live resting order on a market side, if any.
+func (m *MakerService) Resting(marketID domain.MarketID, side domain.PositionSide) (domain.Order, bool) {
+	key := makerKey{marketID, side}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.resting[key]
+	if !ok {
+		return domain.Order{}, false
+	}
+	o, ok := m.orders.Get(id)
+	if !ok || o.Status.Terminal() {
+		delete(m.resting, key)
+		return domain.Order{}, false
+	}
+	return o, true
+}
+
+// OnTarget reports whether a resting order's price is within RepriceTicks of
+// the target price.
+func (m *MakerService) OnTarget(o domain.Order, target float64) bool {
+	return target > 0 && math.Abs(o.Price-target) < float64(m.config.RepriceTicks)*m.config.TickSize-1e-9
+}
+
+// Place rests a limit buy for req.SizeUSD at req.MaxPrice, expiring at
+// expiresAt. Any order already resting on that side must be cancelled first.
+func (m *MakerService) Place(ctx context.Context, req domain.ExecutionRequest, expiresAt time.Time) (ports.OrderResult, error) {
+	side := fillSideToPositionSide(req.Side)
+	shares := math.Floor(req.SizeUSD/req.MaxPrice*100+1e-6) / 100
+	if shares <= 0 {
+		return ports.OrderResult{}, nil
+	}
+	req.Limit = true
+	req.SizeUSD = shares * req.MaxPrice
+	if m.wallet != nil {
+		if err := m.wallet.CheckBuy(req.SizeUSD); err != nil {
+			return ports.OrderResult{}, err
+		}
+	}
+
+	result, err := m.provider.LimitBuy(ctx, req.MarketID, side, req.MaxPrice, shares, expiresAt)
+	if err != nil {
+		return result, err
+	}
+	if result.OrderID == "" {
+		return result, fmt.Errorf("limit buy for %s %s: no order ID in response", req.MarketID, side)
+	}
+	m.orders.Track(req, result)
+
+	m.mu.Lock()
+	m.resting[makerKey{req.MarketID, side}] = result.OrderID
+	m.mu.Unlock()
+	return result, nil
+}
+
+// Cancel pulls the resting order on a market side. Shares it already matched
+// stay pending until their fills are booked.
+func (m *MakerService) Cancel(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	o, ok := m.Resting(marketID, side)
+	if !ok {
+		return nil
+	}
+	if err := m.provider.CancelOrder(ctx, o.ID); err != nil {
+		return fmt.Errorf("cancel %s %s order %s: %w", marketID, side, o.ID, err)
+	}
+	m.orders.OnOrderUpdate(o.ID, ports.OrderState{Status: domain.OrderCancelled, SizeMatched: o.MatchedShares})
+
+	m.mu.Lock()
+	delete(m.resting, makerKey{marketID, side})
+	m.mu.Unlock()
+
+	m.logger.Debug("maker: cancelled resting order",
+		"market", marketID,
+		"side", side,
+		"order_id", o.ID,
+		"price", o.Price,
+		"filled_usd", o.FilledUSD,
+	)
+	return nil
+}
+
+// CancelMarket pulls the resting orders on both sides of a market.
+func (m *MakerService) CancelMarket(ctx context.Context, marketID domain.MarketID) error {
+	errUp := m.Cancel(ctx, marketID, domain.PositionUp)
+	errDown := m.Cancel(ctx, marketID, domain.PositionDown)
+	if errUp != nil {
+		return errUp
+	}
+	return errDown
+}
+
+// Requote keeps the resting bid on req's market side at req.MaxPrice. An
+// order still on target is left alone so it keeps its queue priority, and
+// the result has no OrderID; otherwise the order is cancelled and, when the
+// price is positive, replaced with a bid for size() USD. size runs after the
+// cancel so the replaced order's remainder isn't counted against it; 0 skips
+// the bid. acted reports whether an order was cancelled or submitted, so
+// callers count only those as executions.
+func (m *MakerService) Requote(ctx context.Context, req domain.ExecutionRequest, expiresAt time.Time, size func() float64) (result ports.OrderResult, acted bool, err error) {
+	side := fillSideToPositionSide(req.Side)
+	if o, ok := m.Resting(req.MarketID, side); ok {
+		if m.OnTarget(o, req.MaxPrice) {
+			return ports.OrderResult{}, false, nil
+		}
+		acted = true
+		if err := m.Cancel(ctx, req.MarketID, side); err != nil {
+			return ports.OrderResult{}, true, err
+		}
+	}
+	if req.MaxPrice <= 0 {
+		return ports.OrderResult{}, acted, nil
+	}
+	if req.SizeUSD = size(); req.SizeUSD <= 0 {
+		return ports.OrderResult{}, acted, nil
+	}
+	result, err = m.Place(ctx, req, expiresAt)
+	return result, true, err
+}
+
+// Pull cancels a market's resting bids. A failed cancel is logged rather
+// than returned: the next evaluation pulls again.
+func (m *MakerService) Pull(ctx context.Context, marketID domain.MarketID, reason string) {
+	if _, ok := m.Resting(marketID, domain.PositionUp); !ok {
+		if _, ok := m.Resting(marketID, domain.PositionDown); !ok {
+			return
+		}
+	}
+	m.logger.Debug("maker: pulling quotes", "market", marketID, "reason", reason)
+	if err := m.CancelMarket(ctx, marketID); err != nil {
+		m.logger.Warn("maker: failed to pull quotes", "market", marketID, "reason", reason, "error", err)
+	}
+}
+
+// InventoryWithPending returns a market's booked inventory plus its pending
+// orders, resting bids included. orders may be nil.
+func InventoryWithPending(positions *PositionService, orders *OrderManager, marketID domain.MarketID) (upQty, downQty, upCost, downCost float64) {
+	upQty, downQty, upCost, downCost = positions.GetInventory(marketID)
+	if orders != nil {
+		pu, pd, pcu, pcd := orders.Pending(marketID)
+		upQty, downQty, upCost, downCost = upQty+pu, downQty+pd, upCost+pcu, downCost+pcd
+	}
+	return
+}
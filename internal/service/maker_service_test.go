@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// stubLimitProvider records limit buys and cancels.
+type stubLimitProvider struct {
+	placed    []float64 // prices
+	cancelled []string
+}
+
+func (s *stubLimitProvider) LimitBuy(_ context.Context, _ domain.MarketID, _ domain.PositionSide, price, _ float64, _ time.Time) (ports.OrderResult, error) {
+	s.placed = append(s.placed, price)
+	return ports.OrderResult{OrderID: fmt.Sprintf("o%d", len(s.placed))}, nil
+}
+
+func (s *stubLimitProvider) CancelOrder(_ context.Context, orderID string) error {
+	s.cancelled = append(s.cancelled, orderID)
+	return nil
+}
+
+func TestMakerService(t *testing.T) {
+	ctx := context.Background()
+	newMaker := func() (*MakerService, *OrderManager, *stubLimitProvider) {
+		orders, _, _ := newTestOrderManager()
+		provider := &stubLimitProvider{}
+		return NewMakerService(provider, orders, MakerConfig{}, quietLogger()), orders, provider
+	}
+
+	t.Run("place_tracks_resting_order", func(t *testing.T) {
+		m, orders, _ := newMaker()
+		if _, err := m.Place(ctx, buyUp(10, 0.5), time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		o, ok := m.Resting("m1", domain.PositionUp)
+		if !ok || !o.Resting || o.Price != 0.5 {
+			t.Fatalf("expected resting order, got %+v ok=%v", o, ok)
+		}
+		if upQty, _, upCost, _ := orders.Pending("m1"); upQty != 20 || upCost != 10 {
+			t.Errorf("expected 20 shares pending, got %v ($%v)", upQty, upCost)
+		}
+		if !m.OnTarget(o, 0.5) || m.OnTarget(o, 0.51) {
+			t.Error("expected on target only at the same tick")
+		}
+	})
+
+	t.Run("cancel_keeps_partial_fill_pending", func(t *testing.T) {
+		m, orders, provider := newMaker()
+		result, _ := m.Place(ctx, buyUp(10, 0.5), time.Time{})
+		orders.OnOrderUpdate(result.OrderID, ports.OrderState{Status: domain.OrderLive, SizeMatched: 4})
+
+		if err := m.CancelMarket(ctx, "m1"); err != nil {
+			t.Fatal(err)
+		}
+		if len(provider.cancelled) != 1 || provider.cancelled[0] != result.OrderID {
+			t.Errorf("expected %s cancelled, got %v", result.OrderID, provider.cancelled)
+		}
+		if _, ok := m.Resting("m1", domain.PositionUp); ok {
+			t.Error("expected no resting order after cancel")
+		}
+		if upQty, _, _, _ := orders.Pending("m1"); upQty != 4 {
+			t.Errorf("expected matched 4 shares still pending, got %v", upQty)
+		}
+
+		// Fill arrives after the cancel and completes the order
+		orders.OnFill(result.OrderID, 0.5, 4)
+		if upQty, _, _, _ := orders.Pending("m1"); upQty != 0 {
+			t.Errorf("expected nothing pending after fill, got %v", upQty)
+		}
+	})
+
+	t.Run("requote_keeps_on_target_and_replaces_off_target", func(t *testing.T) {
+		m, _, provider := newMaker()
+		sized := 0
+		size := func() float64 { sized++; return 10 }
+		req := buyUp(0, 0.5)
+
+		for i := 0; i < 2; i++ {
+			_, acted, err := m.Requote(ctx, req, time.Time{}, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if acted != (i == 0) {
+				t.Errorf("requote %d: expected acted=%v, got %v", i, i == 0, acted)
+			}
+		}
+		req.MaxPrice = 0.52
+		result, acted, err := m.Requote(ctx, req, time.Time{}, size)
+		if err != nil || !acted {
+			t.Fatalf("expected a replace, got acted=%v err=%v", acted, err)
+		}
+		if len(provider.placed) != 2 || provider.placed[1] != 0.52 || len(provider.cancelled) != 1 || sized != 2 {
+			t.Errorf("expected one replace, got placed %v cancelled %v sized %d", provider.placed, provider.cancelled, sized)
+		}
+		if o, ok := m.Resting("m1", domain.PositionUp); !ok || o.ID != result.OrderID {
+			t.Errorf("expected %s resting, got %+v", result.OrderID, o)
+		}
+
+		// No price pulls the quote without sizing a new one
+		req.MaxPrice = 0
+		if _, acted, err := m.Requote(ctx, req, time.Time{}, size); err != nil || !acted {
+			t.Fatalf("expected the pull to act, got acted=%v err=%v", acted, err)
+		}
+		if _, ok := m.Resting("m1", domain.PositionUp); ok || sized != 2 {
+			t.Errorf("expected quote pulled, sized %d", sized)
+		}
+	})
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// OrderManagerConfig sets how long submitted orders may stay unconfirmed.
+type OrderManagerConfig struct {
+	PollAfter    time.Duration // ask the exchange about orders unconfirmed this long (default: 5s)
+	PollInterval time.Duration // minimum gap between polls of one order (default: 2s)
+	CancelAfter  time.Duration // cancel orders still resting on the book this long (default: 15s)
+	ExpireAfter  time.Duration // stop counting orders unconfirmed this long (default: 2m)
+}
+
+// fillTolerance absorbs rounding between the submitted notional and the
+// booked fills.
+const fillTolerance = 0.01
+
+// keepTerminal is how long finished orders stay queryable.
+const keepTerminal = 10 * time.Minute
+
+// OrderManager tracks live submissions from the moment the exchange accepts
+// them until their fills are booked into positions. Orders that have not yet
+// filled count as pending inventory (Pending, PendingExposure), so risk
+// checks see them before the fill listener updates positions.
+//
+// State arrives from the user channel (OnFill, OnOrderUpdate) and, for orders
+// that stay unconfirmed past PollAfter, from polling the exchange. Orders
+// resting past CancelAfter are cancelled; orders unconfirmed past ExpireAfter
+// are dropped with a warning. Maker orders (Resting) are exempt from both:
+// their owner cancels them, and they count as pending while live.
+type OrderManager struct {
+	config   OrderManagerConfig
+	provider ports.OrderStatusProvider // optional: nil disables polling and cancels
+	clock    ports.Clock
+	logger   *slog.Logger
+
+	mu         sync.Mutex
+	orders     map[string]*trackedOrder
+	earlyFills map[string]*earlyFill // fills that arrived before Track
+}
+
+type trackedOrder struct {
+	order    domain.Order
+	lastPoll time.Time
+}
+
+type earlyFill struct {
+	usd, shares float64
+	at          time.Time
+}
+
+func NewOrderManager(config OrderManagerConfig, clock ports.Clock, logger *slog.Logger) *OrderManager {
+	if config.PollAfter <= 0 {
+		config.PollAfter = 5 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.CancelAfter <= 0 {
+		config.CancelAfter = 15 * time.Second
+	}
+	if config.ExpireAfter <= 0 {
+		config.ExpireAfter = 2 * time.Minute
+	}
+	return &OrderManager{
+		config:     config,
+		clock:      clock,
+		logger:     logger,
+		orders:     make(map[string]*trackedOrder),
+		earlyFills: make(map[string]*earlyFill),
+	}
+}
+
+// SetStatusProvider enables polling and cancelling orders on the exchange.
+// Must be called before Run.
+func (m *OrderManager) SetStatusProvider(provider ports.OrderStatusProvider) {
+	m.provider = provider
+}
+
+// Track records an accepted order. Fills the user channel delivered before
+// the submission returned are applied immediately.
+func (m *OrderManager) Track(req domain.ExecutionRequest, result ports.OrderResult) {
+	if result.OrderID == "" {
+		return
+	}
+	now := m.clock.Now()
+	o := domain.Order{
+		ID:          result.OrderID,
+		MarketID:    req.MarketID,
+		Side:        req.Side,
+		Price:       req.MaxPrice,
+		SizeUSD:     req.SizeUSD,
+		Resting:     req.Limit,
+		Status:      domain.OrderPending,
+		SubmittedAt: now,
+		UpdatedAt:   now,
+	}
+	if result.Filled {
+		o.Status = domain.OrderMatched
+		o.MatchedShares = result.Size
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.orders[o.ID]; ok {
+		return
+	}
+	t := &trackedOrder{order: o}
+	m.orders[o.ID] = t
+	if ef, ok := m.earlyFills[o.ID]; ok {
+		delete(m.earlyFills, o.ID)
+		m.applyFillLocked(t, ef.usd, ef.shares, now)
+	}
+	m.logger.Debug("order tracked",
+		"order_id", o.ID,
+		"market", o.MarketID,
+		"side", o.Side,
+		"price", o.Price,
+		"size_usd", o.SizeUSD,
+		"status", t.order.Status,
+	)
+}
+
+// OnFill books a confirmed fill against its order. Fills for orders not yet
+// tracked are held until Track or ExpireAfter.
+func (m *OrderManager) OnFill(orderID string, price, shares float64) {
+	if orderID == "" || shares <= 0 {
+		return
+	}
+	now := m.clock.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.orders[orderID]
+	if !ok {
+		ef := m.earlyFills[orderID]
+		if ef == nil {
+			ef = &earlyFill{}
+			m.earlyFills[orderID] = ef
+		}
+		ef.usd += price * shares
+		ef.shares += shares
+		ef.at = now
+		return
+	}
+	if t.order.Status.Terminal() {
+		return
+	}
+	m.applyFillLocked(t, price*shares, shares, now)
+}
+
+// OnOrderUpdate applies the exchange's view of an order.
+func (m *OrderManager) OnOrderUpdate(orderID string, state ports.OrderState) {
+	now := m.clock.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.orders[orderID]; ok {
+		m.applyStateLocked(t, state, now)
+	}
+}
+
+// Get returns a tracked order.
+func (m *OrderManager) Get(orderID string) (domain.Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.orders[orderID]
+	if !ok {
+		return domain.Order{}, false
+	}
+	return t.order, true
+}
+
+// Pending returns the shares and cost of a market's unconfirmed orders, in
+// the shape of PositionService.GetInventory.
+func (m *OrderManager) Pending(marketID domain.MarketID) (upQty, downQty, upCost, downCost float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.orders {
+		o := t.order
+		if o.MarketID != marketID {
+			continue
+		}
+		switch o.Side {
+		case domain.SignalBuyUp, domain.SignalHedgeUp:
+			upQty += o.PendingShares()
+			upCost += o.PendingUSD()
+		case domain.SignalBuyDown, domain.SignalHedgeDown:
+			downQty += o.PendingShares()
+			downCost += o.PendingUSD()
+		}
+	}
+	return
+}
+
+// PendingSells returns the shares of a market's unconfirmed sell orders.
+// Sells are left out of Pending and PendingExposure: they reduce exposure.
+func (m *OrderManager) PendingSells(marketID domain.MarketID) (upQty, downQty float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.orders {
+		o := t.order
+		if o.MarketID != marketID || o.Status.Terminal() || o.Price <= 0 {
+			continue
+		}
+		// Sells can fill above their limit, so count shares, not notional
+		shares := math.Max(o.SizeUSD/o.Price-o.FilledShares, 0)
+		switch o.Side {
+		case domain.SignalSellUp:
+			upQty += shares
+		case domain.SignalSellDown:
+			downQty += shares
+		}
+	}
+	return
+}
+
+// PendingExposure returns the notional of all unconfirmed buy orders.
+func (m *OrderManager) PendingExposure() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total float64
+	for _, t := range m.orders {
+		if t.order.Side.IsSell() {
+			continue
+		}
+		total += t.order.PendingUSD()
+	}
+	return total
+}
+
+// Run sweeps unconfirmed orders until ctx is cancelled.
+func (m *OrderManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep expires stale orders, polls the exchange for unconfirmed ones and
+// cancels orders left resting on the book.
+func (m *OrderManager) Sweep(ctx context.Context) {
+	now := m.clock.Now()
+	var poll []string
+
+	m.mu.Lock()
+	for id, t := range m.orders {
+		age := now.Sub(t.order.SubmittedAt)
+		switch {
+		case t.order.Status.Terminal():
+			if now.Sub(t.order.UpdatedAt) >= keepTerminal {
+				delete(m.orders, id)
+			}
+		case age >= m.config.ExpireAfter && !(t.order.Resting && t.order.Status == domain.OrderLive):
+			m.logger.Warn("order unconfirmed, no longer counted as pending",
+				"order_id", id,
+				"market", t.order.MarketID,
+				"status", t.order.Status,
+				"pending_usd", t.order.PendingUSD(),
+				"age", age.Round(time.Second),
+			)
+			m.setStatusLocked(t, domain.OrderExpired, now)
+		case m.provider != nil && age >= m.config.PollAfter && now.Sub(t.lastPoll) >= m.config.PollInterval:
+			t.lastPoll = now
+			poll = append(poll, id)
+		}
+	}
+	for id, ef := range m.earlyFills {
+		if now.Sub(ef.at) >= m.config.ExpireAfter {
+			delete(m.earlyFills, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range poll {
+		m.poll(ctx, id)
+	}
+}
+
+// poll refreshes one order from the exchange, cancelling it if it has been
+// resting too long.
+func (m *OrderManager) poll(ctx context.Context, orderID string) {
+	state, err := m.provider.GetOrderState(ctx, orderID)
+	if err != nil {
+		m.logger.Warn("order status lookup failed", "order_id", orderID, "error", err)
+		return
+	}
+	m.OnOrderUpdate(orderID, state)
+
+	if state.Status != domain.OrderLive {
+		return
+	}
+	o, ok := m.Get(orderID)
+	if !ok || o.Resting || m.clock.Now().Sub(o.SubmittedAt) < m.config.CancelAfter {
+		return
+	}
+	if err := m.provider.CancelOrder(ctx, orderID); err != nil {
+		m.logger.Warn("failed to cancel stale order", "order_id", orderID, "error", err)
+		return
+	}
+	m.logger.Info("cancelled stale order",
+		"order_id", orderID,
+		"market", o.MarketID,
+		"matched_shares", o.MatchedShares,
+	)
+	m.OnOrderUpdate(orderID, ports.OrderState{Status: domain.OrderCancelled, SizeMatched: o.MatchedShares})
+}
+
+func (m *OrderManager) applyFillLocked(t *trackedOrder, usd, shares float64, now time.Time) {
+	t.order.FilledUSD += usd
+	t.order.FilledShares += shares
+	t.order.UpdatedAt = now
+	m.completeLocked(t, now)
+}
+
+func (m *OrderManager) applyStateLocked(t *trackedOrder, state ports.OrderState, now time.Time) {
+	if t.order.Status.Terminal() {
+		return
+	}
+	if state.SizeMatched > t.order.MatchedShares {
+		t.order.MatchedShares = state.SizeMatched
+	}
+	switch state.Status {
+	case domain.OrderLive:
+		m.setStatusLocked(t, domain.OrderLive, now)
+	case domain.OrderMatched:
+		m.setStatusLocked(t, domain.OrderMatched, now)
+	case domain.OrderCancelled:
+		// Only matched shares can still arrive as fills
+		unbooked := t.order.MatchedShares - t.order.FilledShares
+		if unbooked <= fillTolerance*t.order.MatchedShares {
+			m.setStatusLocked(t, domain.OrderCancelled, now)
+			return
+		}
+		t.order.SizeUSD = t.order.FilledUSD + unbooked*t.order.Price
+		m.setStatusLocked(t, domain.OrderMatched, now)
+	}
+	m.completeLocked(t, now)
+}
+
+// completeLocked marks the order filled once its fills cover the submitted
+// notional (shares, for sells), or everything the exchange reports matched.
+func (m *OrderManager) completeLocked(t *trackedOrder, now time.Time) {
+	o := t.order
+	if o.Status.Terminal() {
+		return
+	}
+	coveredUSD := o.FilledUSD >= o.SizeUSD*(1-fillTolerance)
+	if o.Side.IsSell() && o.Price > 0 {
+		coveredUSD = o.FilledShares >= o.SizeUSD/o.Price*(1-fillTolerance)
+	}
+	coveredMatch := o.Status == domain.OrderMatched && o.MatchedShares > 0 &&
+		o.FilledShares >= o.MatchedShares*(1-fillTolerance)
+	if coveredUSD || coveredMatch {
+		m.setStatusLocked(t, domain.OrderFilled, now)
+	}
+}
+
+func (m *OrderManager) setStatusLocked(t *trackedOrder, status domain.OrderStatus, now time.Time) {
+	if t.order.Status == status {
+		return
+	}
+	m.logger.Debug("order status",
+		"order_id", t.order.ID,
+		"market", t.order.MarketID,
+		"from", t.order.Status,
+		"to", status,
+		"filled_usd", t.order.FilledUSD,
+	)
+	t.order.Status = status
+	t.order.UpdatedAt = now
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// stubOrderStatus is a scripted ports.OrderStatusProvider.
+type stubOrderStatus struct {
+	states    map[string]ports.OrderState
+	cancelled []string
+}
+
+func (s *stubOrderStatus) GetOrderState(_ context.Context, orderID string) (ports.OrderState, error) {
+	return s.states[orderID], nil
+}
+
+func (s *stubOrderStatus) CancelOrder(_ context.Context, orderID string) error {
+	s.cancelled = append(s.cancelled, orderID)
+	return nil
+}
+
+func newTestOrderManager() (*OrderManager, *fixedClock, *stubOrderStatus) {
+	clock := &fixedClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	status := &stubOrderStatus{states: make(map[string]ports.OrderState)}
+	m := NewOrderManager(OrderManagerConfig{
+		PollAfter:   5 * time.Second,
+		CancelAfter: 15 * time.Second,
+		ExpireAfter: time.Minute,
+	}, clock, quietLogger())
+	m.SetStatusProvider(status)
+	return m, clock, status
+}
+
+func buyUp(sizeUSD, price float64) domain.ExecutionRequest {
+	return domain.ExecutionRequest{MarketID: "m1", Side: domain.SignalBuyUp, MaxPrice: price, SizeUSD: sizeUSD}
+}
+
+func TestOrderManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pending_until_fill_booked", func(t *testing.T) {
+		m, _, _ := newTestOrderManager()
+		m.Track(buyUp(10, 0.5), ports.OrderResult{OrderID: "o1"})
+		m.Track(domain.ExecutionRequest{MarketID: "m1", Side: domain.SignalHedgeDown, MaxPrice: 0.4, SizeUSD: 4}, ports.OrderResult{OrderID: "o2"})
+
+		upQty, downQty, upCost, downCost := m.Pending("m1")
+		if upQty != 20 || upCost != 10 || downQty != 10 || downCost != 4 {
+			t.Errorf("unexpected pending inventory %v %v %v %v", upQty, downQty, upCost, downCost)
+		}
+		if got := m.PendingExposure(); got != 14 {
+			t.Errorf("expected pending exposure 14, got %v", got)
+		}
+
+		m.OnFill("o1", 0.48, 10)
+		if _, _, upCost, _ := m.Pending("m1"); math.Abs(upCost-5.2) > 1e-9 {
+			t.Errorf("expected 5.2 USD still pending after partial fill, got %v", upCost)
+		}
+		m.OnFill("o1", 0.5, 10.4)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderFilled {
+			t.Errorf("expected filled, got %s", o.Status)
+		}
+		if upQty, _, _, _ := m.Pending("m1"); upQty != 0 {
+			t.Errorf("expected no pending UP shares, got %v", upQty)
+		}
+	})
+
+	t.Run("fill_before_track_is_applied", func(t *testing.T) {
+		m, _, _ := newTestOrderManager()
+		m.OnFill("o1", 0.5, 19.6) // taker fee taken in shares
+		m.Track(buyUp(9.8, 0.5), ports.OrderResult{OrderID: "o1", Filled: true, Size: 19.6})
+		if o, _ := m.Get("o1"); o.Status != domain.OrderFilled {
+			t.Errorf("expected early fill to complete the order, got %+v", o)
+		}
+	})
+
+	t.Run("partial_fok_completes_on_matched_size", func(t *testing.T) {
+		m, _, _ := newTestOrderManager()
+		m.Track(buyUp(10, 0.5), ports.OrderResult{OrderID: "o1", Filled: true, Size: 8})
+		m.OnFill("o1", 0.5, 8)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderFilled {
+			t.Errorf("expected filled once matched shares are booked, got %+v", o)
+		}
+	})
+
+	t.Run("resting_order_polled_then_cancelled", func(t *testing.T) {
+		m, clock, status := newTestOrderManager()
+		m.Track(buyUp(10, 0.5), ports.OrderResult{OrderID: "o1"})
+		status.states["o1"] = ports.OrderState{Status: domain.OrderLive}
+
+		clock.now = clock.now.Add(2 * time.Second)
+		m.Sweep(ctx)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderPending {
+			t.Fatalf("expected no poll before PollAfter, got %s", o.Status)
+		}
+
+		clock.now = clock.now.Add(4 * time.Second)
+		m.Sweep(ctx)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderLive || len(status.cancelled) != 0 {
+			t.Fatalf("expected live and not cancelled, got %s %v", o.Status, status.cancelled)
+		}
+
+		clock.now = clock.now.Add(10 * time.Second)
+		m.Sweep(ctx)
+		if len(status.cancelled) != 1 || status.cancelled[0] != "o1" {
+			t.Fatalf("expected cancel, got %v", status.cancelled)
+		}
+		if o, _ := m.Get("o1"); o.Status != domain.OrderCancelled {
+			t.Errorf("expected cancelled, got %s", o.Status)
+		}
+		if m.PendingExposure() != 0 {
+			t.Errorf("expected nothing pending after cancel")
+		}
+	})
+
+	t.Run("cancelled_with_matched_shares_waits_for_their_fills", func(t *testing.T) {
+		m, _, _ := newTestOrderManager()
+		m.Track(buyUp(10, 0.5), ports.OrderResult{OrderID: "o1"})
+		m.OnOrderUpdate("o1", ports.OrderState{Status: domain.OrderCancelled, SizeMatched: 6})
+		if _, _, upCost, _ := m.Pending("m1"); upCost != 3 {
+			t.Errorf("expected only matched notional pending, got %v", upCost)
+		}
+		m.OnFill("o1", 0.5, 6)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderFilled {
+			t.Errorf("expected filled, got %s", o.Status)
+		}
+	})
+
+	t.Run("unconfirmed_order_expires", func(t *testing.T) {
+		m, clock, _ := newTestOrderManager()
+		m.Track(buyUp(10, 0.5), ports.OrderResult{OrderID: "o1", Filled: true})
+		clock.now = clock.now.Add(time.Minute)
+		m.Sweep(ctx)
+		if o, _ := m.Get("o1"); o.Status != domain.OrderExpired {
+			t.Errorf("expected expired, got %s", o.Status)
+		}
+		if m.PendingExposure() != 0 {
+			t.Errorf("expected expired order not counted")
+		}
+		// A late fill doesn't revive it
+		m.OnFill("o1", 0.5, 20)
+		if o, _ := m.Get("o1"); o.FilledShares != 0 {
+			t.Errorf("expected late fill ignored, got %+v", o)
+		}
+	})
+}
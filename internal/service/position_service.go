@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"sync"
 
@@ -49,18 +50,27 @@ func (s *PositionService) GetExposureForMarket(marketID domain.MarketID) float64
 	return total
 }
 
+// GetTotalExposure returns the capital at risk across all open markets, with
+// matched UP+DOWN pairs netted against their locked $1 payoff (see NetExposure).
+// Exposure is released when a window settles and its positions are closed.
 func (s *PositionService) GetTotalExposure() float64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	var total float64
 	for _, sides := range s.positions {
-		for _, p := range sides {
-			total += p.NotionalUSD
-		}
+		up, down := sides[domain.PositionUp], sides[domain.PositionDown]
+		total += NetExposure(up.Quantity, down.Quantity, up.NotionalUSD, down.NotionalUSD)
 	}
 	return total
 }
 
+// NetExposure is the worst-case loss of a market's inventory: total cost minus
+// the $1 each matched UP+DOWN pair is guaranteed to pay. Pairs bought below $1
+// offset unmatched cost, but exposure never goes negative.
+func NetExposure(upQty, downQty, upCost, downCost float64) float64 {
+	return math.Max(0, upCost+downCost-math.Min(upQty, downQty))
+}
+
 // ImbalancePenaltyConfig holds the exponential penalty parameters.
 type ImbalancePenaltyConfig struct {
 	Alpha float64 // base penalty scale (default 0.005)
@@ -76,9 +86,12 @@ func (s *PositionService) GetInventoryPenalties(marketID domain.MarketID, cfg Im
 	if len(sides) == 0 {
 		return 0, 0
 	}
+	return ImbalancePenalties(sides[domain.PositionUp].Quantity, sides[domain.PositionDown].Quantity, cfg)
+}
 
-	upQty := sides[domain.PositionUp].Quantity
-	downQty := sides[domain.PositionDown].Quantity
+// ImbalancePenalties returns the per-side penalties for an inventory of upQty
+// and downQty shares.
+func ImbalancePenalties(upQty, downQty float64, cfg ImbalancePenaltyConfig) (penaltyBuyUp, penaltyBuyDown float64) {
 	imbalance := math.Abs(upQty - downQty)
 
 	penalty := cfg.Alpha * (math.Exp(cfg.Beta*imbalance) - 1)
@@ -110,10 +123,31 @@ func (s *PositionService) GetInventory(marketID domain.MarketID) (upQty, downQty
 	return
 }
 
+// GetPosition returns the position held on one side of a market.
+func (s *PositionService) GetPosition(marketID domain.MarketID, side domain.PositionSide) (domain.Position, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.positions[marketID][side]
+	return p, ok
+}
+
 // AccumulateFill adds a fill to the existing position for the given market and side.
 // If no position exists, it creates one. If a position exists, it updates the
 // weighted average entry price, adds quantity, and adds notional cost.
+// Sell fills are applied by reducePosition instead.
 func (s *PositionService) AccumulateFill(ctx context.Context, fill domain.Fill) error {
+	_, err := s.BookFill(ctx, fill)
+	return err
+}
+
+// BookFill is AccumulateFill returning the fill as booked. A sell is capped
+// at the shares held, so its SizeUSD may be smaller than the fill's, and is
+// zero when nothing was booked; ReverseFill of the returned fill undoes
+// exactly what was booked.
+func (s *PositionService) BookFill(ctx context.Context, fill domain.Fill) (domain.Fill, error) {
+	if fill.Side.IsSell() {
+		return s.reducePosition(ctx, fill, 1)
+	}
 	side := fillSideToPositionSide(fill.Side)
 
 	s.mu.Lock()
@@ -142,9 +176,80 @@ func (s *PositionService) AccumulateFill(ctx context.Context, fill domain.Fill)
 	s.positions[fill.MarketID][side] = existing
 	s.mu.Unlock()
 
+	return fill, s.repo.SavePosition(ctx, existing)
+}
+
+// ReverseFill undoes a fill as returned by BookFill, e.g. when its trade
+// failed on-chain. The position is removed once nothing is left.
+func (s *PositionService) ReverseFill(ctx context.Context, fill domain.Fill) error {
+	if fill.Side.IsSell() {
+		_, err := s.reducePosition(ctx, fill, -1)
+		return err
+	}
+	side := fillSideToPositionSide(fill.Side)
+
+	s.mu.Lock()
+	existing, ok := s.positions[fill.MarketID][side]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+
+	fillQty := fill.SizeUSD / fill.Price
+	newQty := existing.Quantity - fillQty
+	switch {
+	case newQty <= 1e-9 && existing.RealizedPnL == 0:
+		delete(s.positions[fill.MarketID], side)
+		s.mu.Unlock()
+		return s.repo.DeletePosition(ctx, fill.MarketID, side)
+	case newQty <= 1e-9:
+		// Keep the realized P&L of earlier sells for settlement
+		existing.Quantity = 0
+		existing.NotionalUSD = 0
+	default:
+		existing.AvgEntryPrice = (existing.AvgEntryPrice*existing.Quantity - fill.Price*fillQty) / newQty
+		existing.Quantity = newQty
+		existing.NotionalUSD = math.Max(0, existing.NotionalUSD-fill.SizeUSD)
+	}
+
+	s.positions[fill.MarketID][side] = existing
+	s.mu.Unlock()
+
 	return s.repo.SavePosition(ctx, existing)
 }
 
+// reducePosition applies a sell fill (sign 1) or undoes one (sign -1) and
+// returns the fill as applied. Sold shares leave at the average entry price,
+// which is unchanged, and the proceeds above that cost are realized P&L. A
+// sale is capped at the shares held; an undo restores the fill as given,
+// which must be the capped fill. A sold-out position is kept with zero
+// quantity so settlement still books its realized P&L.
+func (s *PositionService) reducePosition(ctx context.Context, fill domain.Fill, sign float64) (domain.Fill, error) {
+	side := fillSideToPositionSide(fill.Side)
+
+	s.mu.Lock()
+	existing, ok := s.positions[fill.MarketID][side]
+	if !ok {
+		s.mu.Unlock()
+		return domain.Fill{}, fmt.Errorf("sell of %s %s with no position", fill.MarketID, side)
+	}
+
+	shares := fill.SizeUSD / fill.Price
+	if sign > 0 && shares > existing.Quantity {
+		shares = existing.Quantity
+		fill.SizeUSD = shares * fill.Price
+	}
+	cost := shares * existing.AvgEntryPrice
+	existing.Quantity = math.Max(0, existing.Quantity-sign*shares)
+	existing.NotionalUSD = math.Max(0, existing.NotionalUSD-sign*cost)
+	existing.RealizedPnL += sign * (shares*fill.Price - cost)
+
+	s.positions[fill.MarketID][side] = existing
+	s.mu.Unlock()
+
+	return fill, s.repo.SavePosition(ctx, existing)
+}
+
 // RecordPosition overwrites the position for the given market and side.
 // Used for bootstrap loading and paper mode.
 func (s *PositionService) RecordPosition(ctx context.Context, p domain.Position) error {
@@ -158,9 +263,39 @@ func (s *PositionService) RecordPosition(ctx context.Context, p domain.Position)
 	return s.repo.SavePosition(ctx, p)
 }
 
+// RemovePosition drops the position held on one side of a market.
+func (s *PositionService) RemovePosition(ctx context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	s.mu.Lock()
+	delete(s.positions[marketID], side)
+	if len(s.positions[marketID]) == 0 {
+		delete(s.positions, marketID)
+	}
+	s.mu.Unlock()
+
+	return s.repo.DeletePosition(ctx, marketID, side)
+}
+
+// ClosePositions removes every position held on a market and returns what was
+// held. Used at settlement once the window has resolved.
+func (s *PositionService) ClosePositions(ctx context.Context, marketID domain.MarketID) ([]domain.Position, error) {
+	s.mu.Lock()
+	sides := s.positions[marketID]
+	delete(s.positions, marketID)
+	s.mu.Unlock()
+
+	closed := make([]domain.Position, 0, len(sides))
+	for side, p := range sides {
+		closed = append(closed, p)
+		if err := s.repo.DeletePosition(ctx, marketID, side); err != nil {
+			return closed, err
+		}
+	}
+	return closed, nil
+}
+
 func fillSideToPositionSide(side domain.TradeSignalSide) domain.PositionSide {
 	switch side {
-	case domain.SignalBuyUp, domain.SignalHedgeUp:
+	case domain.SignalBuyUp, domain.SignalHedgeUp, domain.SignalSellUp:
 		return domain.PositionUp
 	default:
 		return domain.PositionDown
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"Polybot/internal/domain"
+)
+
+func TestPositionService_Sell(t *testing.T) {
+	ctx := context.Background()
+	buy := domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.50, SizeUSD: 5, TradeID: "t1"}
+	sell := domain.Fill{MarketID: "m1", Side: domain.SignalSellUp, Price: 0.70, SizeUSD: 2.8, TradeID: "t2"}
+
+	t.Run("books_realized_pnl", func(t *testing.T) {
+		svc := NewPositionService(newMemPositionRepo())
+		_ = svc.AccumulateFill(ctx, buy)
+		if err := svc.AccumulateFill(ctx, sell); err != nil {
+			t.Fatal(err)
+		}
+		p, ok := svc.GetPosition("m1", domain.PositionUp)
+		if !ok {
+			t.Fatal("expected position")
+		}
+		// Sold 4 of 10 shares bought at 0.50 for 0.70: +0.80
+		if math.Abs(p.Quantity-6) > 1e-9 || math.Abs(p.NotionalUSD-3) > 1e-9 || math.Abs(p.RealizedPnL-0.8) > 1e-9 {
+			t.Errorf("unexpected position %+v", p)
+		}
+		if p.AvgEntryPrice != 0.50 {
+			t.Errorf("expected avg entry unchanged, got %f", p.AvgEntryPrice)
+		}
+	})
+
+	t.Run("sold_out_position_keeps_pnl", func(t *testing.T) {
+		svc := NewPositionService(newMemPositionRepo())
+		_ = svc.AccumulateFill(ctx, buy)
+		_ = svc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalSellUp, Price: 0.40, SizeUSD: 4})
+
+		p, ok := svc.GetPosition("m1", domain.PositionUp)
+		if !ok || p.Quantity != 0 || math.Abs(p.RealizedPnL+1) > 1e-9 {
+			t.Errorf("unexpected position %+v, %v", p, ok)
+		}
+		if exp := svc.GetExposureForMarket("m1"); exp != 0 {
+			t.Errorf("expected no exposure, got %f", exp)
+		}
+	})
+
+	t.Run("sell_without_position_fails", func(t *testing.T) {
+		svc := NewPositionService(newMemPositionRepo())
+		if err := svc.AccumulateFill(ctx, sell); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("reverse_restores_shares", func(t *testing.T) {
+		svc := NewPositionService(newMemPositionRepo())
+		_ = svc.AccumulateFill(ctx, buy)
+		_ = svc.AccumulateFill(ctx, sell)
+		if err := svc.ReverseFill(ctx, sell); err != nil {
+			t.Fatal(err)
+		}
+		p, _ := svc.GetPosition("m1", domain.PositionUp)
+		if math.Abs(p.Quantity-10) > 1e-9 || math.Abs(p.NotionalUSD-5) > 1e-9 || math.Abs(p.RealizedPnL) > 1e-9 {
+			t.Errorf("unexpected position %+v", p)
+		}
+	})
+
+	t.Run("reverse_of_capped_sell_restores_booked_shares", func(t *testing.T) {
+		svc := NewPositionService(newMemPositionRepo())
+		_ = svc.AccumulateFill(ctx, buy)
+		// 15 shares sold against 10 held: only 10 are booked
+		oversold := domain.Fill{MarketID: "m1", Side: domain.SignalSellUp, Price: 0.60, SizeUSD: 9, TradeID: "t3"}
+		booked, err := svc.BookFill(ctx, oversold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(booked.SizeUSD-6) > 1e-9 {
+			t.Errorf("expected $6 of proceeds booked, got %f", booked.SizeUSD)
+		}
+		if err := svc.ReverseFill(ctx, booked); err != nil {
+			t.Fatal(err)
+		}
+		p, _ := svc.GetPosition("m1", domain.PositionUp)
+		if math.Abs(p.Quantity-10) > 1e-9 || math.Abs(p.NotionalUSD-5) > 1e-9 || math.Abs(p.RealizedPnL) > 1e-9 {
+			t.Errorf("expected the original 10 shares restored, got %+v", p)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"Polybot/internal/domain"
+)
+
+// QuoterConfig sets how wide and how skewed market-making bids are.
+type QuoterConfig struct {
+	BaseHalfSpread    float64 // distance of each bid below fair value, before widening (default: 0.02)
+	UncertaintyWeight float64 // half-spread added per unit of FairValue.ModelUncertainty (default: 1.0)
+	VolatileWiden     float64 // half-spread added in the volatile regime (default: 0.02)
+	TickSize          float64 // price increment (default: 0.01)
+}
+
+// Quoter prices two-sided bids around model fair value: an UP bid below
+// P(up) and a DOWN bid below 1 - P(up). Both filling completes a pair bought
+// for less than the $1 it pays, so the spread is the market maker's margin.
+//
+// The half-spread widens with model uncertainty and in the volatile regime;
+// nothing is quoted in the jump or unknown regimes. The heavy side's bid is
+// lowered by the inventory imbalance penalty, so the book fills toward
+// balanced pairs.
+type Quoter struct {
+	config    QuoterConfig
+	imbalance ImbalancePenaltyConfig
+}
+
+func NewQuoter(config QuoterConfig, imbalance ImbalancePenaltyConfig) *Quoter {
+	if config.BaseHalfSpread <= 0 {
+		config.BaseHalfSpread = 0.02
+	}
+	if config.UncertaintyWeight <= 0 {
+		config.UncertaintyWeight = 1.0
+	}
+	if config.VolatileWiden <= 0 {
+		config.VolatileWiden = 0.02
+	}
+	if config.TickSize <= 0 {
+		config.TickSize = 0.01
+	}
+	return &Quoter{config: config, imbalance: imbalance}
+}
+
+// HalfSpread returns the distance of each bid below fair value, before the
+// inventory skew. ok is false when the regime is not quotable.
+func (q *Quoter) HalfSpread(fv *domain.FairValue) (half float64, ok bool) {
+	half = q.config.BaseHalfSpread + q.config.UncertaintyWeight*fv.ModelUncertainty
+	switch fv.ModelRegime {
+	case "jump", "unknown":
+		return 0, false
+	case "volatile":
+		half += q.config.VolatileWiden
+	}
+	return half, true
+}
+
+// Quotes returns the UP and DOWN bids for an inventory of upQty and downQty
+// shares (including pending orders). A side's price is 0 when it should not
+// be quoted. Bids never reach the ask, so they always rest and pay no taker
+// fee.
+func (q *Quoter) Quotes(
+	fv *domain.FairValue,
+	quote *domain.MarketQuote,
+	upQty, downQty float64,
+) (up, down MakerQuote) {
+	half, ok := q.HalfSpread(fv)
+	if !ok {
+		return MakerQuote{}, MakerQuote{}
+	}
+	penaltyUp, penaltyDown := ImbalancePenalties(upQty, downQty, q.imbalance)
+
+	fairUp := fv.ProbUp
+	fairDown := 1.0 - fv.ProbUp
+	if price := RestingBidPrice(fairUp-half-penaltyUp, quote.Up.Ask, q.config.TickSize); price > 0 {
+		up = MakerQuote{Price: price, Edge: fairUp - price}
+	}
+	if price := RestingBidPrice(fairDown-half-penaltyDown, quote.Down.Ask, q.config.TickSize); price > 0 {
+		down = MakerQuote{Price: price, Edge: fairDown - price}
+	}
+	return up, down
+}
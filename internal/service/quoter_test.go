@@ -0,0 +1,63 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"Polybot/internal/domain"
+)
+
+func TestQuoter_Quotes(t *testing.T) {
+	quoter := NewQuoter(QuoterConfig{BaseHalfSpread: 0.02, UncertaintyWeight: 1, VolatileWiden: 0.03}, ImbalancePenaltyConfig{Alpha: 0.005, Beta: 0.15})
+	quote := domain.MarketQuote{
+		MarketID: "m1",
+		Up:       domain.SideQuote{Bid: 0.50, Ask: 0.62},
+		Down:     domain.SideQuote{Bid: 0.36, Ask: 0.48},
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	t.Run("bids_below_fair_value_on_both_sides", func(t *testing.T) {
+		fv := domain.FairValue{ProbUp: 0.60, ModelUncertainty: 0.01, ModelRegime: "normal"}
+		up, down := quoter.Quotes(&fv, &quote, 0, 0)
+		// half = 0.02 + 0.01 = 0.03, no taker cost: UP 0.60-0.03 = 0.57, DOWN 0.40-0.03 = 0.37
+		if !near(up.Price, 0.57) || !near(down.Price, 0.37) {
+			t.Errorf("expected 0.57/0.37, got %v/%v", up.Price, down.Price)
+		}
+		if up.Price+down.Price >= 1 {
+			t.Errorf("pair cost %v should be below $1", up.Price+down.Price)
+		}
+	})
+
+	t.Run("volatile_regime_widens", func(t *testing.T) {
+		fv := domain.FairValue{ProbUp: 0.60, ModelUncertainty: 0.01, ModelRegime: "volatile"}
+		up, down := quoter.Quotes(&fv, &quote, 0, 0)
+		if !near(up.Price, 0.54) || !near(down.Price, 0.34) {
+			t.Errorf("expected 0.54/0.34, got %v/%v", up.Price, down.Price)
+		}
+	})
+
+	t.Run("jump_regime_not_quoted", func(t *testing.T) {
+		fv := domain.FairValue{ProbUp: 0.60, ModelRegime: "jump"}
+		up, down := quoter.Quotes(&fv, &quote, 0, 0)
+		if up.Price != 0 || down.Price != 0 {
+			t.Errorf("expected no quotes, got %v/%v", up.Price, down.Price)
+		}
+	})
+
+	t.Run("heavy_side_skewed_down", func(t *testing.T) {
+		fv := domain.FairValue{ProbUp: 0.60, ModelRegime: "normal"}
+		up, down := quoter.Quotes(&fv, &quote, 20, 0)
+		// penalty = 0.005 * (e^3 - 1) ≈ 0.095 on UP only
+		if !near(up.Price, 0.48) || !near(down.Price, 0.38) {
+			t.Errorf("expected 0.48/0.38, got %v/%v", up.Price, down.Price)
+		}
+	})
+
+	t.Run("never_crosses_the_ask", func(t *testing.T) {
+		fv := domain.FairValue{ProbUp: 0.80, ModelRegime: "normal"}
+		up, _ := quoter.Quotes(&fv, &quote, 0, 0)
+		if !near(up.Price, 0.61) {
+			t.Errorf("expected bid one tick below the 0.62 ask, got %v", up.Price)
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// ReconcilerConfig sets how often and how strictly local positions are
+// checked against the venue.
+type ReconcilerConfig struct {
+	Interval      time.Duration // time between passes (default: 1m)
+	Tolerance     float64       // drift in shares ignored as rounding (default: 0.01)
+	MaxCorrection float64       // larger drift halts trading instead of being corrected (default: 5 shares)
+	TradeGrace    time.Duration // markets traded this recently are deferred, the venue may lag (default: 30s)
+}
+
+// Reconciler periodically compares PositionService inventory for every
+// market in the registry with the venue's positions.
+//
+// The first pass that sees a market adopts its venue positions (bootstrap).
+// After that, drift up to MaxCorrection is corrected to the venue and larger
+// drift trips the circuit breaker for review. Sides with recent venue trades
+// or pending orders are deferred to the next pass, since either view may be
+// in flight, as are tokens the venue didn't report at all. Every drift is
+// logged and persisted as a domain.ReconcileEvent.
+type Reconciler struct {
+	config      ReconcilerConfig
+	source      ports.PositionSource
+	registry    *MarketRegistry
+	positionSvc *PositionService
+	eventRepo   ports.EventRepository
+	clock       ports.Clock
+	logger      *slog.Logger
+
+	orders  *OrderManager   // optional: defer sides with pending orders
+	breaker *CircuitBreaker // optional: halt on large drift
+
+	bootstrapped map[domain.MarketID]bool // markets adopted from the venue
+	wake         chan struct{}
+}
+
+func NewReconciler(
+	config ReconcilerConfig,
+	source ports.PositionSource,
+	registry *MarketRegistry,
+	positionSvc *PositionService,
+	eventRepo ports.EventRepository,
+	clock ports.Clock,
+	logger *slog.Logger,
+) *Reconciler {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = 0.01
+	}
+	if config.MaxCorrection <= 0 {
+		config.MaxCorrection = 5
+	}
+	if config.TradeGrace <= 0 {
+		config.TradeGrace = 30 * time.Second
+	}
+	return &Reconciler{
+		config:      config,
+		source:      source,
+		registry:    registry,
+		positionSvc: positionSvc,
+		eventRepo:   eventRepo,
+		clock:       clock,
+		logger:      logger,
+
+		bootstrapped: make(map[domain.MarketID]bool),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// SetOrderManager defers sides with pending orders. Must be called before Run.
+func (r *Reconciler) SetOrderManager(orders *OrderManager) {
+	r.orders = orders
+}
+
+// SetBreaker halts trading on drift beyond MaxCorrection. Must be called
+// before Run.
+func (r *Reconciler) SetBreaker(breaker *CircuitBreaker) {
+	r.breaker = breaker
+}
+
+// Run reconciles until ctx is cancelled. Until a market has been
+// bootstrapped (the registry has no markets yet) it retries every few
+// seconds.
+func (r *Reconciler) Run(ctx context.Context) {
+	for {
+		if err := r.Reconcile(ctx); err != nil {
+			r.logger.Warn("reconcile: pass failed", "error", err)
+		}
+		wait := r.config.Interval
+		if len(r.bootstrapped) == 0 {
+			wait = 2 * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		case <-r.wake:
+		}
+	}
+}
+
+// Trigger makes Run start its next pass now, e.g. after the fill stream
+// reconnects and fills sent while it was down were missed. Safe to call
+// from any goroutine; triggers during a pass coalesce into one more pass.
+func (r *Reconciler) Trigger() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Reconcile runs one pass over every market in the registry.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	markets := r.registry.ListMarkets()
+	if len(markets) == 0 {
+		return nil
+	}
+	now := r.clock.Now()
+
+	marketIDs := make([]domain.MarketID, len(markets))
+	for i, m := range markets {
+		marketIDs[i] = m.ID
+	}
+	positions, err := r.source.GetPositions(ctx, marketIDs)
+	if err != nil {
+		return fmt.Errorf("get venue positions: %w", err)
+	}
+	venue := make(map[string]ports.VenuePosition, len(positions))
+	for _, p := range positions {
+		venue[p.TokenID] = p
+	}
+
+	recent := make(map[string]bool)
+	if len(r.bootstrapped) > 0 {
+		trades, err := r.source.GetRecentTrades(ctx, now.Add(-r.config.TradeGrace))
+		if err != nil {
+			return fmt.Errorf("get recent trades: %w", err)
+		}
+		for _, t := range trades {
+			recent[t.TokenID] = true
+		}
+	}
+
+	checked, drifted, adopted := 0, 0, 0
+	seen := make(map[domain.MarketID]bool, len(markets))
+	for _, m := range markets {
+		seen[m.ID] = true
+		bootstrap := !r.bootstrapped[m.ID]
+		for _, leg := range []struct {
+			side    domain.PositionSide
+			tokenID string
+		}{
+			{domain.PositionUp, m.UpTokenID},
+			{domain.PositionDown, m.DownTokenID},
+		} {
+			if leg.tokenID == "" {
+				continue
+			}
+			checked++
+			pos, reported := venue[leg.tokenID]
+			if r.reconcileSide(ctx, m.ID, leg.side, leg.tokenID, pos, reported, bootstrap, recent[leg.tokenID], now) {
+				drifted++
+			}
+		}
+		if bootstrap {
+			r.bootstrapped[m.ID] = true
+			adopted++
+		}
+	}
+	// Expired markets leave the registry for good
+	for id := range r.bootstrapped {
+		if !seen[id] {
+			delete(r.bootstrapped, id)
+		}
+	}
+
+	if adopted > 0 {
+		r.logger.Info("reconcile: bootstrapped positions from venue", "markets", adopted, "sides", checked, "drifted", drifted)
+	} else {
+		r.logger.Debug("reconcile: pass complete", "sides", checked, "drifted", drifted)
+	}
+	return nil
+}
+
+// reconcileSide checks one side of a market and reports whether it drifted.
+func (r *Reconciler) reconcileSide(
+	ctx context.Context,
+	marketID domain.MarketID,
+	side domain.PositionSide,
+	tokenID string,
+	venue ports.VenuePosition,
+	reported bool,
+	bootstrap bool,
+	recentTrade bool,
+	now time.Time,
+) bool {
+	local, _ := r.positionSvc.GetPosition(marketID, side)
+	drift := venue.Size - local.Quantity
+	if math.Abs(drift) <= r.config.Tolerance {
+		return false
+	}
+
+	event := domain.ReconcileEvent{
+		MarketID:  marketID,
+		Side:      side,
+		TokenID:   tokenID,
+		LocalQty:  local.Quantity,
+		VenueQty:  venue.Size,
+		Drift:     drift,
+		Timestamp: now,
+	}
+
+	switch {
+	case !reported:
+		// Absence is not a zero balance: the venue may lag or have failed
+		// to list the token
+		event.Action = domain.ReconcileDeferred
+		event.Detail = "not reported by venue"
+	case bootstrap:
+		event.Action = domain.ReconcileBootstrap
+		r.adopt(ctx, marketID, side, venue, now)
+	case recentTrade:
+		event.Action = domain.ReconcileDeferred
+		event.Detail = "recent venue trade"
+	case r.hasPending(marketID, side):
+		event.Action = domain.ReconcileDeferred
+		event.Detail = "pending order"
+	case math.Abs(drift) <= r.config.MaxCorrection:
+		event.Action = domain.ReconcileCorrected
+		r.adopt(ctx, marketID, side, venue, now)
+	default:
+		event.Action = domain.ReconcileHalted
+		event.Detail = fmt.Sprintf("drift %.2f shares exceeds %.2f", drift, r.config.MaxCorrection)
+		if r.breaker != nil {
+			r.breaker.Trip(TripPositionDrift, fmt.Sprintf("%s %s: %s", marketID, side, event.Detail))
+		}
+	}
+
+	attrs := []any{
+		"market", marketID,
+		"side", side,
+		"local_qty", event.LocalQty,
+		"venue_qty", event.VenueQty,
+		"drift", drift,
+		"action", event.Action,
+	}
+	if event.Action == domain.ReconcileHalted {
+		r.logger.Warn("reconcile: position drift", attrs...)
+	} else {
+		r.logger.Info("reconcile: position drift", attrs...)
+	}
+	if err := r.eventRepo.SaveReconcileEvent(ctx, event); err != nil {
+		r.logger.Error("reconcile: failed to persist event", "market", marketID, "error", err)
+	}
+	return true
+}
+
+// adopt sets the local position to the venue's. The local entry price is
+// kept when there is one, since it comes from actual fills.
+func (r *Reconciler) adopt(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, venue ports.VenuePosition, now time.Time) {
+	p, ok := r.positionSvc.GetPosition(marketID, side)
+	// A sold-out position stays for the realized P&L of its sells
+	if venue.Size <= r.config.Tolerance && (!ok || p.RealizedPnL == 0) {
+		if err := r.positionSvc.RemovePosition(ctx, marketID, side); err != nil {
+			r.logger.Error("reconcile: failed to remove position", "market", marketID, "side", side, "error", err)
+		}
+		return
+	}
+
+	if !ok {
+		p = domain.Position{MarketID: marketID, Side: side, OpenedAt: now, HoldToSettlement: true}
+	}
+	if p.AvgEntryPrice <= 0 {
+		p.AvgEntryPrice = venue.AvgPrice
+	}
+	if p.AvgEntryPrice <= 0 {
+		p.AvgEntryPrice = 0.5 // unknown cost basis
+	}
+	p.Quantity = venue.Size
+	p.NotionalUSD = p.AvgEntryPrice * venue.Size
+	if err := r.positionSvc.RecordPosition(ctx, p); err != nil {
+		r.logger.Error("reconcile: failed to record position", "market", marketID, "side", side, "error", err)
+	}
+}
+
+func (r *Reconciler) hasPending(marketID domain.MarketID, side domain.PositionSide) bool {
+	if r.orders == nil {
+		return false
+	}
+	upQty, downQty, _, _ := r.orders.Pending(marketID)
+	upSells, downSells := r.orders.PendingSells(marketID)
+	if side == domain.PositionUp {
+		return upQty > 0 || upSells > 0
+	}
+	return downQty > 0 || downSells > 0
+}
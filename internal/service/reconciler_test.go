@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// stubPositionSource serves fixed venue positions and trades.
+type stubPositionSource struct {
+	positions []ports.VenuePosition
+	trades    []ports.VenueTrade
+	markets   []domain.MarketID // markets of the last GetPositions
+	passes    chan struct{}     // optional: signalled on every GetPositions
+}
+
+func (s *stubPositionSource) GetPositions(_ context.Context, marketIDs []domain.MarketID) ([]ports.VenuePosition, error) {
+	s.markets = marketIDs
+	if s.passes != nil {
+		s.passes <- struct{}{}
+	}
+	return s.positions, nil
+}
+
+func (s *stubPositionSource) GetRecentTrades(context.Context, time.Time) ([]ports.VenueTrade, error) {
+	return s.trades, nil
+}
+
+func newTestReconciler(t *testing.T) (*Reconciler, *stubPositionSource, *PositionService, *memEventRepo) {
+	t.Helper()
+	r, source, posSvc, events, _ := newTestReconcilerWithRegistry(t)
+	return r, source, posSvc, events
+}
+
+func newTestReconcilerWithRegistry(t *testing.T) (*Reconciler, *stubPositionSource, *PositionService, *memEventRepo, *MarketRegistry) {
+	t.Helper()
+	clock := &fixedClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	registry := NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"})
+	posSvc := NewPositionService(newMemPositionRepo())
+	events := &memEventRepo{}
+	source := &stubPositionSource{}
+	r := NewReconciler(ReconcilerConfig{MaxCorrection: 5}, source, registry, posSvc, events, clock, quietLogger())
+	return r, source, posSvc, events, registry
+}
+
+func TestReconciler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trigger_runs_a_pass_now", func(t *testing.T) {
+		r, source, _, _ := newTestReconciler(t)
+		r.config.Interval = time.Hour
+		source.passes = make(chan struct{})
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go r.Run(runCtx)
+
+		<-source.passes
+		r.Trigger()
+		select {
+		case <-source.passes:
+		case <-time.After(time.Second):
+			t.Fatal("expected a pass right after the trigger")
+		}
+	})
+
+	t.Run("first_pass_adopts_venue", func(t *testing.T) {
+		r, source, posSvc, events := newTestReconciler(t)
+		source.positions = []ports.VenuePosition{{TokenID: "up", Size: 40, AvgPrice: 0.45}}
+		if err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+		p, ok := posSvc.GetPosition("m1", domain.PositionUp)
+		if !ok || p.Quantity != 40 || p.AvgEntryPrice != 0.45 || p.NotionalUSD != 18 {
+			t.Errorf("unexpected adopted position %+v", p)
+		}
+		got := events.reconcileEvents()
+		if len(got) != 1 || got[0].Action != domain.ReconcileBootstrap || got[0].Drift != 40 {
+			t.Errorf("unexpected events %+v", got)
+		}
+		if len(source.markets) != 1 || source.markets[0] != "m1" {
+			t.Errorf("expected positions requested for m1, got %v", source.markets)
+		}
+	})
+
+	t.Run("new_market_bootstrapped_on_first_sight", func(t *testing.T) {
+		r, source, posSvc, events, registry := newTestReconcilerWithRegistry(t)
+		_ = r.Reconcile(ctx)
+		registry.SetMarket(domain.BinaryMarket{ID: "m2", UpTokenID: "up2", DownTokenID: "down2"})
+		source.positions = []ports.VenuePosition{{TokenID: "up2", Size: 30, AvgPrice: 0.4}}
+		if err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := posSvc.GetPosition("m2", domain.PositionUp); !ok || p.Quantity != 30 {
+			t.Errorf("expected m2 adopted beyond MaxCorrection, got %+v", p)
+		}
+		if got := events.reconcileEvents(); got[len(got)-1].Action != domain.ReconcileBootstrap {
+			t.Errorf("expected bootstrap event, got %+v", got)
+		}
+	})
+
+	t.Run("small_drift_corrected_keeping_entry_price", func(t *testing.T) {
+		r, source, posSvc, events := newTestReconciler(t)
+		_ = r.Reconcile(ctx)
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 5})
+		source.positions = []ports.VenuePosition{{TokenID: "up", Size: 8, AvgPrice: 0.6}}
+		if err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+		p, _ := posSvc.GetPosition("m1", domain.PositionUp)
+		if p.Quantity != 8 || p.AvgEntryPrice != 0.5 || p.NotionalUSD != 4 {
+			t.Errorf("unexpected corrected position %+v", p)
+		}
+		if got := events.reconcileEvents(); got[len(got)-1].Action != domain.ReconcileCorrected {
+			t.Errorf("expected corrected event, got %+v", got)
+		}
+	})
+
+	t.Run("venue_flat_removes_position", func(t *testing.T) {
+		r, source, posSvc, _ := newTestReconciler(t)
+		_ = r.Reconcile(ctx)
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyDown, Price: 0.5, SizeUSD: 1})
+		source.positions = []ports.VenuePosition{{TokenID: "down", Size: 0}}
+		_ = r.Reconcile(ctx)
+		if _, ok := posSvc.GetPosition("m1", domain.PositionDown); ok {
+			t.Error("expected position removed")
+		}
+	})
+
+	t.Run("unreported_token_neither_corrected_nor_halted", func(t *testing.T) {
+		r, source, posSvc, events := newTestReconciler(t)
+		breaker := NewCircuitBreaker(BreakerConfig{}, events, &fixedClock{now: time.Now()}, quietLogger())
+		r.SetBreaker(breaker)
+		_ = r.Reconcile(ctx)
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 10})
+		source.positions = []ports.VenuePosition{{TokenID: "down", Size: 0}}
+		_ = r.Reconcile(ctx)
+
+		if upQty, _, _, _ := posSvc.GetInventory("m1"); upQty != 20 {
+			t.Errorf("expected local position kept, got %v", upQty)
+		}
+		if ok, _ := breaker.Allow(); !ok {
+			t.Error("expected no halt")
+		}
+		got := events.reconcileEvents()
+		if last := got[len(got)-1]; last.Action != domain.ReconcileDeferred || last.Side != domain.PositionUp {
+			t.Errorf("expected deferred up side, got %+v", got)
+		}
+	})
+
+	t.Run("recent_trade_or_pending_order_deferred", func(t *testing.T) {
+		r, source, posSvc, events := newTestReconciler(t)
+		_ = r.Reconcile(ctx)
+		source.positions = []ports.VenuePosition{{TokenID: "up", Size: 3}, {TokenID: "down", Size: 2}}
+		source.trades = []ports.VenueTrade{{TokenID: "up"}}
+		orders := NewOrderManager(OrderManagerConfig{}, fixedClock{time.Now()}, quietLogger())
+		orders.Track(domain.ExecutionRequest{MarketID: "m1", Side: domain.SignalBuyDown, MaxPrice: 0.5, SizeUSD: 1}, ports.OrderResult{OrderID: "o1"})
+		r.SetOrderManager(orders)
+
+		_ = r.Reconcile(ctx)
+		if upQty, downQty, _, _ := posSvc.GetInventory("m1"); upQty != 0 || downQty != 0 {
+			t.Errorf("expected no corrections, got %v %v", upQty, downQty)
+		}
+		for _, e := range events.reconcileEvents() {
+			if e.Action != domain.ReconcileDeferred {
+				t.Errorf("expected deferred, got %+v", e)
+			}
+		}
+	})
+
+	t.Run("large_drift_halts_trading", func(t *testing.T) {
+		r, source, posSvc, events := newTestReconciler(t)
+		breaker := NewCircuitBreaker(BreakerConfig{}, events, &fixedClock{now: time.Now()}, quietLogger())
+		r.SetBreaker(breaker)
+		_ = r.Reconcile(ctx)
+		source.positions = []ports.VenuePosition{{TokenID: "up", Size: 50}}
+		_ = r.Reconcile(ctx)
+
+		if upQty, _, _, _ := posSvc.GetInventory("m1"); upQty != 0 {
+			t.Errorf("expected large drift left uncorrected, got %v", upQty)
+		}
+		if ok, reason := breaker.Allow(); ok || reason != TripPositionDrift {
+			t.Errorf("expected position drift trip, got ok=%v reason=%q", ok, reason)
+		}
+		if got := events.reconcileEvents(); got[len(got)-1].Action != domain.ReconcileHalted {
+			t.Errorf("expected halted event, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// RedemptionConfig sets how settled positions are turned back into collateral.
+type RedemptionConfig struct {
+	Interval       time.Duration // time between checks for on-chain resolution (default: 1m)
+	GiveUpAfter    time.Duration // stop waiting for a condition to resolve after this long (default: 24h)
+	MinMergeShares float64       // fewer complete sets aren't worth the gas (default: 1)
+}
+
+// RedemptionService frees the collateral locked in settled windows. Right
+// after settlement it merges the matched UP+DOWN pairs, which needs no
+// resolution. The remaining tokens are queued and redeemed once the
+// condition's payouts are reported on-chain, typically minutes after the
+// window ends. Conditions still unresolved after GiveUpAfter are dropped with
+// a warning and left to redeem by hand. The queue is persisted through the
+// repository, so a restart picks up where it left off (see LoadFromRepo).
+//
+// Settlement has already booked the P&L; merging and redeeming only move
+// cash, so positions are not touched.
+type RedemptionService struct {
+	config RedemptionConfig
+	chain  ports.ChainBackend
+	repo   ports.RedemptionRepository
+	clock  ports.Clock
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending map[domain.MarketID]time.Time // condition -> when it was queued
+}
+
+func NewRedemptionService(config RedemptionConfig, chain ports.ChainBackend, repo ports.RedemptionRepository, clock ports.Clock, logger *slog.Logger) *RedemptionService {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.GiveUpAfter <= 0 {
+		config.GiveUpAfter = 24 * time.Hour
+	}
+	if config.MinMergeShares <= 0 {
+		config.MinMergeShares = 1
+	}
+	return &RedemptionService{
+		config:  config,
+		chain:   chain,
+		repo:    repo,
+		clock:   clock,
+		logger:  logger,
+		pending: make(map[domain.MarketID]time.Time),
+	}
+}
+
+// LoadFromRepo queues the conditions a previous run left pending. Their
+// original queue times are kept, so GiveUpAfter still counts from settlement.
+func (r *RedemptionService) LoadFromRepo(ctx context.Context) error {
+	pending, err := r.repo.ListPendingRedemptions(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range pending {
+		r.pending[p.MarketID] = p.QueuedAt
+	}
+	return nil
+}
+
+// OnSettlement merges a settled window's complete sets and queues whatever
+// is left for redemption. If the merge fails the pairs are redeemed instead.
+func (r *RedemptionService) OnSettlement(ctx context.Context, st domain.Settlement) {
+	pairs := math.Floor(math.Min(st.UpQuantity, st.DownQuantity)*100) / 100
+	merged := 0.0
+	if pairs >= r.config.MinMergeShares {
+		tx, err := r.chain.MergePositions(ctx, string(st.MarketID), pairs)
+		if err != nil {
+			r.logger.Error("redeem: merge failed, pairs left for redemption",
+				"market", st.MarketID, "slug", st.Slug, "pairs", pairs, "error", err)
+		} else {
+			merged = pairs
+			r.logger.Info("redeem: merged complete sets",
+				"market", st.MarketID, "slug", st.Slug, "pairs", pairs, "tx", tx)
+		}
+	}
+
+	if st.UpQuantity-merged <= 1e-6 && st.DownQuantity-merged <= 1e-6 {
+		return
+	}
+	r.queue(ctx, st.MarketID)
+}
+
+// OnSettlementFailed queues a window that could not be settled, so whatever
+// tokens it holds are still redeemed once the condition resolves. Pairs are
+// redeemed rather than merged: the held quantities aren't settled.
+func (r *RedemptionService) OnSettlementFailed(ctx context.Context, marketID domain.MarketID) {
+	r.queue(ctx, marketID)
+}
+
+// queue adds a condition to the persisted redemption queue, once.
+func (r *RedemptionService) queue(ctx context.Context, marketID domain.MarketID) {
+	queuedAt := r.clock.Now()
+	r.mu.Lock()
+	_, queued := r.pending[marketID]
+	if !queued {
+		r.pending[marketID] = queuedAt
+	}
+	r.mu.Unlock()
+	if queued {
+		return
+	}
+	if err := r.repo.SavePendingRedemption(ctx, domain.PendingRedemption{MarketID: marketID, QueuedAt: queuedAt}); err != nil {
+		r.logger.Error("redeem: failed to persist pending redemption", "market", marketID, "error", err)
+	}
+}
+
+// Pending returns the number of conditions waiting to be redeemed.
+func (r *RedemptionService) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Run redeems queued conditions until ctx is cancelled.
+func (r *RedemptionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep redeems every queued condition that has resolved and drops those
+// that have waited past GiveUpAfter.
+func (r *RedemptionService) Sweep(ctx context.Context) {
+	now := r.clock.Now()
+	r.mu.Lock()
+	queued := make(map[domain.MarketID]time.Time, len(r.pending))
+	for id, at := range r.pending {
+		queued[id] = at
+	}
+	r.mu.Unlock()
+
+	for id, at := range queued {
+		if ctx.Err() != nil {
+			return
+		}
+		resolved, err := r.chain.Resolved(ctx, string(id))
+		if err != nil {
+			r.logger.Warn("redeem: resolution check failed", "market", id, "error", err)
+			continue
+		}
+		if !resolved {
+			if now.Sub(at) >= r.config.GiveUpAfter {
+				r.logger.Warn("redeem: condition still unresolved, giving up",
+					"market", id, "waited", now.Sub(at).Round(time.Minute))
+				r.remove(ctx, id)
+			}
+			continue
+		}
+
+		tx, err := r.chain.RedeemPositions(ctx, string(id))
+		if err != nil {
+			r.logger.Error("redeem: redemption failed", "market", id, "error", err)
+			continue
+		}
+		r.logger.Info("redeem: redeemed resolved positions", "market", id, "tx", tx)
+		r.remove(ctx, id)
+	}
+}
+
+func (r *RedemptionService) remove(ctx context.Context, id domain.MarketID) {
+	r.mu.Lock()
+	delete(r.pending, id)
+	r.mu.Unlock()
+	if err := r.repo.DeletePendingRedemption(ctx, id); err != nil {
+		r.logger.Error("redeem: failed to delete pending redemption", "market", id, "error", err)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// stubChain is a ports.ChainBackend that records merges and redemptions.
+type stubChain struct {
+	mu       sync.Mutex
+	resolved map[string]bool
+	merged   map[string]float64
+	redeemed map[string]int
+	mergeErr error
+}
+
+func newStubChain() *stubChain {
+	return &stubChain{resolved: map[string]bool{}, merged: map[string]float64{}, redeemed: map[string]int{}}
+}
+
+func (c *stubChain) MergePositions(_ context.Context, conditionID string, amount float64) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mergeErr != nil {
+		return "", c.mergeErr
+	}
+	c.merged[conditionID] += amount
+	return "0xmerge", nil
+}
+
+func (c *stubChain) Resolved(_ context.Context, conditionID string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resolved[conditionID], nil
+}
+
+func (c *stubChain) RedeemPositions(_ context.Context, conditionID string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.redeemed[conditionID]++
+	return "0xredeem", nil
+}
+
+func (c *stubChain) resolve(conditionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolved[conditionID] = true
+}
+
+// memRedemptionRepo is an in-memory ports.RedemptionRepository.
+type memRedemptionRepo struct {
+	mu      sync.Mutex
+	pending map[domain.MarketID]domain.PendingRedemption
+}
+
+func newMemRedemptionRepo() *memRedemptionRepo {
+	return &memRedemptionRepo{pending: map[domain.MarketID]domain.PendingRedemption{}}
+}
+
+func (r *memRedemptionRepo) SavePendingRedemption(_ context.Context, p domain.PendingRedemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[p.MarketID] = p
+	return nil
+}
+
+func (r *memRedemptionRepo) ListPendingRedemptions(_ context.Context) ([]domain.PendingRedemption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domain.PendingRedemption, 0, len(r.pending))
+	for _, p := range r.pending {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (r *memRedemptionRepo) DeletePendingRedemption(_ context.Context, marketID domain.MarketID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, marketID)
+	return nil
+}
+
+func TestRedemptionService(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newService := func(repo *memRedemptionRepo) (*RedemptionService, *stubChain, *fixedClock) {
+		chain := newStubChain()
+		clock := &fixedClock{now: start}
+		return NewRedemptionService(RedemptionConfig{GiveUpAfter: time.Hour}, chain, repo, clock, quietLogger()), chain, clock
+	}
+
+	t.Run("merges_pairs_and_redeems_remainder_once_resolved", func(t *testing.T) {
+		repo := newMemRedemptionRepo()
+		svc, chain, _ := newService(repo)
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc1", UpQuantity: 30, DownQuantity: 20.5})
+		if got := chain.merged["0xc1"]; got != 20.5 {
+			t.Errorf("expected 20.5 pairs merged, got %f", got)
+		}
+		if svc.Pending() != 1 || len(repo.pending) != 1 {
+			t.Fatalf("expected remainder queued and persisted, got %d (%d stored)", svc.Pending(), len(repo.pending))
+		}
+
+		svc.Sweep(ctx)
+		if chain.redeemed["0xc1"] != 0 || svc.Pending() != 1 {
+			t.Error("expected no redemption before resolution")
+		}
+
+		chain.resolve("0xc1")
+		svc.Sweep(ctx)
+		if chain.redeemed["0xc1"] != 1 || svc.Pending() != 0 || len(repo.pending) != 0 {
+			t.Errorf("expected one redemption and an empty queue, got %d (pending %d, %d stored)",
+				chain.redeemed["0xc1"], svc.Pending(), len(repo.pending))
+		}
+	})
+
+	t.Run("fully_paired_needs_no_redemption", func(t *testing.T) {
+		svc, chain, _ := newService(newMemRedemptionRepo())
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc2", UpQuantity: 10, DownQuantity: 10})
+		if chain.merged["0xc2"] != 10 || svc.Pending() != 0 {
+			t.Errorf("expected 10 merged and nothing queued, got %f, %d", chain.merged["0xc2"], svc.Pending())
+		}
+	})
+
+	t.Run("one_sided_redeemed_without_merge", func(t *testing.T) {
+		svc, chain, _ := newService(newMemRedemptionRepo())
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc3", UpQuantity: 10})
+		if chain.merged["0xc3"] != 0 || svc.Pending() != 1 {
+			t.Errorf("expected no merge and a queued redemption, got %f, %d", chain.merged["0xc3"], svc.Pending())
+		}
+	})
+
+	t.Run("failed_merge_leaves_pairs_for_redemption", func(t *testing.T) {
+		svc, chain, _ := newService(newMemRedemptionRepo())
+		chain.mergeErr = errors.New("reverted")
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc5", UpQuantity: 10, DownQuantity: 10})
+		if svc.Pending() != 1 {
+			t.Errorf("expected the unmerged pairs queued, got %d", svc.Pending())
+		}
+	})
+
+	t.Run("failed_settlement_still_redeemed", func(t *testing.T) {
+		repo := newMemRedemptionRepo()
+		svc, chain, _ := newService(repo)
+		svc.OnSettlementFailed(ctx, "0xc8")
+		if svc.Pending() != 1 || len(repo.pending) != 1 {
+			t.Fatalf("expected the window queued and persisted, got %d (%d stored)", svc.Pending(), len(repo.pending))
+		}
+		// A later successful settlement doesn't queue it twice
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc8", UpQuantity: 10})
+		chain.resolve("0xc8")
+		svc.Sweep(ctx)
+		if chain.merged["0xc8"] != 0 || chain.redeemed["0xc8"] != 1 || svc.Pending() != 0 {
+			t.Errorf("expected one redemption, got merged %f redeemed %d pending %d",
+				chain.merged["0xc8"], chain.redeemed["0xc8"], svc.Pending())
+		}
+	})
+
+	t.Run("gives_up_on_unresolved_condition", func(t *testing.T) {
+		repo := newMemRedemptionRepo()
+		svc, chain, clock := newService(repo)
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc4", DownQuantity: 10})
+		clock.now = clock.now.Add(2 * time.Hour)
+		svc.Sweep(ctx)
+		if svc.Pending() != 0 || len(repo.pending) != 0 || chain.redeemed["0xc4"] != 0 {
+			t.Errorf("expected condition dropped, pending %d (%d stored)", svc.Pending(), len(repo.pending))
+		}
+	})
+
+	t.Run("queue_survives_restart", func(t *testing.T) {
+		repo := newMemRedemptionRepo()
+		svc, _, _ := newService(repo)
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc6", UpQuantity: 10})
+
+		restarted, chain, _ := newService(repo)
+		if err := restarted.LoadFromRepo(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if restarted.Pending() != 1 {
+			t.Fatalf("expected the queued condition restored, got %d", restarted.Pending())
+		}
+		chain.resolve("0xc6")
+		restarted.Sweep(ctx)
+		if chain.redeemed["0xc6"] != 1 {
+			t.Errorf("expected the restored condition redeemed, got %d", chain.redeemed["0xc6"])
+		}
+
+		// GiveUpAfter counts from the original settlement, not the restart
+		svc.OnSettlement(ctx, domain.Settlement{MarketID: "0xc7", UpQuantity: 10})
+		again, _, clock := newService(repo)
+		_ = again.LoadFromRepo(ctx)
+		clock.now = start.Add(2 * time.Hour)
+		again.Sweep(ctx)
+		if again.Pending() != 0 {
+			t.Errorf("expected the stale condition dropped, got %d", again.Pending())
+		}
+	})
+}
@@ -1,14 +1,19 @@
 package service
 
-import "math"
+import (
+	"math"
+
+	"Polybot/internal/domain"
+)
 
 type RiskConfig struct {
 	MaxPositionUSDPerMarket float64
-	MaxTotalExposureUSD     float64
+	MaxTotalExposureUSD     float64 // portfolio budget of net exposure across markets (0 = unlimited)
 	NoNewTradeCutoffSecs    float64
 	FractionalKelly         float64
 	MinTradeSizeUSD         float64
 	MinTradeShares          float64 // Polymarket minimum order size in shares (default: 5)
+	MaxSlippageEdgeFraction float64 // cap on the slippage past the best ask, as a fraction of the edge (0 = no cap)
 
 	// Inventory risk gates
 	MaxImbalanceShares float64 // hard block on abs(upQty - downQty) after trade
@@ -17,13 +22,64 @@ type RiskConfig struct {
 }
 
 type RiskService struct {
-	Config RiskConfig
+	Config  RiskConfig
+	Breaker *CircuitBreaker // optional: halts new trades when tripped
+	Wallet  *WalletService  // optional: caps sizes at the USDC available in the wallet
 }
 
 func NewRiskService(cfg RiskConfig) *RiskService {
 	return &RiskService{Config: cfg}
 }
 
+// TradingHalted reports whether the circuit breaker is blocking new trades,
+// and why.
+func (r *RiskService) TradingHalted() (bool, string) {
+	if r.Breaker == nil {
+		return false, ""
+	}
+	allowed, reason := r.Breaker.Allow()
+	return !allowed, reason
+}
+
+// SizeToBook fits a buy of sizeUSD, with the given per-share edge at the
+// best ask, to the asks of book. Filling past the best ask costs slippage,
+// the average fill price less the best ask, so the size is capped where
+// slippage reaches MaxSlippageEdgeFraction of the edge, and at the depth of
+// the book. It returns the capped size with its average and worst fill
+// prices; a capped size under MinTradeSizeUSD is 0.
+func (r *RiskService) SizeToBook(book *domain.OrderBook, sizeUSD, edge float64) (capped, avgPrice, limitPrice float64) {
+	best, ok := book.Best(domain.BookAsk)
+	if !ok || sizeUSD <= 0 {
+		return 0, 0, 0
+	}
+	if frac := r.Config.MaxSlippageEdgeFraction; frac > 0 {
+		sizeUSD = math.Min(sizeUSD, book.MaxBuyAtAvg(best.Price+frac*math.Max(edge, 0)))
+	}
+	shares, limitPrice, filled := book.BuyWithUSD(sizeUSD)
+	if !filled {
+		// The book holds less: take all of it
+		sizeUSD, _ = book.BuyCost(shares)
+	}
+	if shares <= 0 || sizeUSD < r.Config.MinTradeSizeUSD {
+		return 0, 0, 0
+	}
+	return sizeUSD, sizeUSD / shares, limitPrice
+}
+
+// RecordExecution feeds an order submission outcome to the circuit breaker.
+func (r *RiskService) RecordExecution(err error) {
+	if r.Breaker != nil {
+		r.Breaker.RecordExecution(err)
+	}
+}
+
+// UpdateMark feeds a market's unrealized P&L to the circuit breaker.
+func (r *RiskService) UpdateMark(marketID domain.MarketID, unrealizedPnL float64) {
+	if r.Breaker != nil {
+		r.Breaker.UpdateMark(marketID, unrealizedPnL)
+	}
+}
+
 func (r *RiskService) ShouldAllowNewTrade(remainingSeconds float64) bool {
 	return remainingSeconds > r.Config.NoNewTradeCutoffSecs
 }
@@ -78,15 +134,46 @@ func (r *RiskService) PreTradeCheck(
 	return ""
 }
 
+// PortfolioCheck rejects a trade that would lift portfolio net exposure above
+// MaxTotalExposureUSD. Trades that reduce exposure (completing UP+DOWN pairs
+// below $1) are always allowed. Returns "" if allowed, or a rejection reason.
+func (r *RiskService) PortfolioCheck(
+	portfolioExposureUSD float64,
+	buyingUp bool,
+	shares float64,
+	price float64,
+	upQty, downQty, upCost, downCost float64,
+) string {
+	if r.Config.MaxTotalExposureUSD <= 0 {
+		return ""
+	}
+	before := NetExposure(upQty, downQty, upCost, downCost)
+	if buyingUp {
+		upQty += shares
+		upCost += shares * price
+	} else {
+		downQty += shares
+		downCost += shares * price
+	}
+	delta := NetExposure(upQty, downQty, upCost, downCost) - before
+	if delta > 0 && portfolioExposureUSD+delta > r.Config.MaxTotalExposureUSD+1e-9 {
+		return "portfolio_exposure_exceeded"
+	}
+	return ""
+}
+
 // ComputeTargetSizeUSD computes the trade size in USD given edge, bankroll,
 // current exposure, and the price per share. The result is snapped to whole
 // shares and enforces both MinTradeSizeUSD and MinTradeShares constraints.
+// portfolioExposureUSD is the net exposure across all markets; the size is
+// capped at what remains of MaxTotalExposureUSD.
 // imbalanceRatio is abs(upQty-downQty)/max(upQty+downQty,1) — used for Kelly decay on same-side trades.
 // increasingImbalance is true if this trade adds to the heavy side.
 func (r *RiskService) ComputeTargetSizeUSD(
 	edge float64,
 	bankrollUSD float64,
 	currentMarketExposureUSD float64,
+	portfolioExposureUSD float64,
 	pricePerShare float64,
 	imbalanceRatio float64,
 	increasingImbalance bool,
@@ -109,6 +196,16 @@ func (r *RiskService) ComputeTargetSizeUSD(
 	if size > maxRemaining {
 		size = maxRemaining
 	}
+	if r.Config.MaxTotalExposureUSD > 0 {
+		if budget := r.Config.MaxTotalExposureUSD - portfolioExposureUSD; size > budget {
+			size = budget
+		}
+	}
+	if r.Wallet != nil {
+		if available, ok := r.Wallet.AvailableUSD(); ok && size > available {
+			size = available
+		}
+	}
 	if size < r.Config.MinTradeSizeUSD {
 		return 0
 	}
@@ -3,6 +3,8 @@ package service
 import (
 	"math"
 	"testing"
+
+	"Polybot/internal/domain"
 )
 
 func TestRiskService_ShouldAllowNewTrade(t *testing.T) {
@@ -45,7 +47,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 			MinTradeShares:          5,
 		})
 
-		size := svc.ComputeTargetSizeUSD(-0.05, 10000, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(-0.05, 10000, 0, 0, price, 0, false)
 		if size != 0 {
 			t.Errorf("expected 0 for negative edge, got %f", size)
 		}
@@ -59,7 +61,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 			MinTradeShares:          5,
 		})
 
-		size := svc.ComputeTargetSizeUSD(0, 10000, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0, 10000, 0, 0, price, 0, false)
 		if size != 0 {
 			t.Errorf("expected 0 for zero edge, got %f", size)
 		}
@@ -77,7 +79,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 		// Capped at maxRemaining = 100
 		// shares = floor(100 / 0.50) = 200
 		// result = 200 * 0.50 = 100
-		size := svc.ComputeTargetSizeUSD(0.10, 100000, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.10, 100000, 0, 0, price, 0, false)
 		if size != 100 {
 			t.Errorf("expected size capped at 100, got %f", size)
 		}
@@ -94,7 +96,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 		// maxRemaining = 100 - 80 = 20
 		// shares = floor(20 / 0.50) = 40
 		// result = 40 * 0.50 = 20
-		size := svc.ComputeTargetSizeUSD(0.10, 100000, 80, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.10, 100000, 80, 0, price, 0, false)
 		if size != 20 {
 			t.Errorf("expected size capped at 20, got %f", size)
 		}
@@ -109,7 +111,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 		})
 
 		// raw size = 100 * 0.25 * 0.001 * 10 = 0.25 < 50 min => 0
-		size := svc.ComputeTargetSizeUSD(0.001, 100, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.001, 100, 0, 0, price, 0, false)
 		if size != 0 {
 			t.Errorf("expected 0 when below min trade size, got %f", size)
 		}
@@ -125,7 +127,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 
 		// raw size = 100 * 0.25 * 0.001 * 10 = 0.25 USD
 		// shares = floor(0.25 / 0.50) = 0 < 5 min shares => 0
-		size := svc.ComputeTargetSizeUSD(0.001, 100, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.001, 100, 0, 0, price, 0, false)
 		if size != 0 {
 			t.Errorf("expected 0 when below min shares, got %f", size)
 		}
@@ -142,7 +144,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 		// raw size = 5000 * 0.25 * 0.05 * 10 = 625 USD
 		// shares = floor(625 / 0.50) = 1250
 		// result = 1250 * 0.50 = 625 (already whole shares at this price)
-		size := svc.ComputeTargetSizeUSD(0.05, 5000, 0, price, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.05, 5000, 0, 0, price, 0, false)
 		expected := 625.0
 		if size != expected {
 			t.Errorf("expected size=%f, got %f", expected, size)
@@ -152,7 +154,7 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 		// raw size = 625 USD, price = 0.30
 		// shares = floor(625 / 0.30) = floor(2083.33) = 2083
 		// result = 2083 * 0.30 = 624.9
-		size2 := svc.ComputeTargetSizeUSD(0.05, 5000, 0, 0.30, 0, false)
+		size2 := svc.ComputeTargetSizeUSD(0.05, 5000, 0, 0, 0.30, 0, false)
 		expectedShares := math.Floor(625.0 / 0.30)
 		expected2 := expectedShares * 0.30
 		if math.Abs(size2-expected2) > 0.001 {
@@ -168,9 +170,128 @@ func TestRiskService_ComputeTargetSizeUSD(t *testing.T) {
 			MinTradeShares:          5,
 		})
 
-		size := svc.ComputeTargetSizeUSD(0.05, 10000, 0, 0, 0, false)
+		size := svc.ComputeTargetSizeUSD(0.05, 10000, 0, 0, 0, 0, false)
 		if size != 0 {
 			t.Errorf("expected 0 for zero price, got %f", size)
 		}
 	})
+
+	t.Run("capped_by_portfolio_budget", func(t *testing.T) {
+		svc := NewRiskService(RiskConfig{
+			MaxPositionUSDPerMarket: 1000,
+			MaxTotalExposureUSD:     200,
+			FractionalKelly:         0.25,
+			MinTradeSizeUSD:         1.0,
+			MinTradeShares:          5,
+		})
+
+		// raw size = 625 USD, budget = 200 - 170 = 30 USD
+		size := svc.ComputeTargetSizeUSD(0.05, 5000, 0, 170, price, 0, false)
+		if size != 30 {
+			t.Errorf("expected size capped at remaining budget 30, got %f", size)
+		}
+
+		if size := svc.ComputeTargetSizeUSD(0.05, 5000, 0, 200, price, 0, false); size != 0 {
+			t.Errorf("expected 0 with budget exhausted, got %f", size)
+		}
+	})
+}
+
+func TestRiskService_SizeToBook(t *testing.T) {
+	book := domain.NewOrderBook("up", nil, []domain.PriceLevel{
+		{Price: 0.50, Size: 10},  // $5
+		{Price: 0.55, Size: 20},  // $11
+		{Price: 0.60, Size: 100}, // $60
+	})
+	svc := NewRiskService(RiskConfig{MinTradeSizeUSD: 1, MaxSlippageEdgeFraction: 0.5})
+
+	t.Run("caps_slippage_at_edge_fraction", func(t *testing.T) {
+		// Edge 0.10 allows an average of 0.55: all of 0.50 and 0.55, and 10
+		// shares at 0.60
+		size, avg, limit := svc.SizeToBook(book, 50, 0.10)
+		if math.Abs(size-22) > 1e-9 || math.Abs(avg-0.55) > 1e-9 || limit != 0.60 {
+			t.Errorf("expected $22 at avg 0.55 up to 0.60, got $%f at %f up to %f", size, avg, limit)
+		}
+	})
+
+	t.Run("small_size_fills_at_best_ask", func(t *testing.T) {
+		size, avg, limit := svc.SizeToBook(book, 3, 0.10)
+		if size != 3 || avg != 0.50 || limit != 0.50 {
+			t.Errorf("expected $3 at 0.50, got $%f at %f up to %f", size, avg, limit)
+		}
+	})
+
+	t.Run("no_edge_stays_at_best_ask", func(t *testing.T) {
+		if size, _, _ := svc.SizeToBook(book, 50, 0); math.Abs(size-5) > 1e-9 {
+			t.Errorf("expected $5, got $%f", size)
+		}
+	})
+
+	t.Run("uncapped_takes_whole_book", func(t *testing.T) {
+		uncapped := NewRiskService(RiskConfig{MinTradeSizeUSD: 1})
+		size, avg, limit := uncapped.SizeToBook(book, 200, 0.10)
+		if math.Abs(size-76) > 1e-9 || math.Abs(avg-76.0/130) > 1e-9 || limit != 0.60 {
+			t.Errorf("expected the whole $76 book, got $%f at %f up to %f", size, avg, limit)
+		}
+	})
+
+	t.Run("returns_zero_below_min_trade_size", func(t *testing.T) {
+		strict := NewRiskService(RiskConfig{MinTradeSizeUSD: 10, MaxSlippageEdgeFraction: 0.5})
+		if size, _, _ := strict.SizeToBook(book, 50, 0); size != 0 {
+			t.Errorf("expected 0, got $%f", size)
+		}
+		if size, _, _ := svc.SizeToBook(domain.NewOrderBook("up", nil, nil), 50, 0.10); size != 0 {
+			t.Errorf("expected 0 on an empty book, got $%f", size)
+		}
+	})
+}
+
+func TestRiskService_PortfolioCheck(t *testing.T) {
+	svc := NewRiskService(RiskConfig{MaxTotalExposureUSD: 100})
+
+	t.Run("rejects_trade_exceeding_budget", func(t *testing.T) {
+		// Flat market: 40 shares @ 0.50 adds $20 to a $90 portfolio
+		if reason := svc.PortfolioCheck(90, true, 40, 0.50, 0, 0, 0, 0); reason != "portfolio_exposure_exceeded" {
+			t.Errorf("expected portfolio_exposure_exceeded, got %q", reason)
+		}
+		if reason := svc.PortfolioCheck(80, true, 40, 0.50, 0, 0, 0, 0); reason != "" {
+			t.Errorf("expected trade within budget allowed, got %q", reason)
+		}
+	})
+
+	t.Run("allows_pair_completing_trade_at_full_budget", func(t *testing.T) {
+		// Holding 20 UP @ 0.60 ($12 at risk). Buying 20 DOWN @ 0.35 locks
+		// 20 pairs for $19 against a $20 payoff: exposure drops to 0.
+		if reason := svc.PortfolioCheck(100, false, 20, 0.35, 20, 0, 12, 0); reason != "" {
+			t.Errorf("expected exposure-reducing trade allowed, got %q", reason)
+		}
+	})
+
+	t.Run("unlimited_when_unset", func(t *testing.T) {
+		unlimited := NewRiskService(RiskConfig{})
+		if reason := unlimited.PortfolioCheck(1e6, true, 1000, 0.5, 0, 0, 0, 0); reason != "" {
+			t.Errorf("expected no budget check, got %q", reason)
+		}
+	})
+}
+
+func TestNetExposure(t *testing.T) {
+	t.Run("unmatched_inventory_is_cost", func(t *testing.T) {
+		if got := NetExposure(10, 0, 6, 0); got != 6 {
+			t.Errorf("expected 6, got %f", got)
+		}
+	})
+
+	t.Run("matched_pairs_net_to_one_dollar", func(t *testing.T) {
+		// 10 pairs + 5 extra UP; cost 10*0.55 + 5*0.55 + 10*0.40 = 12.25, pairs pay 10
+		if got := NetExposure(15, 10, 8.25, 4); math.Abs(got-2.25) > 1e-9 {
+			t.Errorf("expected 2.25, got %f", got)
+		}
+	})
+
+	t.Run("locked_profit_floors_at_zero", func(t *testing.T) {
+		if got := NetExposure(10, 10, 4, 5); got != 0 {
+			t.Errorf("expected 0, got %f", got)
+		}
+	})
 }
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// SettlementConfig controls how expired windows are resolved.
+type SettlementConfig struct {
+	PriceRetries    int           // attempts to fetch the Chainlink report at EndTime
+	PriceRetryDelay time.Duration // delay between attempts (reports can lag EndTime by a few seconds)
+	ConfirmInterval time.Duration // poll interval for the venue's official outcome
+	ConfirmTimeout  time.Duration // stop polling the venue after this long
+}
+
+// SettlementSummary aggregates realized results across settled windows.
+type SettlementSummary struct {
+	Windows     int     // windows settled
+	Traded      int     // windows where we held a position
+	Wins        int     // traded windows with positive realized P&L
+	Losses      int     // traded windows with negative realized P&L
+	RealizedPnL float64 // sum of realized P&L
+}
+
+// SettlementListener is notified of every booked settlement. replaces is the
+// earlier settlement of the same window when the venue corrects it.
+type SettlementListener interface {
+	OnSettlement(st domain.Settlement, replaces *domain.Settlement)
+}
+
+// SettlementService resolves expired windows and books realized P&L.
+//
+// The outcome is determined from the Chainlink price at EndTime versus
+// PriceToBeat (UP wins on >=). Positions are closed immediately and a
+// provisional settlement is persisted. If an OutcomeResolver is configured the
+// venue's official outcome is polled in the background; a confirmed (or
+// corrected) settlement is then persisted and supersedes the provisional one.
+type SettlementService struct {
+	positionSvc *PositionService
+	refPrices   ports.ReferencePriceProvider
+	resolver    ports.OutcomeResolver // optional
+	eventRepo   ports.EventRepository
+	clock       ports.Clock
+	config      SettlementConfig
+	logger      *slog.Logger
+
+	listener SettlementListener // optional
+
+	mu      sync.Mutex
+	summary SettlementSummary
+}
+
+func NewSettlementService(
+	positionSvc *PositionService,
+	refPrices ports.ReferencePriceProvider,
+	resolver ports.OutcomeResolver,
+	eventRepo ports.EventRepository,
+	clock ports.Clock,
+	config SettlementConfig,
+	logger *slog.Logger,
+) *SettlementService {
+	if config.PriceRetries <= 0 {
+		config.PriceRetries = 1
+	}
+	if config.ConfirmInterval <= 0 {
+		config.ConfirmInterval = 30 * time.Second
+	}
+	if config.ConfirmTimeout <= 0 {
+		config.ConfirmTimeout = 15 * time.Minute
+	}
+	return &SettlementService{
+		positionSvc: positionSvc,
+		refPrices:   refPrices,
+		resolver:    resolver,
+		eventRepo:   eventRepo,
+		clock:       clock,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// SetListener registers a listener for booked settlements.
+// Must be called before Settle.
+func (s *SettlementService) SetListener(l SettlementListener) {
+	s.listener = l
+}
+
+// Settle resolves an expired market, closes its positions and persists the
+// settlement. If the outcome cannot be determined the positions are left open
+// and an error is returned.
+func (s *SettlementService) Settle(ctx context.Context, market domain.BinaryMarket) (domain.Settlement, error) {
+	source := domain.SettlementSourceChainlink
+	confirmed := false
+
+	price, outcome, err := s.chainlinkOutcome(ctx, market)
+	if err != nil {
+		if s.resolver == nil {
+			return domain.Settlement{}, err
+		}
+		// No usable reference price — fall back to waiting for the venue.
+		s.logger.Warn("settlement: chainlink outcome unavailable, waiting for venue",
+			"market", market.ID, "slug", market.Slug, "error", err)
+		outcome, err = s.awaitOfficialOutcome(ctx, market)
+		if err != nil {
+			return domain.Settlement{}, err
+		}
+		source = domain.SettlementSourceVenue
+		confirmed = true
+	}
+
+	positions, err := s.positionSvc.ClosePositions(ctx, market.ID)
+	if err != nil {
+		s.logger.Error("settlement: failed to delete closed positions", "market", market.ID, "error", err)
+	}
+
+	settlement := BuildSettlement(market, outcome, price, positions, s.clock.Now())
+	settlement.Source = source
+	settlement.Confirmed = confirmed
+
+	s.book(ctx, settlement, nil)
+
+	if !confirmed && s.resolver != nil {
+		go s.confirm(ctx, market, positions, settlement)
+	}
+	return settlement, nil
+}
+
+// Summary returns the session totals across all settled windows.
+func (s *SettlementService) Summary() SettlementSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summary
+}
+
+// OutcomeFromPrices returns the up/down outcome for a window. Ties resolve UP.
+func OutcomeFromPrices(endPrice, priceToBeat float64) string {
+	if endPrice >= priceToBeat {
+		return domain.OutcomeUp
+	}
+	return domain.OutcomeDown
+}
+
+// BuildSettlement computes per-side realized P&L for the given outcome.
+// Each winning token pays $1; losing tokens pay $0. P&L is payout minus cost,
+// plus what the positions already realized by selling.
+func BuildSettlement(
+	market domain.BinaryMarket,
+	outcome string,
+	settlementPrice float64,
+	positions []domain.Position,
+	settledAt time.Time,
+) domain.Settlement {
+	st := domain.Settlement{
+		MarketID:        market.ID,
+		Slug:            market.Slug,
+		Asset:           market.Asset,
+		Outcome:         outcome,
+		PriceToBeat:     market.PriceToBeat,
+		SettlementPrice: settlementPrice,
+		SettledAt:       settledAt,
+	}
+
+	var upSold, downSold float64 // P&L realized by selling before settlement
+	for _, p := range positions {
+		switch p.Side {
+		case domain.PositionUp:
+			st.UpQuantity += p.Quantity
+			st.UpCost += p.NotionalUSD
+			upSold += p.RealizedPnL
+		case domain.PositionDown:
+			st.DownQuantity += p.Quantity
+			st.DownCost += p.NotionalUSD
+			downSold += p.RealizedPnL
+		}
+	}
+
+	st.UpPnL = upSold - st.UpCost
+	st.DownPnL = downSold - st.DownCost
+	if outcome == domain.OutcomeUp {
+		st.UpPnL += st.UpQuantity
+	} else {
+		st.DownPnL += st.DownQuantity
+	}
+	st.RealizedPnL = st.UpPnL + st.DownPnL
+	return st
+}
+
+func (s *SettlementService) chainlinkOutcome(ctx context.Context, market domain.BinaryMarket) (float64, string, error) {
+	if market.PriceToBeat <= 0 {
+		return 0, "", fmt.Errorf("market %s has no price to beat", market.ID)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < s.config.PriceRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, "", ctx.Err()
+			case <-time.After(s.config.PriceRetryDelay):
+			}
+		}
+		snap, err := s.refPrices.GetPriceAtTime(ctx, market.Asset, market.EndTime)
+		if err == nil && snap.Price > 0 {
+			return snap.Price, OutcomeFromPrices(snap.Price, market.PriceToBeat), nil
+		}
+		lastErr = err
+	}
+	return 0, "", fmt.Errorf("chainlink price at end for %s: %w", market.ID, lastErr)
+}
+
+// awaitOfficialOutcome polls the resolver until it reports a final outcome or
+// ConfirmTimeout elapses.
+func (s *SettlementService) awaitOfficialOutcome(ctx context.Context, market domain.BinaryMarket) (string, error) {
+	deadline := time.NewTimer(s.config.ConfirmTimeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(s.config.ConfirmInterval)
+	defer ticker.Stop()
+
+	for {
+		outcome, resolved, err := s.resolver.ResolvedOutcome(ctx, market)
+		if err != nil {
+			s.logger.Debug("settlement: venue outcome not available", "market", market.ID, "error", err)
+		} else if resolved {
+			return outcome, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline.C:
+			return "", fmt.Errorf("venue outcome for %s not published within %s", market.ID, s.config.ConfirmTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirm checks the provisional settlement against the venue's outcome and
+// re-books it if they disagree.
+func (s *SettlementService) confirm(ctx context.Context, market domain.BinaryMarket, positions []domain.Position, provisional domain.Settlement) {
+	outcome, err := s.awaitOfficialOutcome(ctx, market)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("settlement: could not confirm outcome with venue",
+				"market", market.ID, "slug", market.Slug, "error", err)
+		}
+		return
+	}
+
+	if outcome == provisional.Outcome {
+		confirmed := provisional
+		confirmed.Confirmed = true
+		if err := s.eventRepo.SaveSettlement(ctx, confirmed); err != nil {
+			s.logger.Error("settlement: failed to persist", "market", confirmed.MarketID, "error", err)
+		}
+		s.logger.Info("settlement confirmed", "market", market.ID, "slug", market.Slug, "outcome", outcome)
+		return
+	}
+
+	corrected := BuildSettlement(market, outcome, provisional.SettlementPrice, positions, s.clock.Now())
+	corrected.Source = domain.SettlementSourceVenue
+	corrected.Confirmed = true
+
+	s.logger.Warn("settlement outcome corrected by venue",
+		"market", market.ID,
+		"slug", market.Slug,
+		"chainlink_outcome", provisional.Outcome,
+		"venue_outcome", outcome,
+		"price_to_beat", market.PriceToBeat,
+		"settlement_price", provisional.SettlementPrice,
+	)
+	s.book(ctx, corrected, &provisional)
+}
+
+// book records a settlement in the session summary and persists it. If it
+// replaces an earlier settlement for the same window, the earlier one is
+// backed out of the summary first.
+func (s *SettlementService) book(ctx context.Context, st domain.Settlement, replaces *domain.Settlement) {
+	traded := st.UpQuantity > 0 || st.DownQuantity > 0
+
+	s.mu.Lock()
+	if replaces != nil {
+		s.summary.RealizedPnL -= replaces.RealizedPnL
+		if traded {
+			s.summary.Wins -= boolToInt(replaces.RealizedPnL > 0)
+			s.summary.Losses -= boolToInt(replaces.RealizedPnL < 0)
+		}
+	} else {
+		s.summary.Windows++
+		if traded {
+			s.summary.Traded++
+		}
+	}
+	s.summary.RealizedPnL += st.RealizedPnL
+	if traded {
+		s.summary.Wins += boolToInt(st.RealizedPnL > 0)
+		s.summary.Losses += boolToInt(st.RealizedPnL < 0)
+	}
+	summary := s.summary
+	s.mu.Unlock()
+
+	if err := s.eventRepo.SaveSettlement(ctx, st); err != nil {
+		s.logger.Error("settlement: failed to persist", "market", st.MarketID, "error", err)
+	}
+	if s.listener != nil {
+		s.listener.OnSettlement(st, replaces)
+	}
+
+	s.logger.Info("market settled",
+		"market", st.MarketID,
+		"slug", st.Slug,
+		"outcome", st.Outcome,
+		"source", st.Source,
+		"price_to_beat", st.PriceToBeat,
+		"settlement_price", st.SettlementPrice,
+		"up_qty", st.UpQuantity,
+		"down_qty", st.DownQuantity,
+		"up_pnl", st.UpPnL,
+		"down_pnl", st.DownPnL,
+		"realized_pnl", st.RealizedPnL,
+		"session_pnl", summary.RealizedPnL,
+		"session_windows", summary.Windows,
+		"session_wins", summary.Wins,
+		"session_losses", summary.Losses,
+	)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+)
+
+// memPositionRepo is a minimal in-package ports.PositionRepository.
+type memPositionRepo struct {
+	mu        sync.Mutex
+	positions map[string]domain.Position
+}
+
+func newMemPositionRepo() *memPositionRepo {
+	return &memPositionRepo{positions: make(map[string]domain.Position)}
+}
+
+func (r *memPositionRepo) key(marketID domain.MarketID, side domain.PositionSide) string {
+	return fmt.Sprintf("%s:%s", marketID, side)
+}
+
+func (r *memPositionRepo) SavePosition(_ context.Context, p domain.Position) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions[r.key(p.MarketID, p.Side)] = p
+	return nil
+}
+
+func (r *memPositionRepo) GetPosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) (domain.Position, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.positions[r.key(marketID, side)]
+	if !ok {
+		return domain.Position{}, errors.New("position not found")
+	}
+	return p, nil
+}
+
+func (r *memPositionRepo) ListOpenPositions(_ context.Context) ([]domain.Position, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domain.Position, 0, len(r.positions))
+	for _, p := range r.positions {
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (r *memPositionRepo) DeletePosition(_ context.Context, marketID domain.MarketID, side domain.PositionSide) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.positions, r.key(marketID, side))
+	return nil
+}
+
+// memEventRepo records settlements, breaker and reconcile events and discards
+// everything else.
+type memEventRepo struct {
+	mu          sync.Mutex
+	settlements []domain.Settlement
+	breaker     []domain.BreakerEvent
+	reconcile   []domain.ReconcileEvent
+}
+
+func (r *memEventRepo) SaveFairValue(context.Context, domain.FairValue) error { return nil }
+func (r *memEventRepo) SaveSignal(context.Context, domain.TradeSignal) error  { return nil }
+func (r *memEventRepo) SaveQuote(context.Context, domain.MarketQuote) error   { return nil }
+func (r *memEventRepo) SaveReferenceSnapshot(context.Context, domain.ReferenceSnapshot) error {
+	return nil
+}
+func (r *memEventRepo) SaveFill(context.Context, domain.Fill) error { return nil }
+
+func (r *memEventRepo) SaveSettlement(_ context.Context, s domain.Settlement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settlements = append(r.settlements, s)
+	return nil
+}
+
+func (r *memEventRepo) SaveBreakerEvent(_ context.Context, e domain.BreakerEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breaker = append(r.breaker, e)
+	return nil
+}
+
+func (r *memEventRepo) SaveReconcileEvent(_ context.Context, e domain.ReconcileEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconcile = append(r.reconcile, e)
+	return nil
+}
+
+func (r *memEventRepo) reconcileEvents() []domain.ReconcileEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.ReconcileEvent(nil), r.reconcile...)
+}
+
+func (r *memEventRepo) breakerEvents() []domain.BreakerEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.BreakerEvent(nil), r.breaker...)
+}
+
+func (r *memEventRepo) all() []domain.Settlement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.Settlement(nil), r.settlements...)
+}
+
+// mockRefPrices returns a fixed price for GetPriceAtTime.
+type mockRefPrices struct {
+	price float64
+	err   error
+}
+
+func (m *mockRefPrices) GetLatestPrice(_ context.Context, asset string) (domain.ReferenceSnapshot, error) {
+	return domain.ReferenceSnapshot{Asset: asset, Price: m.price}, m.err
+}
+
+func (m *mockRefPrices) GetPriceAtTime(_ context.Context, asset string, ts time.Time) (domain.ReferenceSnapshot, error) {
+	return domain.ReferenceSnapshot{Asset: asset, Price: m.price, Timestamp: ts}, m.err
+}
+
+func (m *mockRefPrices) SubscribePrices(context.Context, string) (<-chan domain.ReferenceSnapshot, error) {
+	return nil, errors.New("not supported")
+}
+
+// mockResolver reports a fixed venue outcome.
+type mockResolver struct {
+	outcome string
+}
+
+func (m *mockResolver) ResolvedOutcome(context.Context, domain.BinaryMarket) (string, bool, error) {
+	return m.outcome, m.outcome != "", nil
+}
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func TestBuildSettlement(t *testing.T) {
+	market := domain.BinaryMarket{ID: "m1", PriceToBeat: 100}
+	positions := []domain.Position{
+		{MarketID: "m1", Side: domain.PositionUp, Quantity: 20, NotionalUSD: 11},
+		{MarketID: "m1", Side: domain.PositionDown, Quantity: 10, NotionalUSD: 4},
+	}
+
+	t.Run("up_wins", func(t *testing.T) {
+		st := BuildSettlement(market, domain.OutcomeUp, 101, positions, time.Now())
+		// UP: 20 - 11 = 9, DOWN: -4
+		if st.UpPnL != 9 || st.DownPnL != -4 || st.RealizedPnL != 5 {
+			t.Errorf("unexpected pnl up=%f down=%f total=%f", st.UpPnL, st.DownPnL, st.RealizedPnL)
+		}
+	})
+
+	t.Run("down_wins", func(t *testing.T) {
+		st := BuildSettlement(market, domain.OutcomeDown, 99, positions, time.Now())
+		// UP: -11, DOWN: 10 - 4 = 6
+		if st.UpPnL != -11 || st.DownPnL != 6 || st.RealizedPnL != -5 {
+			t.Errorf("unexpected pnl up=%f down=%f total=%f", st.UpPnL, st.DownPnL, st.RealizedPnL)
+		}
+	})
+
+	t.Run("includes_realized_pnl_from_sells", func(t *testing.T) {
+		sold := []domain.Position{
+			{MarketID: "m1", Side: domain.PositionUp, Quantity: 10, NotionalUSD: 5, RealizedPnL: 1.5},
+			{MarketID: "m1", Side: domain.PositionDown, RealizedPnL: -0.5},
+		}
+		st := BuildSettlement(market, domain.OutcomeUp, 101, sold, time.Now())
+		// UP: 10 - 5 + 1.5 = 6.5, DOWN: -0.5
+		if st.UpPnL != 6.5 || st.DownPnL != -0.5 || st.RealizedPnL != 6 {
+			t.Errorf("unexpected pnl up=%f down=%f total=%f", st.UpPnL, st.DownPnL, st.RealizedPnL)
+		}
+	})
+
+	t.Run("tie_resolves_up", func(t *testing.T) {
+		if got := OutcomeFromPrices(100, 100); got != domain.OutcomeUp {
+			t.Errorf("expected up on tie, got %s", got)
+		}
+	})
+}
+
+func TestSettlementService_Settle(t *testing.T) {
+	ctx := context.Background()
+	market := domain.BinaryMarket{ID: "m1", Slug: "btc-updown-5m-1", Asset: "BTC", PriceToBeat: 100}
+
+	newService := func(ref *mockRefPrices, resolver *mockResolver) (*SettlementService, *PositionService, *memEventRepo) {
+		posSvc := NewPositionService(newMemPositionRepo())
+		events := &memEventRepo{}
+		cfg := SettlementConfig{ConfirmInterval: time.Millisecond, ConfirmTimeout: time.Second}
+		var svc *SettlementService
+		if resolver != nil {
+			svc = NewSettlementService(posSvc, ref, resolver, events, fixedClock{time.Now()}, cfg, quietLogger())
+		} else {
+			svc = NewSettlementService(posSvc, ref, nil, events, fixedClock{time.Now()}, cfg, quietLogger())
+		}
+		return svc, posSvc, events
+	}
+
+	t.Run("closes_positions_and_books_pnl", func(t *testing.T) {
+		svc, posSvc, events := newService(&mockRefPrices{price: 105}, nil)
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 5})
+
+		st, err := svc.Settle(ctx, market)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if st.Outcome != domain.OutcomeUp {
+			t.Errorf("expected up, got %s", st.Outcome)
+		}
+		// 10 shares paid $5 → +5
+		if math.Abs(st.RealizedPnL-5) > 1e-9 {
+			t.Errorf("expected pnl 5, got %f", st.RealizedPnL)
+		}
+		if upQty, downQty, _, _ := posSvc.GetInventory("m1"); upQty != 0 || downQty != 0 {
+			t.Errorf("expected positions closed, got up=%f down=%f", upQty, downQty)
+		}
+		if n := len(events.all()); n != 1 {
+			t.Errorf("expected 1 persisted settlement, got %d", n)
+		}
+		if sum := svc.Summary(); sum.Windows != 1 || sum.Wins != 1 || sum.RealizedPnL != st.RealizedPnL {
+			t.Errorf("unexpected summary %+v", sum)
+		}
+	})
+
+	t.Run("no_price_without_resolver_leaves_positions_open", func(t *testing.T) {
+		svc, posSvc, _ := newService(&mockRefPrices{err: errors.New("unavailable")}, nil)
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 5})
+
+		if _, err := svc.Settle(ctx, market); err == nil {
+			t.Fatal("expected error")
+		}
+		if upQty, _, _, _ := posSvc.GetInventory("m1"); upQty == 0 {
+			t.Error("expected position to remain open")
+		}
+	})
+
+	t.Run("venue_correction_rebooks_pnl", func(t *testing.T) {
+		svc, posSvc, events := newService(&mockRefPrices{price: 105}, &mockResolver{outcome: domain.OutcomeDown})
+		_ = posSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 5})
+
+		if _, err := svc.Settle(ctx, market); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for len(events.all()) < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		all := events.all()
+		if len(all) != 2 {
+			t.Fatalf("expected provisional + corrected settlement, got %d", len(all))
+		}
+		corrected := all[1]
+		if corrected.Outcome != domain.OutcomeDown || !corrected.Confirmed || corrected.Source != domain.SettlementSourceVenue {
+			t.Errorf("unexpected corrected settlement %+v", corrected)
+		}
+		sum := svc.Summary()
+		if sum.Windows != 1 || sum.Wins != 0 || sum.Losses != 1 || math.Abs(sum.RealizedPnL+5) > 1e-9 {
+			t.Errorf("unexpected summary after correction %+v", sum)
+		}
+	})
+}
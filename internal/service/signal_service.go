@@ -2,25 +2,29 @@ package service
 
 import (
 	"context"
-	"time"
+	"math"
 
 	"Polybot/internal/domain"
 	"Polybot/internal/ports"
 )
 
 type SignalConfig struct {
-	BaseHurdle float64
-	MaxSizeUSD float64
+	BaseHurdle  float64
+	MaxSizeUSD  float64
+	ExitEnabled bool    // sell held tokens early (Exit)
+	ExitHurdle  float64 // margin the bid must clear upper fair value by to sell
 }
 
 type SignalService struct {
 	CostModel ports.CostModel
+	Clock     ports.Clock
 	Config    SignalConfig
 }
 
-func NewSignalService(costModel ports.CostModel, cfg SignalConfig) *SignalService {
+func NewSignalService(costModel ports.CostModel, clock ports.Clock, cfg SignalConfig) *SignalService {
 	return &SignalService{
 		CostModel: costModel,
+		Clock:     clock,
 		Config:    cfg,
 	}
 }
@@ -31,7 +35,9 @@ func (s *SignalService) Generate(
 	quote *domain.MarketQuote,
 	penaltyBuyUp, penaltyBuyDown float64,
 ) (domain.TradeSignal, error) {
-	cost, err := s.CostModel.EstimateAllInCost(ctx, quote.MarketID)
+	// Fees only: the runner charges slippage at the order's size when it
+	// walks the book
+	costUp, costDown, err := s.CostModel.EstimateSideCosts(ctx, quote, 0)
 	if err != nil {
 		return domain.TradeSignal{}, err
 	}
@@ -39,14 +45,13 @@ func (s *SignalService) Generate(
 	hurdleUp := s.Config.BaseHurdle + penaltyBuyUp
 	hurdleDown := s.Config.BaseHurdle + penaltyBuyDown
 
-	edgeBuyUp := fv.ProbUpLower - quote.Up.Ask - cost
-	edgeBuyDown := (1.0 - fv.ProbUpUpper) - quote.Down.Ask - cost
+	edgeBuyUp, edgeBuyDown := DirectionalEdges(fv, quote, costUp, costDown)
 
 	signal := domain.TradeSignal{
 		MarketID:    quote.MarketID,
 		EdgeBuyUp:   edgeBuyUp,
 		EdgeBuyDown: edgeBuyDown,
-		Timestamp:   time.Now(),
+		Timestamp:   s.Clock.Now(),
 	}
 
 	// Compare each side's edge against its own hurdle
@@ -81,3 +86,127 @@ func (s *SignalService) Generate(
 
 	return signal, nil
 }
+
+// DirectionalEdges returns the edge of a taker buy of each side at its ask,
+// net of that side's cost, against the conservative end of the fair value
+// band.
+func DirectionalEdges(fv *domain.FairValue, quote *domain.MarketQuote, costUp, costDown float64) (edgeBuyUp, edgeBuyDown float64) {
+	edgeBuyUp = fv.ProbUpLower - quote.Up.Ask - costUp
+	edgeBuyDown = (1.0 - fv.ProbUpUpper) - quote.Down.Ask - costDown
+	return edgeBuyUp, edgeBuyDown
+}
+
+// Exit decides whether to sell held tokens before settlement. A side is sold
+// when its bid, net of cost, clears the side's upper fair value by ExitHurdle:
+// even the optimistic value is worth less than the market pays. Failing that,
+// the unpaired excess is sold when the directional signal has flipped to
+// buying the other side. upQty and downQty are booked shares.
+func (s *SignalService) Exit(
+	ctx context.Context,
+	fv *domain.FairValue,
+	quote *domain.MarketQuote,
+	dir *domain.TradeSignal,
+	upQty, downQty float64,
+) (domain.TradeSignal, error) {
+	signal := domain.TradeSignal{
+		MarketID:        quote.MarketID,
+		Side:            domain.SignalNone,
+		SignalType:      "exit",
+		EffectiveHurdle: s.Config.ExitHurdle,
+		Reason:          "no_exit",
+		Timestamp:       s.Clock.Now(),
+	}
+	if upQty <= 0 && downQty <= 0 {
+		return signal, nil
+	}
+
+	cost, err := s.CostModel.EstimateAllInCost(ctx, quote.MarketID)
+	if err != nil {
+		return domain.TradeSignal{}, err
+	}
+
+	exitEdgeUp := quote.Up.Bid - cost - fv.ProbUpUpper
+	exitEdgeDown := quote.Down.Bid - cost - (1.0 - fv.ProbUpLower)
+	upPass := upQty > 0 && quote.Up.Bid > 0 && exitEdgeUp >= s.Config.ExitHurdle
+	downPass := downQty > 0 && quote.Down.Bid > 0 && exitEdgeDown >= s.Config.ExitHurdle
+	flipped := dir != nil && dir.SignalType == "directional"
+
+	switch {
+	case upPass && (!downPass || exitEdgeUp >= exitEdgeDown):
+		signal.Side = domain.SignalSellUp
+		signal.ExitEdge = exitEdgeUp
+		signal.Reason = "sell_up_bid_exceeds_fair_value"
+	case downPass:
+		signal.Side = domain.SignalSellDown
+		signal.ExitEdge = exitEdgeDown
+		signal.Reason = "sell_down_bid_exceeds_fair_value"
+	case flipped && dir.Side == domain.SignalBuyDown && upQty > downQty && quote.Up.Bid > 0:
+		signal.Side = domain.SignalSellUp
+		signal.ExitEdge = exitEdgeUp
+		signal.Reason = "sell_up_edge_flipped"
+	case flipped && dir.Side == domain.SignalBuyUp && downQty > upQty && quote.Down.Bid > 0:
+		signal.Side = domain.SignalSellDown
+		signal.ExitEdge = exitEdgeDown
+		signal.Reason = "sell_down_edge_flipped"
+	}
+	return signal, nil
+}
+
+// MakerQuote is a resting buy price for one side, and its edge at that price.
+// Price is 0 when the side should not be quoted.
+type MakerQuote struct {
+	Price float64
+	Edge  float64
+}
+
+// MakerQuotes prices resting buys for both sides: one tick inside the bid when
+// the edge still clears the hurdle there, at the bid otherwise, and not at all
+// when even the bid doesn't clear it. Makers pay no taker fee and don't walk
+// the book, so no cost is charged.
+func (s *SignalService) MakerQuotes(
+	fv *domain.FairValue,
+	quote *domain.MarketQuote,
+	penaltyBuyUp, penaltyBuyDown float64,
+	tickSize float64,
+) (up, down MakerQuote) {
+	fairUp := fv.ProbUpLower
+	fairDown := 1.0 - fv.ProbUpUpper
+
+	if price := MakerPrice(quote.Up.Bid, quote.Up.Ask, fairUp-s.Config.BaseHurdle-penaltyBuyUp, tickSize); price > 0 {
+		up = MakerQuote{Price: price, Edge: fairUp - price}
+	}
+	if price := MakerPrice(quote.Down.Bid, quote.Down.Ask, fairDown-s.Config.BaseHurdle-penaltyBuyDown, tickSize); price > 0 {
+		down = MakerQuote{Price: price, Edge: fairDown - price}
+	}
+	return up, down
+}
+
+// MakerPrice returns the resting buy price for a book with the given best bid
+// and ask: one tick inside the bid if that is at most maxPrice and doesn't
+// reach the ask, else the bid if at most maxPrice, else 0.
+func MakerPrice(bid, ask, maxPrice, tickSize float64) float64 {
+	const eps = 1e-9
+	if tickSize <= 0 {
+		tickSize = 0.01
+	}
+	price := RestingBidPrice(math.Min(math.Min(maxPrice, bid+tickSize), 1-tickSize), ask, tickSize)
+	if price < bid-eps {
+		return 0
+	}
+	return price
+}
+
+// RestingBidPrice rounds target down to the tick and keeps it at least a
+// tick below the ask, so the bid rests instead of crossing. Returns 0 when no
+// valid price remains.
+func RestingBidPrice(target, ask, tickSize float64) float64 {
+	const eps = 1e-9
+	price := math.Floor(target/tickSize+eps) * tickSize
+	if ask > 0 && price > ask-tickSize+eps {
+		price = math.Round((ask-tickSize)/tickSize) * tickSize
+	}
+	if price < tickSize-eps || price >= 1 {
+		return 0
+	}
+	return price
+}
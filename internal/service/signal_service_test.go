@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/ports"
 )
 
 // mockCostModel is a test double for ports.CostModel.
@@ -18,12 +20,15 @@ func (m *mockCostModel) EstimateAllInCost(_ context.Context, _ domain.MarketID)
 	return m.cost, m.err
 }
 
+func (m *mockCostModel) EstimateSideCosts(_ context.Context, _ *domain.MarketQuote, _ float64) (float64, float64, error) {
+	return m.cost, m.cost, m.err
+}
+
 func TestSignalService_Generate(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("buy_up_signal_when_edge_exceeds_hurdle", func(t *testing.T) {
-		svc := NewSignalService(
-			&mockCostModel{cost: 0.01},
+		svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.02, MaxSizeUSD: 1000},
 		)
 
@@ -58,8 +63,7 @@ func TestSignalService_Generate(t *testing.T) {
 	})
 
 	t.Run("buy_down_signal_when_edge_exceeds_hurdle", func(t *testing.T) {
-		svc := NewSignalService(
-			&mockCostModel{cost: 0.01},
+		svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.02, MaxSizeUSD: 1000},
 		)
 
@@ -94,8 +98,7 @@ func TestSignalService_Generate(t *testing.T) {
 	})
 
 	t.Run("no_signal_when_no_edge", func(t *testing.T) {
-		svc := NewSignalService(
-			&mockCostModel{cost: 0.01},
+		svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.02, MaxSizeUSD: 1000},
 		)
 
@@ -127,8 +130,7 @@ func TestSignalService_Generate(t *testing.T) {
 	})
 
 	t.Run("hurdle_plus_inventory_penalty_blocks_marginal_trades", func(t *testing.T) {
-		svc := NewSignalService(
-			&mockCostModel{cost: 0.01},
+		svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.02, MaxSizeUSD: 1000},
 		)
 
@@ -161,12 +163,10 @@ func TestSignalService_Generate(t *testing.T) {
 	})
 
 	t.Run("cost_reduces_effective_edge", func(t *testing.T) {
-		lowCost := NewSignalService(
-			&mockCostModel{cost: 0.00},
+		lowCost := NewSignalService(&mockCostModel{cost: 0.00}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.05, MaxSizeUSD: 1000},
 		)
-		highCost := NewSignalService(
-			&mockCostModel{cost: 0.10},
+		highCost := NewSignalService(&mockCostModel{cost: 0.10}, ports.SystemClock{},
 			SignalConfig{BaseHurdle: 0.05, MaxSizeUSD: 1000},
 		)
 
@@ -199,3 +199,94 @@ func TestSignalService_Generate(t *testing.T) {
 		}
 	})
 }
+
+func TestMakerPrice(t *testing.T) {
+	tests := []struct {
+		name                      string
+		bid, ask, maxPrice, price float64
+	}{
+		{"inside_bid_when_allowed", 0.50, 0.54, 0.60, 0.51},
+		{"at_bid_when_inside_exceeds_max", 0.50, 0.54, 0.505, 0.50},
+		{"at_bid_when_inside_reaches_ask", 0.50, 0.51, 0.60, 0.50},
+		{"no_quote_below_bid", 0.50, 0.54, 0.45, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MakerPrice(tt.bid, tt.ask, tt.maxPrice, 0.01); math.Abs(got-tt.price) > 1e-9 {
+				t.Errorf("MakerPrice(%v, %v, %v) = %v, want %v", tt.bid, tt.ask, tt.maxPrice, got, tt.price)
+			}
+		})
+	}
+}
+
+func TestSignalService_MakerQuotes(t *testing.T) {
+	svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{}, SignalConfig{BaseHurdle: 0.02, MaxSizeUSD: 1000})
+	fv := domain.FairValue{ProbUp: 0.60, ProbUpLower: 0.58, ProbUpUpper: 0.62}
+	quote := domain.MarketQuote{
+		MarketID: "market-1",
+		Up:       domain.SideQuote{Bid: 0.50, Ask: 0.53},
+		Down:     domain.SideQuote{Bid: 0.45, Ask: 0.48},
+	}
+
+	up, down := svc.MakerQuotes(&fv, &quote, 0, 0, 0.01)
+	// Up: fair 0.58 with no taker cost, max 0.56 -> one tick inside the bid
+	if math.Abs(up.Price-0.51) > 1e-9 || math.Abs(up.Edge-0.07) > 1e-9 {
+		t.Errorf("unexpected up quote %+v", up)
+	}
+	// Down: fair 0.38, max 0.36 -> below the bid, not quoted
+	if down.Price != 0 {
+		t.Errorf("expected no down quote, got %+v", down)
+	}
+}
+
+func TestSignalService_Exit(t *testing.T) {
+	ctx := context.Background()
+	svc := NewSignalService(&mockCostModel{cost: 0.01}, ports.SystemClock{}, SignalConfig{BaseHurdle: 0.02, ExitEnabled: true, ExitHurdle: 0.02})
+	fv := domain.FairValue{ProbUp: 0.60, ProbUpLower: 0.58, ProbUpUpper: 0.62}
+
+	t.Run("sells_when_bid_clears_upper_fair_value", func(t *testing.T) {
+		quote := domain.MarketQuote{MarketID: "m1", Up: domain.SideQuote{Bid: 0.66, Ask: 0.68}}
+		sig, err := svc.Exit(ctx, &fv, &quote, nil, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 0.66 - 0.01 - 0.62 = 0.03 >= 0.02
+		if sig.Side != domain.SignalSellUp || sig.Reason != "sell_up_bid_exceeds_fair_value" {
+			t.Errorf("unexpected exit %s %s", sig.Side, sig.Reason)
+		}
+		if math.Abs(sig.ExitEdge-0.03) > 1e-9 {
+			t.Errorf("expected exit edge 0.03, got %f", sig.ExitEdge)
+		}
+	})
+
+	t.Run("holds_below_hurdle", func(t *testing.T) {
+		quote := domain.MarketQuote{MarketID: "m1", Up: domain.SideQuote{Bid: 0.64, Ask: 0.66}}
+		sig, _ := svc.Exit(ctx, &fv, &quote, nil, 10, 0)
+		if sig.Side != domain.SignalNone {
+			t.Errorf("expected no exit, got %s", sig.Side)
+		}
+	})
+
+	t.Run("nothing_held", func(t *testing.T) {
+		quote := domain.MarketQuote{MarketID: "m1", Up: domain.SideQuote{Bid: 0.90, Ask: 0.92}}
+		sig, _ := svc.Exit(ctx, &fv, &quote, nil, 0, 0)
+		if sig.Side != domain.SignalNone {
+			t.Errorf("expected no exit, got %s", sig.Side)
+		}
+	})
+
+	t.Run("sells_excess_when_edge_flips", func(t *testing.T) {
+		quote := domain.MarketQuote{MarketID: "m1", Up: domain.SideQuote{Bid: 0.55, Ask: 0.57}}
+		dir := domain.TradeSignal{Side: domain.SignalBuyDown, SignalType: "directional"}
+		sig, _ := svc.Exit(ctx, &fv, &quote, &dir, 10, 4)
+		if sig.Side != domain.SignalSellUp || sig.Reason != "sell_up_edge_flipped" {
+			t.Errorf("unexpected exit %s %s", sig.Side, sig.Reason)
+		}
+
+		// Balanced inventory has no excess to sell
+		sig, _ = svc.Exit(ctx, &fv, &quote, &dir, 10, 10)
+		if sig.Side != domain.SignalNone {
+			t.Errorf("expected no exit when balanced, got %s", sig.Side)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+// ErrInsufficientFunds blocks an order the wallet can't fund, before it is
+// signed. It is not an execution failure.
+var ErrInsufficientFunds = errors.New("insufficient balance or allowance")
+
+// WalletConfig sets how often the wallet is read and how long a read is trusted.
+type WalletConfig struct {
+	RefreshInterval time.Duration // time between collateral reads (default: 30s)
+	MaxAge          time.Duration // older reads are treated as unknown (default: 2m)
+}
+
+// WalletService caches the wallet's USDC balance and exchange allowance so
+// sizing and order checks don't hit the venue on every reprice.
+//
+// Available USDC is the spendable collateral less the notional of pending
+// buys. Fills the venue has not settled on-chain yet can still show in the
+// cached balance until the next refresh. Until the first successful read,
+// or once the last one is older than MaxAge, the state is unknown and
+// nothing is capped or blocked: the venue still rejects what it can't fund.
+//
+// Sells are checked against a fresh read of the token balance, since they
+// are rare and their tokens may have just been bought.
+type WalletService struct {
+	config   WalletConfig
+	source   ports.WalletSource
+	registry *MarketRegistry
+	clock    ports.Clock
+	logger   *slog.Logger
+
+	orders *OrderManager // optional: subtract pending orders
+
+	mu         sync.Mutex
+	collateral ports.BalanceAllowance
+	fetchedAt  time.Time
+}
+
+func NewWalletService(config WalletConfig, source ports.WalletSource, registry *MarketRegistry, clock ports.Clock, logger *slog.Logger) *WalletService {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = 30 * time.Second
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 2 * time.Minute
+	}
+	return &WalletService{
+		config:   config,
+		source:   source,
+		registry: registry,
+		clock:    clock,
+		logger:   logger,
+	}
+}
+
+// SetOrderManager subtracts pending orders from the cached balances. Must be
+// called before Run.
+func (w *WalletService) SetOrderManager(orders *OrderManager) {
+	w.orders = orders
+}
+
+// Run refreshes the collateral balance until ctx is cancelled.
+func (w *WalletService) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := w.Refresh(ctx); err != nil {
+			w.logger.Warn("wallet: refresh failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads the collateral balance and allowance from the venue.
+func (w *WalletService) Refresh(ctx context.Context) error {
+	ba, err := w.source.Collateral(ctx)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	first := w.fetchedAt.IsZero()
+	w.collateral = ba
+	w.fetchedAt = w.clock.Now()
+	w.mu.Unlock()
+
+	if first {
+		w.logger.Info("wallet: collateral loaded", "balance", ba.Balance, "allowance", ba.Allowance)
+	} else {
+		w.logger.Debug("wallet: collateral refreshed", "balance", ba.Balance, "allowance", ba.Allowance)
+	}
+	return nil
+}
+
+// AvailableUSD returns the USDC new buys can spend, and false while the
+// wallet state is unknown.
+func (w *WalletService) AvailableUSD() (float64, bool) {
+	ba, ok := w.current()
+	if !ok {
+		return 0, false
+	}
+	return math.Max(ba.Spendable()-w.pendingUSD(), 0), true
+}
+
+// Bankroll returns the bankroll Kelly sizing starts from: the USDC new buys
+// can spend, at most configured. configured is used while the wallet state
+// is unknown.
+func (w *WalletService) Bankroll(configured float64) float64 {
+	available, ok := w.AvailableUSD()
+	if !ok {
+		return configured
+	}
+	return math.Min(available, configured)
+}
+
+// CheckBuy returns ErrInsufficientFunds if a buy of sizeUSD exceeds the
+// balance or the allowance left after pending buys.
+func (w *WalletService) CheckBuy(sizeUSD float64) error {
+	ba, ok := w.current()
+	if !ok {
+		return nil
+	}
+	pending := w.pendingUSD()
+	if balance := ba.Balance - pending; sizeUSD > balance+1e-9 {
+		return fmt.Errorf("%w: buy $%.2f exceeds available balance $%.2f", ErrInsufficientFunds, sizeUSD, math.Max(balance, 0))
+	}
+	if allowance := ba.Allowance - pending; sizeUSD > allowance+1e-9 {
+		return fmt.Errorf("%w: buy $%.2f exceeds exchange allowance $%.2f", ErrInsufficientFunds, sizeUSD, math.Max(allowance, 0))
+	}
+	return nil
+}
+
+// CheckSell returns ErrInsufficientFunds if selling shares of a market side
+// exceeds the token balance left after pending sells, or the exchange is not
+// approved to move the tokens. A failed read lets the sell through.
+func (w *WalletService) CheckSell(ctx context.Context, marketID domain.MarketID, side domain.PositionSide, shares float64) error {
+	market, ok := w.registry.GetMarket(marketID)
+	if !ok {
+		return nil
+	}
+	tokenID := market.UpTokenID
+	if side == domain.PositionDown {
+		tokenID = market.DownTokenID
+	}
+	if tokenID == "" {
+		return nil
+	}
+	ba, err := w.source.Token(ctx, tokenID)
+	if err != nil {
+		w.logger.Warn("wallet: token balance read failed, not checking sell", "market", marketID, "side", side, "error", err)
+		return nil
+	}
+
+	pending := 0.0
+	if w.orders != nil {
+		upSells, downSells := w.orders.PendingSells(marketID)
+		pending = upSells
+		if side == domain.PositionDown {
+			pending = downSells
+		}
+	}
+	if balance := ba.Balance - pending; shares > balance+1e-9 {
+		return fmt.Errorf("%w: sell %.2f %s shares exceeds balance %.2f", ErrInsufficientFunds, shares, side, math.Max(balance, 0))
+	}
+	if shares > ba.Allowance+1e-9 {
+		return fmt.Errorf("%w: exchange not approved to move %s tokens", ErrInsufficientFunds, side)
+	}
+	return nil
+}
+
+func (w *WalletService) current() (ports.BalanceAllowance, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fetchedAt.IsZero() || w.clock.Now().Sub(w.fetchedAt) > w.config.MaxAge {
+		return ports.BalanceAllowance{}, false
+	}
+	return w.collateral, true
+}
+
+func (w *WalletService) pendingUSD() float64 {
+	if w.orders == nil {
+		return 0
+	}
+	return w.orders.PendingExposure()
+}
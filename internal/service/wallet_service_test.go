@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+)
+
+type stubWalletSource struct {
+	collateral ports.BalanceAllowance
+	tokens     map[string]ports.BalanceAllowance
+	err        error
+}
+
+func (s *stubWalletSource) Collateral(context.Context) (ports.BalanceAllowance, error) {
+	return s.collateral, s.err
+}
+
+func (s *stubWalletSource) Token(_ context.Context, tokenID string) (ports.BalanceAllowance, error) {
+	return s.tokens[tokenID], s.err
+}
+
+type stubBuyer struct{ calls int }
+
+func (b *stubBuyer) BuyUp(context.Context, domain.MarketID, float64, float64) (ports.OrderResult, error) {
+	b.calls++
+	return ports.OrderResult{Filled: true}, nil
+}
+
+func (b *stubBuyer) BuyDown(context.Context, domain.MarketID, float64, float64) (ports.OrderResult, error) {
+	b.calls++
+	return ports.OrderResult{Filled: true}, nil
+}
+
+func (b *stubBuyer) Sell(context.Context, domain.MarketID, domain.PositionSide, float64, float64) (ports.OrderResult, error) {
+	b.calls++
+	return ports.OrderResult{Filled: true}, nil
+}
+
+func newTestWallet(t *testing.T, source *stubWalletSource) (*WalletService, *OrderManager, *fixedClock) {
+	t.Helper()
+	orders, clock, _ := newTestOrderManager()
+	registry := NewMarketRegistry()
+	registry.SetMarket(domain.BinaryMarket{ID: "m1", UpTokenID: "up", DownTokenID: "down"})
+	w := NewWalletService(WalletConfig{MaxAge: time.Minute}, source, registry, clock, quietLogger())
+	w.SetOrderManager(orders)
+	return w, orders, clock
+}
+
+func TestWalletService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown_until_first_refresh", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{err: errors.New("down")})
+		if err := w.Refresh(ctx); err == nil {
+			t.Fatal("expected refresh error")
+		}
+		if _, ok := w.AvailableUSD(); ok {
+			t.Error("expected unknown wallet state")
+		}
+		if err := w.CheckBuy(1e6); err != nil {
+			t.Errorf("unknown state should not block, got %v", err)
+		}
+	})
+
+	t.Run("available_is_spendable_less_pending_buys", func(t *testing.T) {
+		w, orders, _ := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 100, Allowance: 80},
+		})
+		if err := w.Refresh(ctx); err != nil {
+			t.Fatal(err)
+		}
+		orders.Track(domain.ExecutionRequest{MarketID: "m1", Side: domain.SignalBuyUp, MaxPrice: 0.5, SizeUSD: 30}, ports.OrderResult{OrderID: "o1"})
+		avail, ok := w.AvailableUSD()
+		if !ok || math.Abs(avail-50) > 1e-9 {
+			t.Errorf("expected $50 available, got %.2f (known=%v)", avail, ok)
+		}
+	})
+
+	t.Run("stale_state_is_unknown", func(t *testing.T) {
+		w, _, clock := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 100, Allowance: 100},
+		})
+		_ = w.Refresh(ctx)
+		clock.now = clock.now.Add(2 * time.Minute)
+		if _, ok := w.AvailableUSD(); ok {
+			t.Error("expected stale state to be unknown")
+		}
+	})
+
+	t.Run("blocks_buys_over_balance_or_allowance", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 100, Allowance: 20},
+		})
+		_ = w.Refresh(ctx)
+		if err := w.CheckBuy(20); err != nil {
+			t.Errorf("expected $20 allowed, got %v", err)
+		}
+		if err := w.CheckBuy(25); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected allowance block, got %v", err)
+		}
+	})
+
+	t.Run("blocks_sells_over_token_balance", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{
+			tokens: map[string]ports.BalanceAllowance{"up": {Balance: 10, Allowance: 1e12}},
+		})
+		if err := w.CheckSell(ctx, "m1", domain.PositionUp, 10); err != nil {
+			t.Errorf("expected sell of held shares allowed, got %v", err)
+		}
+		if err := w.CheckSell(ctx, "m1", domain.PositionUp, 12); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected balance block, got %v", err)
+		}
+		if err := w.CheckSell(ctx, "m1", domain.PositionDown, 5); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected block with no DOWN tokens, got %v", err)
+		}
+	})
+
+	t.Run("execution_blocked_before_provider", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 5, Allowance: 100},
+		})
+		_ = w.Refresh(ctx)
+		buyer := &stubBuyer{}
+		exec := NewExecutionService(buyer)
+		exec.Wallet = w
+		_, err := exec.Execute(ctx, domain.ExecutionRequest{MarketID: "m1", Side: domain.SignalBuyUp, MaxPrice: 0.5, SizeUSD: 10})
+		if !errors.Is(err, ErrInsufficientFunds) || buyer.calls != 0 {
+			t.Errorf("expected block before provider, got err=%v calls=%d", err, buyer.calls)
+		}
+	})
+
+	t.Run("risk_caps_size_at_available", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 12, Allowance: 100},
+		})
+		_ = w.Refresh(ctx)
+		risk := NewRiskService(RiskConfig{MaxPositionUSDPerMarket: 100, FractionalKelly: 0.5, MinTradeShares: 5})
+		risk.Wallet = w
+		// Unconstrained: 1000 * 0.5 * 0.05 * 10 = $250, capped to $100, then $12
+		size := risk.ComputeTargetSizeUSD(0.05, 1000, 0, 0, 0.5, 0, false)
+		if math.Abs(size-12) > 1e-9 {
+			t.Errorf("expected size capped at $12, got %.2f", size)
+		}
+	})
+
+	t.Run("kelly_sizes_from_wallet_bankroll", func(t *testing.T) {
+		w, _, _ := newTestWallet(t, &stubWalletSource{
+			collateral: ports.BalanceAllowance{Balance: 200, Allowance: 1000},
+		})
+		if got := w.Bankroll(1000); got != 1000 {
+			t.Errorf("expected the configured bankroll while unknown, got %.2f", got)
+		}
+		_ = w.Refresh(ctx)
+		bankroll := w.Bankroll(1000)
+		if bankroll != 200 {
+			t.Fatalf("expected the wallet's $200 as bankroll, got %.2f", bankroll)
+		}
+		if got := w.Bankroll(100); got != 100 {
+			t.Errorf("expected the configured bankroll as a ceiling, got %.2f", got)
+		}
+
+		risk := NewRiskService(RiskConfig{MaxPositionUSDPerMarket: 1000, FractionalKelly: 0.5, MinTradeShares: 5})
+		risk.Wallet = w
+		// 200 * 0.5 * 0.05 * 10 = $50, where the configured $1000 gives $250
+		size := risk.ComputeTargetSizeUSD(0.05, bankroll, 0, 0, 0.5, 0, false)
+		if math.Abs(size-50) > 1e-9 {
+			t.Errorf("expected Kelly size $50 from the wallet bankroll, got %.2f", size)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -35,6 +36,22 @@ type StrategyRunner struct {
 	ImbalanceCfg      service.ImbalancePenaltyConfig
 	Logger            *slog.Logger
 
+	// TradeCutoffSecs overrides RiskConfig.NoNewTradeCutoffSecs for this runner
+	// (each market stream has its own window length). Zero uses the risk default.
+	TradeCutoffSecs float64
+
+	// Orders tracks submitted orders until their fills are booked; pending
+	// orders count toward inventory and exposure in risk checks. Optional.
+	Orders *service.OrderManager
+
+	// Maker rests limit buys at or inside the bid instead of taking the ask
+	// (maker mode); hedges still take liquidity. Optional.
+	Maker *service.MakerService
+
+	// Books serves the live order books for MarketState; taker buys are then
+	// sized against the average price of walking the asks. Optional.
+	Books ports.OrderBookSource
+
 	lastTradeTime time.Time // cooldown: prevent rapid-fire trades on buffered events
 }
 
@@ -95,6 +112,7 @@ func (r *StrategyRunner) EvaluateMarket(
 			"market", market.ID,
 			"reason", reason,
 		)
+		r.pullQuotes(ctx, market.ID, reason)
 		return nil
 	}
 
@@ -114,6 +132,7 @@ func (r *StrategyRunner) EvaluateMarket(
 			"market", market.ID,
 			"error", err,
 		)
+		r.pullQuotes(ctx, market.ID, "invalid_quotes")
 		return nil
 	}
 
@@ -123,6 +142,7 @@ func (r *StrategyRunner) EvaluateMarket(
 			"market", market.ID,
 			"jump_score", refState.JumpScore,
 		)
+		r.pullQuotes(ctx, market.ID, "extreme_jump")
 		return nil
 	}
 
@@ -170,11 +190,24 @@ func (r *StrategyRunner) EvaluateMarket(
 		Timestamp: mktState.Timestamp,
 	}
 	_ = r.EventRepo.SaveQuote(ctx, quote)
+	r.updateMark(market.ID, &quote)
 
 	// === SELECTOR: hedge > directional > no trade ===
 	signal := r.selectSignal(ctx, market, &fv, &quote, remaining)
 	_ = r.EventRepo.SaveSignal(ctx, signal)
 
+	// === EXIT: sell tokens the bid overpays for, or held against a flipped edge ===
+	if r.SignalSvc.Config.ExitEnabled {
+		if sold, err := r.exit(ctx, market, &fv, &quote, &signal); sold || err != nil {
+			return err
+		}
+	}
+
+	// Maker mode rests directional buys instead of taking the ask
+	if r.Maker != nil && signal.SignalType != "hedge" {
+		return r.quoteMaker(ctx, market, &fv, &quote, remaining, bankrollUSD)
+	}
+
 	if signal.Side == domain.SignalNone {
 		return nil
 	}
@@ -184,11 +217,17 @@ func (r *StrategyRunner) EvaluateMarket(
 		return nil
 	}
 
-	if !r.RiskSvc.ShouldAllowNewTrade(remaining) {
+	if halted, reason := r.RiskSvc.TradingHalted(); halted {
+		r.Logger.Debug("blocked by circuit breaker", "market", market.ID, "reason", reason)
+		return nil
+	}
+
+	if !r.allowNewTrade(remaining) {
 		r.Logger.Debug("blocked by cutoff", "market", market.ID, "remaining", remaining)
 		return nil
 	}
 
+	r.attachBooks(market, mktState)
 	return r.executeSignal(ctx, market, &signal, mktState, refState, bankrollUSD)
 }
 
@@ -227,7 +266,7 @@ func (r *StrategyRunner) selectSignal(
 			MarketID:   market.ID,
 			Side:       domain.SignalNone,
 			SignalType: "directional",
-			Timestamp:  time.Now(),
+			Timestamp:  r.Clock.Now(),
 		}
 	}
 	dirSignal.SignalType = "directional"
@@ -242,11 +281,8 @@ func (r *StrategyRunner) executeSignal(
 	refState *domain.ReferenceState,
 	bankrollUSD float64,
 ) error {
-	currentExposure := r.PositionSvc.GetExposureForMarket(market.ID)
-
 	var edge float64
 	var maxPrice float64
-	var buyingUp bool
 
 	switch signal.Side {
 	case domain.SignalBuyUp, domain.SignalHedgeUp:
@@ -255,50 +291,53 @@ func (r *StrategyRunner) executeSignal(
 			edge = signal.HedgeEdge
 		}
 		maxPrice = mktState.UpAsk
-		buyingUp = true
 	case domain.SignalBuyDown, domain.SignalHedgeDown:
 		edge = signal.EdgeBuyDown
 		if signal.SignalType == "hedge" {
 			edge = signal.HedgeEdge
 		}
 		maxPrice = mktState.DownAsk
-		buyingUp = false
 	default:
 		return nil
 	}
 
-	// Compute imbalance ratio for Kelly decay
-	upQty, downQty, upCost, downCost := r.PositionSvc.GetInventory(market.ID)
-	total := upQty + downQty
-	imbalance := math.Abs(upQty - downQty)
-	imbalanceRatio := 0.0
-	if total > 0 {
-		imbalanceRatio = imbalance / total
-	}
-	increasingImbalance := (buyingUp && upQty > downQty) || (!buyingUp && downQty > upQty)
-
-	sizeUSD := r.RiskSvc.ComputeTargetSizeUSD(edge, bankrollUSD, currentExposure, maxPrice, imbalanceRatio, increasingImbalance)
+	sizeUSD := r.sizeTrade(market.ID, signal.Side, edge, maxPrice, bankrollUSD)
 	if sizeUSD <= 0 {
 		return nil
 	}
-
-	// Pre-trade risk gates: imbalance, floor, worst-case loss
-	if maxPrice > 0 {
-		proposedShares := math.Floor(sizeUSD / maxPrice)
-		if reason := r.RiskSvc.PreTradeCheck(buyingUp, proposedShares, maxPrice, upQty, downQty, upCost, downCost); reason != "" {
-			r.Logger.Debug("trade blocked by risk gate",
-				"market", market.ID,
-				"side", signal.Side,
-				"reason", reason,
-			)
+	fillPrice := maxPrice
+	if book := askBook(mktState, signal.Side); book != nil {
+		capped, avgPrice, limitPrice := r.RiskSvc.SizeToBook(book, sizeUSD, edge)
+		if capped <= 0 {
+			r.Logger.Debug("blocked by book depth", "market", market.ID, "side", signal.Side, "max_price", maxPrice)
 			return nil
 		}
+		// Walking the book fills at the average price, not the best ask:
+		// the edge shrinks by the slippage and must still clear the hurdle
+		// the signal was generated against (HedgeHurdle for hedges). Past
+		// that the order shrinks to the size whose average price clears it.
+		if edge-(avgPrice-maxPrice) < signal.EffectiveHurdle {
+			affordable := book.MaxBuyAtAvg(maxPrice + edge - signal.EffectiveHurdle)
+			capped, avgPrice, limitPrice = r.RiskSvc.SizeToBook(book, math.Min(capped, affordable), edge)
+			if capped <= 0 {
+				r.Logger.Debug("blocked by slippage", "market", market.ID, "side", signal.Side, "hurdle", signal.EffectiveHurdle)
+				return nil
+			}
+		}
+		edge -= avgPrice - maxPrice
+		if resized := r.sizeTrade(market.ID, signal.Side, edge, avgPrice, bankrollUSD); resized < capped {
+			capped = resized
+		}
+		if capped <= 0 {
+			return nil
+		}
+		sizeUSD, fillPrice, maxPrice = capped, avgPrice, limitPrice
 	}
 
 	// Hedge trades: cap at the number of shares needed to balance inventory.
 	// The hedge edge is per-share ΔG, but only valid up to |Nu - Nd| shares.
-	if signal.SignalType == "hedge" && maxPrice > 0 {
-		upQty, downQty, _, _ := r.PositionSvc.GetInventory(market.ID)
+	if signal.SignalType == "hedge" && fillPrice > 0 {
+		upQty, downQty, _, _ := r.inventory(market.ID)
 		var balanceShares float64
 		switch signal.Side {
 		case domain.SignalHedgeUp:
@@ -309,9 +348,9 @@ func (r *StrategyRunner) executeSignal(
 		if balanceShares <= 0 {
 			return nil
 		}
-		maxHedgeUSD := balanceShares * maxPrice
+		maxHedgeUSD := balanceShares * fillPrice
 		if sizeUSD > maxHedgeUSD {
-			sizeUSD = math.Floor(balanceShares) * maxPrice
+			sizeUSD = math.Floor(balanceShares) * fillPrice
 		}
 		if sizeUSD <= 0 {
 			return nil
@@ -323,40 +362,52 @@ func (r *StrategyRunner) executeSignal(
 		"side", signal.Side,
 		"type", signal.SignalType,
 		"max_price", maxPrice,
+		"avg_price", fillPrice,
 		"size_usd", sizeUSD,
 		"edge", edge,
 		"regime", refState.Regime,
 	)
 
-	result, err := r.ExecSvc.Execute(ctx, domain.ExecutionRequest{
+	req := domain.ExecutionRequest{
 		MarketID: market.ID,
 		Side:     signal.Side,
 		MaxPrice: maxPrice,
 		SizeUSD:  sizeUSD,
 		Reason:   signal.Reason,
-	})
+	}
+	result, err := r.ExecSvc.Execute(ctx, req)
+	r.RiskSvc.RecordExecution(err)
+	if errors.Is(err, service.ErrNotFilled) {
+		// The book moved before the order arrived; the next tick re-evaluates
+		r.Logger.Info("order not filled", "market", market.ID, "side", signal.Side, "error", err)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("execution: %w", err)
 	}
 
-	r.lastTradeTime = time.Now()
+	r.lastTradeTime = r.Clock.Now()
 
 	// In paper mode (Filled=true, no OrderID), record position immediately.
 	// In live mode, the fill listener updates positions from confirmed WS events.
 	if result.Filled && result.OrderID == "" {
-		fillPrice := maxPrice
 		if result.Price > 0 {
 			fillPrice = result.Price
 		}
-		return r.PositionSvc.AccumulateFill(ctx, domain.Fill{
+		fill := domain.Fill{
 			MarketID:  market.ID,
 			Side:      signal.Side,
 			Price:     fillPrice,
 			SizeUSD:   sizeUSD,
-			Timestamp: time.Now(),
-		})
+			Timestamp: r.Clock.Now(),
+		}
+		_ = r.EventRepo.SaveFill(ctx, fill)
+		return r.PositionSvc.AccumulateFill(ctx, fill)
 	}
 
+	if r.Orders != nil {
+		r.Orders.Track(req, result)
+	}
 	r.Logger.Info("order submitted, awaiting fill confirmation",
 		"market", market.ID,
 		"order_id", result.OrderID,
@@ -365,6 +416,287 @@ func (r *StrategyRunner) executeSignal(
 	return nil
 }
 
+// exit sells held tokens at the bid when SignalService.Exit calls for it: the
+// whole side when the bid is rich, only the unpaired excess when the edge has
+// flipped. Shares already being sold are left out. It reports whether a sell
+// was submitted.
+func (r *StrategyRunner) exit(
+	ctx context.Context,
+	market *domain.BinaryMarket,
+	fv *domain.FairValue,
+	quote *domain.MarketQuote,
+	dir *domain.TradeSignal,
+) (bool, error) {
+	upQty, downQty, _, _ := r.PositionSvc.GetInventory(market.ID)
+	if r.Orders != nil {
+		upSells, downSells := r.Orders.PendingSells(market.ID)
+		upQty, downQty = upQty-upSells, downQty-downSells
+	}
+	signal, err := r.SignalSvc.Exit(ctx, fv, quote, dir, upQty, downQty)
+	if err != nil {
+		r.Logger.Warn("exit signal error", "market", market.ID, "error", err)
+		return false, nil
+	}
+	if signal.Side == domain.SignalNone {
+		return false, nil
+	}
+
+	held, other, bid := upQty, downQty, quote.Up.Bid
+	if signal.Side == domain.SignalSellDown {
+		held, other, bid = downQty, upQty, quote.Down.Bid
+	}
+	shares := held
+	if signal.Reason == "sell_up_edge_flipped" || signal.Reason == "sell_down_edge_flipped" {
+		shares = held - other
+	}
+	shares = math.Floor(shares*100+1e-6) / 100
+	if shares <= 0 || shares < r.RiskSvc.Config.MinTradeShares {
+		return false, nil
+	}
+
+	if halted, reason := r.RiskSvc.TradingHalted(); halted {
+		r.Logger.Debug("exit blocked by circuit breaker", "market", market.ID, "reason", reason)
+		return false, nil
+	}
+	_ = r.EventRepo.SaveSignal(ctx, signal)
+
+	r.Logger.Info("exiting",
+		"market", market.ID,
+		"side", signal.Side,
+		"reason", signal.Reason,
+		"shares", shares,
+		"min_price", bid,
+		"exit_edge", signal.ExitEdge,
+	)
+
+	req := domain.ExecutionRequest{
+		MarketID: market.ID,
+		Side:     signal.Side,
+		MaxPrice: bid,
+		SizeUSD:  shares * bid,
+		Reason:   signal.Reason,
+	}
+	result, err := r.ExecSvc.Execute(ctx, req)
+	r.RiskSvc.RecordExecution(err)
+	if errors.Is(err, service.ErrNotFilled) {
+		r.Logger.Info("exit not filled", "market", market.ID, "side", signal.Side, "error", err)
+		return true, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("exit: %w", err)
+	}
+
+	r.lastTradeTime = r.Clock.Now()
+
+	// In paper mode (Filled=true, no OrderID), book the sale immediately.
+	if result.Filled && result.OrderID == "" {
+		fillPrice := bid
+		if result.Price > 0 {
+			fillPrice = result.Price
+		}
+		fill := domain.Fill{
+			MarketID:  market.ID,
+			Side:      signal.Side,
+			Price:     fillPrice,
+			SizeUSD:   shares * fillPrice,
+			Timestamp: r.Clock.Now(),
+		}
+		_ = r.EventRepo.SaveFill(ctx, fill)
+		return true, r.PositionSvc.AccumulateFill(ctx, fill)
+	}
+
+	if r.Orders != nil {
+		r.Orders.Track(req, result)
+	}
+	return true, nil
+}
+
+// sizeTrade returns the Kelly-sized notional for buying side at price, or 0
+// when the size rounds to nothing or a pre-trade risk gate blocks it.
+// Pending orders count toward inventory and exposure.
+func (r *StrategyRunner) sizeTrade(
+	marketID domain.MarketID,
+	side domain.TradeSignalSide,
+	edge float64,
+	price float64,
+	bankrollUSD float64,
+) float64 {
+	buyingUp := side == domain.SignalBuyUp || side == domain.SignalHedgeUp
+
+	currentExposure := r.PositionSvc.GetExposureForMarket(marketID)
+	pendingExposure := 0.0
+	if r.Orders != nil {
+		_, _, pendingUp, pendingDown := r.Orders.Pending(marketID)
+		currentExposure += pendingUp + pendingDown
+		pendingExposure = r.Orders.PendingExposure()
+	}
+
+	// Compute imbalance ratio for Kelly decay
+	upQty, downQty, upCost, downCost := r.inventory(marketID)
+	total := upQty + downQty
+	imbalance := math.Abs(upQty - downQty)
+	imbalanceRatio := 0.0
+	if total > 0 {
+		imbalanceRatio = imbalance / total
+	}
+	increasingImbalance := (buyingUp && upQty > downQty) || (!buyingUp && downQty > upQty)
+
+	// Portfolio budget: shares that rebalance this market complete $1 pairs and
+	// free capital, so they don't draw on the budget
+	portfolioExposure := r.PositionSvc.GetTotalExposure() + pendingExposure
+	if !increasingImbalance {
+		portfolioExposure -= imbalance * price
+	}
+
+	sizeUSD := r.RiskSvc.ComputeTargetSizeUSD(edge, bankrollUSD, currentExposure, portfolioExposure, price, imbalanceRatio, increasingImbalance)
+	if sizeUSD <= 0 {
+		return 0
+	}
+
+	// Pre-trade risk gates: imbalance, floor, worst-case loss
+	if price > 0 {
+		proposedShares := math.Floor(sizeUSD / price)
+		if reason := r.RiskSvc.PreTradeCheck(buyingUp, proposedShares, price, upQty, downQty, upCost, downCost); reason != "" {
+			r.Logger.Debug("trade blocked by risk gate",
+				"market", marketID,
+				"side", side,
+				"reason", reason,
+			)
+			return 0
+		}
+		if reason := r.RiskSvc.PortfolioCheck(r.PositionSvc.GetTotalExposure()+pendingExposure, buyingUp, proposedShares, price, upQty, downQty, upCost, downCost); reason != "" {
+			r.Logger.Debug("trade blocked by portfolio budget",
+				"market", marketID,
+				"side", side,
+				"reason", reason,
+			)
+			return 0
+		}
+	}
+	return sizeUSD
+}
+
+// askBook returns the book of the token a buy signal buys, or nil.
+func askBook(mktState *domain.MarketState, side domain.TradeSignalSide) *domain.OrderBook {
+	if side == domain.SignalBuyDown || side == domain.SignalHedgeDown {
+		return mktState.DownBook
+	}
+	return mktState.UpBook
+}
+
+// attachBooks adds the L2 books and their ask depth to mktState when a book
+// source is set and the caller left them empty.
+func (r *StrategyRunner) attachBooks(market *domain.BinaryMarket, mktState *domain.MarketState) {
+	if r.Books == nil {
+		return
+	}
+	if mktState.UpBook == nil {
+		mktState.UpBook, _ = r.Books.Book(market.UpTokenID)
+	}
+	if mktState.DownBook == nil {
+		mktState.DownBook, _ = r.Books.Book(market.DownTokenID)
+	}
+	mktState.UpDepth = askDepthUSD(mktState.UpBook)
+	mktState.DownDepth = askDepthUSD(mktState.DownBook)
+}
+
+func askDepthUSD(book *domain.OrderBook) float64 {
+	if book == nil {
+		return 0
+	}
+	_, usd := book.Depth(domain.BookAsk, 1)
+	return usd
+}
+
+// quoteMaker keeps a resting buy on each side at the price MakerQuotes
+// allows. Orders still on target are left alone so they keep queue priority;
+// the rest are cancelled and replaced. All quotes are pulled when trading is
+// halted or the cutoff is reached, and resting orders expire at the cutoff in
+// case a cancel is missed.
+func (r *StrategyRunner) quoteMaker(
+	ctx context.Context,
+	market *domain.BinaryMarket,
+	fv *domain.FairValue,
+	quote *domain.MarketQuote,
+	remaining float64,
+	bankrollUSD float64,
+) error {
+	if halted, reason := r.RiskSvc.TradingHalted(); halted {
+		r.pullQuotes(ctx, market.ID, "circuit breaker: "+reason)
+		return nil
+	}
+	if !r.allowNewTrade(remaining) {
+		r.pullQuotes(ctx, market.ID, "cutoff")
+		return nil
+	}
+
+	penaltyUp, penaltyDown := r.PositionSvc.GetInventoryPenalties(market.ID, r.ImbalanceCfg)
+	up, down := r.SignalSvc.MakerQuotes(fv, quote, penaltyUp, penaltyDown, r.Maker.TickSize())
+
+	expiresAt := market.EndTime.Add(-r.tradeCutoff())
+	if err := r.quoteSide(ctx, market.ID, domain.SignalBuyUp, up, expiresAt, bankrollUSD); err != nil {
+		return err
+	}
+	return r.quoteSide(ctx, market.ID, domain.SignalBuyDown, down, expiresAt, bankrollUSD)
+}
+
+func (r *StrategyRunner) quoteSide(
+	ctx context.Context,
+	marketID domain.MarketID,
+	side domain.TradeSignalSide,
+	q service.MakerQuote,
+	expiresAt time.Time,
+	bankrollUSD float64,
+) error {
+	req := domain.ExecutionRequest{
+		MarketID: marketID,
+		Side:     side,
+		MaxPrice: q.Price,
+		Reason:   "maker",
+	}
+	var sizeUSD float64
+	result, acted, err := r.Maker.Requote(ctx, req, expiresAt, func() float64 {
+		// Sized after the cancel so the replaced order's remainder isn't counted
+		sizeUSD = r.sizeTrade(marketID, side, q.Edge, q.Price, bankrollUSD)
+		return sizeUSD
+	})
+	if acted {
+		// An order left on target is no execution; counting it would
+		// dilute the breaker's error rate
+		r.RiskSvc.RecordExecution(err)
+	}
+	if err != nil {
+		return fmt.Errorf("maker: %w", err)
+	}
+	if result.OrderID == "" {
+		return nil
+	}
+
+	r.lastTradeTime = r.Clock.Now()
+	r.Logger.Info("maker order resting",
+		"market", marketID,
+		"side", side,
+		"price", q.Price,
+		"size_usd", sizeUSD,
+		"edge", q.Edge,
+		"order_id", result.OrderID,
+	)
+	return nil
+}
+
+// pullQuotes cancels resting maker orders on a market. No-op in taker mode.
+func (r *StrategyRunner) pullQuotes(ctx context.Context, marketID domain.MarketID, reason string) {
+	if r.Maker == nil {
+		return
+	}
+	r.Maker.Pull(ctx, marketID, reason)
+}
+
+// inventory returns booked inventory plus unconfirmed orders.
+func (r *StrategyRunner) inventory(marketID domain.MarketID) (upQty, downQty, upCost, downCost float64) {
+	return service.InventoryWithPending(r.PositionSvc, r.Orders, marketID)
+}
+
 // OnMarketUpdate is a backward-compatible wrapper that constructs
 // ReferenceState and MarketState from simple arguments.
 func (r *StrategyRunner) OnMarketUpdate(
@@ -392,21 +724,72 @@ func (r *StrategyRunner) OnMarketUpdate(
 	return r.EvaluateMarket(ctx, &market, &refState, &mktState, bankrollUSD)
 }
 
+// updateMark reports the market's unrealized P&L at the current bids to the
+// circuit breaker. Skipped when a held side has no bid to mark against.
+func (r *StrategyRunner) updateMark(marketID domain.MarketID, quote *domain.MarketQuote) {
+	updateMark(r.PositionSvc, r.RiskSvc, marketID, quote)
+}
+
+func updateMark(positionSvc *service.PositionService, riskSvc *service.RiskService, marketID domain.MarketID, quote *domain.MarketQuote) {
+	upQty, downQty, upCost, downCost := positionSvc.GetInventory(marketID)
+	if upQty == 0 && downQty == 0 {
+		return
+	}
+	if (upQty > 0 && quote.Up.Bid <= 0) || (downQty > 0 && quote.Down.Bid <= 0) {
+		return
+	}
+	value := upQty*quote.Up.Bid + downQty*quote.Down.Bid
+	riskSvc.UpdateMark(marketID, value-upCost-downCost)
+}
+
+// allowNewTrade applies the per-stream cutoff if set, else the risk default.
+func (r *StrategyRunner) allowNewTrade(remainingSeconds float64) bool {
+	if r.TradeCutoffSecs > 0 {
+		return remainingSeconds > r.TradeCutoffSecs
+	}
+	return r.RiskSvc.ShouldAllowNewTrade(remainingSeconds)
+}
+
+// tradeCutoff is the no-new-trade window before market end.
+func (r *StrategyRunner) tradeCutoff() time.Duration {
+	secs := r.RiskSvc.Config.NoNewTradeCutoffSecs
+	if r.TradeCutoffSecs > 0 {
+		secs = r.TradeCutoffSecs
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
 func (r *StrategyRunner) checkFreshness(now time.Time, ref *domain.ReferenceState, mkt *domain.MarketState) string {
-	if r.Freshness.MaxReferenceAge > 0 && !ref.LastUpdate.IsZero() {
-		if now.Sub(ref.LastUpdate) > r.Freshness.MaxReferenceAge {
+	return r.Freshness.check(now, ref, mkt)
+}
+
+func (r *StrategyRunner) validateQuotes(mkt *domain.MarketState) error {
+	return validateQuotes(mkt)
+}
+
+// CloseMarket resets the market's persistence streaks.
+func (r *StrategyRunner) CloseMarket(_ context.Context, marketID domain.MarketID) {
+	if r.PersistenceFilter != nil {
+		r.PersistenceFilter.Reset(marketID)
+	}
+}
+
+// check returns why the reference or market data is stale, or "".
+func (c FreshnessConfig) check(now time.Time, ref *domain.ReferenceState, mkt *domain.MarketState) string {
+	if c.MaxReferenceAge > 0 && !ref.LastUpdate.IsZero() {
+		if now.Sub(ref.LastUpdate) > c.MaxReferenceAge {
 			return "stale_chainlink"
 		}
 	}
-	if r.Freshness.MaxQuoteAge > 0 && !mkt.Timestamp.IsZero() {
-		if now.Sub(mkt.Timestamp) > r.Freshness.MaxQuoteAge {
+	if c.MaxQuoteAge > 0 && !mkt.Timestamp.IsZero() {
+		if now.Sub(mkt.Timestamp) > c.MaxQuoteAge {
 			return "stale_polymarket_quote"
 		}
 	}
 	return ""
 }
 
-func (r *StrategyRunner) validateQuotes(mkt *domain.MarketState) error {
+func validateQuotes(mkt *domain.MarketState) error {
 	if mkt.UpAsk <= 0 || mkt.UpAsk > 1.0 {
 		return fmt.Errorf("invalid up ask: %f", mkt.UpAsk)
 	}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"Polybot/internal/domain"
+	"Polybot/internal/infra/storage"
+	"Polybot/internal/service"
 )
 
 func testLogger() *slog.Logger {
@@ -162,3 +164,59 @@ type mockClock struct {
 }
 
 func (c *mockClock) Now() time.Time { return c.now }
+
+func TestStrategyRunner_ExecuteSignal_SlippageHurdle(t *testing.T) {
+	ctx := context.Background()
+	// $10 at the best ask, then deep liquidity 0.10 higher
+	book := domain.NewOrderBook("up", nil, []domain.PriceLevel{
+		{Price: 0.50, Size: 20},
+		{Price: 0.60, Size: 1000},
+	})
+	newRunner := func(minTradeUSD float64) (*StrategyRunner, *stubVenue) {
+		venue := &stubVenue{}
+		return &StrategyRunner{
+			RiskSvc: service.NewRiskService(service.RiskConfig{
+				MaxPositionUSDPerMarket: 100,
+				FractionalKelly:         1,
+				MinTradeSizeUSD:         minTradeUSD,
+			}),
+			ExecSvc:     service.NewExecutionService(venue),
+			PositionSvc: service.NewPositionService(storage.NewInMemoryPositionRepo()),
+			Clock:       &mockClock{now: time.Now()},
+			Logger:      testLogger(),
+		}, venue
+	}
+	signal := func(hurdle float64) *domain.TradeSignal {
+		return &domain.TradeSignal{
+			MarketID:        "m1",
+			Side:            domain.SignalBuyUp,
+			SignalType:      "directional",
+			EdgeBuyUp:       0.10,
+			EffectiveHurdle: hurdle,
+		}
+	}
+	mktState := &domain.MarketState{MarketID: "m1", UpAsk: 0.50, UpBook: book}
+
+	t.Run("shrinks_until_the_average_price_clears_the_hurdle", func(t *testing.T) {
+		runner, venue := newRunner(1)
+		err := runner.executeSignal(ctx, &domain.BinaryMarket{ID: "m1"}, signal(0.05), mktState, &domain.ReferenceState{}, 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// $100 would average 0.588; $22 averages 0.55, leaving the 0.05 hurdle
+		if len(venue.taken) != 1 || venue.taken[0] != "up $22.00" {
+			t.Errorf("expected one $22 buy, got %v", venue.taken)
+		}
+	})
+
+	t.Run("drops_when_the_shrunk_size_is_too_small", func(t *testing.T) {
+		runner, venue := newRunner(15)
+		err := runner.executeSignal(ctx, &domain.BinaryMarket{ID: "m1"}, signal(0.09), mktState, &domain.ReferenceState{}, 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(venue.taken) != 0 {
+			t.Errorf("expected no order, got %v", venue.taken)
+		}
+	})
+}
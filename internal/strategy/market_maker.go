@@ -0,0 +1,305 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/ports"
+	"Polybot/internal/service"
+)
+
+// MarketMakerConfig sets quote size and the end-of-window flatten phase.
+type MarketMakerConfig struct {
+	QuoteShares float64 // shares bid on each side (default: 10)
+	FlattenSecs float64 // window before the cutoff spent completing pairs instead of quoting (default: 30)
+}
+
+// MarketMaker rests UP and DOWN bids around model fair value, priced by the
+// Quoter, and earns the spread when both sides fill into pairs.
+//
+// Quotes are pulled whenever the data is stale, the book is invalid, a jump
+// is detected or trading is halted. FlattenSecs before the trade cutoff it
+// stops quoting and buys the light side at the ask so the window ends flat or
+// in balanced pairs, halted or not; after the cutoff it does nothing.
+type MarketMaker struct {
+	PricingModel service.PricingModel
+	Quoter       *service.Quoter
+	Maker        *service.MakerService
+	RiskSvc      *service.RiskService
+	ExecSvc      *service.ExecutionService
+	PositionSvc  *service.PositionService
+	Orders       *service.OrderManager
+	EventRepo    ports.EventRepository
+	Clock        ports.Clock
+	Freshness    FreshnessConfig
+	Config       MarketMakerConfig
+	Logger       *slog.Logger
+
+	// TradeCutoffSecs overrides RiskConfig.NoNewTradeCutoffSecs for this
+	// stream. Zero uses the risk default.
+	TradeCutoffSecs float64
+}
+
+func NewMarketMaker(
+	pricing service.PricingModel,
+	quoter *service.Quoter,
+	maker *service.MakerService,
+	risk *service.RiskService,
+	exec *service.ExecutionService,
+	position *service.PositionService,
+	orders *service.OrderManager,
+	eventRepo ports.EventRepository,
+	clock ports.Clock,
+	freshness FreshnessConfig,
+	config MarketMakerConfig,
+	logger *slog.Logger,
+) *MarketMaker {
+	if config.QuoteShares <= 0 {
+		config.QuoteShares = 10
+	}
+	if config.FlattenSecs <= 0 {
+		config.FlattenSecs = 30
+	}
+	return &MarketMaker{
+		PricingModel: pricing,
+		Quoter:       quoter,
+		Maker:        maker,
+		RiskSvc:      risk,
+		ExecSvc:      exec,
+		PositionSvc:  position,
+		Orders:       orders,
+		EventRepo:    eventRepo,
+		Clock:        clock,
+		Freshness:    freshness,
+		Config:       config,
+		Logger:       logger,
+	}
+}
+
+// EvaluateMarket reprices, pulls or flattens the market's quotes.
+func (m *MarketMaker) EvaluateMarket(
+	ctx context.Context,
+	market *domain.BinaryMarket,
+	refState *domain.ReferenceState,
+	mktState *domain.MarketState,
+	bankrollUSD float64,
+) error {
+	now := m.Clock.Now()
+
+	// === SAFETY GUARDS: pull quotes on stale data, a bad book or a jump ===
+	if reason := m.Freshness.check(now, refState, mktState); reason != "" {
+		m.pull(ctx, market.ID, reason)
+		return nil
+	}
+	if err := validateQuotes(mktState); err != nil {
+		m.Logger.Debug("quote sanity check failed", "market", market.ID, "error", err)
+		m.pull(ctx, market.ID, "invalid_quotes")
+		return nil
+	}
+	if refState.JumpScore > 6.0 {
+		m.pull(ctx, market.ID, "extreme_jump")
+		return nil
+	}
+	if m.Freshness.MinTickCount > 0 && refState.TickCount < m.Freshness.MinTickCount {
+		m.pull(ctx, market.ID, "warm_up")
+		return nil
+	}
+	remaining := market.EndTime.Sub(now).Seconds()
+	cutoff := m.tradeCutoffSecs()
+	if remaining <= cutoff {
+		m.pull(ctx, market.ID, "cutoff")
+		return nil
+	}
+
+	fv, err := m.PricingModel.FairProbUp(ctx, domain.PricingInput{
+		CurrentPrice:     refState.CurrentPrice,
+		PriceToBeat:      market.PriceToBeat,
+		RemainingSeconds: remaining,
+		RealizedVol1m:    refState.RealizedVol1m,
+		RealizedVol5m:    refState.RealizedVol5m,
+		JumpScore:        refState.JumpScore,
+		Regime:           refState.Regime,
+		DriftPerSec:      refState.DriftPerSec,
+		DriftTicks:       refState.DriftTicks,
+	})
+	if err != nil {
+		return fmt.Errorf("pricing: %w", err)
+	}
+	fv.MarketID = market.ID
+	_ = m.EventRepo.SaveFairValue(ctx, fv)
+
+	quote := domain.MarketQuote{
+		MarketID:  market.ID,
+		Up:        domain.SideQuote{Bid: mktState.UpBid, Ask: mktState.UpAsk},
+		Down:      domain.SideQuote{Bid: mktState.DownBid, Ask: mktState.DownAsk},
+		Timestamp: mktState.Timestamp,
+	}
+	_ = m.EventRepo.SaveQuote(ctx, quote)
+	updateMark(m.PositionSvc, m.RiskSvc, market.ID, &quote)
+
+	// === FLATTEN: stop quoting and complete pairs before the cutoff ===
+	// Runs even when trading is halted: it only reduces risk
+	if remaining <= cutoff+m.Config.FlattenSecs {
+		m.pull(ctx, market.ID, "flatten")
+		return m.flatten(ctx, market.ID, &quote)
+	}
+	if halted, reason := m.RiskSvc.TradingHalted(); halted {
+		m.pull(ctx, market.ID, "circuit breaker: "+reason)
+		return nil
+	}
+
+	// Skew by booked inventory: resting bids aren't held yet
+	upQty, downQty, _, _ := m.PositionSvc.GetInventory(market.ID)
+	up, down := m.Quoter.Quotes(&fv, &quote, upQty, downQty)
+
+	expiresAt := market.EndTime.Add(-time.Duration((cutoff + m.Config.FlattenSecs) * float64(time.Second)))
+	if err := m.quoteSide(ctx, market.ID, domain.SignalBuyUp, up, expiresAt); err != nil {
+		return err
+	}
+	return m.quoteSide(ctx, market.ID, domain.SignalBuyDown, down, expiresAt)
+}
+
+// CloseMarket pulls any quotes left on an expired market.
+func (m *MarketMaker) CloseMarket(ctx context.Context, marketID domain.MarketID) {
+	m.pull(ctx, marketID, "market_expired")
+}
+
+// quoteSide keeps the side's resting bid on target: left alone while within
+// the reprice threshold, otherwise cancelled and replaced after the risk gates.
+func (m *MarketMaker) quoteSide(
+	ctx context.Context,
+	marketID domain.MarketID,
+	side domain.TradeSignalSide,
+	q service.MakerQuote,
+	expiresAt time.Time,
+) error {
+	shares := m.Config.QuoteShares
+	req := domain.ExecutionRequest{
+		MarketID: marketID,
+		Side:     side,
+		MaxPrice: q.Price,
+		Reason:   "market_maker",
+	}
+	result, acted, err := m.Maker.Requote(ctx, req, expiresAt, func() float64 {
+		// Risk gates assume every resting bid fills
+		buyingUp := side == domain.SignalBuyUp
+		upQty, downQty, upCost, downCost := m.inventory(marketID)
+		if reason := m.RiskSvc.PreTradeCheck(buyingUp, shares, q.Price, upQty, downQty, upCost, downCost); reason != "" {
+			m.Logger.Debug("quote blocked by risk gate", "market", marketID, "side", side, "reason", reason)
+			return 0
+		}
+		if reason := m.RiskSvc.PortfolioCheck(m.PositionSvc.GetTotalExposure()+m.Orders.PendingExposure(), buyingUp, shares, q.Price, upQty, downQty, upCost, downCost); reason != "" {
+			m.Logger.Debug("quote blocked by portfolio budget", "market", marketID, "side", side, "reason", reason)
+			return 0
+		}
+		return shares * q.Price
+	})
+	if acted {
+		// An order left on target is no execution; counting it would
+		// dilute the breaker's error rate
+		m.RiskSvc.RecordExecution(err)
+	}
+	if err != nil {
+		return fmt.Errorf("market maker: %w", err)
+	}
+	if result.OrderID == "" {
+		return nil
+	}
+	m.Logger.Info("quote resting",
+		"market", marketID,
+		"side", side,
+		"price", q.Price,
+		"shares", shares,
+		"edge", q.Edge,
+		"order_id", result.OrderID,
+	)
+	return nil
+}
+
+// flatten buys the light side at the ask for the unpaired shares. Orders
+// already pending count, so repeated evaluations don't buy twice. Imbalance
+// below the exchange minimum order size is left as is.
+func (m *MarketMaker) flatten(ctx context.Context, marketID domain.MarketID, quote *domain.MarketQuote) error {
+	upQty, downQty, _, _ := m.inventory(marketID)
+	shares := math.Floor(math.Abs(upQty - downQty))
+	if shares <= 0 || shares < m.RiskSvc.Config.MinTradeShares {
+		return nil
+	}
+
+	side, price := domain.SignalBuyDown, quote.Down.Ask
+	if downQty > upQty {
+		side, price = domain.SignalBuyUp, quote.Up.Ask
+	}
+	if price <= 0 || price >= 1 {
+		return nil
+	}
+
+	m.Logger.Info("flattening",
+		"market", marketID,
+		"side", side,
+		"shares", shares,
+		"price", price,
+		"up_qty", upQty,
+		"down_qty", downQty,
+	)
+
+	req := domain.ExecutionRequest{
+		MarketID: marketID,
+		Side:     side,
+		MaxPrice: price,
+		SizeUSD:  shares * price,
+		Reason:   "flatten",
+	}
+	result, err := m.ExecSvc.Execute(ctx, req)
+	m.RiskSvc.RecordExecution(err)
+	if errors.Is(err, service.ErrNotFilled) {
+		// The book moved before the order arrived; the next tick re-evaluates
+		m.Logger.Info("flatten not filled", "market", marketID, "side", side, "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("flatten: %w", err)
+	}
+
+	// In paper mode (Filled=true, no OrderID), record position immediately.
+	if result.Filled && result.OrderID == "" {
+		fillPrice := price
+		if result.Price > 0 {
+			fillPrice = result.Price
+		}
+		fill := domain.Fill{
+			MarketID:  marketID,
+			Side:      side,
+			Price:     fillPrice,
+			SizeUSD:   req.SizeUSD,
+			Timestamp: m.Clock.Now(),
+		}
+		_ = m.EventRepo.SaveFill(ctx, fill)
+		return m.PositionSvc.AccumulateFill(ctx, fill)
+	}
+	m.Orders.Track(req, result)
+	return nil
+}
+
+// inventory returns booked inventory plus pending orders, resting bids
+// included.
+func (m *MarketMaker) inventory(marketID domain.MarketID) (upQty, downQty, upCost, downCost float64) {
+	return service.InventoryWithPending(m.PositionSvc, m.Orders, marketID)
+}
+
+func (m *MarketMaker) tradeCutoffSecs() float64 {
+	if m.TradeCutoffSecs > 0 {
+		return m.TradeCutoffSecs
+	}
+	return m.RiskSvc.Config.NoNewTradeCutoffSecs
+}
+
+// pull cancels the market's resting bids.
+func (m *MarketMaker) pull(ctx context.Context, marketID domain.MarketID, reason string) {
+	m.Maker.Pull(ctx, marketID, reason)
+}
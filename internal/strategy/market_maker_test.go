@@ -0,0 +1,192 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"Polybot/internal/domain"
+	"Polybot/internal/infra/storage"
+	"Polybot/internal/ports"
+	"Polybot/internal/service"
+)
+
+type stubPricing struct{ fv domain.FairValue }
+
+func (s *stubPricing) FairProbUp(context.Context, domain.PricingInput) (domain.FairValue, error) {
+	return s.fv, nil
+}
+
+// stubVenue records limit buys, cancels and taker orders. Taker buys fail
+// with takeErr when it is set.
+type stubVenue struct {
+	limits    []string // "up@0.56"
+	cancelled []string
+	taken     []string // "down $6.00"
+	takeErr   error
+	limitErr  map[domain.PositionSide]error
+}
+
+func (v *stubVenue) LimitBuy(_ context.Context, _ domain.MarketID, side domain.PositionSide, price, _ float64, _ time.Time) (ports.OrderResult, error) {
+	if err := v.limitErr[side]; err != nil {
+		return ports.OrderResult{}, err
+	}
+	v.limits = append(v.limits, fmt.Sprintf("%s@%.2f", side, price))
+	return ports.OrderResult{OrderID: fmt.Sprintf("o%d", len(v.limits))}, nil
+}
+
+func (v *stubVenue) CancelOrder(_ context.Context, orderID string) error {
+	v.cancelled = append(v.cancelled, orderID)
+	return nil
+}
+
+func (v *stubVenue) BuyUp(_ context.Context, _ domain.MarketID, _, sizeUSD float64) (ports.OrderResult, error) {
+	if v.takeErr != nil {
+		return ports.OrderResult{}, v.takeErr
+	}
+	v.taken = append(v.taken, fmt.Sprintf("up $%.2f", sizeUSD))
+	return ports.OrderResult{OrderID: "t1"}, nil
+}
+
+func (v *stubVenue) BuyDown(_ context.Context, _ domain.MarketID, _, sizeUSD float64) (ports.OrderResult, error) {
+	if v.takeErr != nil {
+		return ports.OrderResult{}, v.takeErr
+	}
+	v.taken = append(v.taken, fmt.Sprintf("down $%.2f", sizeUSD))
+	return ports.OrderResult{OrderID: "t1"}, nil
+}
+
+func (v *stubVenue) Sell(_ context.Context, _ domain.MarketID, side domain.PositionSide, shares, minPrice float64) (ports.OrderResult, error) {
+	v.taken = append(v.taken, fmt.Sprintf("sell %s %.0f@%.2f", side, shares, minPrice))
+	return ports.OrderResult{OrderID: "t1"}, nil
+}
+
+func newTestMarketMaker(t *testing.T) (*MarketMaker, *stubVenue, *mockClock, *domain.BinaryMarket) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := &mockClock{now: now}
+	venue := &stubVenue{}
+	orders := service.NewOrderManager(service.OrderManagerConfig{}, clock, testLogger())
+	mm := NewMarketMaker(
+		&stubPricing{fv: domain.FairValue{ProbUp: 0.60, ModelRegime: "normal"}},
+		service.NewQuoter(service.QuoterConfig{}, service.ImbalancePenaltyConfig{}),
+		service.NewMakerService(venue, orders, service.MakerConfig{}, testLogger()),
+		service.NewRiskService(service.RiskConfig{NoNewTradeCutoffSecs: 30, MinTradeShares: 5}),
+		service.NewExecutionService(venue),
+		service.NewPositionService(storage.NewInMemoryPositionRepo()),
+		orders,
+		storage.NewInMemoryEventRepo(testLogger()),
+		clock,
+		FreshnessConfig{},
+		MarketMakerConfig{QuoteShares: 10, FlattenSecs: 30},
+		testLogger(),
+	)
+	market := &domain.BinaryMarket{ID: "m1", EndTime: now.Add(5 * time.Minute)}
+	return mm, venue, clock, market
+}
+
+func TestMarketMaker_EvaluateMarket(t *testing.T) {
+	ctx := context.Background()
+	book := func(now time.Time) *domain.MarketState {
+		return &domain.MarketState{UpBid: 0.50, UpAsk: 0.62, DownBid: 0.30, DownAsk: 0.42, Timestamp: now}
+	}
+
+	t.Run("quotes_both_sides_and_keeps_them_on_target", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		for i := 0; i < 2; i++ {
+			if err := mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// half 0.02, no cost on resting bids: UP 0.58, DOWN 0.38
+		if len(venue.limits) != 2 || venue.limits[0] != "up@0.58" || venue.limits[1] != "down@0.38" {
+			t.Errorf("unexpected limit orders %v", venue.limits)
+		}
+		if len(venue.cancelled) != 0 {
+			t.Errorf("expected quotes kept, got cancels %v", venue.cancelled)
+		}
+	})
+
+	t.Run("kept_quotes_are_not_executions", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		mm.RiskSvc.Breaker = service.NewCircuitBreaker(service.BreakerConfig{MaxErrorRate: 0.9, ErrorWindow: 4},
+			storage.NewInMemoryEventRepo(testLogger()), clock, testLogger())
+		venue.limitErr = map[domain.PositionSide]error{domain.PositionDown: errors.New("rejected")}
+
+		// UP rests on target while every DOWN bid is rejected
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		for i := 0; i < 4; i++ {
+			_ = mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000)
+		}
+		if halted, _ := mm.RiskSvc.TradingHalted(); !halted {
+			t.Error("expected the rejected bids to trip the breaker")
+		}
+	})
+
+	t.Run("jump_pulls_quotes", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		_ = mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000)
+		ref.JumpScore = 8
+		_ = mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000)
+		if len(venue.cancelled) != 2 {
+			t.Errorf("expected both quotes pulled, got %v", venue.cancelled)
+		}
+	})
+
+	t.Run("flatten_completes_pairs_before_cutoff", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		_ = mm.PositionSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 10})
+		_ = mm.PositionSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyDown, Price: 0.4, SizeUSD: 2})
+
+		clock.now = market.EndTime.Add(-45 * time.Second)
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		for i := 0; i < 2; i++ {
+			_ = mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000)
+		}
+		// 20 UP vs 5 DOWN: buy 15 DOWN at the 0.42 ask, once
+		if len(venue.taken) != 1 || venue.taken[0] != "down $6.30" {
+			t.Errorf("unexpected flatten trades %v", venue.taken)
+		}
+		if len(venue.limits) != 0 {
+			t.Errorf("expected no quotes while flattening, got %v", venue.limits)
+		}
+	})
+
+	t.Run("flattens_while_halted", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		mm.RiskSvc.Breaker = service.NewCircuitBreaker(service.BreakerConfig{}, storage.NewInMemoryEventRepo(testLogger()), clock, testLogger())
+		mm.RiskSvc.Breaker.Trip(service.TripPositionDrift, "test")
+		_ = mm.PositionSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 10})
+
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		_ = mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000)
+		if len(venue.limits) != 0 {
+			t.Errorf("expected no quotes while halted, got %v", venue.limits)
+		}
+
+		clock.now = market.EndTime.Add(-45 * time.Second)
+		ref.LastUpdate = clock.now
+		if err := mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000); err != nil {
+			t.Fatal(err)
+		}
+		if len(venue.taken) != 1 || venue.taken[0] != "down $8.40" {
+			t.Errorf("expected flatten despite halt, got %v", venue.taken)
+		}
+	})
+
+	t.Run("flatten_not_filled_waits_for_next_tick", func(t *testing.T) {
+		mm, venue, clock, market := newTestMarketMaker(t)
+		venue.takeErr = fmt.Errorf("buy down: %w", service.ErrNotFilled)
+		_ = mm.PositionSvc.AccumulateFill(ctx, domain.Fill{MarketID: "m1", Side: domain.SignalBuyUp, Price: 0.5, SizeUSD: 10})
+
+		clock.now = market.EndTime.Add(-45 * time.Second)
+		ref := &domain.ReferenceState{CurrentPrice: 100, LastUpdate: clock.now}
+		if err := mm.EvaluateMarket(ctx, market, ref, book(clock.now), 1000); err != nil {
+			t.Errorf("expected no error on a no-fill, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package strategy
+
+import (
+	"context"
+
+	"Polybot/internal/domain"
+)
+
+// Strategy is evaluated by a market stream on every reprice event.
+type Strategy interface {
+	// EvaluateMarket prices the market and places, replaces or pulls orders.
+	EvaluateMarket(
+		ctx context.Context,
+		market *domain.BinaryMarket,
+		refState *domain.ReferenceState,
+		mktState *domain.MarketState,
+		bankrollUSD float64,
+	) error
+
+	// CloseMarket is called once when a market window expires.
+	CloseMarket(ctx context.Context, marketID domain.MarketID)
+}